@@ -2,9 +2,12 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
 	"os"
 	"os/signal"
-	"strconv"
 	"syscall"
 	"time"
 
@@ -18,13 +21,25 @@ import (
 )
 
 func main() {
-	setupLogger()
+	selfTest := flag.Bool("selftest", false, "run one synthetic tool call through policy, audit and forwarding, then exit")
+	flag.Parse()
 
-	log.Info().Msg("starting AI Governance Sidecar")
+	setupLogger()
 
 	ctx, cancel := setupSignalHandler()
 	defer cancel()
 
+	if *selfTest {
+		log.Info().Msg("running AI Governance Sidecar self-test")
+		if err := runSelfTest(ctx, server.LoadConfig()); err != nil {
+			log.Fatal().Err(err).Msg("self-test failed")
+		}
+		log.Info().Msg("self-test passed")
+		return
+	}
+
+	log.Info().Msg("starting AI Governance Sidecar")
+
 	if err := run(ctx); err != nil {
 		log.Fatal().Err(err).Msg("application error")
 	}
@@ -33,60 +48,125 @@ func main() {
 }
 
 func run(ctx context.Context) error {
-	auditStore, err := initAuditStore()
+	cfg := server.LoadConfig()
+
+	c, err := startComponents(cfg)
 	if err != nil {
 		return err
 	}
-	defer func() {
-		if err := auditStore.Close(); err != nil {
-			log.Warn().Err(err).Msg("failed to close audit store")
-		}
-	}()
+	defer c.close()
+
+	srv := server.New(cfg, c.policy, c.audit, c.approval, c.auth)
 
-	policyEngine, err := initPolicyEngine()
+	return runServer(ctx, srv)
+}
+
+// components are the parts of the sidecar behind the HTTP server
+type components struct {
+	audit    audit.Store
+	policy   policy.Evaluator
+	approval approval.Queue
+	auth     *auth.Manager
+}
+
+// startComponents validates cfg and opens every component. On error the
+// ones already opened are closed again.
+func startComponents(cfg server.Config) (*components, error) {
+	if err := validateConfig(cfg); err != nil {
+		return nil, err
+	}
+
+	c := &components{}
+
+	auditStore, err := initAuditStore(cfg)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer func() {
-		if err := policyEngine.Close(); err != nil {
-			log.Warn().Err(err).Msg("failed to close policy engine")
-		}
-	}()
+	c.audit = auditStore
 
-	approvalQueue := initApprovalQueue()
-	defer func() {
-		if err := approvalQueue.Close(); err != nil {
+	policyEngine, err := initPolicyEngine(cfg, auditStore)
+	if err != nil {
+		c.close()
+		return nil, err
+	}
+	c.policy = policyEngine
+
+	c.approval = initApprovalQueue(cfg)
+
+	authManager, err := initAuthManager(cfg)
+	if err != nil {
+		c.close()
+		return nil, err
+	}
+	c.auth = authManager
+
+	return c, nil
+}
+
+func (c *components) close() {
+	if c.approval != nil {
+		if err := c.approval.Close(); err != nil {
 			log.Warn().Err(err).Msg("failed to close approval queue")
 		}
-	}()
+	}
+	if c.policy != nil {
+		if err := c.policy.Close(); err != nil {
+			log.Warn().Err(err).Msg("failed to close policy engine")
+		}
+	}
+	if err := c.audit.Close(); err != nil {
+		log.Warn().Err(err).Msg("failed to close audit store")
+	}
+}
 
-	authManager := initAuthManager()
+func validateConfig(cfg server.Config) error {
+	if err := cfg.EnvError(); err != nil {
+		return err
+	}
+	if err := cfg.ProxyConfig.Validate(); err != nil {
+		return err
+	}
+	if cfg.AuditSigningKeyFile != "" {
+		if _, err := audit.LoadSigningKey(cfg.AuditSigningKeyFile); err != nil {
+			return err
+		}
+	}
+	if cfg.AuditSignEntries && cfg.AuditSigningKeyFile == "" {
+		// A per-process key would leave rows unverifiable after a restart
+		return errors.New("AUDIT_SIGN_ENTRIES requires AUDIT_SIGNING_KEY_FILE")
+	}
+	if _, err := cfg.TLSConfig(); err != nil {
+		return err
+	}
+	return nil
+}
 
-	cfg := server.LoadConfig()
-	srv := server.New(cfg, policyEngine, auditStore, approvalQueue, authManager)
+// Initialize auth manager. A configured users file that cannot be read
+// stops startup rather than leaving logins to AUTH_USERS or the default admin.
+func initAuthManager(cfg server.Config) (*auth.Manager, error) {
+	log.Info().Bool("required", cfg.AuthConfig.RequireAuth).Msg("initializing auth manager")
 
-	return runServer(ctx, srv)
-}
+	manager := auth.NewManager(cfg.AuthConfig)
+	if cfg.AuthConfig.UsersFile != "" {
+		if _, err := manager.ReloadUsers(); err != nil {
+			return nil, fmt.Errorf("AUTH_USERS_FILE: %w", err)
+		}
+	}
 
-// Initialize auth manager
-func initAuthManager() *auth.Manager {
-	requireAuth := getEnv("REQUIRE_AUTH", "false") == "true"
-	
-	log.Info().Bool("required", requireAuth).Msg("initializing auth manager")
-	
-	manager := auth.NewManager(auth.Config{
-		JWTSecret:       os.Getenv("JWT_SECRET"),
-		TokenExpiration: 24 * time.Hour,
-		RequireAuth:     requireAuth,
-	})
-	
 	log.Info().Msg("auth manager initialized")
-	return manager
+	return manager, nil
 }
 
+// setupLogger writes pretty console logs by default; LOG_FORMAT=json emits
+// structured JSON to stdout for log aggregation
 func setupLogger() {
-	zerolog.TimeFieldFormat = zerolog.TimeFormatUnix
-	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr, TimeFormat: time.RFC3339})
+	format := getEnv("LOG_FORMAT", "console")
+	if format == "json" {
+		zerolog.TimeFieldFormat = time.RFC3339
+	} else {
+		zerolog.TimeFieldFormat = zerolog.TimeFormatUnix
+	}
+	log.Logger = log.Output(logWriter(format))
 
 	level, err := zerolog.ParseLevel(getEnv("LOG_LEVEL", "info"))
 	if err != nil {
@@ -95,6 +175,13 @@ func setupLogger() {
 	zerolog.SetGlobalLevel(level)
 }
 
+func logWriter(format string) io.Writer {
+	if format == "json" {
+		return os.Stdout
+	}
+	return zerolog.ConsoleWriter{Out: os.Stderr, TimeFormat: time.RFC3339}
+}
+
 func setupSignalHandler() (context.Context, context.CancelFunc) {
 	ctx, cancel := context.WithCancel(context.Background())
 
@@ -110,41 +197,111 @@ func setupSignalHandler() (context.Context, context.CancelFunc) {
 	return ctx, cancel
 }
 
-func initAuditStore() (audit.Store, error) {
-	dbPath := getEnv("DB_PATH", "./db/audit.db")
-	
-	log.Info().Str("path", dbPath).Msg("initializing audit store")
-	
-	store, err := audit.NewSQLiteStore(dbPath)
+const auditRetryInterval = 10 * time.Second
+
+func initAuditStore(cfg server.Config) (audit.Store, error) {
+	log.Info().Str("path", cfg.DBPath).Msg("initializing audit store")
+
+	open := func() (audit.Store, error) {
+		store, err := audit.NewSQLiteStore(cfg.DBPath)
+		if err != nil {
+			return nil, err
+		}
+
+		if cfg.AuditSignEntries {
+			key, err := audit.LoadSigningKey(cfg.AuditSigningKeyFile)
+			if err != nil {
+				store.Close()
+				return nil, err
+			}
+			store.SignEntries(key)
+		}
+		store.SetMaxToolInput(cfg.AuditMaxToolInput)
+		return store, nil
+	}
+
+	store, err := open()
 	if err != nil {
-		return nil, err
+		if cfg.AuditRequired {
+			return nil, err
+		}
+
+		log.Error().Err(err).Msg("audit store unavailable, starting degraded (AUDIT_REQUIRED=false)")
+		return audit.NewDeferredStore(open, auditRetryInterval), nil
 	}
 
 	log.Info().Msg("audit store initialized")
 	return store, nil
 }
 
-func initPolicyEngine() (policy.Evaluator, error) {
-	policyDir := getEnv("POLICY_DIR", "./policies")
-	
-	log.Info().Str("dir", policyDir).Msg("initializing policy engine")
-	
-	engine, err := policy.NewEngine(policyDir)
+func initPolicyEngine(cfg server.Config, auditStore audit.Store) (policy.Evaluator, error) {
+	if cfg.PolicyConfig.BundleURL != "" {
+		log.Info().Str("bundle", cfg.PolicyConfig.BundleURL).Msg("initializing policy engine")
+	} else {
+		log.Info().Str("dir", cfg.PolicyConfig.Dir).Msg("initializing policy engine")
+	}
+
+	engine, err := policy.NewEngine(cfg.PolicyConfig)
 	if err != nil {
 		return nil, err
 	}
 
+	if historian, ok := auditStore.(audit.DecisionHistorian); ok {
+		engine.SetHistory(historian)
+	}
+
 	log.Info().Msg("policy engine initialized")
 	return engine, nil
 }
 
-func initApprovalQueue() approval.Queue {
-	timeoutSec := getEnvInt("APPROVAL_TIMEOUT", 300)
-	timeout := time.Duration(timeoutSec) * time.Second
-	
+func initApprovalQueue(cfg server.Config) approval.Queue {
+	timeout := time.Duration(cfg.ApprovalTimeout) * time.Second
+
 	log.Info().Dur("timeout", timeout).Msg("initializing approval queue")
-	
+
 	queue := approval.NewInMemoryQueue(timeout)
+
+	if cfg.ApprovalSLA > 0 {
+		sla := time.Duration(cfg.ApprovalSLA) * time.Second
+		queue.SetSLA(sla)
+		log.Info().Dur("sla", sla).Msg("approval SLA tracking enabled")
+	}
+
+	if cfg.ApprovalMaxPendingAge > 0 {
+		maxAge := time.Duration(cfg.ApprovalMaxPendingAge) * time.Second
+		queue.SetMaxPendingAge(maxAge)
+		log.Info().Dur("max_age", maxAge).Msg("approval auto-deny sweeper enabled")
+	}
+
+	if cfg.ApprovalDedup {
+		queue.SetDeduplicate(true)
+		log.Info().Msg("identical pending approval requests will be merged")
+	}
+
+	if cfg.ApprovalWebhookURL != "" {
+		webhookTimeout := time.Duration(cfg.ApprovalWebhookTimeout) * time.Second
+		queue.SetDecider(approval.NewWebhookDecider(cfg.ApprovalWebhookURL, webhookTimeout))
+		log.Info().Dur("timeout", webhookTimeout).Msg("approval decision webhook enabled")
+	}
+
+	if cfg.ApprovalNotifyURL != "" && cfg.ApprovalNotifyMode == approval.NotifyModeDigest {
+		interval := time.Duration(cfg.ApprovalDigestInterval) * time.Second
+		queue.SetNotifier(approval.NewDigestNotifier(
+			cfg.ApprovalNotifyURL,
+			time.Duration(cfg.ApprovalWebhookTimeout)*time.Second,
+			interval,
+			queue.GetPending,
+		))
+		log.Info().Dur("interval", interval).Msg("approval notification digest enabled")
+	} else if cfg.ApprovalNotifyURL != "" {
+		queue.SetNotifier(approval.NewWebhookNotifier(
+			cfg.ApprovalNotifyURL,
+			time.Duration(cfg.ApprovalWebhookTimeout)*time.Second,
+			cfg.ApprovalNotifyAttempts,
+			time.Duration(cfg.ApprovalNotifyBackoff)*time.Second,
+		))
+		log.Info().Int("attempts", cfg.ApprovalNotifyAttempts).Msg("approval notification webhook enabled")
+	}
 	
 	log.Info().Msg("approval queue initialized")
 	return queue
@@ -173,12 +330,3 @@ func getEnv(key, fallback string) string {
 	}
 	return fallback
 }
-
-func getEnvInt(key string, fallback int) int {
-	if value := os.Getenv(key); value != "" {
-		if intVal, err := strconv.Atoi(value); err == nil {
-			return intVal
-		}
-	}
-	return fallback
-}
\ No newline at end of file
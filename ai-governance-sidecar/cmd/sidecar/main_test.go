@@ -0,0 +1,37 @@
+package main
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/server"
+	"github.com/rs/zerolog"
+)
+
+func TestSetupLoggerFormat(t *testing.T) {
+	defer func(format string) { zerolog.TimeFieldFormat = format }(zerolog.TimeFieldFormat)
+
+	t.Setenv("LOG_FORMAT", "json")
+	setupLogger()
+	if zerolog.TimeFieldFormat != time.RFC3339 {
+		t.Errorf("expected RFC3339 timestamps in json mode, got %q", zerolog.TimeFieldFormat)
+	}
+	if w := logWriter("json"); w != os.Stdout {
+		t.Errorf("expected json logs on stdout, got %T", w)
+	}
+
+	t.Setenv("LOG_FORMAT", "")
+	setupLogger()
+	if _, ok := logWriter(getEnv("LOG_FORMAT", "console")).(zerolog.ConsoleWriter); !ok {
+		t.Error("expected console writer by default")
+	}
+}
+
+func TestValidateConfigRejectsUnparsableSettings(t *testing.T) {
+	t.Setenv("APPROVER_SCOPES", "{not json")
+
+	if err := validateConfig(server.LoadConfig()); err == nil {
+		t.Error("expected startup to fail on invalid APPROVER_SCOPES")
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/audit"
+	"github.com/dagbolade/ai-governance-sidecar/internal/auth"
+	"github.com/dagbolade/ai-governance-sidecar/internal/server"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// selfTestTimeout bounds the synthetic call, including any approval wait
+const selfTestTimeout = 30 * time.Second
+
+// runSelfTest checks the deployed configuration end to end without binding
+// the public port: one synthetic call is sent through auth, policy and
+// audit to a stub upstream on loopback. SELFTEST_TOOL and SELFTEST_ARGS
+// should name a call the loaded policies allow outright.
+func runSelfTest(ctx context.Context, cfg server.Config) error {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return fmt.Errorf("start stub upstream: %w", err)
+	}
+	upstream := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			w.Write([]byte(`{"selftest":"ok"}`))
+		}),
+		ReadHeaderTimeout: selfTestTimeout,
+	}
+	go upstream.Serve(listener)
+	defer upstream.Close()
+
+	return selfTest(ctx, cfg, "http://"+listener.Addr().String())
+}
+
+// selfTest boots every component with tool calls sent to upstreamURL and
+// fails unless the synthetic call is forwarded and audited as allowed
+func selfTest(ctx context.Context, cfg server.Config, upstreamURL string) error {
+	cfg.ProxyConfig.DefaultUpstream = upstreamURL
+	cfg.ProxyConfig.UpstreamRoutes = nil
+	cfg.ProxyConfig.GRPCRoutes = nil
+
+	c, err := startComponents(cfg)
+	if err != nil {
+		return err
+	}
+	defer c.close()
+
+	srv := server.New(cfg, c.policy, c.audit, c.approval, c.auth)
+
+	ctx, cancel := context.WithTimeout(ctx, selfTestTimeout)
+	defer cancel()
+
+	lastID, err := lastAuditID(ctx, c.audit)
+	if err != nil {
+		return fmt.Errorf("read audit log: %w", err)
+	}
+
+	tool := getEnv("SELFTEST_TOOL", "selftest")
+	body, err := json.Marshal(map[string]any{
+		"tool_name": tool,
+		"args":      json.RawMessage(getEnv("SELFTEST_ARGS", "{}")),
+	})
+	if err != nil {
+		return fmt.Errorf("SELFTEST_ARGS must be a JSON object: %w", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/tool/call", bytes.NewReader(body)).WithContext(ctx)
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if cfg.AuthConfig.RequireAuth {
+		token, err := c.auth.GenerateToken(auth.User{
+			ID:    "selftest",
+			Name:  "Self-test",
+			Roles: append([]string{auth.RoleViewer}, cfg.AuthConfig.AllowedRoles...),
+		})
+		if err != nil {
+			return fmt.Errorf("issue self-test token: %w", err)
+		}
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+	}
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		return fmt.Errorf("synthetic call to %q returned %d: %s", tool, rec.Code, bytes.TrimSpace(rec.Body.Bytes()))
+	}
+
+	if err := checkSelfTestAudit(ctx, c.audit, lastID, tool); err != nil {
+		return err
+	}
+
+	log.Info().Str("tool", tool).Msg("synthetic call allowed, forwarded and audited")
+	return nil
+}
+
+func lastAuditID(ctx context.Context, store audit.Store) (int64, error) {
+	entries, err := store.GetAll(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var last int64
+	for _, entry := range entries {
+		last = max(last, entry.ID)
+	}
+	return last, nil
+}
+
+// checkSelfTestAudit looks for an allow entry for tool written after afterID
+func checkSelfTestAudit(ctx context.Context, store audit.Store, afterID int64, tool string) error {
+	entries, err := store.GetAll(ctx)
+	if err != nil {
+		return fmt.Errorf("read audit log: %w", err)
+	}
+
+	for _, entry := range entries {
+		if entry.ID <= afterID || entry.Decision != audit.DecisionAllow {
+			continue
+		}
+		var input struct {
+			ToolName string `json:"tool_name"`
+		}
+		if json.Unmarshal(entry.ToolInput, &input) == nil && input.ToolName == tool {
+			return nil
+		}
+	}
+	return fmt.Errorf("no audit entry was written for the synthetic call to %q", tool)
+}
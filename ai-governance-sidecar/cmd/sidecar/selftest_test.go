@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bytecodealliance/wasmtime-go/v3"
+	"github.com/dagbolade/ai-governance-sidecar/internal/server"
+)
+
+// fixedPolicyWAT answers every evaluation with the given JSON response
+const fixedPolicyWAT = `
+(module
+  (memory (export "memory") 4)
+  (data (i32.const 0) %q)
+  (global $next (mut i32) (i32.const 1024))
+  (func (export "allocate") (param $size i32) (result i32)
+    (local $ptr i32)
+    (local.set $ptr (global.get $next))
+    (global.set $next (i32.add (global.get $next) (local.get $size)))
+    (local.get $ptr))
+  (func (export "evaluate") (param $in i32) (param $len i32) (param $out i32) (param $max i32) (result i32)
+    (memory.copy (local.get $out) (i32.const 0) (i32.const %d))
+    (i32.const 0)))
+`
+
+// selfTestConfig points every component at a fresh temp dir holding one
+// policy that returns response
+func selfTestConfig(t *testing.T, response string) server.Config {
+	t.Helper()
+
+	dir := t.TempDir()
+	policies := filepath.Join(dir, "policies")
+	if err := os.Mkdir(policies, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	wasm, err := wasmtime.Wat2Wasm(fmt.Sprintf(fixedPolicyWAT, response, len(response)))
+	if err != nil {
+		t.Fatalf("compile wat: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(policies, "fixed.wasm"), wasm, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("POLICY_DIR", policies)
+	t.Setenv("DB_PATH", filepath.Join(dir, "audit.db"))
+	t.Setenv("POLICY_COUNTER_DB", filepath.Join(dir, "counters.db"))
+	t.Setenv("TOOL_UPSTREAM", "http://localhost:9000")
+	t.Setenv("SELFTEST_TOOL", "deploy_check")
+	return server.LoadConfig()
+}
+
+func TestSelfTest(t *testing.T) {
+	tests := []struct {
+		name        string
+		response    string
+		expectError string
+		forwarded   bool
+	}{
+		{name: "allowed", response: `{"allow":true,"reason":"ok"}`, forwarded: true},
+		{name: "denied", response: `{"allow":false,"reason":"blocked"}`, expectError: "returned 403"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var forwarded string
+			upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				forwarded = string(body)
+				w.Write([]byte(`{"ok":true}`))
+			}))
+			defer upstream.Close()
+
+			err := selfTest(context.Background(), selfTestConfig(t, tt.response), upstream.URL)
+			if tt.expectError == "" && err != nil {
+				t.Fatalf("expected self-test to pass, got %v", err)
+			}
+			if tt.expectError != "" && (err == nil || !strings.Contains(err.Error(), tt.expectError)) {
+				t.Fatalf("expected error containing %q, got %v", tt.expectError, err)
+			}
+
+			if tt.forwarded != strings.Contains(forwarded, `"deploy_check"`) {
+				t.Errorf("expected forwarded=%v, got upstream body %q", tt.forwarded, forwarded)
+			}
+		})
+	}
+}
+
+func TestRunSelfTestUsesLoopbackStub(t *testing.T) {
+	cfg := selfTestConfig(t, `{"allow":true,"reason":"ok"}`)
+
+	if err := runSelfTest(context.Background(), cfg); err != nil {
+		t.Fatalf("expected self-test to pass against the built-in stub, got %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package approval
+
+import (
+	"context"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/auth"
+	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
+)
+
+// SetDeduplicate makes an Enqueue from the same caller whose tool,
+// arguments, metadata and files match a request still pending wait on that
+// request instead of queueing another, so one decision answers every
+// identical call. Off by default, since some
+// workflows want each call reviewed on its own.
+func (q *InMemoryQueue) SetDeduplicate(enabled bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.dedup = enabled
+}
+
+// dedupKey identifies a request by the same key approval grants use: the
+// authenticated caller and tenant on ctx, tool, canonical args, metadata
+// (including upstream and method) and files. Calls from different callers
+// or to different upstreams are never merged.
+func dedupKey(ctx context.Context, req policy.Request) string {
+	var caller, tenant string
+	if user, ok := auth.GetUserFromStdContext(ctx); ok && user != nil {
+		caller, tenant = user.ID, user.Tenant
+	}
+	return req.DecisionKey(caller, tenant)
+}
+
+// join attaches w to the pending request registered under key and returns
+// its id. It fails once that request has been claimed by a decision or its
+// last caller has left. Callers hold q.mu.
+func (q *InMemoryQueue) join(ctx context.Context, key string, w *waiter) (string, bool) {
+	if !q.dedup {
+		return "", false
+	}
+	id, ok := q.dedupKeys[key]
+	if !ok || len(q.waiters[id]) == 0 {
+		return "", false
+	}
+
+	if _, err := q.store.Update(ctx, id, func(r *Request) { r.Duplicates++ }); err != nil {
+		return "", false
+	}
+	q.waiters[id] = append(q.waiters[id], w)
+	return id, true
+}
+
+// forget stops new callers joining req once it has left the pending store
+func (q *InMemoryQueue) forget(req Request) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for key, id := range q.dedupKeys {
+		if id == req.ID {
+			delete(q.dedupKeys, key)
+			return
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package approval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/clock"
+	"github.com/rs/zerolog/log"
+)
+
+// Notification strategies for APPROVAL_NOTIFY_MODE
+const (
+	NotifyModeEvent  = "event"
+	NotifyModeDigest = "digest"
+)
+
+// PendingLister returns the requests still waiting for a decision
+type PendingLister func(ctx context.Context) ([]Request, error)
+
+// DigestNotifier batches new approval requests into one summary per
+// interval, such as "5 approvals pending, oldest 12m", instead of posting
+// each one. An interval with no new requests sends nothing. A digest that
+// fails to send is retried at the next interval.
+type DigestNotifier struct {
+	url      string
+	client   *http.Client
+	interval time.Duration
+	pending  PendingLister
+	clock    clock.Clock
+
+	mu    sync.Mutex
+	fresh int
+
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+type digest struct {
+	Event             string        `json:"event"`
+	Summary           string        `json:"summary"`
+	Pending           int           `json:"pending"`
+	New               int           `json:"new"`
+	OldestCreatedAt   time.Time     `json:"oldest_created_at"`
+	OldestWaitSeconds float64       `json:"oldest_wait_seconds"`
+	Requests          []digestEntry `json:"requests"`
+}
+
+type digestEntry struct {
+	ID        string    `json:"id"`
+	ToolName  string    `json:"tool_name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewDigestNotifier starts a worker that posts a digest of pending
+// approvals, read from pending, to url every interval
+func NewDigestNotifier(url string, timeout, interval time.Duration, pending PendingLister) *DigestNotifier {
+	return newDigestNotifier(url, timeout, interval, pending, clock.Real{})
+}
+
+func newDigestNotifier(url string, timeout, interval time.Duration, pending PendingLister, clk clock.Clock) *DigestNotifier {
+	n := &DigestNotifier{
+		url:      url,
+		client:   &http.Client{Timeout: timeout},
+		interval: interval,
+		pending:  pending,
+		clock:    clk,
+		done:     make(chan struct{}),
+	}
+
+	n.wg.Add(1)
+	go n.run()
+	return n
+}
+
+// Notify counts req toward the next digest
+func (n *DigestNotifier) Notify(req Request) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.fresh++
+}
+
+// Close stops the worker without sending a final digest
+func (n *DigestNotifier) Close() error {
+	n.closeOnce.Do(func() { close(n.done) })
+	n.wg.Wait()
+	return nil
+}
+
+func (n *DigestNotifier) run() {
+	defer n.wg.Done()
+
+	for {
+		select {
+		case <-n.clock.After(n.interval):
+			n.flush()
+		case <-n.done:
+			return
+		}
+	}
+}
+
+func (n *DigestNotifier) flush() {
+	n.mu.Lock()
+	fresh := n.fresh
+	n.mu.Unlock()
+
+	if fresh == 0 {
+		return
+	}
+
+	pending, err := n.pending(context.Background())
+	if err != nil {
+		log.Warn().Err(err).Msg("approval digest failed to list pending requests")
+		return
+	}
+
+	if len(pending) > 0 {
+		if err := n.send(buildDigest(pending, fresh, n.clock.Now())); err != nil {
+			log.Warn().Err(err).Int("pending", len(pending)).Msg("approval digest failed, retrying next interval")
+			return
+		}
+	}
+
+	n.mu.Lock()
+	n.fresh -= fresh
+	n.mu.Unlock()
+}
+
+func (n *DigestNotifier) send(d digest) error {
+	payload, err := json.Marshal(d)
+	if err != nil {
+		return fmt.Errorf("marshal digest: %w", err)
+	}
+	return postNotification(n.client, n.url, payload)
+}
+
+func buildDigest(pending []Request, fresh int, now time.Time) digest {
+	d := digest{
+		Event:    "approval_digest",
+		Pending:  len(pending),
+		New:      fresh,
+		Requests: make([]digestEntry, 0, len(pending)),
+	}
+
+	oldest := pending[0].CreatedAt
+	for _, req := range pending {
+		if req.CreatedAt.Before(oldest) {
+			oldest = req.CreatedAt
+		}
+		d.Requests = append(d.Requests, digestEntry{ID: req.ID, ToolName: req.ToolName, CreatedAt: req.CreatedAt})
+	}
+
+	wait := now.Sub(oldest)
+	d.OldestCreatedAt = oldest
+	d.OldestWaitSeconds = wait.Seconds()
+
+	noun := "approvals"
+	if len(pending) == 1 {
+		noun = "approval"
+	}
+	d.Summary = fmt.Sprintf("%d %s pending, oldest %s", len(pending), noun, shortWait(wait))
+	return d
+}
+
+// shortWait formats a wait as 45s, 12m or 1h5m
+func shortWait(wait time.Duration) string {
+	if wait < time.Minute {
+		return wait.Round(time.Second).String()
+	}
+	return strings.TrimSuffix(wait.Round(time.Minute).String(), "0s")
+}
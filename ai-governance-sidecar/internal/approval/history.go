@@ -0,0 +1,85 @@
+package approval
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const defaultHistoryRequests = 10000
+
+// HistoryEntry is one recorded step in a request's lifecycle
+type HistoryEntry struct {
+	Type     EventType `json:"type"`
+	Time     time.Time `json:"time"`
+	Actor    string    `json:"actor,omitempty"`
+	Detail   string    `json:"detail,omitempty"`
+	Decision *Decision `json:"decision,omitempty"`
+}
+
+// EventLog is an append-only record of approval lifecycle events
+type EventLog interface {
+	Append(ctx context.Context, id string, entry HistoryEntry) error
+	// List returns events for id in order. Zero since/until are unbounded.
+	List(ctx context.Context, id string, since, until time.Time) ([]HistoryEntry, error)
+}
+
+// HistoryProvider is implemented by queues that keep an event history
+type HistoryProvider interface {
+	History(ctx context.Context, id string, since, until time.Time) ([]HistoryEntry, error)
+}
+
+// MemoryEventLog keeps history for the most recent requests. Once full,
+// the oldest request's history is dropped as a whole.
+type MemoryEventLog struct {
+	mu      sync.RWMutex
+	events  map[string][]HistoryEntry
+	order   []string
+	maxReqs int
+}
+
+func NewMemoryEventLog() *MemoryEventLog {
+	return &MemoryEventLog{
+		events:  make(map[string][]HistoryEntry),
+		maxReqs: defaultHistoryRequests,
+	}
+}
+
+func (l *MemoryEventLog) Append(ctx context.Context, id string, entry HistoryEntry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, exists := l.events[id]; !exists {
+		l.order = append(l.order, id)
+		if len(l.order) > l.maxReqs {
+			delete(l.events, l.order[0])
+			l.order = l.order[1:]
+		}
+	}
+
+	l.events[id] = append(l.events[id], entry)
+	return nil
+}
+
+func (l *MemoryEventLog) List(ctx context.Context, id string, since, until time.Time) ([]HistoryEntry, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	events, exists := l.events[id]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrRequestNotFound, id)
+	}
+
+	filtered := make([]HistoryEntry, 0, len(events))
+	for _, event := range events {
+		if !since.IsZero() && event.Time.Before(since) {
+			continue
+		}
+		if !until.IsZero() && event.Time.After(until) {
+			continue
+		}
+		filtered = append(filtered, event)
+	}
+	return filtered, nil
+}
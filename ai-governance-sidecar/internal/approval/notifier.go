@@ -0,0 +1,228 @@
+package approval
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	notifyQueueSize = 100
+	maxDeadLetters  = 100
+)
+
+// Notifier is told about every request queued for a human. Notify must not
+// block the caller.
+type Notifier interface {
+	Notify(req Request)
+}
+
+// DeadLetter is a notification that was never delivered
+type DeadLetter struct {
+	RequestID string    `json:"request_id"`
+	ToolName  string    `json:"tool_name"`
+	Attempts  int       `json:"attempts"`
+	Error     string    `json:"error"`
+	Time      time.Time `json:"time"`
+}
+
+// DeadLetterReporter is implemented by queues whose notifier keeps a
+// dead-letter record
+type DeadLetterReporter interface {
+	DeadLetters() []DeadLetter
+}
+
+var errNotifierClosed = errors.New("notifier closed")
+
+// WebhookNotifier posts new approval requests to a URL from a background
+// worker. Failed deliveries are retried with exponential backoff; ones that
+// exhaust their attempts are kept as dead letters.
+type WebhookNotifier struct {
+	url      string
+	client   *http.Client
+	attempts int
+	backoff  time.Duration
+
+	jobs      chan Request
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+
+	mu   sync.Mutex
+	dead []DeadLetter
+}
+
+type notification struct {
+	Event     string          `json:"event"`
+	ID        string          `json:"id"`
+	ToolName  string          `json:"tool_name"`
+	Args      json.RawMessage `json:"args"`
+	Reason    string          `json:"reason"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// NewWebhookNotifier starts a worker delivering to url. Each delivery is
+// tried up to attempts times, waiting backoff, then twice that, and so on.
+func NewWebhookNotifier(url string, timeout time.Duration, attempts int, backoff time.Duration) *WebhookNotifier {
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	n := &WebhookNotifier{
+		url:      url,
+		client:   &http.Client{Timeout: timeout},
+		attempts: attempts,
+		backoff:  backoff,
+		jobs:     make(chan Request, notifyQueueSize),
+		done:     make(chan struct{}),
+	}
+
+	n.wg.Add(1)
+	go n.run()
+	return n
+}
+
+// Notify queues req for delivery. A full queue dead-letters req right away
+// rather than delaying the approval.
+func (n *WebhookNotifier) Notify(req Request) {
+	select {
+	case <-n.done:
+		n.deadLetter(req, 0, errNotifierClosed)
+		return
+	default:
+	}
+
+	select {
+	case n.jobs <- req:
+	default:
+		n.deadLetter(req, 0, errors.New("notification queue full"))
+	}
+}
+
+// DeadLetters returns the most recent undelivered notifications
+func (n *WebhookNotifier) DeadLetters() []DeadLetter {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]DeadLetter(nil), n.dead...)
+}
+
+// Close stops the worker. Notifications still queued are dead-lettered.
+func (n *WebhookNotifier) Close() error {
+	n.closeOnce.Do(func() { close(n.done) })
+	n.wg.Wait()
+	return nil
+}
+
+func (n *WebhookNotifier) run() {
+	defer n.wg.Done()
+
+	for {
+		select {
+		case req := <-n.jobs:
+			n.deliver(req)
+		case <-n.done:
+			n.drain()
+			return
+		}
+	}
+}
+
+func (n *WebhookNotifier) drain() {
+	for {
+		select {
+		case req := <-n.jobs:
+			n.deadLetter(req, 0, errNotifierClosed)
+		default:
+			return
+		}
+	}
+}
+
+func (n *WebhookNotifier) deliver(req Request) {
+	wait := n.backoff
+
+	var err error
+	for attempt := 1; attempt <= n.attempts; attempt++ {
+		if err = n.send(req); err == nil {
+			return
+		}
+
+		if attempt == n.attempts {
+			break
+		}
+
+		log.Debug().Err(err).Str("id", req.ID).Int("attempt", attempt).Dur("retry_in", wait).
+			Msg("approval notification failed, retrying")
+
+		select {
+		case <-time.After(wait):
+			wait *= 2
+		case <-n.done:
+			n.deadLetter(req, attempt, err)
+			return
+		}
+	}
+
+	n.deadLetter(req, n.attempts, err)
+}
+
+func (n *WebhookNotifier) send(req Request) error {
+	payload, err := json.Marshal(notification{
+		Event:     "approval_requested",
+		ID:        req.ID,
+		ToolName:  req.ToolName,
+		Args:      req.Args,
+		Reason:    req.Reason,
+		CreatedAt: req.CreatedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("marshal notification: %w", err)
+	}
+	return postNotification(n.client, n.url, payload)
+}
+
+// postNotification posts a JSON payload and treats any non-2xx as failure
+func postNotification(client *http.Client, url string, payload []byte) error {
+	httpReq, err := http.NewRequestWithContext(context.Background(), http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("create notification request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("call notification webhook: %w", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("notification webhook returned %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (n *WebhookNotifier) deadLetter(req Request, attempts int, err error) {
+	log.Error().Err(err).Str("id", req.ID).Str("tool", req.ToolName).Int("attempts", attempts).
+		Msg("approval notification dead-lettered")
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.dead = append(n.dead, DeadLetter{
+		RequestID: req.ID,
+		ToolName:  req.ToolName,
+		Attempts:  attempts,
+		Error:     err.Error(),
+		Time:      time.Now(),
+	})
+	if len(n.dead) > maxDeadLetters {
+		n.dead = n.dead[len(n.dead)-maxDeadLetters:]
+	}
+}
@@ -0,0 +1,167 @@
+package approval
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/clock"
+	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
+)
+
+func TestWebhookNotifierRetriesUntilDelivered(t *testing.T) {
+	var calls atomic.Int32
+	delivered := make(chan notification, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var n notification
+		json.NewDecoder(r.Body).Decode(&n)
+		delivered <- n
+	}))
+	defer server.Close()
+
+	notifier := NewWebhookNotifier(server.URL, time.Second, 5, time.Millisecond)
+	defer notifier.Close()
+
+	notifier.Notify(Request{ID: "req-1", ToolName: "deploy", Args: json.RawMessage(`{}`)})
+
+	select {
+	case n := <-delivered:
+		if n.ID != "req-1" || n.Event != "approval_requested" {
+			t.Errorf("unexpected notification: %+v", n)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("notification was not delivered")
+	}
+
+	if calls.Load() != 3 {
+		t.Errorf("expected 3 attempts, got %d", calls.Load())
+	}
+	if dead := notifier.DeadLetters(); len(dead) != 0 {
+		t.Errorf("expected no dead letters, got %+v", dead)
+	}
+}
+
+func TestWebhookNotifierDeadLettersWithoutBlockingApprovals(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	notifier := NewWebhookNotifier(server.URL, time.Second, 3, 50*time.Millisecond)
+	queue := NewInMemoryQueue(5 * time.Second)
+	queue.SetNotifier(notifier)
+	defer queue.Close()
+
+	ctx := context.Background()
+	doneCh := make(chan Decision, 1)
+	go func() {
+		decision, _ := queue.Enqueue(ctx, policy.Request{ToolName: "deploy", Args: json.RawMessage(`{}`)}, "review")
+		doneCh <- decision
+	}()
+
+	var pending []Request
+	for i := 0; i < 50 && len(pending) == 0; i++ {
+		time.Sleep(5 * time.Millisecond)
+		pending, _ = queue.GetPending(ctx)
+	}
+	if len(pending) != 1 {
+		t.Fatalf("expected 1 pending request, got %d", len(pending))
+	}
+
+	// The approval completes while the notifier is still backing off
+	if err := queue.Decide(ctx, pending[0].ID, Decision{Approved: true, Reason: "ok"}); err != nil {
+		t.Fatalf("decide failed: %v", err)
+	}
+	select {
+	case decision := <-doneCh:
+		if !decision.Approved {
+			t.Errorf("expected approval, got %+v", decision)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("approval blocked on the notifier")
+	}
+
+	var dead []DeadLetter
+	for i := 0; i < 100 && len(dead) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+		dead = queue.DeadLetters()
+	}
+	if len(dead) != 1 {
+		t.Fatalf("expected 1 dead letter, got %+v", dead)
+	}
+	if dead[0].RequestID != pending[0].ID || dead[0].Attempts != 3 {
+		t.Errorf("unexpected dead letter: %+v", dead[0])
+	}
+	if calls.Load() != 3 {
+		t.Errorf("expected 3 attempts, got %d", calls.Load())
+	}
+}
+
+func TestDigestNotifierSummarizesOncePerInterval(t *testing.T) {
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(start)
+
+	digests := make(chan digest, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var d digest
+		json.NewDecoder(r.Body).Decode(&d)
+		digests <- d
+	}))
+	defer server.Close()
+
+	pending := []Request{
+		{ID: "req-1", ToolName: "deploy", CreatedAt: start},
+		{ID: "req-2", ToolName: "delete_user", CreatedAt: start.Add(4 * time.Minute)},
+		{ID: "req-3", ToolName: "deploy", CreatedAt: start.Add(9 * time.Minute)},
+	}
+	lister := func(ctx context.Context) ([]Request, error) { return pending, nil }
+
+	notifier := newDigestNotifier(server.URL, time.Second, 12*time.Minute, lister, fake)
+	defer notifier.Close()
+
+	for _, req := range pending {
+		notifier.Notify(req)
+	}
+
+	for i := 0; i < 100 && fake.Waiters() == 0; i++ {
+		time.Sleep(5 * time.Millisecond)
+	}
+	fake.Advance(12 * time.Minute)
+
+	select {
+	case d := <-digests:
+		if d.Event != "approval_digest" || d.Pending != 3 || d.New != 3 || len(d.Requests) != 3 {
+			t.Errorf("unexpected digest: %+v", d)
+		}
+		if d.Summary != "3 approvals pending, oldest 12m" {
+			t.Errorf("unexpected summary %q", d.Summary)
+		}
+		if !d.OldestCreatedAt.Equal(start) {
+			t.Errorf("expected oldest request at %v, got %v", start, d.OldestCreatedAt)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a digest once the interval passed")
+	}
+
+	// Nothing new arrived, so the next interval stays quiet
+	for i := 0; i < 100 && fake.Waiters() == 0; i++ {
+		time.Sleep(5 * time.Millisecond)
+	}
+	fake.Advance(12 * time.Minute)
+
+	select {
+	case d := <-digests:
+		t.Errorf("expected one digest per interval with new requests, got another: %+v", d)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -2,10 +2,11 @@ package approval
 
 import (
 	"context"
-	"fmt"
+	"io"
 	"sync"
 	"time"
 
+	"github.com/dagbolade/ai-governance-sidecar/internal/clock"
 	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
 	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
@@ -13,139 +14,410 @@ import (
 
 type InMemoryQueue struct {
 	mu       sync.RWMutex
-	pending  map[string]*Request
+	store    PendingStore
+	waiters  map[string][]*waiter
 	timeout  time.Duration
 	notifyCh chan struct{}
+	eventCh  chan Event
+	decider  AutoDecider
+	notifier Notifier
+	history  EventLog
+	sla      *slaTracker
+	clock    clock.Clock
 	closed   bool
+
+	// maxAge is the hard age the sweeper denies pending requests at
+	maxAge    time.Duration
+	stopSweep chan struct{}
+
+	// dedup merges identical pending requests; dedupKeys maps the dedupKey
+	// of each pending request to its id
+	dedup     bool
+	dedupKeys map[string]string
 }
 
 func NewInMemoryQueue(timeout time.Duration) *InMemoryQueue {
+	return NewQueueWithStore(NewMemoryStore(), timeout)
+}
+
+// NewQueueWithStore creates a queue whose pending requests live in store.
+func NewQueueWithStore(store PendingStore, timeout time.Duration) *InMemoryQueue {
 	return &InMemoryQueue{
-		pending:  make(map[string]*Request),
+		store:    store,
+		waiters:  make(map[string][]*waiter),
 		timeout:  timeout,
 		notifyCh: make(chan struct{}, 100),
+		eventCh:  make(chan Event, 100),
+		history:  NewMemoryEventLog(),
+		sla:      newSLATracker(),
+		clock:    clock.Real{},
+
+		dedupKeys: make(map[string]string),
 	}
 }
 
+// SetClock replaces the wall clock used for timestamps and approval
+// timeouts. Call it before the queue is used.
+func (q *InMemoryQueue) SetClock(c clock.Clock) {
+	q.clock = c
+}
+
+// SetSLA warns about requests still pending after threshold and counts
+// them as breaches. Zero disables breach tracking.
+func (q *InMemoryQueue) SetSLA(threshold time.Duration) {
+	q.sla.setThreshold(threshold)
+}
+
+// SLAStats reports the approval wait histogram and breach count
+func (q *InMemoryQueue) SLAStats() SLAStats {
+	return q.sla.stats()
+}
+
+// SetNotifier tells n about every request queued for a human. The queue
+// closes n when it is closed, if n is an io.Closer.
+func (q *InMemoryQueue) SetNotifier(n Notifier) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.notifier = n
+}
+
+// DeadLetters returns notifications the notifier could not deliver
+func (q *InMemoryQueue) DeadLetters() []DeadLetter {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if reporter, ok := q.notifier.(DeadLetterReporter); ok {
+		return reporter.DeadLetters()
+	}
+	return nil
+}
+
+// SetDecider consults d before queueing requests for a human
+func (q *InMemoryQueue) SetDecider(d AutoDecider) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.decider = d
+}
+
 func (q *InMemoryQueue) Enqueue(ctx context.Context, req policy.Request, reason string) (Decision, error) {
+	if decision, ok := q.autoDecide(ctx, req, reason); ok {
+		return decision, nil
+	}
+
 	reqID := uuid.New().String()
-	resultCh := make(chan Decision, 1)
+	w := newWaiter()
 
-	approvalReq := &Request{
+	approvalReq := Request{
 		ID:        reqID,
 		ToolName:  req.ToolName,
 		Args:      req.Args,
 		Reason:    reason,
-		CreatedAt: time.Now(),
+		CreatedAt: q.clock.Now(),
 		Status:    StatusPending,
-		resultCh:  resultCh,
 	}
 
-	q.addPending(approvalReq)
+	id, err := q.addPending(ctx, approvalReq, dedupKey(ctx, req), w)
+	if err != nil {
+		return Decision{}, err
+	}
+	if id != reqID {
+		q.record(id, HistoryEntry{Type: EventDeduplicated, Time: approvalReq.CreatedAt, Detail: reason})
+		q.notifyWatchers()
+		log.Info().Str("id", id).Str("tool", req.ToolName).Msg("identical approval request already pending, waiting on it")
+		return q.waitForDecision(ctx, id, w)
+	}
+
+	q.record(reqID, HistoryEntry{Type: EventEnqueued, Time: approvalReq.CreatedAt, Detail: reason})
+	q.sla.watch(q.clock, approvalReq, q.handleSLABreach)
 	q.notifyWatchers()
+	q.notify(approvalReq)
 
 	log.Info().Str("id", reqID).Str("tool", req.ToolName).Msg("approval request enqueued")
 
-	return q.waitForDecision(ctx, reqID, resultCh)
+	return q.waitForDecision(ctx, reqID, w)
 }
 
-func (q *InMemoryQueue) GetPending(ctx context.Context) ([]Request, error) {
+func (q *InMemoryQueue) autoDecide(ctx context.Context, req policy.Request, reason string) (Decision, bool) {
 	q.mu.RLock()
-	defer q.mu.RUnlock()
+	decider := q.decider
+	q.mu.RUnlock()
 
-	pending := make([]Request, 0, len(q.pending))
-	for _, req := range q.pending {
-		pending = append(pending, *req)
+	if decider == nil {
+		return Decision{}, false
 	}
 
-	return pending, nil
+	decision, err := decider.Resolve(ctx, req, reason)
+	if err != nil {
+		log.Warn().Err(err).Str("tool", req.ToolName).Msg("auto decision failed, falling back to human approval")
+		return Decision{}, false
+	}
+
+	log.Info().Str("tool", req.ToolName).Bool("approved", decision.Approved).
+		Str("decided_by", decision.DecidedBy).Msg("approval resolved automatically")
+	return decision, true
+}
+
+func (q *InMemoryQueue) GetPending(ctx context.Context) ([]Request, error) {
+	return q.store.List(ctx)
 }
 
 func (q *InMemoryQueue) Decide(ctx context.Context, id string, decision Decision) error {
-	q.mu.Lock()
-	req, exists := q.pending[id]
-	if !exists {
-		q.mu.Unlock()
-		return fmt.Errorf("request not found: %s", id)
+	return q.decide(ctx, id, decision, EventDecided)
+}
+
+// decide resolves a pending request and records it in the history as
+// eventType.
+func (q *InMemoryQueue) decide(ctx context.Context, id string, decision Decision, eventType EventType) error {
+	req, err := q.store.Remove(ctx, id)
+	if err != nil {
+		return err
 	}
 
-	delete(q.pending, id)
-	q.mu.Unlock()
+	q.forget(req)
+	waiters := q.takeWaiters(id)
 
 	req.Status = q.statusFromDecision(decision)
 	req.decidedBy = decision.DecidedBy
 
-	select {
-	case req.resultCh <- decision:
-		log.Info().Str("id", id).Bool("approved", decision.Approved).Msg("approval decision made")
-	default:
-		log.Warn().Str("id", id).Msg("result channel closed, decision dropped")
+	resolved := 0
+	for _, w := range waiters {
+		if w.resolve(decision) {
+			resolved++
+		}
+	}
+	if resolved == 0 {
+		log.Warn().Str("id", id).Msg("no waiter for request, decision dropped")
+	} else {
+		log.Info().Str("id", id).Bool("approved", decision.Approved).Int("callers", resolved).Msg("approval decision made")
 	}
 
+	now := q.clock.Now()
+	q.sla.observe(id, now.Sub(req.CreatedAt))
+	q.record(id, HistoryEntry{Type: eventType, Time: now, Actor: decision.DecidedBy, Detail: decision.Reason, Decision: &decision})
+	q.emitEvent(Event{Type: eventType, Request: req, Decision: &decision, Time: now})
+
 	return nil
 }
 
+// Comment appends a note to a pending request without deciding it
+func (q *InMemoryQueue) Comment(ctx context.Context, id string, comment Comment) (Request, error) {
+	if comment.CreatedAt.IsZero() {
+		comment.CreatedAt = q.clock.Now()
+	}
+
+	req, err := q.store.Update(ctx, id, func(r *Request) {
+		// Copy so readers holding the old slice never see the append
+		comments := make([]Comment, len(r.Comments), len(r.Comments)+1)
+		copy(comments, r.Comments)
+		r.Comments = append(comments, comment)
+	})
+	if err != nil {
+		return Request{}, err
+	}
+
+	log.Info().Str("id", id).Str("author", comment.Author).Msg("approval comment added")
+	q.record(id, HistoryEntry{Type: EventCommented, Time: comment.CreatedAt, Actor: comment.Author, Detail: comment.Text})
+	q.notifyWatchers()
+	return req, nil
+}
+
+// History returns the lifecycle events recorded for a request
+func (q *InMemoryQueue) History(ctx context.Context, id string, since, until time.Time) ([]HistoryEntry, error) {
+	return q.history.List(ctx, id, since, until)
+}
+
+func (q *InMemoryQueue) record(id string, entry HistoryEntry) {
+	if err := q.history.Append(context.Background(), id, entry); err != nil {
+		log.Warn().Err(err).Str("id", id).Str("event", string(entry.Type)).Msg("failed to record approval event")
+	}
+}
+
 func (q *InMemoryQueue) NotifyChannel() <-chan struct{} {
 	return q.notifyCh
 }
 
+// Events streams decision and timeout events. Events are dropped when
+// the consumer falls behind.
+func (q *InMemoryQueue) Events() <-chan Event {
+	return q.eventCh
+}
+
 func (q *InMemoryQueue) Close() error {
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
-	if q.closed {  // Prevent double-close
+	if q.closed { // Prevent double-close
 		return nil
 	}
 	q.closed = true
 
-	for id, req := range q.pending {
-		close(req.resultCh)
-		delete(q.pending, id)
+	if q.stopSweep != nil {
+		close(q.stopSweep)
+	}
+
+	ctx := context.Background()
+	clear(q.dedupKeys)
+	for id, waiters := range q.waiters {
+		for _, w := range waiters {
+			w.abandon()
+		}
+		delete(q.waiters, id)
+		if _, err := q.store.Remove(ctx, id); err != nil {
+			log.Debug().Err(err).Str("id", id).Msg("pending request already removed")
+		}
 	}
 
+	q.sla.stopAll()
+	if closer, ok := q.notifier.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			log.Warn().Err(err).Msg("failed to close approval notifier")
+		}
+	}
 	close(q.notifyCh)
+	close(q.eventCh)
 	return nil
 }
 
-func (q *InMemoryQueue) addPending(req *Request) {
+// addPending queues req with w as its caller and returns its id, or, with
+// deduplication on, attaches w to the pending request registered under key
+// and returns that request's id instead
+func (q *InMemoryQueue) addPending(ctx context.Context, req Request, key string, w *waiter) (string, error) {
 	q.mu.Lock()
 	defer q.mu.Unlock()
-	q.pending[req.ID] = req
+
+	// Close has already abandoned every waiter; a new one would only
+	// ever time out
+	if q.closed {
+		return "", ErrQueueClosed
+	}
+
+	if id, ok := q.join(ctx, key, w); ok {
+		return id, nil
+	}
+
+	if err := q.store.Add(ctx, req); err != nil {
+		return "", err
+	}
+	q.waiters[req.ID] = []*waiter{w}
+	if q.dedup {
+		q.dedupKeys[key] = req.ID
+	}
+	return req.ID, nil
 }
 
-func (q *InMemoryQueue) waitForDecision(ctx context.Context, id string, resultCh <-chan Decision) (Decision, error) {
-	timeoutCtx, cancel := context.WithTimeout(ctx, q.timeout)
-	defer cancel()
+// takeWaiters removes and returns the waiters callers are blocked on. Only
+// the first of Decide, timeout and Close to call it gets them.
+func (q *InMemoryQueue) takeWaiters(id string) []*waiter {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	waiters := q.waiters[id]
+	delete(q.waiters, id)
+	return waiters
+}
+
+// leave detaches a caller that stopped waiting and reports whether it was
+// the request's last caller. It reports false if a decision or Close has
+// already claimed the waiters.
+func (q *InMemoryQueue) leave(id string, w *waiter) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	waiters := q.waiters[id]
+	for i, candidate := range waiters {
+		if candidate != w {
+			continue
+		}
+		if len(waiters) == 1 {
+			delete(q.waiters, id)
+			return true
+		}
+		q.waiters[id] = append(waiters[:i:i], waiters[i+1:]...)
+		q.store.Update(context.Background(), id, func(r *Request) { r.Duplicates = max(r.Duplicates-1, 0) })
+		return false
+	}
+	return false
+}
 
+func (q *InMemoryQueue) waitForDecision(ctx context.Context, id string, w *waiter) (Decision, error) {
 	select {
-	case decision := <-resultCh:
+	case decision, ok := <-w.ch:
+		if !ok {
+			return Decision{Approved: false, Reason: "approval queue closed"}, nil
+		}
 		return decision, nil
-	case <-timeoutCtx.Done():
-		q.handleTimeout(id)
-		return Decision{Approved: false, Reason: "approval timeout"}, nil
+	case <-q.clock.After(q.timeout):
+		q.handleTimeout(id, w)
+		return Decision{Approved: false, Reason: "approval timeout", TimedOut: true}, nil
 	case <-ctx.Done():
-		q.handleTimeout(id)
+		q.handleTimeout(id, w)
 		return Decision{Approved: false, Reason: "request cancelled"}, ctx.Err()
 	}
 }
 
-func (q *InMemoryQueue) handleTimeout(id string) {
-	q.mu.Lock()
-	defer q.mu.Unlock()
+// handleTimeout withdraws a caller that gave up. The request itself times
+// out only when no other deduplicated caller is still waiting on it.
+func (q *InMemoryQueue) handleTimeout(id string, w *waiter) {
+	if !q.leave(id, w) {
+		// Already decided or closed, or others still waiting
+		w.abandon()
+		return
+	}
+	w.abandon()
 
-	if req, exists := q.pending[id]; exists {
-		req.Status = StatusTimeout
-		delete(q.pending, id)
-		close(req.resultCh)
-		log.Warn().Str("id", id).Msg("approval request timeout")
+	req, err := q.store.Remove(context.Background(), id)
+	if err != nil {
+		// Already decided or closed
+		return
+	}
+	q.forget(req)
+
+	q.sla.stop(id)
+	req.Status = StatusTimeout
+	log.Warn().Str("id", id).Msg("approval request timeout")
+	now := q.clock.Now()
+	q.record(id, HistoryEntry{Type: EventTimeout, Time: now})
+	q.emitEvent(Event{Type: EventTimeout, Request: req, Time: now})
+}
+
+func (q *InMemoryQueue) handleSLABreach(req Request) {
+	now := q.clock.Now()
+	log.Warn().Str("id", req.ID).Str("tool", req.ToolName).Dur("waiting", now.Sub(req.CreatedAt)).
+		Msg("approval request breached SLA")
+	q.record(req.ID, HistoryEntry{Type: EventSLABreached, Time: now})
+	q.emitEvent(Event{Type: EventSLABreached, Request: req, Time: now})
+}
+
+func (q *InMemoryQueue) emitEvent(event Event) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if q.closed {
+		return
+	}
+
+	select {
+	case q.eventCh <- event:
+	default:
+		log.Debug().Str("id", event.Request.ID).Msg("approval event dropped")
+	}
+}
+
+func (q *InMemoryQueue) notify(req Request) {
+	q.mu.RLock()
+	notifier := q.notifier
+	q.mu.RUnlock()
+
+	if notifier != nil {
+		notifier.Notify(req)
 	}
 }
 
 func (q *InMemoryQueue) notifyWatchers() {
 
 	q.mu.RLock()
-	defer q.mu.RUnlock()  // Keep lock held during entire operation
-	
+	defer q.mu.RUnlock() // Keep lock held during entire operation
+
 	if q.closed {
 		return
 	}
@@ -161,4 +433,4 @@ func (q *InMemoryQueue) statusFromDecision(d Decision) Status {
 		return StatusApproved
 	}
 	return StatusDenied
-}
\ No newline at end of file
+}
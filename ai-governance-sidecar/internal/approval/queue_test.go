@@ -3,9 +3,13 @@ package approval
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/dagbolade/ai-governance-sidecar/internal/auth"
+	"github.com/dagbolade/ai-governance-sidecar/internal/clock"
 	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
 )
 
@@ -84,6 +88,10 @@ func TestTimeout(t *testing.T) {
 	if decision.Reason != "approval timeout" {
 		t.Errorf("unexpected reason: %s", decision.Reason)
 	}
+
+	if !decision.TimedOut {
+		t.Error("expected decision to be marked as timed out")
+	}
 }
 
 func TestDecideNonExistent(t *testing.T) {
@@ -122,4 +130,539 @@ func TestConcurrentEnqueue(t *testing.T) {
 	if len(pending) != numRequests {
 		t.Errorf("expected %d pending requests, got %d", numRequests, len(pending))
 	}
-}
\ No newline at end of file
+}
+func TestCommentKeepsRequestPending(t *testing.T) {
+	queue := NewInMemoryQueue(5 * time.Second)
+	defer queue.Close()
+
+	ctx := context.Background()
+	resultCh := make(chan Decision, 1)
+	go func() {
+		decision, _ := queue.Enqueue(ctx, policy.Request{ToolName: "test_tool", Args: json.RawMessage(`{}`)}, "review")
+		resultCh <- decision
+	}()
+
+	var pending []Request
+	for i := 0; i < 50 && len(pending) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+		pending, _ = queue.GetPending(ctx)
+	}
+	if len(pending) != 1 {
+		t.Fatalf("expected 1 pending request, got %d", len(pending))
+	}
+	id := pending[0].ID
+
+	if _, err := queue.Comment(ctx, id, Comment{Author: "alice", Text: "looks risky"}); err != nil {
+		t.Fatalf("comment failed: %v", err)
+	}
+	if _, err := queue.Comment(ctx, id, Comment{Author: "bob", Text: "agreed, checking"}); err != nil {
+		t.Fatalf("comment failed: %v", err)
+	}
+
+	pending, _ = queue.GetPending(ctx)
+	if len(pending) != 1 {
+		t.Fatalf("expected request to stay pending, got %d", len(pending))
+	}
+	if len(pending[0].Comments) != 2 || pending[0].Comments[1].Author != "bob" {
+		t.Errorf("expected 2 ordered comments, got %+v", pending[0].Comments)
+	}
+	if pending[0].Comments[0].CreatedAt.IsZero() {
+		t.Error("expected comment timestamp to be set")
+	}
+
+	select {
+	case <-resultCh:
+		t.Fatal("commenting must not resolve the request")
+	default:
+	}
+
+	if err := queue.Decide(ctx, id, Decision{Approved: true, Reason: "ok"}); err != nil {
+		t.Fatalf("decide failed: %v", err)
+	}
+	<-resultCh
+
+	if _, err := queue.Comment(ctx, id, Comment{Author: "carol", Text: "late"}); err == nil {
+		t.Error("expected error commenting on a decided request")
+	}
+}
+
+func TestHistoryRecordsLifecycle(t *testing.T) {
+	queue := NewInMemoryQueue(5 * time.Second)
+	defer queue.Close()
+
+	ctx := context.Background()
+	done := make(chan struct{})
+	go func() {
+		queue.Enqueue(ctx, policy.Request{ToolName: "test_tool", Args: json.RawMessage(`{}`)}, "needs review")
+		close(done)
+	}()
+
+	var pending []Request
+	for i := 0; i < 50 && len(pending) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+		pending, _ = queue.GetPending(ctx)
+	}
+	if len(pending) != 1 {
+		t.Fatalf("expected 1 pending request, got %d", len(pending))
+	}
+	id := pending[0].ID
+
+	if _, err := queue.Comment(ctx, id, Comment{Author: "alice", Text: "checking"}); err != nil {
+		t.Fatalf("comment failed: %v", err)
+	}
+	if err := queue.Decide(ctx, id, Decision{Approved: true, Reason: "ok", DecidedBy: "bob"}); err != nil {
+		t.Fatalf("decide failed: %v", err)
+	}
+	<-done
+
+	events, err := queue.History(ctx, id, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("history failed: %v", err)
+	}
+
+	want := []EventType{EventEnqueued, EventCommented, EventDecided}
+	if len(events) != len(want) {
+		t.Fatalf("expected %d events, got %+v", len(want), events)
+	}
+	for i, eventType := range want {
+		if events[i].Type != eventType {
+			t.Errorf("event %d: expected %s, got %s", i, eventType, events[i].Type)
+		}
+		if i > 0 && events[i].Time.Before(events[i-1].Time) {
+			t.Errorf("event %d is out of order", i)
+		}
+	}
+	if events[2].Actor != "bob" || events[2].Decision == nil || !events[2].Decision.Approved {
+		t.Errorf("expected decision by bob, got %+v", events[2])
+	}
+
+	// A window after the decision is empty
+	later, err := queue.History(ctx, id, time.Now().Add(time.Minute), time.Time{})
+	if err != nil || len(later) != 0 {
+		t.Errorf("expected no events after the decision, got %+v (%v)", later, err)
+	}
+
+	if _, err := queue.History(ctx, "missing", time.Time{}, time.Time{}); !errors.Is(err, ErrRequestNotFound) {
+		t.Errorf("expected ErrRequestNotFound, got %v", err)
+	}
+}
+
+func TestMemoryEventLogIsBounded(t *testing.T) {
+	eventLog := NewMemoryEventLog()
+	eventLog.maxReqs = 2
+
+	ctx := context.Background()
+	for _, id := range []string{"a", "b", "c"} {
+		eventLog.Append(ctx, id, HistoryEntry{Type: EventEnqueued, Time: time.Now()})
+	}
+
+	if _, err := eventLog.List(ctx, "a", time.Time{}, time.Time{}); !errors.Is(err, ErrRequestNotFound) {
+		t.Errorf("expected oldest request history to be evicted, got %v", err)
+	}
+	if events, err := eventLog.List(ctx, "c", time.Time{}, time.Time{}); err != nil || len(events) != 1 {
+		t.Errorf("expected newest history kept, got %+v (%v)", events, err)
+	}
+}
+
+func TestSLABreachIsCounted(t *testing.T) {
+	queue := NewInMemoryQueue(5 * time.Second)
+	defer queue.Close()
+	queue.SetSLA(30 * time.Millisecond)
+
+	ctx := context.Background()
+	doneCh := make(chan Decision)
+	go func() {
+		decision, _ := queue.Enqueue(ctx, policy.Request{ToolName: "slow_review", Args: json.RawMessage(`{}`)}, "needs review")
+		doneCh <- decision
+	}()
+
+	var breach Event
+	select {
+	case breach = <-queue.Events():
+	case <-time.After(time.Second):
+		t.Fatal("expected an SLA breach event")
+	}
+	if breach.Type != EventSLABreached || breach.Request.ToolName != "slow_review" {
+		t.Errorf("unexpected event: %+v", breach)
+	}
+	if got := queue.SLAStats().Breached; got != 1 {
+		t.Errorf("expected 1 breach while pending, got %d", got)
+	}
+
+	pending, _ := queue.GetPending(ctx)
+	if err := queue.Decide(ctx, pending[0].ID, Decision{Approved: true, DecidedBy: "tester"}); err != nil {
+		t.Fatalf("decide failed: %v", err)
+	}
+	<-doneCh
+
+	stats := queue.SLAStats()
+	if stats.Breached != 1 {
+		t.Errorf("expected breach to be counted once, got %d", stats.Breached)
+	}
+	if stats.Decided != 1 || stats.WaitSecondsSum <= 0.03 {
+		t.Errorf("expected one decision waiting past the SLA, got %+v", stats)
+	}
+	if first := stats.Buckets[0]; first.Le != "10" || first.Count != 1 {
+		t.Errorf("expected the wait in the 10s bucket, got %+v", first)
+	}
+	if last := stats.Buckets[len(stats.Buckets)-1]; last.Le != "+Inf" || last.Count != 1 {
+		t.Errorf("expected cumulative +Inf bucket of 1, got %+v", last)
+	}
+}
+
+func TestSLANotBreachedWhenDecidedInTime(t *testing.T) {
+	queue := NewInMemoryQueue(5 * time.Second)
+	defer queue.Close()
+	queue.SetSLA(time.Second)
+
+	ctx := context.Background()
+	go queue.Enqueue(ctx, policy.Request{ToolName: "quick_review", Args: json.RawMessage(`{}`)}, "needs review")
+
+	var pending []Request
+	for deadline := time.Now().Add(time.Second); len(pending) == 0 && time.Now().Before(deadline); {
+		pending, _ = queue.GetPending(ctx)
+	}
+	if len(pending) != 1 {
+		t.Fatal("request never became pending")
+	}
+	if err := queue.Decide(ctx, pending[0].ID, Decision{Approved: true}); err != nil {
+		t.Fatalf("decide failed: %v", err)
+	}
+
+	if stats := queue.SLAStats(); stats.Breached != 0 || stats.Decided != 1 {
+		t.Errorf("expected one timely decision and no breach, got %+v", stats)
+	}
+}
+
+func TestSLABreachWithFakeClock(t *testing.T) {
+	fake := clock.NewFake(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+
+	queue := NewInMemoryQueue(time.Hour)
+	queue.SetClock(fake)
+	queue.SetSLA(time.Minute)
+	defer queue.Close()
+
+	go queue.Enqueue(context.Background(), policy.Request{ToolName: "deploy", Args: json.RawMessage(`{}`)}, "review")
+
+	// The approval timeout and the SLA watch
+	for i := 0; i < 100 && fake.Waiters() < 2; i++ {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	fake.Advance(time.Minute - time.Second)
+	select {
+	case event := <-queue.Events():
+		t.Fatalf("breach reported before the threshold: %+v", event)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	fake.Advance(time.Second)
+	select {
+	case event := <-queue.Events():
+		if event.Type != EventSLABreached {
+			t.Errorf("expected an SLA breach, got %+v", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a breach once the fake clock passed the threshold")
+	}
+}
+
+func TestTimeoutWithFakeClock(t *testing.T) {
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(start)
+
+	queue := NewInMemoryQueue(5 * time.Minute)
+	queue.SetClock(fake)
+	defer queue.Close()
+
+	doneCh := make(chan Decision, 1)
+	go func() {
+		decision, _ := queue.Enqueue(context.Background(), policy.Request{ToolName: "deploy", Args: json.RawMessage(`{}`)}, "review")
+		doneCh <- decision
+	}()
+
+	for i := 0; i < 100 && fake.Waiters() == 0; i++ {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	pending, _ := queue.GetPending(context.Background())
+	if len(pending) != 1 || !pending[0].CreatedAt.Equal(start) {
+		t.Fatalf("expected one request created at the fake time, got %+v", pending)
+	}
+
+	fake.Advance(5*time.Minute - time.Second)
+	select {
+	case <-doneCh:
+		t.Fatal("request timed out before the deadline")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	fake.Advance(time.Second)
+	select {
+	case decision := <-doneCh:
+		if !decision.TimedOut {
+			t.Errorf("expected timed out decision, got %+v", decision)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected timeout once the fake clock passed the deadline")
+	}
+}
+
+func TestMaxPendingAgeAutoDenies(t *testing.T) {
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(start)
+
+	queue := NewInMemoryQueue(time.Hour)
+	queue.SetClock(fake)
+	queue.SetMaxPendingAge(time.Minute)
+	defer queue.Close()
+
+	ctx := context.Background()
+	doneCh := make(chan Decision, 1)
+	go func() {
+		decision, _ := queue.Enqueue(ctx, policy.Request{ToolName: "deploy", Args: json.RawMessage(`{}`)}, "review")
+		doneCh <- decision
+	}()
+
+	// The sweeper and the waiting caller are both blocked on the clock
+	for i := 0; i < 100 && fake.Waiters() < 2; i++ {
+		time.Sleep(5 * time.Millisecond)
+	}
+	pending, _ := queue.GetPending(ctx)
+	if len(pending) != 1 {
+		t.Fatalf("expected 1 pending request, got %d", len(pending))
+	}
+	id := pending[0].ID
+
+	for i := 0; i < 7; i++ {
+		fake.Advance(10 * time.Second)
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	var decision Decision
+	select {
+	case decision = <-doneCh:
+	case <-time.After(time.Second):
+		t.Fatal("expected the request to be auto-denied past the max pending age")
+	}
+	if decision.Approved || decision.TimedOut || decision.DecidedBy != SystemDecider {
+		t.Errorf("expected a deny decided by system, got %+v", decision)
+	}
+
+	events, err := queue.History(ctx, id, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("history failed: %v", err)
+	}
+	last := events[len(events)-1]
+	if last.Type != EventAutoDenied || last.Actor != SystemDecider {
+		t.Errorf("expected an auto_denied entry by system, got %+v", last)
+	}
+	for _, event := range events {
+		if event.Type == EventTimeout || event.Type == EventDecided {
+			t.Errorf("auto-deny must not be recorded as %s", event.Type)
+		}
+	}
+}
+
+// Decide, a cancelled caller and Close all try to finish the same request.
+// Exactly one of them may, and none of them may panic.
+func TestResolveRacesFinishWaiterOnce(t *testing.T) {
+	for i := 0; i < 200; i++ {
+		queue := NewInMemoryQueue(time.Minute)
+		ctx, cancel := context.WithCancel(context.Background())
+
+		done := make(chan Decision, 1)
+		go func() {
+			decision, _ := queue.Enqueue(ctx, policy.Request{ToolName: "test_tool", Args: json.RawMessage(`{}`)}, "race")
+			done <- decision
+		}()
+
+		<-queue.NotifyChannel()
+		pending, _ := queue.GetPending(context.Background())
+		if len(pending) != 1 {
+			t.Fatalf("iteration %d: expected 1 pending request, got %d", i, len(pending))
+		}
+		id := pending[0].ID
+
+		var wg sync.WaitGroup
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			queue.Decide(context.Background(), id, Decision{Approved: true, Reason: "ok"})
+		}()
+		go func() {
+			defer wg.Done()
+			cancel()
+		}()
+		go func() {
+			defer wg.Done()
+			queue.Close()
+		}()
+		wg.Wait()
+
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("iteration %d: Enqueue never returned", i)
+		}
+
+		if err := queue.Decide(context.Background(), id, Decision{Approved: false}); err == nil {
+			t.Fatalf("iteration %d: expected a second decision to be rejected", i)
+		}
+	}
+}
+
+func TestEnqueueAfterCloseFails(t *testing.T) {
+	queue := NewInMemoryQueue(time.Minute)
+	queue.Close()
+
+	_, err := queue.Enqueue(context.Background(), policy.Request{ToolName: "test_tool"}, "late")
+	if !errors.Is(err, ErrQueueClosed) {
+		t.Errorf("expected ErrQueueClosed, got %v", err)
+	}
+}
+
+// waitForPending polls until the pending list satisfies done
+func waitForPending(t *testing.T, queue *InMemoryQueue, done func([]Request) bool) []Request {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		pending, err := queue.GetPending(context.Background())
+		if err != nil {
+			t.Fatalf("get pending failed: %v", err)
+		}
+		if done(pending) {
+			return pending
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("pending requests never settled: %+v", pending)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestDeduplicatedRequestsShareDecision(t *testing.T) {
+	queue := NewInMemoryQueue(time.Minute)
+	queue.SetDeduplicate(true)
+	defer queue.Close()
+
+	results := make(chan Decision, 2)
+	enqueue := func(args string) {
+		decision, err := queue.Enqueue(context.Background(), policy.Request{
+			ToolName: "delete_file",
+			Args:     json.RawMessage(args),
+		}, "requires approval")
+		if err != nil {
+			t.Errorf("enqueue failed: %v", err)
+		}
+		results <- decision
+	}
+
+	go enqueue(`{"path":"/tmp/a","force":true}`)
+	waitForPending(t, queue, func(p []Request) bool { return len(p) == 1 })
+	go enqueue(`{"force": true, "path": "/tmp/a"}`)
+
+	pending := waitForPending(t, queue, func(p []Request) bool { return len(p) == 1 && p[0].Duplicates == 1 })
+
+	if err := queue.Decide(context.Background(), pending[0].ID, Decision{Approved: true, Reason: "ok", DecidedBy: "alice"}); err != nil {
+		t.Fatalf("decide failed: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case decision := <-results:
+			if !decision.Approved || decision.DecidedBy != "alice" {
+				t.Errorf("caller %d got %+v, want alice's approval", i, decision)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("caller %d never received the decision", i)
+		}
+	}
+
+	// The key is released with the decision, so a new call queues afresh
+	go enqueue(`{"path":"/tmp/a","force":true}`)
+	pending = waitForPending(t, queue, func(p []Request) bool { return len(p) == 1 })
+	if pending[0].ID == "" || pending[0].Duplicates != 0 {
+		t.Errorf("expected a fresh request, got %+v", pending[0])
+	}
+}
+
+func TestDeduplicationOffQueuesEachRequest(t *testing.T) {
+	queue := NewInMemoryQueue(time.Minute)
+	defer queue.Close()
+
+	for i := 0; i < 2; i++ {
+		go queue.Enqueue(context.Background(), policy.Request{ToolName: "delete_file", Args: json.RawMessage(`{"path":"/tmp/a"}`)}, "requires approval")
+	}
+	waitForPending(t, queue, func(p []Request) bool { return len(p) == 2 })
+}
+
+func TestDeduplicationKeepsCallersAndUpstreamsApart(t *testing.T) {
+	queue := NewInMemoryQueue(time.Minute)
+	queue.SetDeduplicate(true)
+	defer queue.Close()
+
+	enqueue := func(user *auth.User, upstream string) {
+		ctx := auth.WithUser(context.Background(), user)
+		go queue.Enqueue(ctx, policy.Request{
+			ToolName: "delete_file",
+			Args:     json.RawMessage(`{"path":"/tmp/a"}`),
+			Metadata: map[string]any{"upstream": upstream, "method": "POST"},
+		}, "requires approval")
+	}
+
+	enqueue(&auth.User{ID: "alice"}, "http://files-a")
+	waitForPending(t, queue, func(p []Request) bool { return len(p) == 1 })
+	enqueue(&auth.User{ID: "bob"}, "http://files-a")
+	waitForPending(t, queue, func(p []Request) bool { return len(p) == 2 })
+	enqueue(&auth.User{ID: "alice"}, "http://files-b")
+	waitForPending(t, queue, func(p []Request) bool { return len(p) == 3 })
+
+	enqueue(&auth.User{ID: "alice"}, "http://files-a")
+	pending := waitForPending(t, queue, func(p []Request) bool {
+		duplicates := 0
+		for _, r := range p {
+			duplicates += r.Duplicates
+		}
+		return duplicates == 1
+	})
+	if len(pending) != 3 {
+		t.Errorf("expected only the repeated call to merge, got %d pending", len(pending))
+	}
+}
+
+func TestDeduplicatedRequestOutlivesFirstCaller(t *testing.T) {
+	queue := NewInMemoryQueue(time.Minute)
+	queue.SetDeduplicate(true)
+	defer queue.Close()
+
+	req := policy.Request{ToolName: "delete_file", Args: json.RawMessage(`{"path":"/tmp/a"}`)}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	first := make(chan error, 1)
+	go func() {
+		_, err := queue.Enqueue(ctx, req, "requires approval")
+		first <- err
+	}()
+	waitForPending(t, queue, func(p []Request) bool { return len(p) == 1 })
+
+	second := make(chan Decision, 1)
+	go func() {
+		decision, _ := queue.Enqueue(context.Background(), req, "requires approval")
+		second <- decision
+	}()
+	pending := waitForPending(t, queue, func(p []Request) bool { return len(p) == 1 && p[0].Duplicates == 1 })
+
+	cancel()
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the first caller to be cancelled, got %v", err)
+	}
+	waitForPending(t, queue, func(p []Request) bool { return len(p) == 1 && p[0].Duplicates == 0 })
+
+	if err := queue.Decide(context.Background(), pending[0].ID, Decision{Approved: false, Reason: "no"}); err != nil {
+		t.Fatalf("decide failed: %v", err)
+	}
+	if decision := <-second; decision.Approved || decision.Reason != "no" {
+		t.Errorf("expected the remaining caller to get the denial, got %+v", decision)
+	}
+}
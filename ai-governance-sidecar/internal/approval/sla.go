@@ -0,0 +1,168 @@
+package approval
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/clock"
+)
+
+// slaBuckets are the upper bounds of the approval wait histogram
+var slaBuckets = []time.Duration{
+	10 * time.Second,
+	30 * time.Second,
+	time.Minute,
+	5 * time.Minute,
+	15 * time.Minute,
+	time.Hour,
+}
+
+// HistogramBucket counts decisions that waited at most Le seconds.
+// Counts are cumulative, as in Prometheus.
+type HistogramBucket struct {
+	Le    string `json:"le"`
+	Count uint64 `json:"count"`
+}
+
+// SLAStats summarises how long approvals waited for a human decision
+type SLAStats struct {
+	ThresholdSeconds float64           `json:"threshold_seconds,omitempty"`
+	Decided          uint64            `json:"decided"`
+	WaitSecondsSum   float64           `json:"wait_seconds_sum"`
+	Buckets          []HistogramBucket `json:"buckets"`
+	// Breached counts requests that were still pending at the threshold
+	Breached uint64 `json:"breached"`
+}
+
+// SLAReporter is implemented by queues that track approval wait times
+type SLAReporter interface {
+	SLAStats() SLAStats
+}
+
+// slaTracker watches each pending request for a breach and records the
+// wait of every decision.
+type slaTracker struct {
+	mu        sync.Mutex
+	threshold time.Duration
+	counts    []uint64 // per bucket, plus one for +Inf
+	decided   uint64
+	waitSum   time.Duration
+	breached  uint64
+	// watching holds a channel per watched request, closed to stop the watch
+	watching map[string]chan struct{}
+}
+
+func newSLATracker() *slaTracker {
+	return &slaTracker{
+		counts:   make([]uint64, len(slaBuckets)+1),
+		watching: make(map[string]chan struct{}),
+	}
+}
+
+func (t *slaTracker) setThreshold(threshold time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.threshold = threshold
+}
+
+// watch calls onBreach if req is still pending once the threshold passes
+// on clk
+func (t *slaTracker) watch(clk clock.Clock, req Request, onBreach func(Request)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.threshold <= 0 {
+		return
+	}
+
+	done := make(chan struct{})
+	t.watching[req.ID] = done
+	expired := clk.After(t.threshold)
+
+	go func() {
+		select {
+		case <-done:
+			return
+		case <-expired:
+		}
+
+		t.mu.Lock()
+		if t.watching[req.ID] != done {
+			t.mu.Unlock()
+			return
+		}
+		delete(t.watching, req.ID)
+		t.breached++
+		t.mu.Unlock()
+
+		onBreach(req)
+	}()
+}
+
+// unwatchLocked stops the watch on id, if any
+func (t *slaTracker) unwatchLocked(id string) {
+	if done, ok := t.watching[id]; ok {
+		close(done)
+		delete(t.watching, id)
+	}
+}
+
+// observe records a decision that waited for wait
+func (t *slaTracker) observe(id string, wait time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.unwatchLocked(id)
+
+	t.decided++
+	t.waitSum += wait
+	for i, bound := range slaBuckets {
+		if wait <= bound {
+			t.counts[i]++
+			return
+		}
+	}
+	t.counts[len(slaBuckets)]++
+}
+
+// stop forgets a request that left the queue without a decision
+func (t *slaTracker) stop(id string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.unwatchLocked(id)
+}
+
+func (t *slaTracker) stopAll() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for id := range t.watching {
+		t.unwatchLocked(id)
+	}
+}
+
+func (t *slaTracker) stats() SLAStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stats := SLAStats{
+		ThresholdSeconds: t.threshold.Seconds(),
+		Decided:          t.decided,
+		WaitSecondsSum:   t.waitSum.Seconds(),
+		Breached:         t.breached,
+		Buckets:          make([]HistogramBucket, 0, len(t.counts)),
+	}
+
+	var cumulative uint64
+	for i, count := range t.counts {
+		cumulative += count
+		le := "+Inf"
+		if i < len(slaBuckets) {
+			le = strconv.FormatFloat(slaBuckets[i].Seconds(), 'f', -1, 64)
+		}
+		stats.Buckets = append(stats.Buckets, HistogramBucket{Le: le, Count: cumulative})
+	}
+	return stats
+}
@@ -0,0 +1,102 @@
+package approval
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+var ErrRequestNotFound = errors.New("request not found")
+
+// PendingStore persists approval requests. It knows nothing about how
+// callers wait for decisions, so alternate backends only implement storage.
+type PendingStore interface {
+	Add(ctx context.Context, req Request) error
+	Get(ctx context.Context, id string) (Request, error)
+	// Remove atomically claims a request; only one caller can remove a given id.
+	Remove(ctx context.Context, id string) (Request, error)
+	List(ctx context.Context) ([]Request, error)
+	// Update applies fn to a pending request atomically and returns the result.
+	Update(ctx context.Context, id string, fn func(*Request)) (Request, error)
+}
+
+// MemoryStore is the default in-process PendingStore.
+type MemoryStore struct {
+	mu       sync.RWMutex
+	requests map[string]Request
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		requests: make(map[string]Request),
+	}
+}
+
+func (s *MemoryStore) Add(ctx context.Context, req Request) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.requests[req.ID]; exists {
+		return fmt.Errorf("request already exists: %s", req.ID)
+	}
+
+	s.requests[req.ID] = req
+	return nil
+}
+
+func (s *MemoryStore) Get(ctx context.Context, id string) (Request, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	req, exists := s.requests[id]
+	if !exists {
+		return Request{}, fmt.Errorf("%w: %s", ErrRequestNotFound, id)
+	}
+
+	return req, nil
+}
+
+func (s *MemoryStore) Remove(ctx context.Context, id string) (Request, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	req, exists := s.requests[id]
+	if !exists {
+		return Request{}, fmt.Errorf("%w: %s", ErrRequestNotFound, id)
+	}
+
+	delete(s.requests, id)
+	return req, nil
+}
+
+func (s *MemoryStore) Update(ctx context.Context, id string, fn func(*Request)) (Request, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	req, exists := s.requests[id]
+	if !exists {
+		return Request{}, fmt.Errorf("%w: %s", ErrRequestNotFound, id)
+	}
+
+	fn(&req)
+	s.requests[id] = req
+	return req, nil
+}
+
+func (s *MemoryStore) List(ctx context.Context) ([]Request, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	requests := make([]Request, 0, len(s.requests))
+	for _, req := range s.requests {
+		requests = append(requests, req)
+	}
+
+	sort.Slice(requests, func(i, j int) bool {
+		return requests[i].CreatedAt.Before(requests[j].CreatedAt)
+	})
+
+	return requests, nil
+}
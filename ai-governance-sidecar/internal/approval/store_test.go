@@ -0,0 +1,124 @@
+package approval
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
+)
+
+// testPendingStore exercises the PendingStore contract so every backend
+// can be checked against the same expectations.
+func testPendingStore(t *testing.T, store PendingStore) {
+	ctx := context.Background()
+	now := time.Now()
+
+	first := Request{ID: "first", ToolName: "tool_a", CreatedAt: now, Status: StatusPending}
+	second := Request{ID: "second", ToolName: "tool_b", CreatedAt: now.Add(time.Second), Status: StatusPending}
+
+	if err := store.Add(ctx, second); err != nil {
+		t.Fatalf("add failed: %v", err)
+	}
+	if err := store.Add(ctx, first); err != nil {
+		t.Fatalf("add failed: %v", err)
+	}
+	if err := store.Add(ctx, first); err == nil {
+		t.Error("expected error adding duplicate id")
+	}
+
+	got, err := store.Get(ctx, "first")
+	if err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	if got.ToolName != "tool_a" {
+		t.Errorf("expected tool_a, got %s", got.ToolName)
+	}
+
+	list, err := store.List(ctx)
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if len(list) != 2 || list[0].ID != "first" || list[1].ID != "second" {
+		t.Errorf("expected requests ordered by creation time, got %+v", list)
+	}
+
+	removed, err := store.Remove(ctx, "first")
+	if err != nil {
+		t.Fatalf("remove failed: %v", err)
+	}
+	if removed.ID != "first" {
+		t.Errorf("expected removed request 'first', got %s", removed.ID)
+	}
+
+	if _, err := store.Remove(ctx, "first"); !errors.Is(err, ErrRequestNotFound) {
+		t.Errorf("expected ErrRequestNotFound on second remove, got %v", err)
+	}
+	if _, err := store.Get(ctx, "first"); !errors.Is(err, ErrRequestNotFound) {
+		t.Errorf("expected ErrRequestNotFound after remove, got %v", err)
+	}
+
+	list, _ = store.List(ctx)
+	if len(list) != 1 {
+		t.Errorf("expected 1 remaining request, got %d", len(list))
+	}
+
+	updated, err := store.Update(ctx, "second", func(r *Request) { r.Reason = "updated" })
+	if err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+	if updated.Reason != "updated" {
+		t.Errorf("expected updated reason, got %q", updated.Reason)
+	}
+	if got, _ := store.Get(ctx, "second"); got.Reason != "updated" {
+		t.Errorf("expected update to persist, got %q", got.Reason)
+	}
+	if _, err := store.Update(ctx, "first", func(r *Request) {}); !errors.Is(err, ErrRequestNotFound) {
+		t.Errorf("expected ErrRequestNotFound updating removed request, got %v", err)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testPendingStore(t, NewMemoryStore())
+}
+
+// recordingStore wraps a PendingStore to show the queue only talks to
+// storage through the interface.
+type recordingStore struct {
+	PendingStore
+	adds    int
+	removes int
+}
+
+func (s *recordingStore) Add(ctx context.Context, req Request) error {
+	s.adds++
+	return s.PendingStore.Add(ctx, req)
+}
+
+func (s *recordingStore) Remove(ctx context.Context, id string) (Request, error) {
+	s.removes++
+	return s.PendingStore.Remove(ctx, id)
+}
+
+func TestQueueUsesPendingStore(t *testing.T) {
+	store := &recordingStore{PendingStore: NewMemoryStore()}
+	queue := NewQueueWithStore(store, 100*time.Millisecond)
+	defer queue.Close()
+
+	decision, err := queue.Enqueue(context.Background(), policy.Request{
+		ToolName: "store_test",
+		Args:     json.RawMessage(`{}`),
+	}, "needs review")
+	if err != nil {
+		t.Fatalf("enqueue failed: %v", err)
+	}
+	if decision.Approved {
+		t.Error("expected timeout denial")
+	}
+
+	if store.adds != 1 || store.removes != 1 {
+		t.Errorf("expected 1 add and 1 remove, got %d adds and %d removes", store.adds, store.removes)
+	}
+}
@@ -0,0 +1,78 @@
+package approval
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// SystemDecider is DecidedBy on decisions the sidecar makes on its own
+const SystemDecider = "system"
+
+// maxSweepInterval bounds how late a request past the hard age is denied
+const maxSweepInterval = 10 * time.Second
+
+// SetMaxPendingAge starts a sweeper that denies requests still pending
+// after age, including ones with no caller waiting on them. Unlike a
+// timeout, the denial is a decision by "system" and is recorded as
+// auto_denied. Zero disables the sweeper. Call it once, before the queue
+// is used.
+func (q *InMemoryQueue) SetMaxPendingAge(age time.Duration) {
+	if age <= 0 {
+		return
+	}
+
+	q.mu.Lock()
+	q.maxAge = age
+	q.stopSweep = make(chan struct{})
+	stop := q.stopSweep
+	q.mu.Unlock()
+
+	interval := age / 2
+	if interval > maxSweepInterval {
+		interval = maxSweepInterval
+	}
+	go q.sweepLoop(interval, stop)
+}
+
+func (q *InMemoryQueue) sweepLoop(interval time.Duration, stop <-chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			return
+		case <-q.clock.After(interval):
+			q.sweepExpired(context.Background())
+		}
+	}
+}
+
+// sweepExpired auto-denies every pending request older than the hard age
+func (q *InMemoryQueue) sweepExpired(ctx context.Context) {
+	pending, err := q.store.List(ctx)
+	if err != nil {
+		log.Warn().Err(err).Msg("approval sweep failed to list pending requests")
+		return
+	}
+
+	now := q.clock.Now()
+	for _, req := range pending {
+		if now.Sub(req.CreatedAt) < q.maxAge {
+			continue
+		}
+
+		decision := Decision{
+			Approved:  false,
+			Reason:    fmt.Sprintf("auto-denied: pending longer than %s", q.maxAge),
+			DecidedBy: SystemDecider,
+		}
+		if err := q.decide(ctx, req.ID, decision, EventAutoDenied); err != nil {
+			// Decided or timed out since the list was read
+			log.Debug().Err(err).Str("id", req.ID).Msg("approval sweep skipped request")
+			continue
+		}
+		log.Warn().Str("id", req.ID).Str("tool", req.ToolName).Dur("age", now.Sub(req.CreatedAt)).
+			Msg("approval request auto-denied past max pending age")
+	}
+}
@@ -18,20 +18,63 @@ const (
 )
 
 type Request struct {
-	ID        string              `json:"id"`
-	ToolName  string              `json:"tool_name"`
-	Args      json.RawMessage     `json:"args"`
-	Reason    string              `json:"reason"`
-	CreatedAt time.Time           `json:"created_at"`
-	Status    Status              `json:"status"`
-	decidedBy string              `json:"-"`
-	resultCh  chan<- Decision     `json:"-"`
+	ID        string          `json:"id"`
+	ToolName  string          `json:"tool_name"`
+	Args      json.RawMessage `json:"args"`
+	Reason    string          `json:"reason"`
+	CreatedAt time.Time       `json:"created_at"`
+	Status    Status          `json:"status"`
+	Comments  []Comment       `json:"comments,omitempty"`
+	decidedBy string          `json:"-"`
+
+	// Duplicates counts identical submissions waiting on this request
+	// besides the first, when deduplication is on
+	Duplicates int `json:"duplicates,omitempty"`
+}
+
+// Comment is a reviewer note left on a pending request
+type Comment struct {
+	Author    string    `json:"author"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// EventType identifies an approval lifecycle event
+type EventType string
+
+const (
+	EventEnqueued  EventType = "enqueued"
+	EventCommented EventType = "commented"
+	EventDecided   EventType = "decided"
+	EventTimeout   EventType = "timeout"
+	// EventSLABreached fires once when a request stays pending past the SLA
+	EventSLABreached EventType = "sla_breached"
+	// EventAutoDenied records a denial by the sweeper once a request
+	// passes the max pending age
+	EventAutoDenied EventType = "auto_denied"
+	// EventDeduplicated records an identical submission attached to a
+	// request already pending
+	EventDeduplicated EventType = "deduplicated"
+)
+
+// Event describes a change to an approval request
+type Event struct {
+	Type     EventType `json:"type"`
+	Request  Request   `json:"request"`
+	Decision *Decision `json:"decision,omitempty"`
+	Time     time.Time `json:"time"`
 }
 
 type Decision struct {
-	Approved bool   `json:"approved"`
-	Reason   string `json:"reason"`
+	Approved  bool   `json:"approved"`
+	Reason    string `json:"reason"`
 	DecidedBy string `json:"decided_by,omitempty"`
+	TimedOut  bool   `json:"timed_out,omitempty"`
+}
+
+// Commenter is implemented by queues that accept reviewer notes
+type Commenter interface {
+	Comment(ctx context.Context, id string, comment Comment) (Request, error)
 }
 
 type Queue interface {
@@ -39,4 +82,4 @@ type Queue interface {
 	GetPending(ctx context.Context) ([]Request, error)
 	Decide(ctx context.Context, id string, decision Decision) error
 	Close() error
-}
\ No newline at end of file
+}
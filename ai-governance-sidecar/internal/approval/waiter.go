@@ -0,0 +1,38 @@
+package approval
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrQueueClosed is returned for requests enqueued after Close
+var ErrQueueClosed = errors.New("approval queue closed")
+
+// waiter is the channel an Enqueue caller blocks on. Decide, the timeout
+// path and Close can all race to finish the same request; takeWaiters hands
+// its waiters to only one of them, and once guarantees that even a second
+// claimant could never send on or close the channel again.
+type waiter struct {
+	ch   chan Decision
+	once sync.Once
+}
+
+func newWaiter() *waiter {
+	return &waiter{ch: make(chan Decision, 1)}
+}
+
+// resolve delivers decision and reports whether this call finished the
+// waiter
+func (w *waiter) resolve(decision Decision) bool {
+	resolved := false
+	w.once.Do(func() {
+		w.ch <- decision
+		resolved = true
+	})
+	return resolved
+}
+
+// abandon wakes the caller without a decision
+func (w *waiter) abandon() {
+	w.once.Do(func() { close(w.ch) })
+}
@@ -0,0 +1,94 @@
+package approval
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
+)
+
+// webhookDecider is recorded as DecidedBy for webhook decisions
+const webhookDecider = "webhook"
+
+// AutoDecider resolves an approval without a human. An error means no
+// decision was reached and the request should go to a human.
+type AutoDecider interface {
+	Resolve(ctx context.Context, req policy.Request, reason string) (Decision, error)
+}
+
+// WebhookDecider asks an external service to approve or deny a request
+type WebhookDecider struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookDecider(url string, timeout time.Duration) *WebhookDecider {
+	return &WebhookDecider{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+type webhookRequest struct {
+	ToolName string          `json:"tool_name"`
+	Args     json.RawMessage `json:"args"`
+	Metadata map[string]any  `json:"metadata,omitempty"`
+	Reason   string          `json:"reason"`
+}
+
+type webhookResponse struct {
+	Approved *bool  `json:"approved"`
+	Reason   string `json:"reason"`
+}
+
+func (w *WebhookDecider) Resolve(ctx context.Context, req policy.Request, reason string) (Decision, error) {
+	payload, err := json.Marshal(webhookRequest{
+		ToolName: req.ToolName,
+		Args:     req.Args,
+		Metadata: req.Metadata,
+		Reason:   reason,
+	})
+	if err != nil {
+		return Decision{}, fmt.Errorf("marshal webhook request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(payload))
+	if err != nil {
+		return Decision{}, fmt.Errorf("create webhook request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(httpReq)
+	if err != nil {
+		return Decision{}, fmt.Errorf("call webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Decision{}, fmt.Errorf("webhook returned %d", resp.StatusCode)
+	}
+
+	var result webhookResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&result); err != nil {
+		return Decision{}, fmt.Errorf("decode webhook response: %w", err)
+	}
+
+	if result.Approved == nil {
+		return Decision{}, fmt.Errorf("webhook response missing approved")
+	}
+
+	if result.Reason == "" {
+		result.Reason = "decided by webhook"
+	}
+
+	return Decision{
+		Approved:  *result.Approved,
+		Reason:    result.Reason,
+		DecidedBy: webhookDecider,
+	}, nil
+}
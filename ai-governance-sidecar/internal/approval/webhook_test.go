@@ -0,0 +1,108 @@
+package approval
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
+)
+
+func TestWebhookDecider(t *testing.T) {
+	tests := []struct {
+		name           string
+		handler        http.HandlerFunc
+		expectApproved bool
+		expectReason   string
+		expectHuman    bool
+	}{
+		{
+			name: "webhook approves",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"approved":true,"reason":"low risk score"}`))
+			},
+			expectApproved: true,
+			expectReason:   "low risk score",
+		},
+		{
+			name: "webhook denies",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"approved":false,"reason":"risk score 0.97"}`))
+			},
+			expectReason: "risk score 0.97",
+		},
+		{
+			name: "webhook timeout falls back to human",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(300 * time.Millisecond)
+				w.Write([]byte(`{"approved":true}`))
+			},
+			expectApproved: true,
+			expectReason:   "human approved",
+			expectHuman:    true,
+		},
+		{
+			name: "webhook error falls back to human",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+			expectApproved: true,
+			expectReason:   "human approved",
+			expectHuman:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received webhookRequest
+			webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				json.NewDecoder(r.Body).Decode(&received)
+				tt.handler(w, r)
+			}))
+			defer webhook.Close()
+
+			queue := NewInMemoryQueue(5 * time.Second)
+			defer queue.Close()
+			queue.SetDecider(NewWebhookDecider(webhook.URL, 100*time.Millisecond))
+
+			ctx := context.Background()
+			if tt.expectHuman {
+				go approveFirstPending(t, queue)
+			}
+
+			decision, err := queue.Enqueue(ctx, policy.Request{
+				ToolName: "transfer_funds",
+				Args:     json.RawMessage(`{"amount":50}`),
+			}, "large transfer")
+			if err != nil {
+				t.Fatalf("enqueue failed: %v", err)
+			}
+
+			if received.ToolName != "transfer_funds" || received.Reason != "large transfer" {
+				t.Errorf("unexpected webhook payload: %+v", received)
+			}
+			if decision.Approved != tt.expectApproved || decision.Reason != tt.expectReason {
+				t.Errorf("unexpected decision: %+v", decision)
+			}
+			if !tt.expectHuman && decision.DecidedBy != webhookDecider {
+				t.Errorf("expected decided_by %q, got %q", webhookDecider, decision.DecidedBy)
+			}
+		})
+	}
+}
+
+func approveFirstPending(t *testing.T, queue *InMemoryQueue) {
+	ctx := context.Background()
+	for i := 0; i < 200; i++ {
+		pending, _ := queue.GetPending(ctx)
+		if len(pending) > 0 {
+			queue.Decide(ctx, pending[0].ID, Decision{Approved: true, Reason: "human approved"})
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Error("request never reached the human queue")
+}
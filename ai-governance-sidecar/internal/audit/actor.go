@@ -0,0 +1,52 @@
+package audit
+
+import "context"
+
+// Actor identifies the authenticated caller an entry is recorded for
+type Actor struct {
+	Email  string
+	Tenant string
+}
+
+type actorKey struct{}
+
+// WithActor attaches actor to ctx; stores record it on every entry logged
+// with that context
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor attached by WithActor
+func ActorFromContext(ctx context.Context) (Actor, bool) {
+	actor, ok := ctx.Value(actorKey{}).(Actor)
+	return actor, ok
+}
+
+// withContextActor fills in the entry's actor, a deny entry's category and
+// the dry-run mark from ctx
+func withContextActor(ctx context.Context, entry Entry) Entry {
+	if actor, ok := ActorFromContext(ctx); ok {
+		entry.Actor = actor.Email
+		entry.Tenant = actor.Tenant
+	}
+	if category, ok := DenyCategoryFromContext(ctx); ok && entry.Decision == DecisionDeny {
+		entry.DenyCategory = category
+	}
+	entry.DryRun = IsDryRun(ctx)
+	return entry
+}
+
+// entryContext carries a buffered entry's actor, category and dry-run mark
+// to the store it is replayed into
+func entryContext(ctx context.Context, entry Entry) context.Context {
+	if entry.DenyCategory != "" {
+		ctx = WithDenyCategory(ctx, entry.DenyCategory)
+	}
+	if entry.DryRun {
+		ctx = WithDryRun(ctx)
+	}
+	if entry.Actor == "" && entry.Tenant == "" {
+		return ctx
+	}
+	return WithActor(ctx, Actor{Email: entry.Actor, Tenant: entry.Tenant})
+}
@@ -3,6 +3,7 @@ package audit
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"path/filepath"
 	"strings"
@@ -202,6 +203,252 @@ func TestValidation(t *testing.T) {
 	}
 }
 
+func TestSQLiteStoreDetail(t *testing.T) {
+	store := setupTestStore(t)
+	defer store.Close()
+
+	ctx := context.Background()
+	toolInput := json.RawMessage(`{"tool":"test"}`)
+	if err := store.LogDetail(ctx, toolInput, DecisionDeny, "denied", json.RawMessage(`{"policy":"pii_guard"}`)); err != nil {
+		t.Fatalf("log detail failed: %v", err)
+	}
+	if err := store.Log(ctx, toolInput, DecisionAllow, "allowed"); err != nil {
+		t.Fatalf("log failed: %v", err)
+	}
+	if err := store.LogDetail(ctx, toolInput, DecisionDeny, "denied", json.RawMessage(`{bad`)); err == nil {
+		t.Error("expected error for invalid detail JSON")
+	}
+
+	entries, err := store.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("get all failed: %v", err)
+	}
+
+	for _, entry := range entries {
+		switch entry.Reason {
+		case "denied":
+			if !strings.Contains(string(entry.Detail), "pii_guard") {
+				t.Errorf("expected stored detail, got %s", entry.Detail)
+			}
+		case "allowed":
+			if entry.Detail != nil {
+				t.Errorf("expected no detail on lean entry, got %s", entry.Detail)
+			}
+		}
+	}
+}
+
+func TestSQLiteStoreAddsDetailColumn(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "old.db")
+
+	// Database created before the detail column existed
+	db, err := openDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	if _, err := db.Exec(`CREATE TABLE audit_log (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		timestamp DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		tool_input TEXT NOT NULL,
+		decision TEXT NOT NULL,
+		reason TEXT NOT NULL)`); err != nil {
+		t.Fatalf("create old table failed: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO audit_log (tool_input, decision, reason) VALUES ('{}', 'allow', 'old')`); err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+	db.Close()
+
+	store, err := NewSQLiteStore(dbPath)
+	if err != nil {
+		t.Fatalf("expected old database to be upgraded, got %v", err)
+	}
+	defer store.Close()
+
+	if err := store.LogDetail(context.Background(), json.RawMessage(`{}`), DecisionDeny, "new", json.RawMessage(`{}`)); err != nil {
+		t.Fatalf("log detail after upgrade failed: %v", err)
+	}
+
+	entries, err := store.GetAll(context.Background())
+	if err != nil {
+		t.Fatalf("get all failed: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("expected 2 entries, got %d", len(entries))
+	}
+}
+
+func TestSQLiteStoreAllowsAutoApproveInOldDatabase(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "old.db")
+
+	// Database created when decision was limited to allow and deny
+	db, err := openDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	oldSchema := strings.Replace(tableSchema, ", 'auto_approve'", "", 1)
+	for _, stmt := range []string{oldSchema, triggerPreventUpdate, triggerPreventDelete, indexTimestamp} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("create old schema failed: %v", err)
+		}
+	}
+	if _, err := db.Exec(`INSERT INTO audit_log (id, tool_input, decision, reason) VALUES (7, '{"tool_name":"read"}', 'allow', 'old')`); err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+	db.Close()
+
+	store, err := NewSQLiteStore(dbPath)
+	if err != nil {
+		t.Fatalf("expected old database to be upgraded, got %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	if err := store.Log(ctx, json.RawMessage(`{"tool_name":"read"}`), DecisionAutoApprove, "auto-approved: low risk"); err != nil {
+		t.Fatalf("log auto-approval after upgrade failed: %v", err)
+	}
+
+	old, err := store.GetEntry(ctx, 7)
+	if err != nil || old.Reason != "old" {
+		t.Errorf("expected the old entry to keep its id, got %+v (%v)", old, err)
+	}
+	entries, err := store.GetAll(ctx)
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d (%v)", len(entries), err)
+	}
+
+	summary, err := store.DecisionHistory(ctx, "", "read", time.Time{})
+	if err != nil || summary.Allow != 2 || summary.LastDecision != DecisionAutoApprove {
+		t.Errorf("expected auto-approvals to count as allows, got %+v (%v)", summary, err)
+	}
+
+	if _, err := store.db.Exec(`UPDATE audit_log SET reason = 'changed'`); err == nil {
+		t.Error("expected the rebuilt table to stay immutable")
+	}
+}
+
+func TestSQLiteStoreRecordsActor(t *testing.T) {
+	store := setupTestStore(t)
+	defer store.Close()
+
+	ctx := context.Background()
+	toolInput := json.RawMessage(`{"tool":"test"}`)
+	authed := WithActor(ctx, Actor{Email: "alice@example.com", Tenant: "acme"})
+	if err := store.Log(authed, toolInput, DecisionAllow, "authenticated"); err != nil {
+		t.Fatalf("log failed: %v", err)
+	}
+	if err := store.Log(ctx, toolInput, DecisionAllow, "anonymous"); err != nil {
+		t.Fatalf("log failed: %v", err)
+	}
+
+	entries, err := store.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("get all failed: %v", err)
+	}
+	for _, entry := range entries {
+		switch entry.Reason {
+		case "authenticated":
+			if entry.Actor != "alice@example.com" || entry.Tenant != "acme" {
+				t.Errorf("expected actor and tenant recorded, got %q/%q", entry.Actor, entry.Tenant)
+			}
+		case "anonymous":
+			if entry.Actor != "" || entry.Tenant != "" {
+				t.Errorf("expected no actor, got %q/%q", entry.Actor, entry.Tenant)
+			}
+		}
+	}
+
+	var nulls int
+	if err := store.db.QueryRow(`SELECT COUNT(*) FROM audit_log WHERE actor IS NULL AND tenant IS NULL`).Scan(&nulls); err != nil {
+		t.Fatalf("query failed: %v", err)
+	}
+	if nulls != 1 {
+		t.Errorf("expected the anonymous entry to store NULL actor and tenant, got %d rows", nulls)
+	}
+
+	if _, err := store.db.Exec(`UPDATE audit_log SET actor = 'mallory'`); err == nil {
+		t.Error("expected the actor column to be immutable")
+	}
+}
+
+func TestSQLiteStoreGetEntry(t *testing.T) {
+	store := setupTestStore(t)
+	defer store.Close()
+
+	ctx := context.Background()
+	for _, reason := range []string{"first", "second"} {
+		if err := store.Log(ctx, json.RawMessage(`{"tool":"test"}`), DecisionDeny, reason); err != nil {
+			t.Fatalf("log failed: %v", err)
+		}
+	}
+
+	entries, err := store.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("get all failed: %v", err)
+	}
+	// The server hands the proxy an observed store, which must read through
+	for _, reader := range []EntryReader{store, NewObserver(store)} {
+		for _, want := range entries {
+			got, err := reader.GetEntry(ctx, want.ID)
+			if err != nil {
+				t.Fatalf("get entry %d failed: %v", want.ID, err)
+			}
+			if got.Reason != want.Reason || got.Decision != want.Decision {
+				t.Errorf("entry %d: expected %s %q, got %s %q", want.ID, want.Decision, want.Reason, got.Decision, got.Reason)
+			}
+		}
+
+		if _, err := reader.GetEntry(ctx, 999); !errors.Is(err, ErrEntryNotFound) {
+			t.Errorf("expected ErrEntryNotFound for an unknown id, got %v", err)
+		}
+	}
+}
+
+func TestSQLiteStoreEntriesAfter(t *testing.T) {
+	store := setupTestStore(t)
+	defer store.Close()
+
+	ctx := context.Background()
+	for _, reason := range []string{"first", "second", "third", "fourth"} {
+		if err := store.Log(ctx, json.RawMessage(`{"tool":"test"}`), DecisionAllow, reason); err != nil {
+			t.Fatalf("log failed: %v", err)
+		}
+	}
+
+	reasons := func(entries []Entry) string {
+		var out []string
+		for _, entry := range entries {
+			out = append(out, entry.Reason)
+		}
+		return strings.Join(out, ",")
+	}
+
+	tests := []struct {
+		name   string
+		after  int64
+		limit  int
+		expect string
+	}{
+		{"everything", 0, 0, "first,second,third,fourth"},
+		{"after a cursor", 2, 0, "third,fourth"},
+		{"newest within limit", 0, 2, "third,fourth"},
+		{"nothing newer", 4, 0, ""},
+	}
+
+	// The stream reads through the observer as well
+	for _, reader := range []EntryReader{store, NewObserver(store)} {
+		for _, tt := range tests {
+			entries, err := reader.EntriesAfter(ctx, tt.after, tt.limit)
+			if err != nil {
+				t.Fatalf("%s: entries after failed: %v", tt.name, err)
+			}
+			if got := reasons(entries); got != tt.expect {
+				t.Errorf("%s: expected %q, got %q", tt.name, tt.expect, got)
+			}
+		}
+	}
+}
+
 func setupTestStore(t *testing.T) *SQLiteStore {
 	dbPath := filepath.Join(t.TempDir(), "test.db")
 	store, err := NewSQLiteStore(dbPath)
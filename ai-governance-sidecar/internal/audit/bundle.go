@@ -0,0 +1,129 @@
+package audit
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+const bundleVersion = 1
+
+// genesisHash seeds the hash chain for an empty log
+var genesisHash = strings.Repeat("0", sha256.Size*2)
+
+var ErrBundleInvalid = errors.New("audit bundle verification failed")
+
+// Bundle is a portable, signed export of the audit log. The signature
+// covers the chain head, which in turn covers every entry, so a recipient
+// holding the public key can verify the whole export offline.
+type Bundle struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	Entries   []Entry   `json:"entries"`
+	ChainHead string    `json:"chain_head"`
+	PublicKey string    `json:"public_key"`
+	Signature string    `json:"signature"`
+}
+
+// ChainHead folds entries into a SHA-256 hash chain. Each link hashes the
+// previous link followed by the entry's JSON encoding.
+func ChainHead(entries []Entry) (string, error) {
+	head := genesisHash
+	for _, entry := range entries {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return "", fmt.Errorf("encode entry %d: %w", entry.ID, err)
+		}
+
+		h := sha256.New()
+		h.Write([]byte(head))
+		h.Write(data)
+		head = hex.EncodeToString(h.Sum(nil))
+	}
+	return head, nil
+}
+
+// NewBundle hashes and signs entries with key
+func NewBundle(entries []Entry, key ed25519.PrivateKey, now time.Time) (Bundle, error) {
+	if entries == nil {
+		entries = []Entry{}
+	}
+
+	head, err := ChainHead(entries)
+	if err != nil {
+		return Bundle{}, err
+	}
+
+	bundle := Bundle{
+		Version:   bundleVersion,
+		CreatedAt: now.UTC(),
+		Entries:   entries,
+		ChainHead: head,
+		PublicKey: base64.StdEncoding.EncodeToString(key.Public().(ed25519.PublicKey)),
+	}
+	bundle.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(key, bundle.signingPayload()))
+	return bundle, nil
+}
+
+// VerifyBundle checks the chain against the entries and the signature
+// against pub. Pass the published key, not the one embedded in the bundle.
+func VerifyBundle(bundle Bundle, pub ed25519.PublicKey) error {
+	head, err := ChainHead(bundle.Entries)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrBundleInvalid, err)
+	}
+	if head != bundle.ChainHead {
+		return fmt.Errorf("%w: chain head mismatch", ErrBundleInvalid)
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(bundle.Signature)
+	if err != nil {
+		return fmt.Errorf("%w: malformed signature", ErrBundleInvalid)
+	}
+	if !ed25519.Verify(pub, bundle.signingPayload(), sig) {
+		return fmt.Errorf("%w: bad signature", ErrBundleInvalid)
+	}
+	return nil
+}
+
+func (b Bundle) signingPayload() []byte {
+	return []byte(fmt.Sprintf("audit-bundle-v%d\n%s\n%d\n%s",
+		b.Version, b.CreatedAt.UTC().Format(time.RFC3339Nano), len(b.Entries), b.ChainHead))
+}
+
+// LoadSigningKey reads a base64 Ed25519 seed (32 bytes) or private key
+// (64 bytes) from path. An empty path yields a random key that only lives
+// as long as the process.
+func LoadSigningKey(path string) (ed25519.PrivateKey, error) {
+	if path == "" {
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		return key, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read signing key: %w", err)
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("decode signing key: %w", err)
+	}
+
+	switch len(raw) {
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(raw), nil
+	case ed25519.PrivateKeySize:
+		return ed25519.PrivateKey(raw), nil
+	default:
+		return nil, fmt.Errorf("signing key must be %d or %d bytes, got %d", ed25519.SeedSize, ed25519.PrivateKeySize, len(raw))
+	}
+}
@@ -0,0 +1,94 @@
+package audit
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBundleVerifies(t *testing.T) {
+	store := setupTestStore(t)
+	defer store.Close()
+
+	ctx := context.Background()
+	for _, reason := range []string{"first", "second", "third"} {
+		if err := store.Log(ctx, json.RawMessage(`{"tool_name":"t","args":{"n": 1}}`), DecisionAllow, reason); err != nil {
+			t.Fatalf("log failed: %v", err)
+		}
+	}
+
+	entries, err := store.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("get all failed: %v", err)
+	}
+
+	pub, key, _ := ed25519.GenerateKey(rand.Reader)
+	bundle, err := NewBundle(entries, key, time.Now())
+	if err != nil {
+		t.Fatalf("bundle failed: %v", err)
+	}
+
+	// Verify what a recipient would see: the downloaded file
+	data, err := json.Marshal(bundle)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	var downloaded Bundle
+	if err := json.Unmarshal(data, &downloaded); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+
+	if err := VerifyBundle(downloaded, pub); err != nil {
+		t.Fatalf("expected bundle to verify, got %v", err)
+	}
+
+	tampered := downloaded
+	tampered.Entries = append([]Entry(nil), downloaded.Entries...)
+	tampered.Entries[1].Reason = "rewritten"
+	if err := VerifyBundle(tampered, pub); !errors.Is(err, ErrBundleInvalid) {
+		t.Errorf("expected edited entry to fail verification, got %v", err)
+	}
+
+	dropped := downloaded
+	dropped.Entries = downloaded.Entries[:2]
+	if err := VerifyBundle(dropped, pub); !errors.Is(err, ErrBundleInvalid) {
+		t.Errorf("expected dropped entry to fail verification, got %v", err)
+	}
+
+	otherPub, _, _ := ed25519.GenerateKey(rand.Reader)
+	if err := VerifyBundle(downloaded, otherPub); !errors.Is(err, ErrBundleInvalid) {
+		t.Errorf("expected wrong key to fail verification, got %v", err)
+	}
+}
+
+func TestLoadSigningKey(t *testing.T) {
+	seed := make([]byte, ed25519.SeedSize)
+	rand.Read(seed)
+
+	path := filepath.Join(t.TempDir(), "audit.key")
+	if err := os.WriteFile(path, []byte(base64.StdEncoding.EncodeToString(seed)+"\n"), 0o600); err != nil {
+		t.Fatalf("write key: %v", err)
+	}
+
+	key, err := LoadSigningKey(path)
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if !key.Equal(ed25519.NewKeyFromSeed(seed)) {
+		t.Error("expected key derived from seed")
+	}
+
+	if err := os.WriteFile(path, []byte(base64.StdEncoding.EncodeToString([]byte("short"))), 0o600); err != nil {
+		t.Fatalf("write key: %v", err)
+	}
+	if _, err := LoadSigningKey(path); err == nil {
+		t.Error("expected error for wrong key length")
+	}
+}
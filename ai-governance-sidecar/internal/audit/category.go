@@ -0,0 +1,56 @@
+package audit
+
+import "context"
+
+// DenyCategory classifies why a call was denied, for reporting
+type DenyCategory string
+
+const (
+	// DenyPolicy is a deny from a policy module
+	DenyPolicy DenyCategory = "policy"
+	// DenyRateLimit is a caller over their quota
+	DenyRateLimit DenyCategory = "rate_limit"
+	// DenyApprovalTimeout is an approval nobody decided in time
+	DenyApprovalTimeout DenyCategory = "approval_timeout"
+	// DenyApprovalRejected is an approval, override or appeal a human rejected
+	DenyApprovalRejected DenyCategory = "approval_rejected"
+	// DenySSRFBlock is an upstream outside the allowlist
+	DenySSRFBlock DenyCategory = "ssrf_block"
+	// DenySchemaViolation is a call missing required metadata or justification
+	DenySchemaViolation DenyCategory = "schema_violation"
+	// DenyToolBlocked is a tool name matched by TOOL_NAME_PATTERNS
+	DenyToolBlocked DenyCategory = "tool_blocked"
+	// DenySensitiveData is arguments matched by SENSITIVE_PATTERNS
+	DenySensitiveData DenyCategory = "sensitive_data"
+	// DenyTimeWindow is a call inside a deny TIME_WINDOWS entry
+	DenyTimeWindow DenyCategory = "time_window"
+	// DenyUncategorized is reported for denials logged without a category
+	DenyUncategorized DenyCategory = "uncategorized"
+)
+
+type denyCategoryKey struct{}
+
+// WithDenyCategory attaches category to ctx; stores record it on deny
+// entries logged with that context
+func WithDenyCategory(ctx context.Context, category DenyCategory) context.Context {
+	return context.WithValue(ctx, denyCategoryKey{}, category)
+}
+
+// DenyCategoryFromContext returns the category attached by WithDenyCategory
+func DenyCategoryFromContext(ctx context.Context) (DenyCategory, bool) {
+	category, ok := ctx.Value(denyCategoryKey{}).(DenyCategory)
+	return category, ok
+}
+
+type dryRunKey struct{}
+
+// WithDryRun marks entries logged with ctx as dry-run decisions
+func WithDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, true)
+}
+
+// IsDryRun reports whether ctx was marked by WithDryRun
+func IsDryRun(ctx context.Context) bool {
+	dryRun, _ := ctx.Value(dryRunKey{}).(bool)
+	return dryRun
+}
@@ -0,0 +1,253 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const defaultDeferredBufferSize = 1000
+
+// Opener creates the real audit store
+type Opener func() (Store, error)
+
+// DeferredStore stands in for an audit store that could not be opened at
+// startup. Entries are buffered in memory while the real store is retried in
+// the background, then flushed once it becomes available. Buffered entries
+// are timestamped when flushed, not when logged.
+type DeferredStore struct {
+	mu       sync.RWMutex
+	open     Opener
+	store    Store
+	buffer   []Entry
+	maxBuf   int
+	dropped  int
+	interval time.Duration
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+	closed   bool
+}
+
+// NewDeferredStore starts retrying open every interval until it succeeds.
+func NewDeferredStore(open Opener, interval time.Duration) *DeferredStore {
+	d := &DeferredStore{
+		open:     open,
+		maxBuf:   defaultDeferredBufferSize,
+		interval: interval,
+		stopCh:   make(chan struct{}),
+		doneCh:   make(chan struct{}),
+	}
+
+	go d.retryLoop()
+	return d
+}
+
+// Available reports whether the real store has been opened
+func (d *DeferredStore) Available() bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.store != nil
+}
+
+func (d *DeferredStore) Log(ctx context.Context, toolInput json.RawMessage, decision Decision, reason string) error {
+	return d.LogDetail(ctx, toolInput, decision, reason, nil)
+}
+
+func (d *DeferredStore) LogDetail(ctx context.Context, toolInput json.RawMessage, decision Decision, reason string, detail json.RawMessage) error {
+	if err := validateLogInput(toolInput, decision, reason); err != nil {
+		return err
+	}
+	if err := validateDetail(detail); err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.store != nil {
+		return LogWithDetail(ctx, d.store, toolInput, decision, reason, detail)
+	}
+
+	return d.bufferLocked(withContextActor(ctx, Entry{
+		Timestamp: time.Now(),
+		ToolInput: toolInput,
+		Decision:  decision,
+		Reason:    reason,
+		Detail:    detail,
+	}))
+}
+
+func (d *DeferredStore) bufferLocked(entry Entry) error {
+	if len(d.buffer) >= d.maxBuf {
+		d.dropped++
+		log.Warn().Int("dropped", d.dropped).Msg("audit buffer full, entry dropped")
+		return nil
+	}
+
+	d.buffer = append(d.buffer, entry)
+	return nil
+}
+
+func (d *DeferredStore) LogApproval(ctx context.Context, toolInput json.RawMessage, decision Decision, reason, approver string, latency time.Duration) error {
+	if err := validateLogInput(toolInput, decision, reason); err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.store != nil {
+		return LogApproval(ctx, d.store, toolInput, decision, reason, approver, latency)
+	}
+
+	return d.bufferLocked(withContextActor(ctx, Entry{
+		Timestamp:         time.Now(),
+		ToolInput:         toolInput,
+		Decision:          decision,
+		Reason:            reason,
+		Approver:          approver,
+		ApprovalLatencyMs: latency.Milliseconds(),
+	}))
+}
+
+// ApproverReport is served by the real store once it is open
+func (d *DeferredStore) ApproverReport(ctx context.Context, from, to time.Time) ([]ApproverStats, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	reporter, ok := d.store.(ApproverReporter)
+	if !ok {
+		return nil, ErrStoreUnavailable
+	}
+	return reporter.ApproverReport(ctx, from, to)
+}
+
+// DenialReport is served by the real store once it is open
+func (d *DeferredStore) DenialReport(ctx context.Context, from, to time.Time) ([]DenialStats, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	reporter, ok := d.store.(DenialReporter)
+	if !ok {
+		return nil, ErrStoreUnavailable
+	}
+	return reporter.DenialReport(ctx, from, to)
+}
+
+// DecisionHistory is served by the real store once it is open
+func (d *DeferredStore) DecisionHistory(ctx context.Context, actor, tool string, since time.Time) (DecisionSummary, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	historian, ok := d.store.(DecisionHistorian)
+	if !ok {
+		return DecisionSummary{}, ErrStoreUnavailable
+	}
+	return historian.DecisionHistory(ctx, actor, tool, since)
+}
+
+// GetEntry is served by the real store once it is open; buffered entries
+// have no id yet
+func (d *DeferredStore) GetEntry(ctx context.Context, id int64) (Entry, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	reader, ok := d.store.(EntryReader)
+	if !ok {
+		return Entry{}, ErrStoreUnavailable
+	}
+	return reader.GetEntry(ctx, id)
+}
+
+// EntriesAfter is served by the real store once it is open. Until then
+// every entry is buffered without an id, so there are none to return.
+func (d *DeferredStore) EntriesAfter(ctx context.Context, after int64, limit int) ([]Entry, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if d.store == nil {
+		return nil, nil
+	}
+	return EntriesAfter(ctx, d.store, after, limit)
+}
+
+func (d *DeferredStore) GetAll(ctx context.Context) ([]Entry, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if d.store != nil {
+		return d.store.GetAll(ctx)
+	}
+
+	entries := make([]Entry, len(d.buffer))
+	copy(entries, d.buffer)
+	return entries, nil
+}
+
+func (d *DeferredStore) Close() error {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return nil
+	}
+	d.closed = true
+	close(d.stopCh)
+	d.mu.Unlock()
+
+	<-d.doneCh
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if len(d.buffer) > 0 {
+		log.Warn().Int("entries", len(d.buffer)).Msg("audit store never became available, buffered entries lost")
+	}
+	if d.store != nil {
+		return d.store.Close()
+	}
+	return nil
+}
+
+func (d *DeferredStore) retryLoop() {
+	defer close(d.doneCh)
+
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.stopCh:
+			return
+		case <-ticker.C:
+			if d.tryOpen() {
+				return
+			}
+		}
+	}
+}
+
+func (d *DeferredStore) tryOpen() bool {
+	store, err := d.open()
+	if err != nil {
+		log.Warn().Err(err).Msg("audit store still unavailable")
+		return false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	ctx := context.Background()
+	for _, entry := range d.buffer {
+		if err := writeEntry(ctx, store, entry); err != nil {
+			log.Error().Err(err).Msg("failed to flush buffered audit entry")
+		}
+	}
+
+	log.Info().Int("flushed", len(d.buffer)).Msg("audit store recovered")
+	d.buffer = nil
+	d.store = store
+	return true
+}
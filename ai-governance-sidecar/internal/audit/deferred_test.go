@@ -0,0 +1,98 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDeferredStoreBuffersUntilAvailable(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "deferred.db")
+
+	var ready atomic.Bool
+	open := func() (Store, error) {
+		if !ready.Load() {
+			return nil, errors.New("disk unavailable")
+		}
+		return NewSQLiteStore(dbPath)
+	}
+
+	store := NewDeferredStore(open, 10*time.Millisecond)
+	defer store.Close()
+
+	if store.Available() {
+		t.Fatal("expected store to start degraded")
+	}
+
+	ctx := context.Background()
+	toolInput := json.RawMessage(`{"tool":"test"}`)
+	if err := store.Log(ctx, toolInput, DecisionAllow, "buffered"); err != nil {
+		t.Fatalf("log while degraded failed: %v", err)
+	}
+
+	entries, err := store.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("get all while degraded failed: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 buffered entry, got %d", len(entries))
+	}
+
+	ready.Store(true)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !store.Available() {
+		if time.Now().After(deadline) {
+			t.Fatal("store did not recover")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := store.Log(ctx, toolInput, DecisionDeny, "after recovery"); err != nil {
+		t.Fatalf("log after recovery failed: %v", err)
+	}
+
+	entries, err = store.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("get all after recovery failed: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected buffered entry to be flushed, got %d entries", len(entries))
+	}
+}
+
+func TestDeferredStoreDropsWhenBufferFull(t *testing.T) {
+	open := func() (Store, error) { return nil, errors.New("disk unavailable") }
+
+	store := NewDeferredStore(open, time.Hour)
+	store.maxBuf = 2
+	defer store.Close()
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		if err := store.Log(ctx, json.RawMessage(`{}`), DecisionAllow, "entry"); err != nil {
+			t.Fatalf("log failed: %v", err)
+		}
+	}
+
+	entries, _ := store.GetAll(ctx)
+	if len(entries) != 2 {
+		t.Errorf("expected buffer capped at 2, got %d", len(entries))
+	}
+	if store.dropped != 1 {
+		t.Errorf("expected 1 dropped entry, got %d", store.dropped)
+	}
+}
+
+func TestDeferredStoreRejectsInvalidInput(t *testing.T) {
+	store := NewDeferredStore(func() (Store, error) { return nil, errors.New("down") }, time.Hour)
+	defer store.Close()
+
+	if err := store.Log(context.Background(), json.RawMessage(`{}`), Decision("maybe"), "reason"); err == nil {
+		t.Error("expected invalid decision to be rejected while degraded")
+	}
+}
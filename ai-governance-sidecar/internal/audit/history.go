@@ -0,0 +1,54 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// DecisionSummary counts one caller's decisions on one tool over a period
+type DecisionSummary struct {
+	Allow        int        `json:"allow"`
+	Deny         int        `json:"deny"`
+	LastDecision Decision   `json:"last_decision,omitempty"`
+	LastAt       *time.Time `json:"last_at,omitempty"`
+}
+
+// DecisionHistorian is implemented by stores that can summarize a caller's
+// recent decisions without loading the whole log. An empty actor matches
+// entries logged without auth.
+type DecisionHistorian interface {
+	DecisionHistory(ctx context.Context, actor, tool string, since time.Time) (DecisionSummary, error)
+}
+
+// DecisionHistory summarizes entries for actor and tool logged at or after
+// since.
+func (s *SQLiteStore) DecisionHistory(ctx context.Context, actor, tool string, since time.Time) (DecisionSummary, error) {
+	lower := since.UTC().Format(timestampLayout)
+
+	var summary DecisionSummary
+	if err := s.db.QueryRowContext(ctx, queryDecisionCounts, actor, tool, lower).Scan(&summary.Allow, &summary.Deny); err != nil {
+		return DecisionSummary{}, fmt.Errorf("query decision history: %w", err)
+	}
+	if summary.Allow+summary.Deny == 0 {
+		return summary, nil
+	}
+
+	var decision, timestamp string
+	err := s.db.QueryRowContext(ctx, queryLastDecision, actor, tool, lower).Scan(&decision, &timestamp)
+	if err == sql.ErrNoRows {
+		return summary, nil
+	}
+	if err != nil {
+		return DecisionSummary{}, fmt.Errorf("query last decision: %w", err)
+	}
+
+	lastAt, err := parseTimestamp(timestamp)
+	if err != nil {
+		return DecisionSummary{}, err
+	}
+	summary.LastDecision = Decision(decision)
+	summary.LastAt = &lastAt
+	return summary, nil
+}
@@ -0,0 +1,52 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestDecisionHistory(t *testing.T) {
+	store := setupTestStore(t)
+	defer store.Close()
+
+	bob := WithActor(context.Background(), Actor{Email: "bob@example.com"})
+	eve := WithActor(context.Background(), Actor{Email: "eve@example.com"})
+	dropTable := json.RawMessage(`{"tool_name":"drop_table"}`)
+
+	logs := []struct {
+		ctx      context.Context
+		input    json.RawMessage
+		decision Decision
+	}{
+		{bob, dropTable, DecisionAllow},
+		{bob, dropTable, DecisionDeny},
+		{bob, json.RawMessage(`{"tool_name":"read_file"}`), DecisionAllow},
+		{eve, dropTable, DecisionAllow},
+	}
+	for _, l := range logs {
+		if err := store.Log(l.ctx, l.input, l.decision, "logged"); err != nil {
+			t.Fatalf("log failed: %v", err)
+		}
+	}
+
+	summary, err := store.DecisionHistory(context.Background(), "bob@example.com", "drop_table", time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("history failed: %v", err)
+	}
+	if summary.Allow != 1 || summary.Deny != 1 {
+		t.Errorf("expected 1 allow and 1 deny, got %+v", summary)
+	}
+	if summary.LastDecision != DecisionDeny || summary.LastAt == nil {
+		t.Errorf("expected the last decision to be deny, got %+v", summary)
+	}
+
+	empty, err := store.DecisionHistory(context.Background(), "bob@example.com", "drop_table", time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("history failed: %v", err)
+	}
+	if empty.Allow != 0 || empty.Deny != 0 || empty.LastAt != nil {
+		t.Errorf("expected no decisions after since, got %+v", empty)
+	}
+}
@@ -0,0 +1,91 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// Observer wraps a store and wakes subscribers after every successful write.
+// Subscribers are told that something was written, not what; they re-read
+// the store from their own cursor, so a coalesced wake-up never loses an
+// entry.
+type Observer struct {
+	Store
+
+	mu   sync.Mutex
+	subs map[chan struct{}]struct{}
+}
+
+func NewObserver(store Store) *Observer {
+	return &Observer{Store: store, subs: make(map[chan struct{}]struct{})}
+}
+
+func (o *Observer) Log(ctx context.Context, toolInput json.RawMessage, decision Decision, reason string) error {
+	if err := o.Store.Log(ctx, toolInput, decision, reason); err != nil {
+		return err
+	}
+	o.notify()
+	return nil
+}
+
+func (o *Observer) LogDetail(ctx context.Context, toolInput json.RawMessage, decision Decision, reason string, detail json.RawMessage) error {
+	if err := LogWithDetail(ctx, o.Store, toolInput, decision, reason, detail); err != nil {
+		return err
+	}
+	o.notify()
+	return nil
+}
+
+func (o *Observer) LogApproval(ctx context.Context, toolInput json.RawMessage, decision Decision, reason, approver string, latency time.Duration) error {
+	if err := LogApproval(ctx, o.Store, toolInput, decision, reason, approver, latency); err != nil {
+		return err
+	}
+	o.notify()
+	return nil
+}
+
+// GetEntry reads through to the wrapped store, so appeals work on an
+// observed store
+func (o *Observer) GetEntry(ctx context.Context, id int64) (Entry, error) {
+	reader, ok := o.Store.(EntryReader)
+	if !ok {
+		return Entry{}, ErrStoreUnavailable
+	}
+	return reader.GetEntry(ctx, id)
+}
+
+// EntriesAfter reads through to the wrapped store
+func (o *Observer) EntriesAfter(ctx context.Context, after int64, limit int) ([]Entry, error) {
+	return EntriesAfter(ctx, o.Store, after, limit)
+}
+
+// Subscribe returns a channel that receives a value after writes, and a
+// function that stops the subscription. Bursts of writes may be delivered
+// as a single wake-up.
+func (o *Observer) Subscribe() (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	o.mu.Lock()
+	o.subs[ch] = struct{}{}
+	o.mu.Unlock()
+
+	return ch, func() {
+		o.mu.Lock()
+		delete(o.subs, ch)
+		o.mu.Unlock()
+	}
+}
+
+func (o *Observer) notify() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for ch := range o.subs {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
@@ -2,13 +2,62 @@ package audit
 
 const (
 	queryInsertEntry = `
-		INSERT INTO audit_log (tool_input, decision, reason) 
-		VALUES (?, ?, ?)`
+		INSERT INTO audit_log (timestamp, tool_input, decision, reason, detail, signature, approver, approval_latency_ms, actor, tenant, deny_category, dry_run) 
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
 	querySelectAll = `
-		SELECT id, timestamp, tool_input, decision, reason 
+		SELECT id, timestamp, tool_input, decision, reason, detail, signature, approver, approval_latency_ms, actor, tenant, deny_category, dry_run 
 		FROM audit_log 
 		ORDER BY timestamp DESC`
 
+	querySelectByID = `
+		SELECT id, timestamp, tool_input, decision, reason, detail, signature, approver, approval_latency_ms, actor, tenant, deny_category, dry_run
+		FROM audit_log
+		WHERE id = ?`
+
+	// Newest first so LIMIT keeps the latest entries; -1 means no limit
+	querySelectAfter = `
+		SELECT id, timestamp, tool_input, decision, reason, detail, signature, approver, approval_latency_ms, actor, tenant, deny_category, dry_run
+		FROM audit_log
+		WHERE id > ?
+		ORDER BY id DESC
+		LIMIT ?`
+
+	queryApproverReport = `
+		SELECT approver,
+			SUM(CASE WHEN decision = 'allow' THEN 1 ELSE 0 END),
+			SUM(CASE WHEN decision = 'deny' THEN 1 ELSE 0 END),
+			AVG(approval_latency_ms)
+		FROM audit_log
+		WHERE approver IS NOT NULL AND timestamp >= ? AND timestamp <= ?
+		GROUP BY approver
+		ORDER BY approver`
+
+	queryDenialReport = `
+		SELECT COALESCE(deny_category, 'uncategorized'), COUNT(*)
+		FROM audit_log
+		WHERE decision = 'deny' AND dry_run IS NULL AND timestamp >= ? AND timestamp <= ?
+		GROUP BY 1
+		ORDER BY 2 DESC, 1`
+
+	// historyFilter matches one caller's real calls to one tool since a
+	// time; dry runs are left out
+	historyFilter = `
+		WHERE dry_run IS NULL
+			AND COALESCE(actor, '') = ?
+			AND json_extract(tool_input, '$.tool_name') = ?
+			AND timestamp >= ?`
+
+	queryDecisionCounts = `
+		SELECT COALESCE(SUM(CASE WHEN decision IN ('allow', 'auto_approve') THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN decision = 'deny' THEN 1 ELSE 0 END), 0)
+		FROM audit_log` + historyFilter
+
+	queryLastDecision = `
+		SELECT decision, timestamp
+		FROM audit_log` + historyFilter + `
+		ORDER BY id DESC
+		LIMIT 1`
+
 	timestampLayout = "2006-01-02 15:04:05"
 )
\ No newline at end of file
@@ -0,0 +1,98 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ApproverStats summarises one approver's decisions over a period
+type ApproverStats struct {
+	Approver     string  `json:"approver"`
+	Approvals    int     `json:"approvals"`
+	Denials      int     `json:"denials"`
+	AvgLatencyMs float64 `json:"avg_latency_ms"`
+}
+
+// ApproverReporter is implemented by stores that can aggregate approval
+// entries by approver.
+type ApproverReporter interface {
+	ApproverReport(ctx context.Context, from, to time.Time) ([]ApproverStats, error)
+}
+
+// ApproverReport aggregates approval entries logged between from and to,
+// inclusive. A zero from or to leaves that end open.
+func (s *SQLiteStore) ApproverReport(ctx context.Context, from, to time.Time) ([]ApproverStats, error) {
+	lower, upper := "", "9999-12-31 23:59:59"
+	if !from.IsZero() {
+		lower = from.UTC().Format(timestampLayout)
+	}
+	if !to.IsZero() {
+		upper = to.UTC().Format(timestampLayout)
+	}
+
+	rows, err := s.db.QueryContext(ctx, queryApproverReport, lower, upper)
+	if err != nil {
+		return nil, fmt.Errorf("query approver report: %w", err)
+	}
+	defer rows.Close()
+
+	stats := []ApproverStats{}
+	for rows.Next() {
+		var row ApproverStats
+		if err := rows.Scan(&row.Approver, &row.Approvals, &row.Denials, &row.AvgLatencyMs); err != nil {
+			return nil, fmt.Errorf("scan approver report: %w", err)
+		}
+		stats = append(stats, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+
+	return stats, nil
+}
+
+// DenialStats counts denials of one category over a period
+type DenialStats struct {
+	Category DenyCategory `json:"category"`
+	Count    int          `json:"count"`
+}
+
+// DenialReporter is implemented by stores that can aggregate denials by
+// category.
+type DenialReporter interface {
+	DenialReport(ctx context.Context, from, to time.Time) ([]DenialStats, error)
+}
+
+// DenialReport counts deny entries logged between from and to, inclusive,
+// by category, most frequent first. Denials logged without a category are
+// counted as DenyUncategorized. A zero from or to leaves that end open.
+func (s *SQLiteStore) DenialReport(ctx context.Context, from, to time.Time) ([]DenialStats, error) {
+	lower, upper := "", "9999-12-31 23:59:59"
+	if !from.IsZero() {
+		lower = from.UTC().Format(timestampLayout)
+	}
+	if !to.IsZero() {
+		upper = to.UTC().Format(timestampLayout)
+	}
+
+	rows, err := s.db.QueryContext(ctx, queryDenialReport, lower, upper)
+	if err != nil {
+		return nil, fmt.Errorf("query denial report: %w", err)
+	}
+	defer rows.Close()
+
+	stats := []DenialStats{}
+	for rows.Next() {
+		var row DenialStats
+		if err := rows.Scan(&row.Category, &row.Count); err != nil {
+			return nil, fmt.Errorf("scan denial report: %w", err)
+		}
+		stats = append(stats, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,148 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestApproverReport(t *testing.T) {
+	store := setupTestStore(t)
+	defer store.Close()
+
+	ctx := context.Background()
+	input := json.RawMessage(`{"tool_name":"send_payment"}`)
+
+	approvals := []struct {
+		approver string
+		decision Decision
+		latency  time.Duration
+	}{
+		{"alice@example.com", DecisionAllow, 2 * time.Second},
+		{"alice@example.com", DecisionAllow, 4 * time.Second},
+		{"alice@example.com", DecisionDeny, 6 * time.Second},
+		{"bob@example.com", DecisionDeny, 10 * time.Second},
+	}
+	for _, a := range approvals {
+		if err := store.LogApproval(ctx, input, a.decision, "decided", a.approver, a.latency); err != nil {
+			t.Fatalf("log approval failed: %v", err)
+		}
+	}
+	// Policy decisions carry no approver and are left out of the report
+	if err := store.Log(ctx, input, DecisionAllow, "all policies passed"); err != nil {
+		t.Fatalf("log failed: %v", err)
+	}
+
+	report, err := store.ApproverReport(ctx, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("report failed: %v", err)
+	}
+
+	want := []ApproverStats{
+		{Approver: "alice@example.com", Approvals: 2, Denials: 1, AvgLatencyMs: 4000},
+		{Approver: "bob@example.com", Approvals: 0, Denials: 1, AvgLatencyMs: 10000},
+	}
+	if len(report) != len(want) {
+		t.Fatalf("expected %d approvers, got %+v", len(want), report)
+	}
+	for i := range want {
+		if report[i] != want[i] {
+			t.Errorf("approver %d: expected %+v, got %+v", i, want[i], report[i])
+		}
+	}
+}
+
+func TestApproverReportWindow(t *testing.T) {
+	store := setupTestStore(t)
+	defer store.Close()
+
+	ctx := context.Background()
+	if err := store.LogApproval(ctx, json.RawMessage(`{}`), DecisionAllow, "ok", "alice@example.com", time.Second); err != nil {
+		t.Fatalf("log approval failed: %v", err)
+	}
+
+	future := time.Now().Add(time.Hour)
+	report, err := store.ApproverReport(ctx, future, time.Time{})
+	if err != nil {
+		t.Fatalf("report failed: %v", err)
+	}
+	if len(report) != 0 {
+		t.Errorf("expected no approvers after from, got %+v", report)
+	}
+
+	report, err = store.ApproverReport(ctx, time.Now().Add(-time.Hour), future)
+	if err != nil {
+		t.Fatalf("report failed: %v", err)
+	}
+	if len(report) != 1 || report[0].Approvals != 1 {
+		t.Errorf("expected alice in window, got %+v", report)
+	}
+}
+
+func TestDenialReport(t *testing.T) {
+	store := setupTestStore(t)
+	defer store.Close()
+
+	ctx := context.Background()
+	input := json.RawMessage(`{"tool_name":"deploy"}`)
+	logs := []struct {
+		category DenyCategory
+		decision Decision
+		dryRun   bool
+	}{
+		{DenyPolicy, DecisionDeny, false},
+		{DenyPolicy, DecisionDeny, false},
+		{DenyRateLimit, DecisionDeny, false},
+		{"", DecisionDeny, false},
+		// A category on an allow is ignored
+		{DenyPolicy, DecisionAllow, false},
+		// Dry runs are not real denials
+		{DenyPolicy, DecisionDeny, true},
+	}
+	for _, l := range logs {
+		logCtx := ctx
+		if l.category != "" {
+			logCtx = WithDenyCategory(ctx, l.category)
+		}
+		if l.dryRun {
+			logCtx = WithDryRun(logCtx)
+		}
+		if err := store.Log(logCtx, input, l.decision, "logged"); err != nil {
+			t.Fatalf("log failed: %v", err)
+		}
+	}
+
+	report, err := store.DenialReport(ctx, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("report failed: %v", err)
+	}
+	want := []DenialStats{
+		{Category: DenyPolicy, Count: 2},
+		{Category: DenyRateLimit, Count: 1},
+		{Category: DenyUncategorized, Count: 1},
+	}
+	if len(report) != len(want) {
+		t.Fatalf("expected %+v, got %+v", want, report)
+	}
+	for i := range want {
+		if report[i] != want[i] {
+			t.Errorf("row %d: expected %+v, got %+v", i, want[i], report[i])
+		}
+	}
+
+	entries, _ := store.GetAll(ctx)
+	for _, entry := range entries {
+		if entry.Decision == DecisionAllow && entry.DenyCategory != "" {
+			t.Errorf("expected no category on an allow, got %q", entry.DenyCategory)
+		}
+	}
+
+	report, err = store.DenialReport(ctx, time.Now().Add(time.Hour), time.Time{})
+	if err != nil {
+		t.Fatalf("report failed: %v", err)
+	}
+	if len(report) != 0 {
+		t.Errorf("expected no denials after from, got %+v", report)
+	}
+}
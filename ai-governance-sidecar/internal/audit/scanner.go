@@ -29,8 +29,14 @@ func scanEntry(rows *sql.Rows) (Entry, error) {
 	var e Entry
 	var timestamp string
 	var toolInput string
+	var detail sql.NullString
+	var signature sql.NullString
+	var approver sql.NullString
+	var latency sql.NullInt64
+	var actor, tenant, category sql.NullString
+	var dryRun sql.NullInt64
 
-	if err := rows.Scan(&e.ID, &timestamp, &toolInput, &e.Decision, &e.Reason); err != nil {
+	if err := rows.Scan(&e.ID, &timestamp, &toolInput, &e.Decision, &e.Reason, &detail, &signature, &approver, &latency, &actor, &tenant, &category, &dryRun); err != nil {
 		return Entry{}, fmt.Errorf("scan row: %w", err)
 	}
 
@@ -41,6 +47,16 @@ func scanEntry(rows *sql.Rows) (Entry, error) {
 	e.Timestamp = parsedTime
 
 	e.ToolInput = json.RawMessage(toolInput)
+	if detail.Valid {
+		e.Detail = json.RawMessage(detail.String)
+	}
+	e.Signature = signature.String
+	e.Approver = approver.String
+	e.ApprovalLatencyMs = latency.Int64
+	e.Actor = actor.String
+	e.Tenant = tenant.String
+	e.DenyCategory = DenyCategory(category.String)
+	e.DryRun = dryRun.Int64 != 0
 
 	return e, nil
 }
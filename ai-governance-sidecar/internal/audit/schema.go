@@ -6,8 +6,16 @@ const (
 			id INTEGER PRIMARY KEY AUTOINCREMENT,
 			timestamp DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 			tool_input TEXT NOT NULL,
-			decision TEXT NOT NULL CHECK(decision IN ('allow', 'deny')),
-			reason TEXT NOT NULL
+			decision TEXT NOT NULL CHECK(decision IN ('allow', 'deny', 'auto_approve')),
+			reason TEXT NOT NULL,
+			detail TEXT,
+			signature TEXT,
+			approver TEXT,
+			approval_latency_ms INTEGER,
+			actor TEXT,
+			tenant TEXT,
+			deny_category TEXT,
+			dry_run INTEGER
 		)`
 
 	triggerPreventUpdate = `
@@ -37,4 +45,36 @@ func schemaStatements() []string {
 		triggerPreventDelete,
 		indexTimestamp,
 	}
-}
\ No newline at end of file
+}
+
+// decisionCheckRebuild copies a table created before auto_approve was a
+// valid decision into one whose CHECK allows it, keeping ids, timestamps
+// and signatures. SQLite cannot alter a CHECK in place. The triggers are
+// recreated by schemaStatements afterwards.
+var decisionCheckRebuild = []string{
+	`DROP TRIGGER IF EXISTS prevent_update`,
+	`DROP TRIGGER IF EXISTS prevent_delete`,
+	`DROP INDEX IF EXISTS idx_timestamp`,
+	`ALTER TABLE audit_log RENAME TO audit_log_old`,
+	tableSchema,
+	`INSERT INTO audit_log (` + auditColumns + `) SELECT ` + auditColumns + ` FROM audit_log_old`,
+	`DROP TABLE audit_log_old`,
+}
+
+const auditColumns = `id, timestamp, tool_input, decision, reason, detail, signature, approver, approval_latency_ms, actor, tenant, deny_category, dry_run`
+
+// addedColumns are columns introduced after the original schema, added to
+// existing databases on startup
+var addedColumns = []struct {
+	name       string
+	definition string
+}{
+	{name: "detail", definition: "TEXT"},
+	{name: "signature", definition: "TEXT"},
+	{name: "approver", definition: "TEXT"},
+	{name: "approval_latency_ms", definition: "INTEGER"},
+	{name: "actor", definition: "TEXT"},
+	{name: "tenant", definition: "TEXT"},
+	{name: "deny_category", definition: "TEXT"},
+	{name: "dry_run", definition: "INTEGER"},
+}
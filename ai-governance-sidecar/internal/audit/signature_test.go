@@ -0,0 +1,115 @@
+package audit
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestSignedEntriesVerify(t *testing.T) {
+	store := setupTestStore(t)
+	defer store.Close()
+
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	store.SignEntries(key)
+
+	ctx := context.Background()
+	if err := store.Log(ctx, json.RawMessage(`{"tool_name":"read_file"}`), DecisionAllow, "all policies passed"); err != nil {
+		t.Fatalf("log failed: %v", err)
+	}
+	if err := store.LogDetail(ctx, json.RawMessage(`{"tool_name":"drop_table"}`), DecisionDeny, "destructive", json.RawMessage(`{"policy":"sql_guard"}`)); err != nil {
+		t.Fatalf("log detail failed: %v", err)
+	}
+
+	entries, err := store.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("get all failed: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+
+	for _, entry := range entries {
+		if entry.Signature == "" {
+			t.Errorf("entry %d was not signed", entry.ID)
+		}
+		if !store.VerifyEntry(entry) {
+			t.Errorf("intact entry %d failed verification", entry.ID)
+		}
+	}
+
+	// A row copied out of the database verifies with just the public key
+	data, _ := json.Marshal(entries[0])
+	var extracted Entry
+	json.Unmarshal(data, &extracted)
+	if !VerifyEntry(extracted, key.Public().(ed25519.PublicKey)) {
+		t.Error("extracted entry failed verification")
+	}
+}
+
+func TestTamperedEntryFailsVerification(t *testing.T) {
+	store := setupTestStore(t)
+	defer store.Close()
+
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	store.SignEntries(key)
+
+	ctx := context.Background()
+	if err := store.LogDetail(ctx, json.RawMessage(`{"tool_name":"drop_table"}`), DecisionDeny, "destructive", json.RawMessage(`{"policy":"sql_guard"}`)); err != nil {
+		t.Fatalf("log failed: %v", err)
+	}
+
+	entries, err := store.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("get all failed: %v", err)
+	}
+	original := entries[0]
+
+	tests := []struct {
+		name   string
+		tamper func(e *Entry)
+	}{
+		{"decision", func(e *Entry) { e.Decision = DecisionAllow }},
+		{"reason", func(e *Entry) { e.Reason = "all policies passed" }},
+		{"tool input", func(e *Entry) { e.ToolInput = json.RawMessage(`{"tool_name":"read_file"}`) }},
+		{"detail", func(e *Entry) { e.Detail = nil }},
+		{"timestamp", func(e *Entry) { e.Timestamp = e.Timestamp.Add(-time.Hour) }},
+		{"signature stripped", func(e *Entry) { e.Signature = "" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := original
+			tt.tamper(&entry)
+			if store.VerifyEntry(entry) {
+				t.Error("tampered entry passed verification")
+			}
+		})
+	}
+
+	_, otherKey, _ := ed25519.GenerateKey(rand.Reader)
+	if VerifyEntry(original, otherKey.Public().(ed25519.PublicKey)) {
+		t.Error("entry verified against the wrong key")
+	}
+}
+
+func TestUnsignedStoreLeavesSignatureEmpty(t *testing.T) {
+	store := setupTestStore(t)
+	defer store.Close()
+
+	ctx := context.Background()
+	if err := store.Log(ctx, json.RawMessage(`{}`), DecisionAllow, "ok"); err != nil {
+		t.Fatalf("log failed: %v", err)
+	}
+
+	entries, _ := store.GetAll(ctx)
+	if entries[0].Signature != "" {
+		t.Errorf("expected no signature, got %q", entries[0].Signature)
+	}
+	if store.VerifyEntry(entries[0]) {
+		t.Error("unsigned entry must not verify")
+	}
+}
@@ -2,9 +2,11 @@ package audit
 
 import (
 	"context"
+	"crypto/ed25519"
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -12,7 +14,11 @@ import (
 )
 
 type SQLiteStore struct {
-	db *sql.DB
+	db     *sql.DB
+	signer ed25519.PrivateKey
+	// maxToolInput is the largest tool_input or detail stored whole; zero
+	// is no limit
+	maxToolInput int
 }
 
 func NewSQLiteStore(dbPath string) (*SQLiteStore, error) {
@@ -31,12 +37,50 @@ func NewSQLiteStore(dbPath string) (*SQLiteStore, error) {
 	return store, nil
 }
 
+// SignEntries signs every entry written from now on with key. Call it
+// before the store is shared.
+func (s *SQLiteStore) SignEntries(key ed25519.PrivateKey) {
+	s.signer = key
+}
+
+// VerifyEntry reports whether entry was signed by this store's key
+func (s *SQLiteStore) VerifyEntry(entry Entry) bool {
+	if s.signer == nil {
+		return false
+	}
+	return VerifyEntry(entry, s.signer.Public().(ed25519.PublicKey))
+}
+
 func (s *SQLiteStore) Log(ctx context.Context, toolInput json.RawMessage, decision Decision, reason string) error {
+	return s.LogDetail(ctx, toolInput, decision, reason, nil)
+}
+
+func (s *SQLiteStore) LogDetail(ctx context.Context, toolInput json.RawMessage, decision Decision, reason string, detail json.RawMessage) error {
 	if err := validateLogInput(toolInput, decision, reason); err != nil {
 		return err
 	}
+	if err := validateDetail(detail); err != nil {
+		return err
+	}
 
-	return s.insertEntry(ctx, toolInput, decision, reason)
+	return s.insertEntry(ctx, withContextActor(ctx, Entry{ToolInput: toolInput, Decision: decision, Reason: reason, Detail: detail}))
+}
+
+func (s *SQLiteStore) LogApproval(ctx context.Context, toolInput json.RawMessage, decision Decision, reason, approver string, latency time.Duration) error {
+	if err := validateLogInput(toolInput, decision, reason); err != nil {
+		return err
+	}
+	if approver == "" {
+		return fmt.Errorf("approver is required")
+	}
+
+	return s.insertEntry(ctx, withContextActor(ctx, Entry{
+		ToolInput:         toolInput,
+		Decision:          decision,
+		Reason:            reason,
+		Approver:          approver,
+		ApprovalLatencyMs: latency.Milliseconds(),
+	}))
 }
 
 func (s *SQLiteStore) GetAll(ctx context.Context) ([]Entry, error) {
@@ -49,6 +93,43 @@ func (s *SQLiteStore) GetAll(ctx context.Context) ([]Entry, error) {
 	return scanEntries(rows)
 }
 
+// GetEntry returns the entry logged with id, or ErrEntryNotFound
+func (s *SQLiteStore) GetEntry(ctx context.Context, id int64) (Entry, error) {
+	rows, err := s.db.QueryContext(ctx, querySelectByID, id)
+	if err != nil {
+		return Entry{}, fmt.Errorf("query entry: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return Entry{}, fmt.Errorf("query entry: %w", err)
+		}
+		return Entry{}, ErrEntryNotFound
+	}
+	return scanEntry(rows)
+}
+
+// EntriesAfter returns entries with an id above after, oldest first
+func (s *SQLiteStore) EntriesAfter(ctx context.Context, after int64, limit int) ([]Entry, error) {
+	if limit <= 0 {
+		limit = -1
+	}
+
+	rows, err := s.db.QueryContext(ctx, querySelectAfter, after, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries, err := scanEntries(rows)
+	if err != nil {
+		return nil, err
+	}
+	slices.Reverse(entries)
+	return entries, nil
+}
+
 func (s *SQLiteStore) Close() error {
 	return s.db.Close()
 }
@@ -59,15 +140,115 @@ func (s *SQLiteStore) initializeSchema() error {
 			return fmt.Errorf("execute schema: %w", err)
 		}
 	}
+	if err := s.addMissingColumns(); err != nil {
+		return err
+	}
+	return s.allowAutoApproveDecision()
+}
+
+// allowAutoApproveDecision rebuilds audit_log when its decision CHECK
+// predates auto_approve
+func (s *SQLiteStore) allowAutoApproveDecision() error {
+	var table string
+	if err := s.db.QueryRow(`SELECT sql FROM sqlite_master WHERE type = 'table' AND name = 'audit_log'`).Scan(&table); err != nil {
+		return fmt.Errorf("read table schema: %w", err)
+	}
+	if strings.Contains(table, "'auto_approve'") {
+		return nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("rebuild audit_log: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, stmt := range append(decisionCheckRebuild, schemaStatements()...) {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("rebuild audit_log: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("rebuild audit_log: %w", err)
+	}
+	return nil
+}
+
+// addMissingColumns upgrades databases created before a column existed
+func (s *SQLiteStore) addMissingColumns() error {
+	rows, err := s.db.Query(`SELECT name FROM pragma_table_info('audit_log')`)
+	if err != nil {
+		return fmt.Errorf("read table info: %w", err)
+	}
+	defer rows.Close()
+
+	existing := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return fmt.Errorf("scan table info: %w", err)
+		}
+		existing[name] = true
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("read table info: %w", err)
+	}
+
+	for _, col := range addedColumns {
+		if existing[col.name] {
+			continue
+		}
+		if _, err := s.db.Exec(fmt.Sprintf("ALTER TABLE audit_log ADD COLUMN %s %s", col.name, col.definition)); err != nil {
+			return fmt.Errorf("add column %s: %w", col.name, err)
+		}
+	}
 	return nil
 }
 
-func (s *SQLiteStore) insertEntry(ctx context.Context, toolInput json.RawMessage, decision Decision, reason string) error {
+func (s *SQLiteStore) insertEntry(ctx context.Context, entry Entry) error {
+	entry.ToolInput = truncateJSON(entry.ToolInput, s.maxToolInput)
+	entry.Detail = truncateJSON(entry.Detail, s.maxToolInput)
+
+	var detailValue any
+	if len(entry.Detail) > 0 {
+		detailValue = string(entry.Detail)
+	}
+
+	var approverValue, latencyValue any
+	if entry.Approver != "" {
+		approverValue = entry.Approver
+		latencyValue = entry.ApprovalLatencyMs
+	}
+
+	// Unauthenticated calls leave actor and tenant NULL
+	var actorValue, tenantValue any
+	if entry.Actor != "" {
+		actorValue = entry.Actor
+	}
+	if entry.Tenant != "" {
+		tenantValue = entry.Tenant
+	}
+	var categoryValue any
+	if entry.DenyCategory != "" {
+		categoryValue = string(entry.DenyCategory)
+	}
+	// Real calls leave dry_run NULL
+	var dryRunValue any
+	if entry.DryRun {
+		dryRunValue = 1
+	}
+
+	timestamp := time.Now().UTC().Format(timestampLayout)
+	var signature any
+	if s.signer != nil {
+		signature = signEntry(s.signer, timestamp, entry)
+	}
+
 	const maxRetries = 3
 	var err error
 	
 	for attempt := 0; attempt < maxRetries; attempt++ {
-		_, err = s.db.ExecContext(ctx, queryInsertEntry, string(toolInput), string(decision), reason)
+		_, err = s.db.ExecContext(ctx, queryInsertEntry, timestamp, string(entry.ToolInput), string(entry.Decision), entry.Reason, detailValue, signature, approverValue, latencyValue, actorValue, tenantValue, categoryValue, dryRunValue)
 		if err == nil {
 			return nil
 		}
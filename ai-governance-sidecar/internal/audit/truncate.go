@@ -0,0 +1,75 @@
+package audit
+
+import (
+	"encoding/json"
+	"unicode/utf8"
+)
+
+// truncatedInput replaces a tool_input or detail over the store's size
+// limit. It is still valid JSON and keeps any tool_name, so queries by tool
+// keep working, plus as much of the original as fits in preview.
+type truncatedInput struct {
+	Truncated     bool   `json:"_truncated"`
+	OriginalBytes int    `json:"_original_bytes"`
+	ToolName      string `json:"tool_name,omitempty"`
+	Preview       string `json:"_preview,omitempty"`
+}
+
+// SetMaxToolInput caps the stored size of tool_input, and of detail, in
+// bytes. Larger values are kept as a truncation marker rather than
+// rejected, so the decision is still recorded. Zero stores them whole.
+// Call it before the store is shared.
+func (s *SQLiteStore) SetMaxToolInput(n int) {
+	s.maxToolInput = n
+}
+
+// IsTruncated reports whether a stored tool_input is a truncation marker
+// rather than the call as made
+func IsTruncated(toolInput json.RawMessage) bool {
+	var marker struct {
+		Truncated bool `json:"_truncated"`
+	}
+	return json.Unmarshal(toolInput, &marker) == nil && marker.Truncated
+}
+
+// truncateJSON returns raw unchanged when it fits in max bytes, otherwise
+// a truncatedInput marker of at most max bytes where possible
+func truncateJSON(raw json.RawMessage, max int) json.RawMessage {
+	if max <= 0 || len(raw) <= max {
+		return raw
+	}
+
+	marker := truncatedInput{Truncated: true, OriginalBytes: len(raw)}
+	var call struct {
+		ToolName string `json:"tool_name"`
+	}
+	if json.Unmarshal(raw, &call) == nil {
+		marker.ToolName = call.ToolName
+	}
+
+	// Marshalling a struct of strings and ints cannot fail
+	out, _ := json.Marshal(marker)
+	room := max - len(out) - len(`,"_preview":""`)
+	for room > 0 {
+		marker.Preview = prefix(raw, room)
+		withPreview, _ := json.Marshal(marker)
+		if len(withPreview) <= max {
+			return withPreview
+		}
+		// Escaping grew the preview; shrink by the overshoot and retry
+		room -= len(withPreview) - max
+	}
+	return out
+}
+
+// prefix is the first n bytes of b, without a rune split at the cut
+func prefix(b []byte, n int) string {
+	b = b[:n]
+	for i := 0; i < utf8.UTFMax && len(b) > 0; i++ {
+		if r, size := utf8.DecodeLastRune(b); r != utf8.RuneError || size != 1 {
+			break
+		}
+		b = b[:len(b)-1]
+	}
+	return string(b)
+}
@@ -0,0 +1,100 @@
+package audit
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestSQLiteStoreTruncatesLargeToolInput(t *testing.T) {
+	const maxBytes = 256
+
+	store := setupTestStore(t)
+	defer store.Close()
+	store.SetMaxToolInput(maxBytes)
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	store.SignEntries(key)
+
+	ctx := context.Background()
+	small := json.RawMessage(`{"tool_name":"read","args":{"path":"/tmp/x"}}`)
+	// Multi-byte runes and quotes make the preview cut and escape
+	large, _ := json.Marshal(map[string]any{
+		"tool_name": "upload",
+		"args":      map[string]string{"data": strings.Repeat(`é"x`, 1000)},
+	})
+
+	if err := store.Log(ctx, small, DecisionAllow, "ok"); err != nil {
+		t.Fatalf("failed to log small input: %v", err)
+	}
+	if err := store.Log(ctx, large, DecisionDeny, "too big to keep"); err != nil {
+		t.Fatalf("expected an oversized input to be recorded, got %v", err)
+	}
+
+	entries, err := store.GetAll(ctx)
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d (%v)", len(entries), err)
+	}
+
+	if string(entries[0].ToolInput) != string(small) || IsTruncated(entries[0].ToolInput) {
+		t.Errorf("expected a small input to be stored whole, got %s", entries[0].ToolInput)
+	}
+
+	stored := entries[1]
+	if len(stored.ToolInput) > maxBytes {
+		t.Errorf("expected at most %d stored bytes, got %d", maxBytes, len(stored.ToolInput))
+	}
+	var marker truncatedInput
+	if err := json.Unmarshal(stored.ToolInput, &marker); err != nil {
+		t.Fatalf("expected the marker to be valid JSON, got %s: %v", stored.ToolInput, err)
+	}
+	if !IsTruncated(stored.ToolInput) || marker.OriginalBytes != len(large) || marker.ToolName != "upload" {
+		t.Errorf("unexpected truncation marker %+v", marker)
+	}
+	if marker.Preview == "" || !strings.HasPrefix(string(large), marker.Preview) {
+		t.Errorf("expected a preview from the start of the input, got %q", marker.Preview)
+	}
+	if stored.Decision != DecisionDeny || stored.Reason != "too big to keep" {
+		t.Errorf("expected the decision to be kept, got %s %q", stored.Decision, stored.Reason)
+	}
+	if !store.VerifyEntry(stored) {
+		t.Error("expected the truncated entry to verify against its signature")
+	}
+}
+
+func TestSQLiteStoreTruncatesLargeDetail(t *testing.T) {
+	const maxBytes = 256
+
+	store := setupTestStore(t)
+	defer store.Close()
+	store.SetMaxToolInput(maxBytes)
+
+	ctx := context.Background()
+	input := json.RawMessage(`{"tool_name":"read"}`)
+	detail, _ := json.Marshal(map[string]any{
+		"policies": []string{strings.Repeat("p", 1000)},
+	})
+
+	if err := store.LogDetail(ctx, input, DecisionAllow, "ok", detail); err != nil {
+		t.Fatalf("expected an oversized detail to be recorded, got %v", err)
+	}
+
+	entries, err := store.GetAll(ctx)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d (%v)", len(entries), err)
+	}
+
+	stored := entries[0]
+	if string(stored.ToolInput) != string(input) {
+		t.Errorf("expected the small input to be stored whole, got %s", stored.ToolInput)
+	}
+	if len(stored.Detail) > maxBytes {
+		t.Errorf("expected at most %d detail bytes, got %d", maxBytes, len(stored.Detail))
+	}
+	var marker truncatedInput
+	if err := json.Unmarshal(stored.Detail, &marker); err != nil || !marker.Truncated || marker.OriginalBytes != len(detail) {
+		t.Errorf("expected a truncation marker for the detail, got %s (%v)", stored.Detail, err)
+	}
+}
@@ -3,6 +3,8 @@ package audit
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"sort"
 	"time"
 )
 
@@ -11,6 +13,9 @@ type Decision string
 const (
 	DecisionAllow Decision = "allow"
 	DecisionDeny  Decision = "deny"
+	// DecisionAutoApprove records a human_required call that AUTO_APPROVE
+	// let through without an approver; it counts as an allow
+	DecisionAutoApprove Decision = "auto_approve"
 )
 
 type Entry struct {
@@ -19,10 +24,112 @@ type Entry struct {
 	ToolInput json.RawMessage `json:"tool_input"`
 	Decision  Decision        `json:"decision"`
 	Reason    string          `json:"reason"`
+	// Detail is structured decision context, recorded with AUDIT_DETAIL=full
+	Detail json.RawMessage `json:"detail,omitempty"`
+	// Signature is a base64 Ed25519 signature over the stored row, set
+	// when entry signing is enabled
+	Signature string `json:"signature,omitempty"`
+	// Approver and ApprovalLatencyMs are set on entries that record a
+	// human approval decision
+	Approver          string `json:"approver,omitempty"`
+	ApprovalLatencyMs int64  `json:"approval_latency_ms,omitempty"`
+	// Actor and Tenant identify the authenticated caller; both are empty
+	// when auth is disabled
+	Actor  string `json:"actor,omitempty"`
+	Tenant string `json:"tenant,omitempty"`
+	// DenyCategory classifies a denial for reporting; empty on allows and
+	// on denials logged before categories were recorded
+	DenyCategory DenyCategory `json:"deny_category,omitempty"`
+	// DryRun marks a decision evaluated for an X-Dry-Run request, which
+	// was never forwarded or queued. Reports and appeals skip these.
+	DryRun bool `json:"dry_run,omitempty"`
 }
 
 type Store interface {
 	Log(ctx context.Context, toolInput json.RawMessage, decision Decision, reason string) error
 	GetAll(ctx context.Context) ([]Entry, error)
 	Close() error
-}
\ No newline at end of file
+}
+
+// DetailLogger is implemented by stores that can record structured detail
+// alongside an entry. A nil detail is the same as Log.
+type DetailLogger interface {
+	LogDetail(ctx context.Context, toolInput json.RawMessage, decision Decision, reason string, detail json.RawMessage) error
+}
+
+// ApprovalLogger is implemented by stores that record who decided an
+// approval and how long the call waited for the decision.
+type ApprovalLogger interface {
+	LogApproval(ctx context.Context, toolInput json.RawMessage, decision Decision, reason, approver string, latency time.Duration) error
+}
+
+// EntryReader is implemented by stores that can load entries by id without
+// reading the whole log
+type EntryReader interface {
+	GetEntry(ctx context.Context, id int64) (Entry, error)
+	// EntriesAfter returns entries with an id above after, oldest first. A
+	// positive limit keeps only the newest limit of them.
+	EntriesAfter(ctx context.Context, after int64, limit int) ([]Entry, error)
+}
+
+// ErrStoreUnavailable is returned for queries while the store is down
+var ErrStoreUnavailable = errors.New("audit store unavailable")
+
+// ErrEntryNotFound is returned by GetEntry for an id that was never logged
+var ErrEntryNotFound = errors.New("audit entry not found")
+
+// EntriesAfter reads entries with an id above after, oldest first, through
+// EntryReader when store supports it and by filtering GetAll otherwise.
+// Entries still buffered by a degraded store have no id yet and are left
+// out until they are flushed.
+func EntriesAfter(ctx context.Context, store Store, after int64, limit int) ([]Entry, error) {
+	if reader, ok := store.(EntryReader); ok {
+		return reader.EntriesAfter(ctx, after, limit)
+	}
+
+	entries, err := store.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var newer []Entry
+	for _, entry := range entries {
+		if entry.ID > after {
+			newer = append(newer, entry)
+		}
+	}
+	sort.Slice(newer, func(i, j int) bool { return newer[i].ID < newer[j].ID })
+	if limit > 0 && len(newer) > limit {
+		newer = newer[len(newer)-limit:]
+	}
+	return newer, nil
+}
+
+// LogApproval records the approver when store supports it and falls back
+// to a plain entry otherwise.
+func LogApproval(ctx context.Context, store Store, toolInput json.RawMessage, decision Decision, reason, approver string, latency time.Duration) error {
+	if al, ok := store.(ApprovalLogger); ok {
+		return al.LogApproval(ctx, toolInput, decision, reason, approver, latency)
+	}
+	return store.Log(ctx, toolInput, decision, reason)
+}
+
+// writeEntry replays a buffered entry through the richest interface store
+// supports
+func writeEntry(ctx context.Context, store Store, entry Entry) error {
+	ctx = entryContext(ctx, entry)
+	if entry.Approver != "" {
+		latency := time.Duration(entry.ApprovalLatencyMs) * time.Millisecond
+		return LogApproval(ctx, store, entry.ToolInput, entry.Decision, entry.Reason, entry.Approver, latency)
+	}
+	return LogWithDetail(ctx, store, entry.ToolInput, entry.Decision, entry.Reason, entry.Detail)
+}
+
+// LogWithDetail records detail when store supports it and falls back to a
+// plain entry otherwise.
+func LogWithDetail(ctx context.Context, store Store, toolInput json.RawMessage, decision Decision, reason string, detail json.RawMessage) error {
+	if dl, ok := store.(DetailLogger); ok && len(detail) > 0 {
+		return dl.LogDetail(ctx, toolInput, decision, reason, detail)
+	}
+	return store.Log(ctx, toolInput, decision, reason)
+}
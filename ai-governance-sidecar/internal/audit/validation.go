@@ -26,5 +26,12 @@ func validateLogInput(toolInput json.RawMessage, decision Decision, reason strin
 }
 
 func isValidDecision(d Decision) bool {
-	return d == DecisionAllow || d == DecisionDeny
-}
\ No newline at end of file
+	return d == DecisionAllow || d == DecisionDeny || d == DecisionAutoApprove
+}
+
+func validateDetail(detail json.RawMessage) error {
+	if len(detail) > 0 && !json.Valid(detail) {
+		return fmt.Errorf("detail must be valid JSON")
+	}
+	return nil
+}
@@ -2,8 +2,8 @@ package auth
 
 import (
 	"crypto/subtle"
+	"errors"
 	"net/http"
-	"os"
 	"strings"
 
 	"github.com/labstack/echo/v4"
@@ -67,6 +67,28 @@ func (h *Handler) Login(c echo.Context) error {
 	})
 }
 
+// ReloadUsers re-reads the users file
+func (h *Handler) ReloadUsers(c echo.Context) error {
+	n, err := h.manager.ReloadUsers()
+	if errors.Is(err, ErrNoUsersFile) {
+		return c.JSON(http.StatusConflict, map[string]string{
+			"error": "AUTH_USERS_FILE is not configured",
+		})
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("failed to reload users")
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to reload users",
+		})
+	}
+
+	log.Info().Int("users", n).Msg("users reloaded")
+
+	return c.JSON(http.StatusOK, map[string]int{
+		"users": n,
+	})
+}
+
 // Me returns current user info
 func (h *Handler) Me(c echo.Context) error {
 	user := GetUserFromContext(c)
@@ -79,39 +101,78 @@ func (h *Handler) Me(c echo.Context) error {
 	return c.JSON(http.StatusOK, user)
 }
 
-// validateCredentials checks user credentials
-// Format: EMAIL:PASSWORD:NAME:ROLES (semicolon-separated users)
-// Example: admin@example.com:pass123:Admin:admin,approver
-func (h *Handler) validateCredentials(email, password string) (*User, error) {
-	usersEnv := os.Getenv("AUTH_USERS")
-	if usersEnv == "" {
-		// Default admin user for development
-		usersEnv = "admin@example.com:admin:Administrator:admin,approver"
+// IntrospectResponse is the decoded content of the caller's token
+type IntrospectResponse struct {
+	Subject   string   `json:"sub"`
+	Email     string   `json:"email"`
+	Name      string   `json:"name,omitempty"`
+	Roles     []string `json:"roles"`
+	Issuer    string   `json:"iss,omitempty"`
+	ID        string   `json:"jti,omitempty"`
+	IssuedAt  int64    `json:"iat,omitempty"`
+	NotBefore int64    `json:"nbf,omitempty"`
+	ExpiresAt int64    `json:"exp,omitempty"`
+	// ExpiresIn is the number of seconds until the token expires
+	ExpiresIn int64 `json:"expires_in,omitempty"`
+}
+
+// Introspect decodes the bearer token the caller presented. A valid token
+// is required even when authentication is otherwise disabled.
+func (h *Handler) Introspect(c echo.Context) error {
+	token, ok := strings.CutPrefix(c.Request().Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Missing bearer token",
+		})
 	}
 
-	// Parse users
-	users := strings.Split(usersEnv, ";")
-	for _, userStr := range users {
-		parts := strings.Split(userStr, ":")
-		if len(parts) < 4 {
-			continue
-		}
+	claims, err := h.manager.ParseClaims(token)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Invalid token",
+		})
+	}
+
+	resp := IntrospectResponse{
+		Subject: claims.Subject,
+		Email:   claims.User.Email,
+		Name:    claims.User.Name,
+		Roles:   claims.User.Roles,
+		Issuer:  claims.Issuer,
+		ID:      claims.ID,
+	}
+	if resp.Subject == "" {
+		resp.Subject = claims.User.ID
+	}
+	if claims.IssuedAt != nil {
+		resp.IssuedAt = claims.IssuedAt.Unix()
+	}
+	if claims.NotBefore != nil {
+		resp.NotBefore = claims.NotBefore.Unix()
+	}
+	if claims.ExpiresAt != nil {
+		resp.ExpiresAt = claims.ExpiresAt.Unix()
+		resp.ExpiresIn = int64(claims.ExpiresAt.Sub(h.manager.clock.Now()).Seconds())
+	}
 
-		userEmail := parts[0]
-		userPassword := parts[1]
-		userName := parts[2]
-		rolesStr := parts[3]
+	return c.JSON(http.StatusOK, resp)
+}
 
+// validateCredentials checks user credentials
+// Format: EMAIL:PASSWORD:NAME:ROLES[:TENANT] (semicolon-separated users)
+// Example: admin@example.com:pass123:Admin:admin,approver
+func (h *Handler) validateCredentials(email, password string) (*User, error) {
+	for _, u := range h.manager.users.list() {
 		// Constant-time comparison to prevent timing attacks
-		if subtle.ConstantTimeCompare([]byte(email), []byte(userEmail)) == 1 &&
-			subtle.ConstantTimeCompare([]byte(password), []byte(userPassword)) == 1 {
+		if subtle.ConstantTimeCompare([]byte(email), []byte(u.email)) == 1 &&
+			subtle.ConstantTimeCompare([]byte(password), []byte(u.password)) == 1 {
 
-			roles := strings.Split(rolesStr, ",")
 			return &User{
-				ID:    generateUserID(email),
-				Email: email,
-				Name:  userName,
-				Roles: roles,
+				ID:     generateUserID(email),
+				Email:  email,
+				Name:   u.name,
+				Roles:  u.roles,
+				Tenant: u.tenant,
 			}, nil
 		}
 	}
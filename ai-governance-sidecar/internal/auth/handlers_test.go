@@ -1,12 +1,16 @@
 package auth
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/dagbolade/ai-governance-sidecar/internal/clock"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 )
@@ -224,4 +228,126 @@ func TestGenerateUserID(t *testing.T) {
 			assert.Equal(t, tt.expected, result)
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestReloadedUsersFileTakesEffectOnNextLogin(t *testing.T) {
+	usersFile := filepath.Join(t.TempDir(), "users")
+	writeUsers := func(content string) {
+		if err := os.WriteFile(usersFile, []byte(content), 0o600); err != nil {
+			t.Fatalf("write users file: %v", err)
+		}
+	}
+	writeUsers("old@example.com:oldpass:Old User:viewer\n")
+
+	manager := NewManager(Config{
+		JWTSecret:   "test-secret-key",
+		RequireAuth: true,
+		UsersFile:   usersFile,
+	})
+	handler := NewHandler(manager)
+	e := echo.New()
+
+	login := func(email, password string) int {
+		body := `{"email":"` + email + `","password":"` + password + `"}`
+		req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		assert.NoError(t, handler.Login(e.NewContext(req, rec)))
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusOK, login("old@example.com", "oldpass"))
+	assert.Equal(t, http.StatusUnauthorized, login("new@example.com", "newpass"))
+
+	writeUsers("# rotated\nnew@example.com:newpass:New User:approver\n")
+
+	// The file is only re-read on reload
+	assert.Equal(t, http.StatusUnauthorized, login("new@example.com", "newpass"))
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/auth/reload", nil)
+	assert.NoError(t, handler.ReloadUsers(e.NewContext(req, rec)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"users":1}`, rec.Body.String())
+
+	assert.Equal(t, http.StatusOK, login("new@example.com", "newpass"))
+	assert.Equal(t, http.StatusUnauthorized, login("old@example.com", "oldpass"))
+}
+
+func TestUnreadableUsersFileDisablesFallbackLogins(t *testing.T) {
+	t.Setenv("AUTH_USERS", "env@example.com:envpass:Env User:admin")
+	manager := NewManager(Config{
+		JWTSecret:   "test-secret-key",
+		RequireAuth: true,
+		UsersFile:   filepath.Join(t.TempDir(), "missing"),
+	})
+	handler := NewHandler(manager)
+	e := echo.New()
+
+	for _, creds := range [][2]string{{"env@example.com", "envpass"}, {"admin@example.com", "admin"}} {
+		body := `{"email":"` + creds[0] + `","password":"` + creds[1] + `"}`
+		req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		assert.NoError(t, handler.Login(e.NewContext(req, rec)))
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, creds[0])
+	}
+}
+
+func TestReloadUsersWithoutFile(t *testing.T) {
+	_, handler, e := setupTestAuth()
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/auth/reload", nil)
+	assert.NoError(t, handler.ReloadUsers(e.NewContext(req, rec)))
+	assert.Equal(t, http.StatusConflict, rec.Code)
+}
+
+func TestIntrospectReturnsIssuedClaims(t *testing.T) {
+	manager, handler, e := setupTestAuth()
+	fake := clock.NewFake(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	manager.SetClock(fake)
+
+	user := User{ID: "user-1", Email: "dev@example.com", Name: "Dev", Roles: []string{RoleApprover}}
+	token, err := manager.GenerateToken(user)
+	assert.NoError(t, err)
+
+	issued, err := manager.ParseClaims(token)
+	assert.NoError(t, err)
+
+	fake.Advance(time.Hour)
+
+	req := httptest.NewRequest(http.MethodGet, "/auth/introspect", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+
+	assert.NoError(t, handler.Introspect(e.NewContext(req, rec)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "test-secret-key")
+
+	var resp IntrospectResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "user-1", resp.Subject)
+	assert.Equal(t, "dev@example.com", resp.Email)
+	assert.Equal(t, []string{RoleApprover}, resp.Roles)
+	assert.Equal(t, issued.ID, resp.ID)
+	assert.NotEmpty(t, resp.ID)
+	assert.Equal(t, issued.IssuedAt.Unix(), resp.IssuedAt)
+	assert.Equal(t, issued.ExpiresAt.Unix(), resp.ExpiresAt)
+	assert.Equal(t, int64(23*time.Hour/time.Second), resp.ExpiresIn)
+}
+
+func TestIntrospectRequiresValidToken(t *testing.T) {
+	_, handler, e := setupTestAuth()
+
+	for _, header := range []string{"", "Bearer not-a-token", "Basic abc"} {
+		req := httptest.NewRequest(http.MethodGet, "/auth/introspect", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		rec := httptest.NewRecorder()
+
+		assert.NoError(t, handler.Introspect(e.NewContext(req, rec)))
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, "header %q", header)
+	}
+}
@@ -9,18 +9,22 @@ import (
 	"strings"
 	"time"
 
+	"github.com/dagbolade/ai-governance-sidecar/internal/clock"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/rs/zerolog/log"
 )
 
 // User represents an authenticated user
 type User struct {
-	ID       string   `json:"id"`
-	Email    string   `json:"email"`
-	Name     string   `json:"name"`
-	Roles    []string `json:"roles"`
-	IssuedAt int64    `json:"iat"`
+	ID    string   `json:"id"`
+	Email string   `json:"email"`
+	Name  string   `json:"name"`
+	Roles []string `json:"roles"`
+	// Tenant is the organisation the user acts for, if configured
+	Tenant   string `json:"tenant,omitempty"`
+	IssuedAt int64  `json:"iat"`
 }
 
 // Claims extends JWT standard claims
@@ -35,12 +39,32 @@ type Config struct {
 	TokenExpiration time.Duration
 	RequireAuth     bool
 	AllowedRoles    []string
+	// UsersFile holds EMAIL:PASSWORD:NAME:ROLES lines and can be reloaded at
+	// runtime; AUTH_USERS is used only when it is empty
+	UsersFile string
+	// MaxTokenAge rejects tokens issued longer ago than this, even before
+	// they expire; zero accepts any unexpired token
+	MaxTokenAge time.Duration
+	// ClockSkew is the leeway allowed on exp, nbf and iat for clock drift
+	// between the token issuer and the sidecar
+	ClockSkew time.Duration
+	// ApproverScopes maps a role to the tool name globs its holders may
+	// approve, e.g. {"db_admin": ["db.*"]}; empty leaves approvers unscoped
+	ApproverScopes map[string][]string
+	// ClientCertRoles maps a verified client certificate identity (URI,
+	// DNS or email SAN, or subject CN) to its roles. Those connections are
+	// authenticated by the certificate and skip the bearer token.
+	ClientCertRoles map[string][]string
 }
 
 // Manager handles authentication
 type Manager struct {
 	config Config
 	secret []byte
+	users  *userSource
+	clock  clock.Clock
+
+	delegations *delegationStore
 }
 
 // NewManager creates auth manager
@@ -57,10 +81,30 @@ func NewManager(config Config) *Manager {
 		log.Warn().Msg("Using generated JWT secret. Set JWT_SECRET env var for production.")
 	}
 
-	return &Manager{
+	m := &Manager{
 		config: config,
 		secret: []byte(secret),
+		users:  &userSource{path: config.UsersFile},
+		clock:  clock.Real{},
+
+		delegations: newDelegationStore(),
 	}
+
+	if config.UsersFile != "" {
+		if n, err := m.ReloadUsers(); err != nil {
+			log.Error().Err(err).Str("file", config.UsersFile).Msg("failed to load users file, no users can sign in")
+		} else {
+			log.Info().Int("users", n).Str("file", config.UsersFile).Msg("loaded users file")
+		}
+	}
+
+	return m
+}
+
+// ReloadUsers re-reads Config.UsersFile so added or removed users take effect
+// on the next login. Tokens already issued stay valid until they expire.
+func (m *Manager) ReloadUsers() (int, error) {
+	return m.users.reload()
 }
 
 // Middleware returns Echo middleware for authentication
@@ -78,6 +122,11 @@ func (m *Manager) Middleware() echo.MiddlewareFunc {
 				return next(c)
 			}
 
+			// A mapped client certificate stands in for a token
+			if user := m.certUser(c.Request()); user != nil {
+				return m.admit(c, next, user)
+			}
+
 			// Extract token from Authorization header
 			authHeader := c.Request().Header.Get("Authorization")
 			if authHeader == "" {
@@ -102,24 +151,54 @@ func (m *Manager) Middleware() echo.MiddlewareFunc {
 				})
 			}
 
-			// Check role requirements
-			if len(m.config.AllowedRoles) > 0 {
-				if !m.hasRequiredRole(user) {
-					return c.JSON(403, map[string]string{
-						"error": "Insufficient permissions",
-					})
-				}
-			}
+			return m.admit(c, next, user)
+		}
+	}
+}
 
-			// Add user to context
-			c.Set("user", user)
-			return next(c)
+// admit applies AllowedRoles to an authenticated user and runs next with
+// them on the context
+func (m *Manager) admit(c echo.Context, next echo.HandlerFunc, user *User) error {
+	// Check role requirements
+	if len(m.config.AllowedRoles) > 0 {
+		if !m.hasRequiredRole(user) {
+			return c.JSON(403, map[string]string{
+				"error": "Insufficient permissions",
+			})
 		}
 	}
+
+	// Add user to context, and to the request context for code that
+	// only sees the latter
+	c.Set("user", user)
+	c.SetRequest(c.Request().WithContext(WithUser(c.Request().Context(), user)))
+	return next(c)
 }
 
 // RequireRole returns middleware that checks for specific role
 func (m *Manager) RequireRole(role string) echo.MiddlewareFunc {
+	return m.RequireAnyRole(role)
+}
+
+// RequireAnyRoleWhenAuth is RequireAnyRole when authentication is on and
+// admits every caller when it is off, for routes that must keep working in
+// deployments without REQUIRE_AUTH
+func (m *Manager) RequireAnyRoleWhenAuth(roles ...string) echo.MiddlewareFunc {
+	guard := m.RequireAnyRole(roles...)
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		guarded := guard(next)
+		return func(c echo.Context) error {
+			if !m.config.RequireAuth {
+				return next(c)
+			}
+			return guarded(c)
+		}
+	}
+}
+
+// RequireAnyRole returns middleware that admits users holding any of roles,
+// either in their token or through an active delegation
+func (m *Manager) RequireAnyRole(roles ...string) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			user := GetUserFromContext(c)
@@ -129,39 +208,51 @@ func (m *Manager) RequireRole(role string) echo.MiddlewareFunc {
 				})
 			}
 
-			// Check if user has required role
-			hasRole := false
-			for _, userRole := range user.Roles {
-				if userRole == role {
-					hasRole = true
-					break
+			for _, role := range roles {
+				if m.HasRole(user, role) {
+					return next(c)
 				}
 			}
 
-			if !hasRole {
-				return c.JSON(403, map[string]string{
-					"error": fmt.Sprintf("Role '%s' required", role),
-				})
-			}
+			return c.JSON(403, map[string]string{
+				"error": fmt.Sprintf("Role '%s' required", strings.Join(roles, "' or '")),
+			})
+		}
+	}
+}
 
-			return next(c)
+// HasRole reports whether user holds role in their token or through an
+// active delegation
+func (m *Manager) HasRole(user *User, role string) bool {
+	for _, userRole := range user.Roles {
+		if userRole == role {
+			return true
 		}
 	}
+	return m.delegated(user.ID, role)
+}
+
+// SetClock replaces the wall clock used to issue and expire tokens
+func (m *Manager) SetClock(c clock.Clock) {
+	m.clock = c
 }
 
 // GenerateToken creates JWT for user
 func (m *Manager) GenerateToken(user User) (string, error) {
-	expiresAt := time.Now().Add(m.config.TokenExpiration)
+	now := m.clock.Now()
+	expiresAt := now.Add(m.config.TokenExpiration)
 	if m.config.TokenExpiration == 0 {
-		expiresAt = time.Now().Add(24 * time.Hour)
+		expiresAt = now.Add(24 * time.Hour)
 	}
 
 	claims := &Claims{
 		User: user,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Subject:   user.ID,
 			ExpiresAt: jwt.NewNumericDate(expiresAt),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
 			Issuer:    "governance-sidecar",
 		},
 	}
@@ -172,22 +263,50 @@ func (m *Manager) GenerateToken(user User) (string, error) {
 
 // ValidateToken verifies JWT and returns user
 func (m *Manager) ValidateToken(tokenString string) (*User, error) {
+	claims, err := m.ParseClaims(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	return &claims.User, nil
+}
+
+// ParseClaims verifies JWT and returns all of its claims
+func (m *Manager) ParseClaims(tokenString string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
 		return m.secret, nil
-	})
+	}, jwt.WithTimeFunc(m.clock.Now), jwt.WithLeeway(m.config.ClockSkew))
 
 	if err != nil {
 		return nil, err
 	}
 
-	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
-		return &claims.User, nil
+	claims, ok := token.Claims.(*Claims)
+	if !ok || !token.Valid {
+		return nil, fmt.Errorf("invalid token")
+	}
+
+	if err := m.checkTokenAge(claims); err != nil {
+		return nil, err
 	}
+	return claims, nil
+}
 
-	return nil, fmt.Errorf("invalid token")
+// checkTokenAge enforces MaxTokenAge from the iat claim, so a token minted
+// with a long expiry is still cut off server-side
+func (m *Manager) checkTokenAge(claims *Claims) error {
+	if m.config.MaxTokenAge <= 0 {
+		return nil
+	}
+	if claims.IssuedAt == nil {
+		return fmt.Errorf("token has no iat claim")
+	}
+	if age := m.clock.Now().Sub(claims.IssuedAt.Time); age > m.config.MaxTokenAge {
+		return fmt.Errorf("token is older than the maximum age of %s", m.config.MaxTokenAge)
+	}
+	return nil
 }
 
 // GetUserFromContext extracts user from Echo context
@@ -198,9 +317,16 @@ func GetUserFromContext(c echo.Context) *User {
 	return nil
 }
 
+type userContextKey struct{}
+
+// WithUser attaches user to a standard context
+func WithUser(ctx context.Context, user *User) context.Context {
+	return context.WithValue(ctx, userContextKey{}, user)
+}
+
 // GetUserFromStdContext extracts user from standard context
 func GetUserFromStdContext(ctx context.Context) (*User, bool) {
-	user, ok := ctx.Value("user").(*User)
+	user, ok := ctx.Value(userContextKey{}).(*User)
 	return user, ok
 }
 
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/dagbolade/ai-governance-sidecar/internal/clock"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 )
@@ -146,6 +147,10 @@ func TestMiddlewareValidToken(t *testing.T) {
 		contextUser := GetUserFromContext(c)
 		assert.NotNil(t, contextUser)
 		assert.Equal(t, user.Email, contextUser.Email)
+		// Also reachable from the request context, e.g. by audit logging
+		stdUser, ok := GetUserFromStdContext(c.Request().Context())
+		assert.True(t, ok)
+		assert.Equal(t, user.Email, stdUser.Email)
 		return c.String(http.StatusOK, "success")
 	})
 	
@@ -350,4 +355,83 @@ func TestRoleConstants(t *testing.T) {
 	assert.Equal(t, "admin", RoleAdmin)
 	assert.Equal(t, "approver", RoleApprover)
 	assert.Equal(t, "viewer", RoleViewer)
-}
\ No newline at end of file
+}
+func TestTokenExpiresWithFakeClock(t *testing.T) {
+	fake := clock.NewFake(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	manager := NewManager(Config{JWTSecret: "test-secret", TokenExpiration: time.Hour})
+	manager.SetClock(fake)
+
+	token, err := manager.GenerateToken(User{ID: "u1", Email: "user@example.com"})
+	assert.NoError(t, err)
+
+	fake.Advance(59 * time.Minute)
+	user, err := manager.ValidateToken(token)
+	assert.NoError(t, err)
+	assert.Equal(t, "u1", user.ID)
+
+	fake.Advance(2 * time.Minute)
+	_, err = manager.ValidateToken(token)
+	assert.Error(t, err)
+}
+
+func TestMaxTokenAgeRejectsOldTokens(t *testing.T) {
+	fake := clock.NewFake(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	manager := NewManager(Config{JWTSecret: "test-secret", TokenExpiration: 24 * time.Hour, MaxTokenAge: time.Hour})
+	manager.SetClock(fake)
+
+	token, err := manager.GenerateToken(User{ID: "u1", Email: "user@example.com"})
+	assert.NoError(t, err)
+
+	fake.Advance(59 * time.Minute)
+	user, err := manager.ValidateToken(token)
+	assert.NoError(t, err)
+	assert.Equal(t, "u1", user.ID)
+
+	// Still hours from exp, but past the cap
+	fake.Advance(2 * time.Minute)
+	_, err = manager.ValidateToken(token)
+	assert.ErrorContains(t, err, "maximum age")
+
+	// The cap applies to tokens issued elsewhere with the same secret
+	issuer := NewManager(Config{JWTSecret: "test-secret", TokenExpiration: 24 * time.Hour})
+	issuer.SetClock(fake)
+	fresh, err := issuer.GenerateToken(User{ID: "u2"})
+	assert.NoError(t, err)
+	_, err = manager.ValidateToken(fresh)
+	assert.NoError(t, err)
+}
+
+func TestClockSkewAcceptsTokensWithinLeeway(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// The issuer's clock runs ahead, so nbf is in the sidecar's future
+	issuerClock := clock.NewFake(now.Add(5 * time.Second))
+	issuer := NewManager(Config{JWTSecret: "test-secret", TokenExpiration: time.Hour})
+	issuer.SetClock(issuerClock)
+	token, err := issuer.GenerateToken(User{ID: "u1"})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		skew      time.Duration
+		expectErr bool
+	}{
+		{name: "within leeway", skew: 30 * time.Second},
+		{name: "beyond leeway", skew: 2 * time.Second, expectErr: true},
+		{name: "no leeway", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := NewManager(Config{JWTSecret: "test-secret", TokenExpiration: time.Hour, ClockSkew: tt.skew})
+			manager.SetClock(clock.NewFake(now))
+
+			_, err := manager.ValidateToken(token)
+			if tt.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
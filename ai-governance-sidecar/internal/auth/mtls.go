@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"crypto/x509"
+	"net/http"
+)
+
+// certUser maps a client certificate, already verified against the client
+// CA during the TLS handshake, to the user configured for its identity.
+// It returns nil for plain connections, unverified certificates and
+// identities missing from ClientCertRoles, which then need a bearer token.
+func (m *Manager) certUser(r *http.Request) *User {
+	if len(m.config.ClientCertRoles) == 0 || r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+		return nil
+	}
+
+	leaf := r.TLS.VerifiedChains[0][0]
+	for _, identity := range certIdentities(leaf) {
+		roles, ok := m.config.ClientCertRoles[identity]
+		if !ok {
+			continue
+		}
+
+		email := identity
+		if len(leaf.EmailAddresses) > 0 {
+			email = leaf.EmailAddresses[0]
+		}
+		return &User{
+			ID:       identity,
+			Email:    email,
+			Name:     leaf.Subject.CommonName,
+			Roles:    append([]string(nil), roles...),
+			IssuedAt: leaf.NotBefore.Unix(),
+		}
+	}
+	return nil
+}
+
+// certIdentities lists the names a certificate may be configured under, most
+// specific first: URI SANs (such as SPIFFE IDs), DNS and email SANs, then
+// the subject CN
+func certIdentities(cert *x509.Certificate) []string {
+	var ids []string
+	for _, uri := range cert.URIs {
+		ids = append(ids, uri.String())
+	}
+	ids = append(ids, cert.DNSNames...)
+	ids = append(ids, cert.EmailAddresses...)
+	if cert.Subject.CommonName != "" {
+		ids = append(ids, cert.Subject.CommonName)
+	}
+	return ids
+}
@@ -0,0 +1,185 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T, name string) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCA{cert: cert, key: key}
+}
+
+// issue signs a certificate for cn, usable as a server or client cert
+func (ca *testCA) issue(t *testing.T, cn string, dnsNames ...string) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		DNSNames:     dnsNames,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func (ca *testCA) pool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	return pool
+}
+
+// newMTLSServer serves /whoami behind the auth middleware, verifying
+// client certificates against ca when one is sent
+func newMTLSServer(t *testing.T, manager *Manager, ca *testCA) *httptest.Server {
+	t.Helper()
+	e := echo.New()
+	e.Use(manager.Middleware())
+	e.GET("/whoami", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, GetUserFromContext(c))
+	})
+
+	srv := httptest.NewUnstartedServer(e)
+	srv.TLS = &tls.Config{
+		Certificates: []tls.Certificate{ca.issue(t, "sidecar", "sidecar.test")},
+		ClientCAs:    ca.pool(),
+		ClientAuth:   tls.VerifyClientCertIfGiven,
+	}
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func mtlsClient(ca *testCA, cert *tls.Certificate) *http.Client {
+	cfg := &tls.Config{RootCAs: ca.pool(), ServerName: "sidecar.test"}
+	if cert != nil {
+		// Send cert even when the server doesn't list its issuer
+		cfg.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return cert, nil
+		}
+	}
+	return &http.Client{Transport: &http.Transport{TLSClientConfig: cfg}}
+}
+
+func TestMiddlewareClientCert(t *testing.T) {
+	ca := newTestCA(t, "test-ca")
+	manager := NewManager(Config{
+		JWTSecret:   "test-secret",
+		RequireAuth: true,
+		ClientCertRoles: map[string][]string{
+			"billing.agents.internal": {RoleViewer},
+		},
+	})
+	srv := newMTLSServer(t, manager, ca)
+
+	t.Run("trusted and mapped cert authenticates without a token", func(t *testing.T) {
+		cert := ca.issue(t, "billing-agent", "billing.agents.internal")
+		resp, err := mtlsClient(ca, &cert).Get(srv.URL + "/whoami")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		var user User
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&user))
+		assert.Equal(t, "billing.agents.internal", user.ID)
+		assert.Equal(t, "billing-agent", user.Name)
+		assert.Equal(t, []string{RoleViewer}, user.Roles)
+	})
+
+	t.Run("trusted but unmapped cert still needs a token", func(t *testing.T) {
+		cert := ca.issue(t, "stranger")
+		resp, err := mtlsClient(ca, &cert).Get(srv.URL + "/whoami")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("cert from an untrusted CA is refused", func(t *testing.T) {
+		rogue := newTestCA(t, "rogue-ca")
+		cert := rogue.issue(t, "billing-agent", "billing.agents.internal")
+		resp, err := mtlsClient(ca, &cert).Get(srv.URL + "/whoami")
+		if err == nil {
+			resp.Body.Close()
+		}
+		assert.Error(t, err, "handshake with an untrusted client cert should fail")
+	})
+
+	t.Run("token still works without a cert", func(t *testing.T) {
+		token, err := manager.GenerateToken(User{ID: "alice", Roles: []string{RoleAdmin}})
+		require.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodGet, srv.URL+"/whoami", nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := mtlsClient(ca, nil).Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+}
+
+func TestMiddlewareClientCertAllowedRoles(t *testing.T) {
+	ca := newTestCA(t, "test-ca")
+	manager := NewManager(Config{
+		JWTSecret:    "test-secret",
+		RequireAuth:  true,
+		AllowedRoles: []string{RoleAdmin},
+		ClientCertRoles: map[string][]string{
+			"billing-agent": {RoleViewer},
+		},
+	})
+	srv := newMTLSServer(t, manager, ca)
+
+	cert := ca.issue(t, "billing-agent")
+	resp, err := mtlsClient(ca, &cert).Get(srv.URL + "/whoami")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
@@ -0,0 +1,28 @@
+package auth
+
+import (
+	"github.com/dagbolade/ai-governance-sidecar/internal/toolmatch"
+)
+
+// MayApprove reports whether user may decide an approval for tool. Without
+// ApproverScopes any approver may decide any tool. With them, admins still
+// may, and everyone else only tools matching a pattern listed for a role
+// they hold, in their token or through an active delegation.
+func (m *Manager) MayApprove(user *User, tool string) bool {
+	if !m.config.RequireAuth || len(m.config.ApproverScopes) == 0 {
+		return true
+	}
+	if user == nil {
+		return false
+	}
+	if m.HasRole(user, RoleAdmin) {
+		return true
+	}
+
+	for role, patterns := range m.config.ApproverScopes {
+		if toolmatch.MatchAny(patterns, tool) && m.HasRole(user, role) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// defaultUsers is the development login used when no users are configured
+const defaultUsers = "admin@example.com:admin:Administrator:admin,approver"
+
+// ErrNoUsersFile is returned by ReloadUsers when AUTH_USERS_FILE is unset
+var ErrNoUsersFile = errors.New("no users file configured")
+
+// userRecord is one configured login
+type userRecord struct {
+	email    string
+	password string
+	name     string
+	roles    []string
+	tenant   string
+}
+
+// userSource holds the users loaded from Config.UsersFile. Without a file,
+// logins fall back to the AUTH_USERS environment variable. With one, no one
+// can sign in until it has loaded.
+type userSource struct {
+	mu     sync.RWMutex
+	path   string
+	users  []userRecord
+	loaded bool
+}
+
+func (s *userSource) list() []userRecord {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.loaded {
+		return s.users
+	}
+	if s.path != "" {
+		return nil
+	}
+
+	spec := os.Getenv("AUTH_USERS")
+	if spec == "" {
+		spec = defaultUsers
+	}
+	return parseUsers(spec)
+}
+
+// reload re-reads the users file and swaps it in, keeping the previous set
+// when the file cannot be read.
+func (s *userSource) reload() (int, error) {
+	if s.path == "" {
+		return 0, ErrNoUsersFile
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return 0, fmt.Errorf("read users file: %w", err)
+	}
+
+	users := parseUsers(string(data))
+
+	s.mu.Lock()
+	s.users = users
+	s.loaded = true
+	s.mu.Unlock()
+
+	return len(users), nil
+}
+
+// parseUsers reads EMAIL:PASSWORD:NAME:ROLES[:TENANT] entries separated by
+// semicolons or newlines. Blank lines, lines starting with # and malformed entries are
+// skipped.
+func parseUsers(spec string) []userRecord {
+	entries := strings.FieldsFunc(spec, func(r rune) bool {
+		return r == ';' || r == '\n'
+	})
+
+	var users []userRecord
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+
+		parts := strings.Split(entry, ":")
+		if len(parts) < 4 {
+			continue
+		}
+
+		record := userRecord{
+			email:    parts[0],
+			password: parts[1],
+			name:     parts[2],
+			roles:    strings.Split(parts[3], ","),
+		}
+		if len(parts) > 4 {
+			record.tenant = parts[4]
+		}
+		users = append(users, record)
+	}
+	return users
+}
@@ -0,0 +1,55 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFakeAdvanceFiresDueWaiters(t *testing.T) {
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	fake := NewFake(start)
+
+	short := fake.After(time.Second)
+	long := fake.After(time.Minute)
+
+	fake.Advance(999 * time.Millisecond)
+	select {
+	case <-short:
+		t.Fatal("fired before its deadline")
+	default:
+	}
+
+	fake.Advance(time.Millisecond)
+	select {
+	case at := <-short:
+		if !at.Equal(start.Add(time.Second)) {
+			t.Errorf("expected fire time %v, got %v", start.Add(time.Second), at)
+		}
+	default:
+		t.Fatal("expected waiter to fire at its deadline")
+	}
+
+	if fake.Waiters() != 1 {
+		t.Errorf("expected 1 waiter left, got %d", fake.Waiters())
+	}
+
+	fake.Set(start.Add(time.Hour))
+	select {
+	case <-long:
+	default:
+		t.Fatal("expected Set past the deadline to fire the waiter")
+	}
+
+	if got := fake.Now(); !got.Equal(start.Add(time.Hour)) {
+		t.Errorf("expected now %v, got %v", start.Add(time.Hour), got)
+	}
+}
+
+func TestFakeAfterNonPositiveFiresImmediately(t *testing.T) {
+	fake := NewFake(time.Now())
+	select {
+	case <-fake.After(0):
+	default:
+		t.Fatal("expected zero duration to fire immediately")
+	}
+}
@@ -0,0 +1,450 @@
+package policy
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// defaultBundlePollInterval applies when BundlePollInterval is unset
+	defaultBundlePollInterval = 60 * time.Second
+	// maxBundleSize bounds a downloaded bundle and each file in it
+	maxBundleSize = 64 << 20
+	// defaultBundleTimeout applies when BundleTimeout is unset
+	defaultBundleTimeout = 30 * time.Second
+	// bundleVersionFile holds the bundle's version, a non-negative integer
+	bundleVersionFile = "VERSION"
+)
+
+var (
+	ErrBundleSignature = errors.New("policy bundle signature invalid")
+	ErrBundleEmpty     = errors.New("policy bundle contains no .wasm files")
+	ErrBundleRollback  = errors.New("policy bundle is older than the loaded one")
+)
+
+// bundleSource downloads a policy bundle: a gzipped tar of .wasm files
+// served at a URL, signed with Ed25519. The base64 signature over the
+// bundle bytes is served at the same URL with ".sig" appended to the path.
+// A top-level VERSION file, covered by the signature, numbers the bundle;
+// a bundle numbered below the loaded one is refused so an old signed
+// bundle cannot be replayed. Bundles without one are version 0.
+type bundleSource struct {
+	url       string
+	sigURL    string
+	publicKey ed25519.PublicKey
+	client    *http.Client
+	// timeout bounds a whole fetch, bundle and signature together
+	timeout time.Duration
+
+	etag    string
+	digest  string
+	version uint64
+}
+
+func newBundleSource(cfg Config) (*bundleSource, error) {
+	parsed, err := url.Parse(cfg.BundleURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid POLICY_BUNDLE_URL: %w", err)
+	}
+	switch parsed.Scheme {
+	case "http", "https":
+	case "oci":
+		return nil, fmt.Errorf("OCI policy bundles are not supported; serve the bundle over HTTP(S)")
+	default:
+		return nil, fmt.Errorf("invalid POLICY_BUNDLE_URL scheme %q", parsed.Scheme)
+	}
+
+	if cfg.BundlePublicKey == "" {
+		return nil, fmt.Errorf("POLICY_BUNDLE_PUBLIC_KEY is required with POLICY_BUNDLE_URL")
+	}
+	key, err := base64.StdEncoding.DecodeString(cfg.BundlePublicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("POLICY_BUNDLE_PUBLIC_KEY must be a base64 Ed25519 public key")
+	}
+
+	timeout := time.Duration(cfg.BundleTimeout) * time.Second
+	if timeout <= 0 {
+		timeout = defaultBundleTimeout
+	}
+
+	// The query string, if any, stays after the path
+	sigURL := *parsed
+	sigURL.Path += ".sig"
+	if sigURL.RawPath != "" {
+		sigURL.RawPath += ".sig"
+	}
+
+	return &bundleSource{
+		url:       cfg.BundleURL,
+		sigURL:    sigURL.String(),
+		publicKey: ed25519.PublicKey(key),
+		client:    &http.Client{Timeout: timeout},
+		timeout:   timeout,
+	}, nil
+}
+
+// fetchedBundle is a verified download not yet loaded
+type fetchedBundle struct {
+	data   []byte
+	etag   string
+	digest string
+	// version is read from the bundle when it is extracted
+	version uint64
+}
+
+// fetch returns the verified bundle, or nil when it has not changed since
+// the last one loaded.
+func (s *bundleSource) fetch(ctx context.Context) (*fetchedBundle, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	if s.etag != "" {
+		req.Header.Set("If-None-Match", s.etag)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch bundle: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch bundle: server returned %d", resp.StatusCode)
+	}
+
+	data, err := readLimited(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read bundle: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	digest := hex.EncodeToString(sum[:])
+	if digest == s.digest {
+		return nil, nil
+	}
+
+	if err := s.verify(ctx, data); err != nil {
+		return nil, err
+	}
+
+	return &fetchedBundle{data: data, etag: resp.Header.Get("ETag"), digest: digest}, nil
+}
+
+// loaded records a bundle as current so unchanged downloads are skipped.
+// Bundles that fail to load are not recorded and are retried next poll.
+func (s *bundleSource) loaded(bundle *fetchedBundle) {
+	s.etag = bundle.etag
+	s.digest = bundle.digest
+	s.version = bundle.version
+}
+
+// extract unpacks a verified bundle, refusing one older than the bundle
+// already loaded
+func (s *bundleSource) extract(bundle *fetchedBundle) (string, error) {
+	dir, version, err := extractBundle(bundle.data)
+	if err != nil {
+		return "", err
+	}
+	if version < s.version {
+		os.RemoveAll(dir)
+		return "", fmt.Errorf("%w: version %d, loaded %d", ErrBundleRollback, version, s.version)
+	}
+	bundle.version = version
+	return dir, nil
+}
+
+func (s *bundleSource) verify(ctx context.Context, data []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.sigURL, nil)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("fetch bundle signature: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("fetch bundle signature: server returned %d", resp.StatusCode)
+	}
+
+	encoded, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if err != nil {
+		return fmt.Errorf("read bundle signature: %w", err)
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(encoded)))
+	if err != nil || !ed25519.Verify(s.publicKey, data, sig) {
+		return ErrBundleSignature
+	}
+	return nil
+}
+
+func readLimited(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxBundleSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxBundleSize {
+		return nil, fmt.Errorf("bundle exceeds %d bytes", maxBundleSize)
+	}
+	return data, nil
+}
+
+// extractBundle writes the bundle's top-level .wasm files into a new
+// directory and returns its path and the bundle version. Other entries are
+// ignored.
+func extractBundle(data []byte) (string, uint64, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return "", 0, fmt.Errorf("open bundle: %w", err)
+	}
+	defer gz.Close()
+
+	dir, err := os.MkdirTemp("", "policy-bundle-")
+	if err != nil {
+		return "", 0, fmt.Errorf("create bundle directory: %w", err)
+	}
+
+	count, version, err := extractWASMFiles(tar.NewReader(gz), dir)
+	if err == nil && count == 0 {
+		err = ErrBundleEmpty
+	}
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", 0, err
+	}
+	return dir, version, nil
+}
+
+func extractWASMFiles(tr *tar.Reader, dir string) (int, uint64, error) {
+	count := 0
+	var version uint64
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return count, version, nil
+		}
+		if err != nil {
+			return 0, 0, fmt.Errorf("read bundle: %w", err)
+		}
+
+		name := strings.TrimPrefix(header.Name, "./")
+		if header.Typeflag == tar.TypeReg && name == bundleVersionFile {
+			raw, err := io.ReadAll(io.LimitReader(tr, 64))
+			if err != nil {
+				return 0, 0, fmt.Errorf("read %s: %w", name, err)
+			}
+			if version, err = strconv.ParseUint(strings.TrimSpace(string(raw)), 10, 64); err != nil {
+				return 0, 0, fmt.Errorf("bundle %s must be a non-negative integer", name)
+			}
+			continue
+		}
+		if header.Typeflag != tar.TypeReg || name != filepath.Base(name) || !strings.HasSuffix(strings.ToLower(name), ".wasm") {
+			continue
+		}
+
+		data, err := readLimited(tr)
+		if err != nil {
+			return 0, 0, fmt.Errorf("read %s: %w", name, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o600); err != nil {
+			return 0, 0, fmt.Errorf("write %s: %w", name, err)
+		}
+		count++
+	}
+}
+
+// bundlePoller re-fetches the bundle on an interval and swaps in updates.
+// It replaces the file watcher when policies come from a bundle.
+type bundlePoller struct {
+	source   *bundleSource
+	interval time.Duration
+	done     chan struct{}
+	wg       sync.WaitGroup
+
+	mu          sync.Mutex
+	lastRefresh time.Time
+	lastErr     error
+}
+
+// BundleStatus reports how fresh the policies loaded from a bundle are
+type BundleStatus struct {
+	// LastRefresh is when the bundle server last answered a poll with a
+	// bundle that is now loaded, changed or not
+	LastRefresh time.Time
+	// Error is why the most recent poll failed, empty if it succeeded
+	Error string
+}
+
+func newBundleEngine(cfg Config) (*Engine, error) {
+	source, err := newBundleSource(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), source.timeout)
+	defer cancel()
+
+	bundle, err := source.fetch(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("initial bundle load: %w", err)
+	}
+	dir, err := source.extract(bundle)
+	if err != nil {
+		return nil, fmt.Errorf("initial bundle load: %w", err)
+	}
+
+	engine, err := newEngine(cfg, dir)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	if err := engine.loadPolicies(dir); err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("initial load: %w", err)
+	}
+	if err := checkHash(engine.expectedHash, engine.evaluators); err != nil {
+		engine.Close()
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	source.loaded(bundle)
+
+	interval := time.Duration(cfg.BundlePollInterval) * time.Second
+	if interval <= 0 {
+		interval = defaultBundlePollInterval
+	}
+
+	engine.bundle = &bundlePoller{source: source, interval: interval, done: make(chan struct{}), lastRefresh: time.Now()}
+	engine.bundle.wg.Add(1)
+	go engine.pollBundle()
+
+	log.Info().Str("url", cfg.BundleURL).Dur("interval", interval).Msg("loaded policy bundle")
+
+	if cfg.Warmup {
+		engine.Warmup(context.Background())
+	}
+
+	return engine, nil
+}
+
+func (p *bundlePoller) stop() {
+	close(p.done)
+	p.wg.Wait()
+}
+
+func (e *Engine) pollBundle() {
+	defer e.bundle.wg.Done()
+
+	ticker := time.NewTicker(e.bundle.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-e.bundle.done:
+			return
+		case <-ticker.C:
+		}
+
+		e.pollBundleOnce()
+	}
+}
+
+// pollBundleOnce refreshes the bundle and records the outcome. A slow or
+// failing bundle server never holds up evaluation: the fetch is bounded by
+// the bundle timeout and the last good policies keep serving, marked stale.
+func (e *Engine) pollBundleOnce() {
+	err := e.refreshBundle()
+
+	e.bundle.mu.Lock()
+	e.bundle.lastErr = err
+	if err == nil {
+		e.bundle.lastRefresh = time.Now()
+	}
+	e.bundle.mu.Unlock()
+
+	e.mu.Lock()
+	e.stale = err != nil
+	e.mu.Unlock()
+
+	if err != nil {
+		log.Error().Err(err).Str("url", e.bundle.source.url).Msg("policy bundle update failed, keeping previous policies")
+	}
+}
+
+// BundleStatus reports the freshness of bundle-loaded policies. ok is false
+// when policies come from a local directory.
+func (e *Engine) BundleStatus() (status BundleStatus, ok bool) {
+	if e.bundle == nil {
+		return BundleStatus{}, false
+	}
+
+	e.bundle.mu.Lock()
+	defer e.bundle.mu.Unlock()
+
+	status.LastRefresh = e.bundle.lastRefresh
+	if e.bundle.lastErr != nil {
+		status.Error = e.bundle.lastErr.Error()
+	}
+	return status, true
+}
+
+// refreshBundle loads a changed bundle. The previous policies keep serving
+// if the new bundle cannot be verified, extracted or loaded.
+func (e *Engine) refreshBundle() error {
+	ctx, cancel := context.WithTimeout(context.Background(), e.bundle.source.timeout)
+	defer cancel()
+
+	bundle, err := e.bundle.source.fetch(ctx)
+	if err != nil || bundle == nil {
+		return err
+	}
+
+	dir, err := e.bundle.source.extract(bundle)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	previous := e.dir
+	e.dir = dir
+	e.mu.Unlock()
+
+	if err := e.Reload(); err != nil {
+		e.mu.Lock()
+		e.dir = previous
+		e.mu.Unlock()
+		os.RemoveAll(dir)
+		return err
+	}
+
+	e.bundle.source.loaded(bundle)
+	os.RemoveAll(previous)
+	log.Info().Str("url", e.bundle.source.url).Msg("policy bundle updated")
+	return nil
+}
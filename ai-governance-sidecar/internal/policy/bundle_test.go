@@ -0,0 +1,281 @@
+package policy
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bytecodealliance/wasmtime-go/v3"
+)
+
+const bundlePolicyWAT = `(module
+	(memory (export "memory") 1)
+	(func (export "allocate") (param i32) (result i32) (i32.const 1024))
+	(func (export "evaluate") (param i32 i32 i32 i32) (result i32) (i32.const 0)))`
+
+// bundleServer serves a signed bundle that tests can replace
+type bundleServer struct {
+	mu     sync.Mutex
+	key    ed25519.PrivateKey
+	bundle []byte
+	sig    string
+	// version, when set, is published as the bundle's VERSION file
+	version string
+	// sigQuery is the query string of the last signature request
+	sigQuery string
+	// stall makes requests hang until the client gives up
+	stall bool
+}
+
+func (s *bundleServer) publish(t *testing.T, names ...string) {
+	t.Helper()
+
+	wasm, err := wasmtime.Wat2Wasm(bundlePolicyWAT)
+	if err != nil {
+		t.Fatalf("compile wat: %v", err)
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	files := make(map[string][]byte, len(names)+1)
+	for _, name := range names {
+		files[name] = wasm
+	}
+	s.mu.Lock()
+	if s.version != "" {
+		files[bundleVersionFile] = []byte(s.version + "\n")
+	}
+	s.mu.Unlock()
+	for name, data := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(data)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tw.Close()
+	gz.Close()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.bundle = buf.Bytes()
+	s.sig = base64.StdEncoding.EncodeToString(ed25519.Sign(s.key, s.bundle))
+}
+
+func (s *bundleServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	stall := s.stall
+	s.mu.Unlock()
+	if stall {
+		<-r.Context().Done()
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch r.URL.Path {
+	case "/policies.tar.gz":
+		w.Write(s.bundle)
+	case "/policies.tar.gz.sig":
+		s.sigQuery = r.URL.RawQuery
+		w.Write([]byte(s.sig))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func newTestBundleServer(t *testing.T) (*bundleServer, *httptest.Server, string) {
+	t.Helper()
+
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs := &bundleServer{key: priv}
+	srv := httptest.NewServer(bs)
+	t.Cleanup(srv.Close)
+	return bs, srv, base64.StdEncoding.EncodeToString(pub)
+}
+
+func policyNames(e *Engine) map[string]bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	names := make(map[string]bool)
+	for name := range e.evaluators {
+		names[name] = true
+	}
+	return names
+}
+
+func TestBundleEngineLoadsUpdatedBundle(t *testing.T) {
+	bs, srv, pub := newTestBundleServer(t)
+	bs.publish(t, "base_v1.wasm")
+
+	engine, err := NewEngine(Config{BundleURL: srv.URL + "/policies.tar.gz", BundlePublicKey: pub})
+	if err != nil {
+		t.Fatalf("create engine: %v", err)
+	}
+	defer engine.Close()
+
+	if names := policyNames(engine); !names["base_v1"] || len(names) != 1 {
+		t.Fatalf("expected base_v1 from the bundle, got %v", names)
+	}
+
+	// Unchanged bundle is a no-op
+	if err := engine.refreshBundle(); err != nil {
+		t.Fatalf("refresh unchanged bundle: %v", err)
+	}
+
+	bs.publish(t, "base_v2.wasm", "pii.wasm")
+	if err := engine.refreshBundle(); err != nil {
+		t.Fatalf("refresh updated bundle: %v", err)
+	}
+
+	names := policyNames(engine)
+	if !names["base_v2"] || !names["pii"] || names["base_v1"] {
+		t.Errorf("expected updated bundle policies, got %v", names)
+	}
+}
+
+func TestBundleEngineRejectsBadSignature(t *testing.T) {
+	bs, srv, pub := newTestBundleServer(t)
+	bs.publish(t, "base_v1.wasm")
+
+	engine, err := NewEngine(Config{BundleURL: srv.URL + "/policies.tar.gz", BundlePublicKey: pub})
+	if err != nil {
+		t.Fatalf("create engine: %v", err)
+	}
+	defer engine.Close()
+
+	// A bundle signed by someone else must not replace the loaded one
+	_, otherKey, _ := ed25519.GenerateKey(nil)
+	bs.key = otherKey
+	bs.publish(t, "evil.wasm")
+
+	if err := engine.refreshBundle(); !errors.Is(err, ErrBundleSignature) {
+		t.Fatalf("expected signature error, got %v", err)
+	}
+	if names := policyNames(engine); !names["base_v1"] || names["evil"] {
+		t.Errorf("expected previous policies to keep serving, got %v", names)
+	}
+}
+
+func TestBundleEngineKeepsQueryOnSignatureURL(t *testing.T) {
+	bs, srv, pub := newTestBundleServer(t)
+	bs.publish(t, "base_v1.wasm")
+
+	engine, err := NewEngine(Config{BundleURL: srv.URL + "/policies.tar.gz?token=abc", BundlePublicKey: pub})
+	if err != nil {
+		t.Fatalf("create engine: %v", err)
+	}
+	defer engine.Close()
+
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	if bs.sigQuery != "token=abc" {
+		t.Errorf("expected the signature fetched with the bundle query, got %q", bs.sigQuery)
+	}
+}
+
+func TestBundleEngineRejectsRollback(t *testing.T) {
+	bs, srv, pub := newTestBundleServer(t)
+	bs.version = "2"
+	bs.publish(t, "base_v2.wasm")
+
+	engine, err := NewEngine(Config{BundleURL: srv.URL + "/policies.tar.gz", BundlePublicKey: pub})
+	if err != nil {
+		t.Fatalf("create engine: %v", err)
+	}
+	defer engine.Close()
+
+	// An older bundle, validly signed, must not replace the loaded one
+	bs.version = "1"
+	bs.publish(t, "base_v1.wasm")
+	if err := engine.refreshBundle(); !errors.Is(err, ErrBundleRollback) {
+		t.Fatalf("expected rollback error, got %v", err)
+	}
+	if names := policyNames(engine); !names["base_v2"] || names["base_v1"] {
+		t.Errorf("expected version 2 policies to keep serving, got %v", names)
+	}
+
+	bs.version = "3"
+	bs.publish(t, "base_v3.wasm")
+	if err := engine.refreshBundle(); err != nil {
+		t.Fatalf("refresh newer bundle: %v", err)
+	}
+	if names := policyNames(engine); !names["base_v3"] || names["base_v2"] {
+		t.Errorf("expected version 3 policies, got %v", names)
+	}
+}
+
+func TestBundleEngineTimeoutKeepsPreviousPolicies(t *testing.T) {
+	bs, srv, pub := newTestBundleServer(t)
+	bs.publish(t, "base_v1.wasm")
+
+	engine, err := NewEngine(Config{BundleURL: srv.URL + "/policies.tar.gz", BundlePublicKey: pub})
+	if err != nil {
+		t.Fatalf("create engine: %v", err)
+	}
+	defer engine.Close()
+
+	before, _ := engine.BundleStatus()
+
+	engine.bundle.source.timeout = 50 * time.Millisecond
+	bs.mu.Lock()
+	bs.stall = true
+	bs.mu.Unlock()
+
+	start := time.Now()
+	engine.pollBundleOnce()
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("expected the refresh to give up at the timeout, took %v", elapsed)
+	}
+
+	if names := policyNames(engine); !names["base_v1"] || len(names) != 1 {
+		t.Errorf("expected previous policies to keep serving, got %v", names)
+	}
+	if !engine.Stale() {
+		t.Error("expected policies to be reported stale after a failed refresh")
+	}
+	status, ok := engine.BundleStatus()
+	if !ok || status.Error == "" || !status.LastRefresh.Equal(before.LastRefresh) {
+		t.Errorf("expected the error recorded and last refresh unchanged, got %+v", status)
+	}
+
+	resp, err := engine.Evaluate(context.Background(), Request{ToolName: "deploy", Args: json.RawMessage(`{}`)})
+	if err != nil || resp.Policy == "" {
+		t.Errorf("expected evaluation against the previous policies, got %+v (%v)", resp, err)
+	}
+
+	// The source recovering clears the staleness
+	bs.mu.Lock()
+	bs.stall = false
+	bs.mu.Unlock()
+	engine.pollBundleOnce()
+	if engine.Stale() {
+		t.Error("expected a successful refresh to clear staleness")
+	}
+	if status, _ := engine.BundleStatus(); status.Error != "" || !status.LastRefresh.After(before.LastRefresh) {
+		t.Errorf("expected a fresh refresh time and no error, got %+v", status)
+	}
+}
+
+func TestBundleEngineRequiresPublicKey(t *testing.T) {
+	if _, err := NewEngine(Config{BundleURL: "https://policies.example.com/bundle.tar.gz"}); err == nil {
+		t.Error("expected error without a bundle public key")
+	}
+}
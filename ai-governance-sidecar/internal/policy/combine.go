@@ -0,0 +1,111 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// policyResult is one selected policy's answer to a request
+type policyResult struct {
+	name     string
+	resp     Response
+	err      error
+	duration time.Duration
+}
+
+// runSelected lifts the configured argument fields into req's metadata and
+// runs the policies selected for its tool. It returns the request the
+// policies saw, for Evaluate and EvaluateTrace alike. Callers hold e.mu.
+func (e *Engine) runSelected(ctx context.Context, req Request) (Request, []policyResult) {
+	req = e.extractor.apply(req)
+	return req, runPolicies(ctx, e.selectEvaluators(req.ToolName), req)
+}
+
+// runPolicies evaluates every policy in evaluators, in name order, so the
+// combined decision never depends on map iteration
+func runPolicies(ctx context.Context, evaluators map[string]moduleEvaluator, req Request) []policyResult {
+	names := make([]string, 0, len(evaluators))
+	for name := range evaluators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	results := make([]policyResult, 0, len(names))
+	for _, name := range names {
+		start := time.Now()
+		resp, err := evaluateModule(ctx, name, evaluators[name], req)
+		if err != nil {
+			log.Warn().Err(err).Str("policy", name).Msg("policy evaluation failed")
+		}
+		results = append(results, policyResult{name: name, resp: resp, err: err, duration: time.Since(start)})
+	}
+	return results
+}
+
+// combine merges the results of runPolicies. A policy error or hard deny
+// wins, then an overridable deny, then a human_required escalation,
+// otherwise the call is allowed; ties go to the first policy by name.
+// JustificationRequired and warnings are gathered from every enforced
+// policy whichever one decides. Shadowed policies are recorded but never
+// decide.
+func (e *Engine) combine(toolName string, results []policyResult) Response {
+	if len(results) == 0 {
+		return e.denyResponse(fmt.Sprintf("no policies configured for tool: %s", toolName))
+	}
+
+	var deny, overridable, escalate, shadow *Response
+	var warnings []string
+	justify := false
+	for _, result := range results {
+		resp := result.resp
+		if result.err != nil {
+			resp = e.denyResponse(fmt.Sprintf("policy error: %s", result.name))
+		}
+		resp.Policy = result.name
+
+		if e.isShadow(result.name) {
+			if shadow == nil && (!resp.Allow || resp.HumanRequired) {
+				shadow = &resp
+			}
+			continue
+		}
+
+		justify = justify || resp.JustificationRequired
+		warnings = mergeWarnings(warnings, resp.Warnings)
+
+		switch {
+		case !resp.Allow && !resp.OverrideAllowed:
+			if deny == nil {
+				deny = &resp
+			}
+		case !resp.Allow:
+			if overridable == nil {
+				overridable = &resp
+			}
+		case resp.HumanRequired:
+			if escalate == nil {
+				escalate = &resp
+			}
+		}
+	}
+
+	decision := Response{Allow: true, Reason: "all policies passed"}
+	switch {
+	case deny != nil:
+		decision = *deny
+	case overridable != nil:
+		decision = *overridable
+	case escalate != nil:
+		decision = *escalate
+	}
+	if deny == nil {
+		decision.JustificationRequired = justify
+	}
+	decision.Shadow = shadow
+	decision.Warnings = warnings
+	return decision
+}
@@ -0,0 +1,87 @@
+package policy
+
+import (
+	"strings"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/toolmatch"
+)
+
+// Config holds policy engine configuration
+type Config struct {
+	Dir          string
+	ToolPolicies ToolPolicyMap
+	MaxPolicies  int  // 0 means unlimited
+	Warmup       bool // run a synthetic evaluation through each policy at startup
+	// Shadow names policies that are evaluated but never block; their
+	// would-be decision is reported in Response.Shadow. ShadowAll ("*")
+	// shadows every policy.
+	Shadow []string
+	// BundleURL loads policies from a signed HTTP(S) bundle instead of Dir.
+	// The signature is fetched from the same URL with ".sig" appended to
+	// the path.
+	BundleURL string
+	// BundlePublicKey is the base64 Ed25519 key that signs the bundle
+	BundlePublicKey string
+	// BundlePollInterval is how often the bundle is checked for updates
+	BundlePollInterval int // seconds
+	// BundleTimeout bounds each bundle fetch; on timeout the last good
+	// policies keep serving
+	BundleTimeout int // seconds
+	// ExpectedHash pins the policy set: the engine refuses to start, and
+	// refuses reloads, when PolicyHash differs
+	ExpectedHash string
+	// CounterDB is the SQLite file backing policy counters; empty keeps
+	// counts in memory
+	CounterDB string
+	// MetadataFields lifts argument fields into metadata before evaluation,
+	// metadata key to path, e.g. {"database": "args.connection.database"}
+	MetadataFields map[string]string
+	// Egress names policies that evaluate upstream responses instead of
+	// requests; they can block a response or redact fields from it
+	Egress []string
+}
+
+// ShadowAll in Config.Shadow puts every policy in shadow mode
+const ShadowAll = "*"
+
+// shadowSet returns the normalized shadowed policy names
+func shadowSet(names []string) map[string]bool {
+	if len(names) == 0 {
+		return nil
+	}
+
+	set := make(map[string]bool, len(names))
+	for _, name := range normalizePolicyNames(names) {
+		set[name] = true
+	}
+	return set
+}
+
+// ToolPolicyMap restricts which policies evaluate a given tool.
+// Tools maps a tool name or glob to policy names, an exact name taking
+// precedence over globs; Default applies to unmapped tools. An empty Default means unmapped tools run every loaded policy.
+type ToolPolicyMap struct {
+	Tools   map[string][]string `json:"tools"`
+	Default []string            `json:"default"`
+}
+
+func (m ToolPolicyMap) policiesFor(toolName string) ([]string, bool) {
+	if names, _, ok := toolmatch.Lookup(m.Tools, toolName); ok {
+		return normalizePolicyNames(names), true
+	}
+
+	if len(m.Default) > 0 {
+		return normalizePolicyNames(m.Default), true
+	}
+
+	return nil, false
+}
+
+// normalizePolicyNames matches the loader, which lower-cases file names
+func normalizePolicyNames(names []string) []string {
+	normalized := make([]string, 0, len(names))
+	for _, name := range names {
+		normalized = append(normalized, strings.ToLower(strings.TrimSpace(name)))
+	}
+	return normalized
+}
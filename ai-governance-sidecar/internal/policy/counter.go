@@ -0,0 +1,175 @@
+package policy
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/clock"
+	"github.com/rs/zerolog/log"
+	_ "modernc.org/sqlite"
+)
+
+// Counters keep fixed-window call counts on behalf of policies, which are
+// otherwise stateless. A count belongs to a key and a window length and
+// resets when the window rolls over.
+type Counters interface {
+	Increment(key string, window time.Duration) (int64, error)
+	Get(key string, window time.Duration) (int64, error)
+	Close() error
+}
+
+// windowStart is the start of the fixed window now falls in
+func windowStart(now time.Time, window time.Duration) int64 {
+	return now.Truncate(window).Unix()
+}
+
+type counterKey struct {
+	key    string
+	window time.Duration
+	start  int64
+}
+
+// memoryCounters lose their counts on restart; they back policies when no
+// counter database is configured
+type memoryCounters struct {
+	clock clock.Clock
+
+	mu     sync.Mutex
+	counts map[counterKey]int64
+}
+
+func newMemoryCounters(clk clock.Clock) *memoryCounters {
+	return &memoryCounters{clock: clk, counts: make(map[counterKey]int64)}
+}
+
+func (m *memoryCounters) Increment(key string, window time.Duration) (int64, error) {
+	now := m.clock.Now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for k := range m.counts {
+		if k.start+int64(k.window/time.Second) <= now.Unix() {
+			delete(m.counts, k)
+		}
+	}
+
+	k := counterKey{key: key, window: window, start: windowStart(now, window)}
+	m.counts[k]++
+	return m.counts[k], nil
+}
+
+func (m *memoryCounters) Get(key string, window time.Duration) (int64, error) {
+	now := m.clock.Now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.counts[counterKey{key: key, window: window, start: windowStart(now, window)}], nil
+}
+
+func (m *memoryCounters) Close() error { return nil }
+
+// sqliteCounters persist counts so a restart does not reset rate limits
+type sqliteCounters struct {
+	db    *sql.DB
+	clock clock.Clock
+}
+
+func newSQLiteCounters(path string, clk clock.Clock) (*sqliteCounters, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("create counter directory: %w", err)
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("open counter database: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+
+	for _, stmt := range []string{
+		"PRAGMA busy_timeout = 5000",
+		`CREATE TABLE IF NOT EXISTS policy_counters (
+			key TEXT NOT NULL,
+			window_secs INTEGER NOT NULL,
+			window_start INTEGER NOT NULL,
+			count INTEGER NOT NULL,
+			PRIMARY KEY (key, window_secs, window_start)
+		)`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("initialize counter database: %w", err)
+		}
+	}
+
+	return &sqliteCounters{db: db, clock: clk}, nil
+}
+
+func (s *sqliteCounters) Increment(key string, window time.Duration) (int64, error) {
+	now := s.clock.Now()
+	secs := int64(window / time.Second)
+
+	if _, err := s.db.Exec("DELETE FROM policy_counters WHERE window_start + window_secs <= ?", now.Unix()); err != nil {
+		log.Warn().Err(err).Msg("failed to prune expired policy counters")
+	}
+
+	var count int64
+	err := s.db.QueryRow(`INSERT INTO policy_counters (key, window_secs, window_start, count) VALUES (?, ?, ?, 1)
+		ON CONFLICT (key, window_secs, window_start) DO UPDATE SET count = count + 1
+		RETURNING count`, key, secs, windowStart(now, window)).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("increment counter: %w", err)
+	}
+	return count, nil
+}
+
+func (s *sqliteCounters) Get(key string, window time.Duration) (int64, error) {
+	var count int64
+	err := s.db.QueryRow("SELECT count FROM policy_counters WHERE key = ? AND window_secs = ? AND window_start = ?",
+		key, int64(window/time.Second), windowStart(s.clock.Now(), window)).Scan(&count)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("read counter: %w", err)
+	}
+	return count, nil
+}
+
+func (s *sqliteCounters) Close() error {
+	return s.db.Close()
+}
+
+// openCounters opens the counter database at path. Without one counts are
+// kept in memory and reset on restart; a configured database that cannot
+// be opened is an error rather than a silent reset of every limit.
+func openCounters(path string) (Counters, error) {
+	if path == "" {
+		return newMemoryCounters(clock.Real{}), nil
+	}
+
+	counters, err := newSQLiteCounters(path, clock.Real{})
+	if err != nil {
+		return nil, fmt.Errorf("open policy counters: %w", err)
+	}
+	return counters, nil
+}
+
+type readOnlyCountersKey struct{}
+
+// WithReadOnlyCounters marks evaluations under ctx as not enforcing, as for
+// dry runs and previews: incr_counter reports the count the call would
+// reach without recording it.
+func WithReadOnlyCounters(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readOnlyCountersKey{}, true)
+}
+
+func readOnlyCounters(ctx context.Context) bool {
+	readOnly, _ := ctx.Value(readOnlyCountersKey{}).(bool)
+	return readOnly
+}
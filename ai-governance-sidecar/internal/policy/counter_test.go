@@ -0,0 +1,168 @@
+package policy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	wasmtime "github.com/bytecodealliance/wasmtime-go/v3"
+	"github.com/dagbolade/ai-governance-sidecar/internal/clock"
+)
+
+// rateLimitPolicyWAT counts every call under "deploy" in a 60 second window
+// and denies once the count passes 3.
+const rateLimitPolicyWAT = `
+(module
+  (import "env" "incr_counter" (func $incr (param i32 i32 i32) (result i32)))
+  (memory (export "memory") 1)
+  (data (i32.const 0) "deploy")
+  (data (i32.const 16) "{\"allow\":true,\"reason\":\"under limit\"}\00")
+  (data (i32.const 64) "{\"allow\":false,\"reason\":\"rate limit exceeded\"}\00")
+  (global $next (mut i32) (i32.const 1024))
+  (func (export "allocate") (param $size i32) (result i32)
+    (local $ptr i32)
+    (local.set $ptr (global.get $next))
+    (global.set $next (i32.add (global.get $next) (local.get $size)))
+    (local.get $ptr))
+  (func (export "evaluate") (param $in i32) (param $len i32) (param $out i32) (param $max i32) (result i32)
+    (if (i32.gt_s (call $incr (i32.const 0) (i32.const 6) (i32.const 60)) (i32.const 3))
+      (then (memory.copy (local.get $out) (i32.const 64) (i32.const 48)))
+      (else (memory.copy (local.get $out) (i32.const 16) (i32.const 40))))
+    (i32.const 0)))
+`
+
+func newRateLimitEvaluator(t *testing.T, counters Counters) *WASMEvaluator {
+	t.Helper()
+
+	wasm, err := wasmtime.Wat2Wasm(rateLimitPolicyWAT)
+	if err != nil {
+		t.Fatalf("compile wat: %v", err)
+	}
+
+	engine := wasmtime.NewEngine()
+	module, err := wasmtime.NewModule(engine, wasm)
+	if err != nil {
+		t.Fatalf("create module: %v", err)
+	}
+
+	eval, err := NewWASMEvaluator(engine, module)
+	if err != nil {
+		t.Fatalf("create evaluator: %v", err)
+	}
+	eval.counters = counters
+	return eval
+}
+
+func evaluateAllowed(t *testing.T, eval *WASMEvaluator) bool {
+	t.Helper()
+
+	resp, err := eval.Evaluate(context.Background(), Request{ToolName: "deploy"})
+	if err != nil {
+		t.Fatalf("evaluate: %v", err)
+	}
+	return resp.Allow
+}
+
+func TestCounterPolicyDeniesAfterLimit(t *testing.T) {
+	clk := clock.NewFake(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	eval := newRateLimitEvaluator(t, newMemoryCounters(clk))
+
+	for i := 1; i <= 3; i++ {
+		if !evaluateAllowed(t, eval) {
+			t.Fatalf("expected call %d to be allowed", i)
+		}
+	}
+	if evaluateAllowed(t, eval) {
+		t.Fatal("expected the fourth call in the window to be denied")
+	}
+
+	clk.Advance(time.Minute)
+	if !evaluateAllowed(t, eval) {
+		t.Error("expected the count to reset in the next window")
+	}
+}
+
+func TestReadOnlyEvaluationLeavesCounters(t *testing.T) {
+	clk := clock.NewFake(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	counters := newMemoryCounters(clk)
+	eval := newRateLimitEvaluator(t, counters)
+	readOnly := WithReadOnlyCounters(context.Background())
+
+	for i := 0; i < 3; i++ {
+		evaluateAllowed(t, eval)
+	}
+
+	// A dry run of the fourth call sees it would pass the limit
+	resp, err := eval.Evaluate(readOnly, Request{ToolName: "deploy"})
+	if err != nil {
+		t.Fatalf("evaluate: %v", err)
+	}
+	if resp.Allow {
+		t.Error("expected the read-only evaluation to see the fourth call denied")
+	}
+	if count, _ := counters.Get("deploy", time.Minute); count != 3 {
+		t.Errorf("expected the read-only evaluation to leave the count at 3, got %d", count)
+	}
+
+	if evaluateAllowed(t, eval) {
+		t.Error("expected the next enforcing call to be counted as the fourth")
+	}
+}
+
+func TestOpenCountersFailsForUnusableDatabase(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0o600); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	if _, err := openCounters(filepath.Join(file, "counters.db")); err == nil {
+		t.Error("expected an error for a counter database that cannot be created")
+	}
+
+	counters, err := openCounters("")
+	if err != nil {
+		t.Fatalf("expected in-memory counters without a path, got %v", err)
+	}
+	if _, ok := counters.(*memoryCounters); !ok {
+		t.Errorf("expected in-memory counters, got %T", counters)
+	}
+}
+
+func TestCounterHostFailsWithoutCounters(t *testing.T) {
+	eval := newRateLimitEvaluator(t, nil)
+
+	// incr_counter returns -1, which this policy treats as under the limit
+	if !evaluateAllowed(t, eval) {
+		t.Error("expected the policy to see -1 and allow")
+	}
+}
+
+func TestSQLiteCountersPersistAcrossReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "counters.db")
+	clk := clock.NewFake(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+
+	counters, err := newSQLiteCounters(path, clk)
+	if err != nil {
+		t.Fatalf("open counters: %v", err)
+	}
+	eval := newRateLimitEvaluator(t, counters)
+	for i := 0; i < 3; i++ {
+		evaluateAllowed(t, eval)
+	}
+	counters.Close()
+
+	reopened, err := newSQLiteCounters(path, clk)
+	if err != nil {
+		t.Fatalf("reopen counters: %v", err)
+	}
+	defer reopened.Close()
+
+	if count, err := reopened.Get("deploy", time.Minute); err != nil || count != 3 {
+		t.Fatalf("expected count 3 after reopen, got %d (%v)", count, err)
+	}
+	if evaluateAllowed(t, newRateLimitEvaluator(t, reopened)) {
+		t.Error("expected the limit to hold across a restart")
+	}
+}
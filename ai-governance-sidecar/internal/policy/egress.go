@@ -0,0 +1,185 @@
+package policy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/redaction"
+	"github.com/rs/zerolog/log"
+)
+
+// ErrNoEgressPolicies is returned by EvaluateResponse when no egress policy
+// is loaded and enabled, so responses pass through unchecked
+var ErrNoEgressPolicies = errors.New("no egress policies loaded")
+
+// ResponseEvaluator is implemented by evaluators that can also check an
+// upstream response before it is returned to the caller
+type ResponseEvaluator interface {
+	EvaluateResponse(ctx context.Context, req Request) (Response, error)
+}
+
+// egressSet returns the normalized egress policy names
+func egressSet(names []string) map[string]bool {
+	if len(names) == 0 {
+		return nil
+	}
+
+	set := make(map[string]bool, len(names))
+	for _, name := range normalizePolicyNames(names) {
+		set[name] = true
+	}
+	return set
+}
+
+// EvaluateResponse runs every enabled egress policy against req, whose
+// Response holds the upstream result. Egress policies see every tool and
+// filter on tool_name themselves. Any deny blocks the response; otherwise
+// the fields each policy asked to redact are merged into Redact. Shadowed
+// egress policies are recorded but never block or redact.
+func (e *Engine) EvaluateResponse(ctx context.Context, req Request) (Response, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	evaluators := e.egressEvaluators()
+	if len(evaluators) == 0 {
+		return Response{}, ErrNoEgressPolicies
+	}
+
+	req = e.extractor.apply(req)
+
+	names := make([]string, 0, len(evaluators))
+	for name := range evaluators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var shadow *Response
+	var redact, warnings []string
+	for _, name := range names {
+		resp, err := evaluateModule(ctx, name, evaluators[name], req)
+		if err != nil {
+			log.Warn().Err(err).Str("policy", name).Msg("egress policy evaluation failed")
+			resp = e.denyResponse(fmt.Sprintf("policy error: %s", name))
+		}
+		resp.Policy = name
+
+		if e.isShadow(name) {
+			if shadow == nil && !resp.Allow {
+				shadow = &resp
+			}
+			continue
+		}
+
+		warnings = mergeWarnings(warnings, resp.Warnings)
+		if !resp.Allow {
+			resp.Redact = nil
+			resp.Shadow = shadow
+			resp.Warnings = warnings
+			return resp, nil
+		}
+		redact = mergeWarnings(redact, resp.Redact)
+	}
+
+	return Response{Allow: true, Reason: "all egress policies passed", Redact: redact, Shadow: shadow, Warnings: warnings}, nil
+}
+
+// egressEvaluators returns the enabled egress policies
+func (e *Engine) egressEvaluators() map[string]moduleEvaluator {
+	selected := make(map[string]moduleEvaluator, len(e.egress))
+	for name, eval := range e.evaluators {
+		if e.egress[name] {
+			selected[name] = eval
+		}
+	}
+	return e.withoutDisabled(selected)
+}
+
+// withoutEgress drops egress policies from a request selection
+func (e *Engine) withoutEgress(evaluators map[string]moduleEvaluator) map[string]moduleEvaluator {
+	if len(e.egress) == 0 {
+		return evaluators
+	}
+
+	selected := make(map[string]moduleEvaluator, len(evaluators))
+	for name, eval := range evaluators {
+		if !e.egress[name] {
+			selected[name] = eval
+		}
+	}
+	return selected
+}
+
+// RedactResponse replaces each field of body named in paths with
+// redaction.Placeholder and returns the new body with the paths that were
+// found. Paths take the metadata field syntax rooted at the response,
+// e.g. "response.items[0].email" or "$.email". body is returned unchanged
+// when it is not JSON or no path matches.
+func RedactResponse(body json.RawMessage, paths []string) (json.RawMessage, []string) {
+	if len(paths) == 0 || len(body) == 0 {
+		return body, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return body, nil
+	}
+
+	var redacted []string
+	for _, raw := range paths {
+		path, err := parseFieldPath(strings.TrimPrefix(strings.TrimSpace(raw), "response."))
+		if err != nil {
+			log.Warn().Err(err).Str("path", raw).Msg("invalid egress redact path, skipping")
+			continue
+		}
+		if path.replace(value, redaction.Placeholder) {
+			redacted = append(redacted, raw)
+		}
+	}
+	if len(redacted) == 0 {
+		return body, nil
+	}
+
+	out, err := json.Marshal(value)
+	if err != nil {
+		return body, nil
+	}
+	return out, redacted
+}
+
+// replace sets the field at p inside value, which must already exist
+func (p fieldPath) replace(value any, replacement any) bool {
+	if len(p) == 0 {
+		return false
+	}
+
+	parent, ok := p[:len(p)-1].lookup(value)
+	if !ok {
+		return false
+	}
+
+	last := p[len(p)-1]
+	switch v := parent.(type) {
+	case map[string]any:
+		if _, ok := v[last]; !ok {
+			return false
+		}
+		v[last] = replacement
+		return true
+	case []any:
+		index, err := strconv.Atoi(last)
+		if err != nil || index < 0 || index >= len(v) {
+			return false
+		}
+		v[index] = replacement
+		return true
+	}
+	return false
+}
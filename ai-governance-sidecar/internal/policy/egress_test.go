@@ -0,0 +1,109 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestEgressPoliciesSkipRequests(t *testing.T) {
+	request := &mockEvaluator{response: Response{Allow: true, Reason: "ok"}}
+	egress := &mockEvaluator{response: Response{Allow: false, Reason: "would block"}}
+	engine := &Engine{
+		evaluators: map[string]moduleEvaluator{"allow": request, "pii_filter": egress},
+		egress:     egressSet([]string{"PII_Filter"}),
+	}
+
+	resp, err := engine.Evaluate(context.Background(), Request{ToolName: "get_customer", Args: json.RawMessage(`{}`)})
+	if err != nil {
+		t.Fatalf("evaluate failed: %v", err)
+	}
+	if !resp.Allow {
+		t.Errorf("expected the egress policy to be left out of the request pass, got %+v", resp)
+	}
+	if egress.calls != 0 {
+		t.Errorf("expected no request evaluation by the egress policy, got %d", egress.calls)
+	}
+}
+
+func TestEvaluateResponse(t *testing.T) {
+	tests := []struct {
+		name       string
+		policies   map[string]Response
+		wantAllow  bool
+		wantRedact []string
+		wantPolicy string
+	}{
+		{
+			name: "redactions from every policy are merged",
+			policies: map[string]Response{
+				"pii":     {Allow: true, Redact: []string{"response.ssn", "response.email"}},
+				"secrets": {Allow: true, Redact: []string{"response.email", "response.token"}},
+			},
+			wantAllow:  true,
+			wantRedact: []string{"response.email", "response.ssn", "response.token"},
+		},
+		{
+			name: "any deny blocks the response",
+			policies: map[string]Response{
+				"pii":     {Allow: true, Redact: []string{"response.ssn"}},
+				"secrets": {Allow: false, Reason: "private key in output"},
+			},
+			wantPolicy: "secrets",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := &Engine{evaluators: map[string]moduleEvaluator{"allow": &mockEvaluator{response: Response{Allow: true}}}}
+			var names []string
+			for name, resp := range tt.policies {
+				engine.evaluators[name] = &mockEvaluator{response: resp}
+				names = append(names, name)
+			}
+			engine.egress = egressSet(names)
+
+			resp, err := engine.EvaluateResponse(context.Background(), Request{ToolName: "get_customer", Response: json.RawMessage(`{}`)})
+			if err != nil {
+				t.Fatalf("evaluate response failed: %v", err)
+			}
+			if resp.Allow != tt.wantAllow {
+				t.Errorf("expected allow=%v, got %+v", tt.wantAllow, resp)
+			}
+			if !reflect.DeepEqual(resp.Redact, tt.wantRedact) {
+				t.Errorf("expected redact %v, got %v", tt.wantRedact, resp.Redact)
+			}
+			if resp.Policy != tt.wantPolicy {
+				t.Errorf("expected policy %q, got %q", tt.wantPolicy, resp.Policy)
+			}
+		})
+	}
+}
+
+func TestEvaluateResponseWithoutEgressPolicies(t *testing.T) {
+	engine := &Engine{evaluators: map[string]moduleEvaluator{"allow": &mockEvaluator{response: Response{Allow: true}}}}
+
+	if _, err := engine.EvaluateResponse(context.Background(), Request{ToolName: "t"}); !errors.Is(err, ErrNoEgressPolicies) {
+		t.Errorf("expected ErrNoEgressPolicies, got %v", err)
+	}
+}
+
+func TestRedactResponse(t *testing.T) {
+	body := json.RawMessage(`{"name":"Ada","ssn":"078-05-1120","cards":[{"number":"4111","id":12345678901234567890}]}`)
+
+	out, redacted := RedactResponse(body, []string{"response.ssn", "$.cards[0].number", "response.missing", "bad[x]"})
+
+	want := `{"cards":[{"id":12345678901234567890,"number":"[REDACTED]"}],"name":"Ada","ssn":"[REDACTED]"}`
+	if string(out) != want {
+		t.Errorf("expected %s, got %s", want, out)
+	}
+	if !reflect.DeepEqual(redacted, []string{"response.ssn", "$.cards[0].number"}) {
+		t.Errorf("unexpected redacted paths %v", redacted)
+	}
+
+	if out, redacted := RedactResponse(json.RawMessage(`"plain text"`), []string{"response.ssn"}); string(out) != `"plain text"` || redacted != nil {
+		t.Errorf("expected a non-object body unchanged, got %s %v", out, redacted)
+	}
+}
@@ -2,29 +2,91 @@ package policy
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
+	"os"
 	"sync"
+	"time"
 
 	"github.com/rs/zerolog/log"
 )
 
+// moduleEvaluator is a single loaded policy module.
+type moduleEvaluator interface {
+	Evaluate(ctx context.Context, req Request) (Response, error)
+	Close() error
+}
+
+// CombineDenyOverrides is how the engine merges policy results: any deny
+// wins, then any escalation, otherwise allow.
+const CombineDenyOverrides = "deny_overrides"
+
+// policyLoader loads every policy module in a directory.
+type policyLoader interface {
+	LoadFromDir(dir string) (map[string]*WASMEvaluator, error)
+}
+
 type Engine struct {
-	mu         sync.RWMutex
-	loader     *WASMLoader
-	watcher    *FileWatcher
-	evaluators map[string]*WASMEvaluator
+	mu           sync.RWMutex
+	dir          string
+	loader       policyLoader
+	watcher      *FileWatcher
+	bundle       *bundlePoller
+	evaluators   map[string]moduleEvaluator
+	toolPolicies ToolPolicyMap
+	shadow       map[string]bool
+	egress       map[string]bool
+	expectedHash string
+	stale        bool
+	// disabled policies are skipped until re-enabled or reloaded
+	disabled map[string]bool
+	// counters persist rate-limit counts across evaluations and reloads
+	counters Counters
+	// extractor copies configured argument fields into metadata
+	extractor *metadataExtractor
+
+	reloadMu sync.Mutex
+	// pending is the next reload pass, queued but not yet started
+	pending *reloadCall
 }
 
-func NewEngine(policyDir string) (*Engine, error) {
-	loader := NewWASMLoader()
-	
-	engine := &Engine{
-		loader:     loader,
-		evaluators: make(map[string]*WASMEvaluator),
+// reloadCall is a reload pass shared by every caller that asks for it
+// before it starts
+type reloadCall struct {
+	done chan struct{}
+	err  error
+}
+
+// NewEngine loads policies from cfg.Dir and watches it for changes. When
+// cfg.BundleURL is set, policies come from the remote bundle instead.
+func NewEngine(cfg Config) (*Engine, error) {
+	if cfg.BundleURL != "" {
+		return newBundleEngine(cfg)
+	}
+
+	policyDir := cfg.Dir
+	if err := checkPolicyDir(policyDir); err != nil {
+		return nil, err
+	}
+
+	engine, err := newEngine(cfg, policyDir)
+	if err != nil {
+		return nil, err
 	}
 
 	if err := engine.loadPolicies(policyDir); err != nil {
-		return nil, fmt.Errorf("initial load: %w", err)
+		if !errors.Is(err, ErrNoPolicies) {
+			return nil, fmt.Errorf("initial load: %w", err)
+		}
+		// Start anyway: with no policies every request is denied
+		log.Warn().Str("dir", policyDir).Msg("policy directory is empty, all requests will be denied")
+	}
+
+	if err := checkHash(engine.expectedHash, engine.evaluators); err != nil {
+		engine.Close()
+		return nil, err
 	}
 
 	watcher, err := NewFileWatcher(policyDir, engine.handlePolicyChange)
@@ -33,45 +95,130 @@ func NewEngine(policyDir string) (*Engine, error) {
 	}
 	engine.watcher = watcher
 
+	if cfg.Warmup {
+		engine.Warmup(context.Background())
+	}
+
 	return engine, nil
 }
 
-func (e *Engine) Evaluate(ctx context.Context, req Request) (Response, error) {
+func newEngine(cfg Config, dir string) (*Engine, error) {
+	counters, err := openCounters(cfg.CounterDB)
+	if err != nil {
+		return nil, err
+	}
+
+	loader := NewWASMLoader()
+	loader.maxPolicies = cfg.MaxPolicies
+	loader.counters = counters
+
+	return &Engine{
+		dir:          dir,
+		loader:       loader,
+		evaluators:   make(map[string]moduleEvaluator),
+		toolPolicies: cfg.ToolPolicies,
+		shadow:       shadowSet(cfg.Shadow),
+		egress:       egressSet(cfg.Egress),
+		expectedHash: cfg.ExpectedHash,
+		counters:     loader.counters,
+		extractor:    newMetadataExtractor(cfg.MetadataFields),
+	}, nil
+}
+
+// warmupToolName marks synthetic warmup requests in policy logs
+const warmupToolName = "__warmup__"
+
+// Warmup evaluates a synthetic request against every loaded policy so the
+// first real request doesn't pay instantiation costs. Failures are logged
+// and never block startup.
+func (e *Engine) Warmup(ctx context.Context) {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 
-	if len(e.evaluators) == 0 {
-		return e.denyResponse("no policies loaded"), nil
+	req := Request{
+		ToolName: warmupToolName,
+		Args:     json.RawMessage(`{}`),
 	}
 
-	// Evaluate all policies; deny if any denies
+	// Synthetic calls must not count against real rate limits
+	ctx = WithReadOnlyCounters(ctx)
+
+	start := time.Now()
 	for name, eval := range e.evaluators {
-		resp, err := eval.Evaluate(ctx, req)
-		if err != nil {
-			log.Warn().Err(err).Str("policy", name).Msg("policy evaluation failed")
-			return e.denyResponse(fmt.Sprintf("policy error: %s", name)), nil
+		if _, err := evaluateModule(ctx, name, eval, req); err != nil {
+			log.Warn().Err(err).Str("policy", name).Msg("policy warmup failed")
 		}
+	}
 
-		if !resp.Allow {
-			return resp, nil
-		}
+	log.Info().Int("count", len(e.evaluators)).Dur("duration", time.Since(start)).Msg("policy warmup complete")
+}
 
-		if resp.HumanRequired {
-			return resp, nil
-		}
+func (e *Engine) Evaluate(ctx context.Context, req Request) (Response, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if len(e.evaluators) == 0 {
+		return e.denyResponse("no policies loaded"), nil
 	}
 
-	return Response{Allow: true, Reason: "all policies passed"}, nil
+	// Every selected policy runs, so a later deny is never skipped by an
+	// earlier escalation
+	req, results := e.runSelected(ctx, req)
+	return e.combine(req.ToolName, results), nil
 }
 
+func (e *Engine) isShadow(name string) bool {
+	return e.shadow[ShadowAll] || e.shadow[name]
+}
+
+// Reload loads the policy directory again. A call made while a pass is
+// running queues one more pass, so changes made during the running pass are
+// still picked up; calls that arrive while that pass is queued share it.
 func (e *Engine) Reload() error {
+	e.reloadMu.Lock()
+	if call := e.pending; call != nil {
+		e.reloadMu.Unlock()
+		<-call.done
+		return call.err
+	}
+	call := &reloadCall{done: make(chan struct{})}
+	e.pending = call
+	e.reloadMu.Unlock()
+
+	// Passes run under e.mu, one at a time. Once this one starts, later
+	// callers queue a new pass rather than joining it.
 	e.mu.Lock()
-	defer e.mu.Unlock()
+	e.reloadMu.Lock()
+	e.pending = nil
+	e.reloadMu.Unlock()
+
+	call.err = e.reloadLocked()
+	e.mu.Unlock()
+	close(call.done)
 
-	return e.reloadLocked()
+	return call.err
+}
+
+// WatcherHealthy reports whether policy hot reload is active. It is false
+// while the directory watch is down and being re-established.
+func (e *Engine) WatcherHealthy() bool {
+	return e.watcher == nil || e.watcher.Healthy()
+}
+
+// Stale reports whether the last reload failed and the engine is still
+// serving the previously loaded policy set.
+func (e *Engine) Stale() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.stale
 }
 
 func (e *Engine) Close() error {
+	// The poller reloads under e.mu, so stop it before taking the lock
+	if e.bundle != nil {
+		e.bundle.stop()
+	}
+
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
@@ -87,6 +234,16 @@ func (e *Engine) Close() error {
 		}
 	}
 
+	if e.counters != nil {
+		if err := e.counters.Close(); err != nil {
+			log.Warn().Err(err).Msg("failed to close policy counters")
+		}
+	}
+
+	if e.bundle != nil {
+		os.RemoveAll(e.dir)
+	}
+
 	return nil
 }
 
@@ -105,37 +262,88 @@ func (e *Engine) loadPolicies(dir string) error {
 }
 
 func (e *Engine) reloadLocked() error {
-	// Close existing evaluators
-	for _, eval := range e.evaluators {
-		eval.Close()
+	policies, err := e.loader.LoadFromDir(e.dir)
+	if err != nil {
+		if !errors.Is(err, ErrNoPolicies) {
+			// Directory unreadable or over the limit: keep serving the previous set
+			e.stale = true
+			log.Error().Err(err).Str("dir", e.dir).Int("count", len(e.evaluators)).
+				Msg("policy reload failed, keeping previous policies")
+			return err
+		}
+		log.Warn().Str("dir", e.dir).Msg("policy directory is empty, all requests will be denied")
+		policies = make(map[string]*WASMEvaluator)
 	}
-	e.evaluators = make(map[string]*WASMEvaluator)
 
-	// Reload from directory
-	policies, err := e.loader.LoadFromDir(e.watcher.dir)
-	if err != nil {
+	if err := checkHash(e.expectedHash, policies); err != nil {
+		for _, eval := range policies {
+			eval.Close()
+		}
+		e.stale = true
+		log.Error().Err(err).Str("dir", e.dir).Msg("policy reload refused, keeping previous policies")
 		return err
 	}
 
+	for _, eval := range e.evaluators {
+		eval.Close()
+	}
+	e.evaluators = make(map[string]moduleEvaluator, len(policies))
 	for name, eval := range policies {
 		e.evaluators[name] = eval
 	}
+	e.disabled = nil
+	e.stale = false
 
 	log.Info().Int("count", len(policies)).Msg("policies reloaded")
 	return nil
 }
 
+// selectEvaluators returns the enabled request policies that apply to a
+// tool. Without a tool mapping every loaded policy applies. Egress
+// policies never evaluate requests.
+func (e *Engine) selectEvaluators(toolName string) map[string]moduleEvaluator {
+	names, mapped := e.toolPolicies.policiesFor(toolName)
+	if !mapped {
+		return e.withoutEgress(e.withoutDisabled(e.evaluators))
+	}
+
+	selected := make(map[string]moduleEvaluator, len(names))
+	for _, name := range names {
+		eval, ok := e.evaluators[name]
+		if !ok {
+			log.Warn().Str("policy", name).Str("tool", toolName).Msg("mapped policy not loaded")
+			continue
+		}
+		selected[name] = eval
+	}
+
+	return e.withoutEgress(e.withoutDisabled(selected))
+}
+
 func (e *Engine) handlePolicyChange(path string) {
 	log.Info().Str("path", path).Msg("policy change detected")
-	
-	e.mu.Lock()
-	defer e.mu.Unlock()
 
-	if err := e.reloadLocked(); err != nil {
+	if err := e.Reload(); err != nil {
 		log.Error().Err(err).Msg("failed to reload policies")
 	}
 }
 
+func checkPolicyDir(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("policy directory does not exist: %s", dir)
+		}
+		return fmt.Errorf("stat policy directory: %w", err)
+	}
+
+	if !info.IsDir() {
+		return fmt.Errorf("policy path is not a directory: %s", dir)
+	}
+
+	return nil
+}
+
 func (e *Engine) denyResponse(reason string) Response {
 	return Response{
 		Allow:  false,
@@ -3,15 +3,23 @@ package policy
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 type mockEvaluator struct {
 	response Response
 	err      error
+	calls    int
 }
 
 func (m *mockEvaluator) Evaluate(ctx context.Context, req Request) (Response, error) {
+	m.calls++
 	if m.err != nil {
 		return Response{}, m.err
 	}
@@ -23,7 +31,7 @@ func (m *mockEvaluator) Close() error  { return nil }
 
 func TestEngineEvaluation(t *testing.T) {
 	engine := &Engine{
-		evaluators: map[string]*WASMEvaluator{},
+		evaluators: map[string]moduleEvaluator{},
 	}
 
 	ctx := context.Background()
@@ -50,7 +58,7 @@ func TestEngineReload(t *testing.T) {
 	policyDir := t.TempDir()
 	
 	engine := &Engine{
-		evaluators: make(map[string]*WASMEvaluator),
+		evaluators: make(map[string]moduleEvaluator),
 		loader:     NewWASMLoader(),
 	}
 
@@ -80,4 +88,643 @@ func TestDenyResponse(t *testing.T) {
 	if resp.HumanRequired {
 		t.Error("expected HumanRequired to be false")
 	}
-}
\ No newline at end of file
+}
+
+func TestNewEngineMissingDirectory(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "does-not-exist")
+
+	engine, err := NewEngine(Config{Dir: dir})
+	if err == nil {
+		engine.Close()
+		t.Fatal("expected error for missing policy directory")
+	}
+}
+
+func TestNewEngineEmptyDirectory(t *testing.T) {
+	engine, err := NewEngine(Config{Dir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("expected empty directory to start, got: %v", err)
+	}
+	defer engine.Close()
+
+	resp, err := engine.Evaluate(context.Background(), Request{ToolName: "test_tool"})
+	if err != nil {
+		t.Fatalf("evaluation failed: %v", err)
+	}
+
+	if resp.Allow {
+		t.Error("expected deny when policy directory is empty")
+	}
+}
+
+func TestReloadUnreadableDirectoryKeepsPolicies(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "policies")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	engine := &Engine{
+		dir:        dir,
+		loader:     NewWASMLoader(),
+		evaluators: map[string]moduleEvaluator{"existing": &mockEvaluator{}},
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := engine.Reload(); err == nil {
+		t.Error("expected reload error for unreadable directory")
+	}
+
+	if _, ok := engine.evaluators["existing"]; !ok {
+		t.Error("expected previous policies to be kept")
+	}
+
+	if !engine.Stale() {
+		t.Error("expected engine to be marked stale")
+	}
+}
+
+func TestReloadEmptyDirectoryClearsPolicies(t *testing.T) {
+	engine := &Engine{
+		dir:        t.TempDir(),
+		loader:     NewWASMLoader(),
+		evaluators: map[string]moduleEvaluator{"existing": &mockEvaluator{}},
+		stale:      true,
+	}
+
+	if err := engine.Reload(); err != nil {
+		t.Fatalf("expected empty directory reload to succeed, got: %v", err)
+	}
+
+	if len(engine.evaluators) != 0 {
+		t.Errorf("expected no policies, got %d", len(engine.evaluators))
+	}
+
+	if engine.Stale() {
+		t.Error("expected stale flag to be cleared")
+	}
+}
+
+// blockingLoader counts loads, records how many files each load found and
+// holds each load until release is closed
+type blockingLoader struct {
+	loads   atomic.Int32
+	started chan struct{}
+	release chan struct{}
+
+	mu    sync.Mutex
+	files []int
+}
+
+func (l *blockingLoader) LoadFromDir(dir string) (map[string]*WASMEvaluator, error) {
+	entries, _ := os.ReadDir(dir)
+	l.mu.Lock()
+	l.files = append(l.files, len(entries))
+	l.mu.Unlock()
+
+	if l.loads.Add(1) == 1 {
+		close(l.started)
+	}
+	<-l.release
+	return nil, ErrTooManyPolicies
+}
+
+func TestConcurrentReloadsShareOneQueuedPass(t *testing.T) {
+	loader := &blockingLoader{started: make(chan struct{}), release: make(chan struct{})}
+	engine := &Engine{
+		dir:        t.TempDir(),
+		loader:     loader,
+		evaluators: map[string]moduleEvaluator{},
+	}
+
+	const callers = 20
+	errs := make(chan error, callers)
+
+	go func() { errs <- engine.Reload() }()
+	<-loader.started
+
+	var wg sync.WaitGroup
+	for i := 1; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			wg.Done()
+			errs <- engine.Reload()
+		}()
+	}
+	wg.Wait()
+
+	// Give the later callers time to queue behind the running pass
+	time.Sleep(50 * time.Millisecond)
+	close(loader.release)
+
+	for i := 0; i < callers; i++ {
+		if err := <-errs; !errors.Is(err, ErrTooManyPolicies) {
+			t.Errorf("expected every caller to get a pass result, got %v", err)
+		}
+	}
+
+	// The running pass, then one pass shared by everyone who asked during it
+	if got := loader.loads.Load(); got != 2 {
+		t.Errorf("expected loader to run twice, ran %d times", got)
+	}
+
+	// A reload after the passes finished runs again
+	if err := engine.Reload(); !errors.Is(err, ErrTooManyPolicies) {
+		t.Errorf("expected loader error, got %v", err)
+	}
+	if got := loader.loads.Load(); got != 3 {
+		t.Errorf("expected a fresh pass after completion, got %d loads", got)
+	}
+}
+
+func TestReloadDuringPassSeesChanges(t *testing.T) {
+	loader := &blockingLoader{started: make(chan struct{}), release: make(chan struct{})}
+	dir := t.TempDir()
+	engine := &Engine{
+		dir:        dir,
+		loader:     loader,
+		evaluators: map[string]moduleEvaluator{},
+	}
+
+	first := make(chan error, 1)
+	go func() { first <- engine.Reload() }()
+	<-loader.started
+
+	// A policy written after the running pass read the directory, as the
+	// watcher would report it
+	if err := os.WriteFile(filepath.Join(dir, "late.wasm"), []byte("x"), 0o644); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+	second := make(chan error, 1)
+	go func() { second <- engine.Reload() }()
+
+	time.Sleep(50 * time.Millisecond)
+	close(loader.release)
+	<-first
+	<-second
+
+	loader.mu.Lock()
+	defer loader.mu.Unlock()
+	if len(loader.files) != 2 || loader.files[1] != 1 {
+		t.Errorf("expected a second pass that sees the new policy, got file counts %v", loader.files)
+	}
+}
+
+func TestLoaderEmptyDirectoryError(t *testing.T) {
+	_, err := NewWASMLoader().LoadFromDir(t.TempDir())
+	if !errors.Is(err, ErrNoPolicies) {
+		t.Errorf("expected ErrNoPolicies, got: %v", err)
+	}
+}
+
+func TestEngineToolPolicyMapping(t *testing.T) {
+	emailPolicy := &mockEvaluator{response: Response{Allow: true, Reason: "ok"}}
+	dbPolicy := &mockEvaluator{response: Response{Allow: false, Reason: "db blocked"}}
+
+	engine := &Engine{
+		evaluators: map[string]moduleEvaluator{
+			"email_policy": emailPolicy,
+			"db_policy":    dbPolicy,
+		},
+		toolPolicies: ToolPolicyMap{
+			Tools: map[string][]string{
+				"send_email": {"Email_Policy"},
+			},
+		},
+	}
+
+	resp, err := engine.Evaluate(context.Background(), Request{ToolName: "send_email"})
+	if err != nil {
+		t.Fatalf("evaluation failed: %v", err)
+	}
+
+	if !resp.Allow {
+		t.Errorf("expected allow, got deny: %s", resp.Reason)
+	}
+	if emailPolicy.calls != 1 {
+		t.Errorf("expected mapped policy to run once, ran %d times", emailPolicy.calls)
+	}
+	if dbPolicy.calls != 0 {
+		t.Errorf("expected unrelated policy not to run, ran %d times", dbPolicy.calls)
+	}
+
+	// Unmapped tools without a default run every policy
+	resp, _ = engine.Evaluate(context.Background(), Request{ToolName: "query_db"})
+	if resp.Allow {
+		t.Error("expected deny from db policy for unmapped tool")
+	}
+	if dbPolicy.calls != 1 {
+		t.Errorf("expected db policy to run for unmapped tool, ran %d times", dbPolicy.calls)
+	}
+}
+
+func TestEngineToolPolicyDefault(t *testing.T) {
+	defaultPolicy := &mockEvaluator{response: Response{Allow: true, Reason: "ok"}}
+	otherPolicy := &mockEvaluator{response: Response{Allow: true, Reason: "ok"}}
+
+	engine := &Engine{
+		evaluators: map[string]moduleEvaluator{
+			"default_policy": defaultPolicy,
+			"other_policy":   otherPolicy,
+		},
+		toolPolicies: ToolPolicyMap{
+			Default: []string{"default_policy"},
+		},
+	}
+
+	if _, err := engine.Evaluate(context.Background(), Request{ToolName: "anything"}); err != nil {
+		t.Fatalf("evaluation failed: %v", err)
+	}
+
+	if defaultPolicy.calls != 1 || otherPolicy.calls != 0 {
+		t.Errorf("expected only default policy to run, got default=%d other=%d", defaultPolicy.calls, otherPolicy.calls)
+	}
+}
+
+func TestEngineToolPolicyMissingPolicyDenies(t *testing.T) {
+	engine := &Engine{
+		evaluators: map[string]moduleEvaluator{
+			"loaded": &mockEvaluator{response: Response{Allow: true}},
+		},
+		toolPolicies: ToolPolicyMap{
+			Tools: map[string][]string{"send_email": {"not_loaded"}},
+		},
+	}
+
+	resp, _ := engine.Evaluate(context.Background(), Request{ToolName: "send_email"})
+	if resp.Allow {
+		t.Error("expected deny when mapped policy is not loaded")
+	}
+}
+
+func TestReloadOverMaxPoliciesKeepsPolicies(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.wasm", "b.wasm"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("not wasm"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	loader := NewWASMLoader()
+	loader.maxPolicies = 1
+	engine := &Engine{
+		dir:        dir,
+		loader:     loader,
+		evaluators: map[string]moduleEvaluator{"existing": &mockEvaluator{}},
+	}
+
+	if err := engine.Reload(); !errors.Is(err, ErrTooManyPolicies) {
+		t.Fatalf("expected ErrTooManyPolicies, got %v", err)
+	}
+
+	if _, ok := engine.evaluators["existing"]; !ok {
+		t.Error("expected previous policies to be kept")
+	}
+}
+
+func TestEvaluateTrace(t *testing.T) {
+	risk := 0.4
+	engine := &Engine{
+		evaluators: map[string]moduleEvaluator{
+			"pii":    &mockEvaluator{response: Response{Allow: true, HumanRequired: true, Reason: "contains email", Risk: &risk}},
+			"limits": &mockEvaluator{response: Response{Allow: true, Reason: "under limit"}},
+			"broken": &mockEvaluator{err: errors.New("trap")},
+		},
+	}
+
+	req := Request{
+		ToolName: "send_email",
+		Args:     json.RawMessage(`{"to":"a@example.com"}`),
+		Metadata: map[string]any{"upstream": "http://mail"},
+		Headers:  map[string]string{"X-Environment": "production"},
+	}
+
+	trace, err := engine.EvaluateTrace(context.Background(), req)
+	if err != nil {
+		t.Fatalf("trace failed: %v", err)
+	}
+
+	if len(trace.Policies) != 3 {
+		t.Fatalf("expected all 3 policies traced, got %d", len(trace.Policies))
+	}
+	if trace.Policies[0].Name != "broken" || trace.Policies[0].Error != "trap" {
+		t.Errorf("expected sorted verdicts with error recorded, got %+v", trace.Policies[0])
+	}
+
+	if trace.Decision.Allow || trace.Decision.Reason != "policy error: broken" {
+		t.Errorf("expected error to deny, got %+v", trace.Decision)
+	}
+
+	input, ok := trace.Input.(inputV2)
+	if !ok {
+		t.Fatalf("expected current input schema, got %T", trace.Input)
+	}
+	if input.Headers["X-Environment"] != "production" || input.Metadata["upstream"] != "http://mail" {
+		t.Errorf("expected enriched input, got %+v", input)
+	}
+}
+
+func TestCombineEscalates(t *testing.T) {
+	engine := &Engine{}
+
+	resp := engine.combine("tool", []policyResult{
+		{name: "a", resp: Response{Allow: true}},
+		{name: "b", resp: Response{Allow: true, HumanRequired: true, Reason: "review"}},
+	})
+	if !resp.HumanRequired || resp.Reason != "review" {
+		t.Errorf("expected escalation, got %+v", resp)
+	}
+
+	resp = engine.combine("tool", []policyResult{
+		{name: "a", resp: Response{Allow: true, HumanRequired: true, Reason: "review"}},
+		{name: "b", resp: Response{Allow: false, Reason: "blocked"}},
+	})
+	if resp.Allow || resp.Reason != "blocked" {
+		t.Errorf("expected deny to win over escalation, got %+v", resp)
+	}
+}
+
+func TestCombineOverride(t *testing.T) {
+	engine := &Engine{}
+
+	resp := engine.combine("tool", []policyResult{
+		{name: "a", resp: Response{Allow: true, HumanRequired: true, Reason: "review"}},
+		{name: "b", resp: Response{Allow: false, OverrideAllowed: true, Reason: "soft block"}},
+	})
+	if resp.Allow || !resp.OverrideAllowed || resp.Reason != "soft block" {
+		t.Errorf("expected overridable deny, got %+v", resp)
+	}
+
+	resp = engine.combine("tool", []policyResult{
+		{name: "a", resp: Response{Allow: false, OverrideAllowed: true, Reason: "soft block"}},
+		{name: "b", resp: Response{Allow: false, Reason: "hard block"}},
+	})
+	if resp.Allow || resp.OverrideAllowed || resp.Reason != "hard block" {
+		t.Errorf("expected hard deny to win over overridable deny, got %+v", resp)
+	}
+}
+
+func TestEvaluateTraceMatchesEvaluate(t *testing.T) {
+	engine := &Engine{
+		evaluators: map[string]moduleEvaluator{
+			"base":    &mockEvaluator{response: Response{Allow: true, HumanRequired: true, JustificationRequired: true, Reason: "review"}},
+			"new_pii": &mockEvaluator{response: Response{Allow: false, Reason: "contains SSN"}},
+		},
+		shadow: map[string]bool{"new_pii": true},
+	}
+	req := Request{ToolName: "tool", Args: json.RawMessage(`{}`)}
+
+	want, err := engine.Evaluate(context.Background(), req)
+	if err != nil {
+		t.Fatalf("evaluate failed: %v", err)
+	}
+	trace, err := engine.EvaluateTrace(context.Background(), req)
+	if err != nil {
+		t.Fatalf("trace failed: %v", err)
+	}
+
+	got := trace.Decision
+	if !got.Allow || !got.HumanRequired || !got.JustificationRequired || got.Shadow == nil || got.Shadow.Reason != "contains SSN" {
+		t.Errorf("expected the shadowed deny recorded without deciding, got %+v", got)
+	}
+	if got.Allow != want.Allow || got.HumanRequired != want.HumanRequired || got.JustificationRequired != want.JustificationRequired || got.Policy != want.Policy {
+		t.Errorf("trace decided %+v, Evaluate decided %+v", got, want)
+	}
+}
+
+func TestEngineOverridableDenyBeatsEscalationInEitherOrder(t *testing.T) {
+	escalate := Response{Allow: true, HumanRequired: true, Reason: "review"}
+	soft := Response{Allow: false, OverrideAllowed: true, JustificationRequired: true, Reason: "soft block"}
+
+	for _, order := range []map[string]moduleEvaluator{
+		{"a_escalate": &mockEvaluator{response: escalate}, "b_soft": &mockEvaluator{response: soft}},
+		{"a_soft": &mockEvaluator{response: soft}, "b_escalate": &mockEvaluator{response: escalate}},
+	} {
+		engine := &Engine{evaluators: order}
+
+		resp, err := engine.Evaluate(context.Background(), Request{ToolName: "tool"})
+		if err != nil {
+			t.Fatalf("evaluate failed: %v", err)
+		}
+		if resp.Allow || !resp.OverrideAllowed || resp.Reason != "soft block" || !resp.JustificationRequired {
+			t.Errorf("expected the overridable deny with justification, got %+v", resp)
+		}
+		for name, eval := range order {
+			if eval.(*mockEvaluator).calls != 1 {
+				t.Errorf("expected %s to be evaluated once, got %d", name, eval.(*mockEvaluator).calls)
+			}
+		}
+	}
+}
+
+func TestWarmupEvaluatesEachPolicyOnce(t *testing.T) {
+	first := &mockEvaluator{response: Response{Allow: true}}
+	second := &mockEvaluator{response: Response{Allow: true}}
+	failing := &mockEvaluator{err: errors.New("trap")}
+
+	engine := &Engine{
+		evaluators: map[string]moduleEvaluator{
+			"first":   first,
+			"second":  second,
+			"failing": failing,
+		},
+	}
+
+	engine.Warmup(context.Background())
+
+	for name, eval := range map[string]*mockEvaluator{"first": first, "second": second, "failing": failing} {
+		if eval.calls != 1 {
+			t.Errorf("expected %s to be warmed once, got %d calls", name, eval.calls)
+		}
+	}
+}
+
+func TestEngineShadowPolicyNeverBlocks(t *testing.T) {
+	engine := &Engine{
+		evaluators: map[string]moduleEvaluator{
+			"base":    &mockEvaluator{response: Response{Allow: true, Reason: "ok"}},
+			"new_pii": &mockEvaluator{response: Response{Allow: false, Reason: "contains SSN"}},
+		},
+		shadow: shadowSet([]string{"New_PII"}),
+	}
+
+	resp, err := engine.Evaluate(context.Background(), Request{ToolName: "send_email"})
+	if err != nil {
+		t.Fatalf("evaluation failed: %v", err)
+	}
+
+	if !resp.Allow {
+		t.Errorf("expected shadowed deny not to block, got %s", resp.Reason)
+	}
+	if resp.Shadow == nil || resp.Shadow.Allow || resp.Shadow.Policy != "new_pii" {
+		t.Fatalf("expected would-be deny from new_pii, got %+v", resp.Shadow)
+	}
+
+	// Shadowing everything also ignores evaluation errors
+	engine.shadow = shadowSet([]string{ShadowAll})
+	engine.evaluators["broken"] = &mockEvaluator{err: errors.New("trap")}
+	resp, _ = engine.Evaluate(context.Background(), Request{ToolName: "send_email"})
+	if !resp.Allow || resp.Shadow == nil {
+		t.Errorf("expected allow with a shadow verdict, got %+v", resp)
+	}
+}
+
+type panickingEvaluator struct{}
+
+func (panickingEvaluator) Evaluate(ctx context.Context, req Request) (Response, error) {
+	panic("host function blew up")
+}
+
+func (panickingEvaluator) Close() error { return nil }
+
+func TestEnginePanickingPolicyDenies(t *testing.T) {
+	engine := &Engine{
+		evaluators: map[string]moduleEvaluator{
+			"broken": panickingEvaluator{},
+			"fine":   &mockEvaluator{response: Response{Allow: true}},
+		},
+	}
+
+	resp, err := engine.Evaluate(context.Background(), Request{ToolName: "anything"})
+	if err != nil {
+		t.Fatalf("expected the panic to be handled, got error %v", err)
+	}
+	if resp.Allow {
+		t.Error("expected a panicking policy to deny")
+	}
+	if resp.Policy != "broken" || resp.Reason != "policy error: broken" {
+		t.Errorf("expected the deny attributed to the broken policy, got %+v", resp)
+	}
+
+	if _, err := evaluateModule(context.Background(), "broken", panickingEvaluator{}, Request{}); !errors.Is(err, ErrPolicyPanic) {
+		t.Errorf("expected ErrPolicyPanic, got %v", err)
+	}
+}
+
+func TestEngineToolPolicyGlob(t *testing.T) {
+	dbPolicy := &mockEvaluator{response: Response{Allow: true, Reason: "ok"}}
+	otherPolicy := &mockEvaluator{response: Response{Allow: true, Reason: "ok"}}
+
+	engine := &Engine{
+		evaluators: map[string]moduleEvaluator{
+			"db_policy":    dbPolicy,
+			"other_policy": otherPolicy,
+		},
+		toolPolicies: ToolPolicyMap{
+			Tools: map[string][]string{"db.*": {"db_policy"}},
+		},
+	}
+
+	ctx := context.Background()
+	for _, tool := range []string{"db.query", "db.delete"} {
+		if _, err := engine.Evaluate(ctx, Request{ToolName: tool}); err != nil {
+			t.Fatalf("evaluation failed: %v", err)
+		}
+	}
+	if dbPolicy.calls != 2 || otherPolicy.calls != 0 {
+		t.Errorf("expected only db_policy for db.* tools, got db=%d other=%d", dbPolicy.calls, otherPolicy.calls)
+	}
+
+	// A non-matching tool is unmapped and runs every policy
+	if _, err := engine.Evaluate(ctx, Request{ToolName: "dbx.query"}); err != nil {
+		t.Fatalf("evaluation failed: %v", err)
+	}
+	if otherPolicy.calls != 1 {
+		t.Errorf("expected dbx.query to run every policy, got other=%d", otherPolicy.calls)
+	}
+}
+
+func TestEngineSkipsDisabledPolicy(t *testing.T) {
+	blocking := &mockEvaluator{response: Response{Allow: false, Reason: "blocked"}}
+	passing := &mockEvaluator{response: Response{Allow: true, Reason: "ok"}}
+
+	engine := &Engine{
+		evaluators: map[string]moduleEvaluator{
+			"blocking": blocking,
+			"passing":  passing,
+		},
+	}
+
+	ctx := context.Background()
+	req := Request{ToolName: "test_tool"}
+
+	if err := engine.SetPolicyEnabled("blocking", false); err != nil {
+		t.Fatalf("disable failed: %v", err)
+	}
+
+	resp, err := engine.Evaluate(ctx, req)
+	if err != nil {
+		t.Fatalf("evaluation failed: %v", err)
+	}
+	if !resp.Allow {
+		t.Errorf("expected allow with blocking policy disabled, got %q", resp.Reason)
+	}
+	if blocking.calls != 0 {
+		t.Errorf("expected disabled policy to be skipped, got %d calls", blocking.calls)
+	}
+
+	infos := engine.Policies()
+	if len(infos) != 2 || !infos[0].Disabled || infos[1].Disabled {
+		t.Errorf("expected only blocking listed as disabled, got %+v", infos)
+	}
+
+	if err := engine.SetPolicyEnabled("blocking", true); err != nil {
+		t.Fatalf("enable failed: %v", err)
+	}
+	resp, err = engine.Evaluate(ctx, req)
+	if err != nil {
+		t.Fatalf("evaluation failed: %v", err)
+	}
+	if resp.Allow {
+		t.Error("expected deny once the policy is enabled again")
+	}
+
+	if err := engine.SetPolicyEnabled("missing", false); !errors.Is(err, ErrPolicyNotLoaded) {
+		t.Errorf("expected ErrPolicyNotLoaded, got %v", err)
+	}
+}
+
+func TestEngineDeniesWhenEveryPolicyDisabled(t *testing.T) {
+	engine := &Engine{
+		evaluators: map[string]moduleEvaluator{
+			"passing": &mockEvaluator{response: Response{Allow: true}},
+		},
+	}
+
+	if err := engine.SetPolicyEnabled("passing", false); err != nil {
+		t.Fatalf("disable failed: %v", err)
+	}
+
+	resp, err := engine.Evaluate(context.Background(), Request{ToolName: "test_tool"})
+	if err != nil {
+		t.Fatalf("evaluation failed: %v", err)
+	}
+	if resp.Allow {
+		t.Error("expected deny with no enabled policies")
+	}
+}
+
+func TestEngineCollectsWarningsOnAllow(t *testing.T) {
+	engine := &Engine{
+		evaluators: map[string]moduleEvaluator{
+			"deprecation": &mockEvaluator{response: Response{Allow: true, Reason: "ok", Warnings: []string{"tool is deprecated"}}},
+			"cost":        &mockEvaluator{response: Response{Allow: true, Reason: "ok", Warnings: []string{"expensive call", "tool is deprecated"}}},
+			"plain":       &mockEvaluator{response: Response{Allow: true, Reason: "ok"}},
+		},
+	}
+
+	resp, err := engine.Evaluate(context.Background(), Request{ToolName: "legacy_tool"})
+	if err != nil {
+		t.Fatalf("evaluation failed: %v", err)
+	}
+	if !resp.Allow {
+		t.Fatalf("expected warnings not to block, got deny: %s", resp.Reason)
+	}
+
+	want := []string{"expensive call", "tool is deprecated"}
+	if len(resp.Warnings) != len(want) || resp.Warnings[0] != want[0] || resp.Warnings[1] != want[1] {
+		t.Errorf("expected warnings %v, got %v", want, resp.Warnings)
+	}
+}
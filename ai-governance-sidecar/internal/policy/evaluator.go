@@ -3,17 +3,83 @@ package policy
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"os"
+	"strings"
+	"sync"
+	"time"
 
 	wasmtime "github.com/bytecodealliance/wasmtime-go/v3"
+	"github.com/dagbolade/ai-governance-sidecar/internal/audit"
+	"github.com/rs/zerolog/log"
 )
 
+// ErrMissingExport means a module does not implement the policy ABI
+var ErrMissingExport = errors.New("policy module is missing a required export")
+
+// requiredExport is one item of the policy ABI
+type requiredExport struct {
+	name    string
+	params  int // i32 parameters; -1 for memory
+	results int
+	abi     string
+}
+
+var requiredExports = []requiredExport{
+	{name: "memory", params: -1, abi: "(memory (export \"memory\") 1), the linear memory the sidecar reads and writes"},
+	{name: "allocate", params: 1, results: 1, abi: "allocate(size: i32) -> i32, returning a pointer to size free bytes"},
+	{name: "evaluate", params: 4, results: 1, abi: "evaluate(input_ptr: i32, input_len: i32, output_ptr: i32, output_max: i32) -> i32, writing NUL-terminated response JSON and returning 0 on success"},
+}
+
 type WASMEvaluator struct {
-	store    *wasmtime.Store
-	instance *wasmtime.Instance
-	memory   *wasmtime.Memory
-	evaluate *wasmtime.Func
+	store        *wasmtime.Store
+	instance     *wasmtime.Instance
+	memory       *wasmtime.Memory
+	allocate     *wasmtime.Func
+	evaluate     *wasmtime.Func
+	inputVersion int
+	// digest is the hex sha256 of the module file
+	digest string
+	// counters back the incr_counter and get_counter host functions
+	counters Counters
+	// history backs the audit_history host function
+	history *historyCache
+
+	// mu serializes calls into the instance; call is the request being
+	// evaluated, which audit_history answers for, and readOnly is set while
+	// it is a non-enforcing evaluation that must not change counters
+	mu       sync.Mutex
+	call     historyScope
+	readOnly bool
+}
+
+// evaluationFuel is the instruction budget for one call into a policy when
+// the engine meters fuel, so a runaway policy traps instead of hanging
+const evaluationFuel = 100_000_000
+
+// refuel tops the store back up to evaluationFuel. Engines that don't
+// meter fuel reject the query and need nothing.
+func (e *WASMEvaluator) refuel() {
+	remaining, err := e.store.ConsumeFuel(0)
+	if err != nil || remaining >= evaluationFuel {
+		return
+	}
+	if err := e.store.AddFuel(evaluationFuel - remaining); err != nil {
+		log.Warn().Err(err).Msg("failed to add policy fuel")
+	}
+}
+
+// historyScope is the caller and tool of the call being evaluated
+type historyScope struct {
+	actor string
+	tool  string
+}
+
+// Digest returns the hex sha256 of the module file
+func (e *WASMEvaluator) Digest() string {
+	return e.digest
 }
 
 func NewWASMEvaluator(engine *wasmtime.Engine, module *wasmtime.Module) (*WASMEvaluator, error) {
@@ -21,6 +87,7 @@ func NewWASMEvaluator(engine *wasmtime.Engine, module *wasmtime.Module) (*WASMEv
 	linker := wasmtime.NewLinker(engine)
 
 	eval := &WASMEvaluator{store: store}
+	eval.refuel()
 
 	if err := eval.defineHostFunctions(linker); err != nil {
 		return nil, fmt.Errorf("define host functions: %w", err)
@@ -40,11 +107,19 @@ func NewWASMEvaluator(engine *wasmtime.Engine, module *wasmtime.Module) (*WASMEv
 }
 
 func (e *WASMEvaluator) Evaluate(ctx context.Context, req Request) (Response, error) {
-	inputJSON, err := json.Marshal(req)
+	inputJSON, err := json.Marshal(req.Input(e.inputVersion))
 	if err != nil {
 		return Response{}, fmt.Errorf("marshal request: %w", err)
 	}
 
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	actor, _ := audit.ActorFromContext(ctx)
+	e.call = historyScope{actor: actor.Email, tool: req.ToolName}
+	e.readOnly = readOnlyCounters(ctx)
+	defer func() { e.call, e.readOnly = historyScope{}, false }()
+
 	outputJSON, err := e.callEvaluate(inputJSON)
 	if err != nil {
 		return Response{}, err
@@ -63,6 +138,8 @@ func (e *WASMEvaluator) Close() error {
 }
 
 func (e *WASMEvaluator) callEvaluate(input []byte) ([]byte, error) {
+	e.refuel()
+
 	inputPtr, err := e.allocateMemory(len(input))
 	if err != nil {
 		return nil, fmt.Errorf("allocate input: %w", err)
@@ -120,32 +197,134 @@ func (e *WASMEvaluator) defineHostFunctions(linker *wasmtime.Linker) error {
 		return err
 	}
 
+	// Define incr_counter and get_counter: (key_ptr: i32, key_len: i32, window_secs: i32) -> i32
+	counterType := wasmtime.NewFuncType(
+		[]*wasmtime.ValType{
+			wasmtime.NewValType(wasmtime.KindI32),
+			wasmtime.NewValType(wasmtime.KindI32),
+			wasmtime.NewValType(wasmtime.KindI32),
+		},
+		[]*wasmtime.ValType{
+			wasmtime.NewValType(wasmtime.KindI32),
+		},
+	)
+
+	if err := linker.FuncNew("env", "incr_counter", counterType, e.hostIncrCounter); err != nil {
+		return err
+	}
+	if err := linker.FuncNew("env", "get_counter", counterType, e.hostGetCounter); err != nil {
+		return err
+	}
+
+	// Define audit_history: (tool_ptr: i32, tool_len: i32, window_secs: i32, out_ptr: i32, out_max_len: i32) -> i32
+	auditHistoryType := wasmtime.NewFuncType(
+		[]*wasmtime.ValType{
+			wasmtime.NewValType(wasmtime.KindI32),
+			wasmtime.NewValType(wasmtime.KindI32),
+			wasmtime.NewValType(wasmtime.KindI32),
+			wasmtime.NewValType(wasmtime.KindI32),
+			wasmtime.NewValType(wasmtime.KindI32),
+		},
+		[]*wasmtime.ValType{
+			wasmtime.NewValType(wasmtime.KindI32),
+		},
+	)
+
+	if err := linker.FuncNew("env", "audit_history", auditHistoryType, e.hostAuditHistory); err != nil {
+		return err
+	}
+
 	return nil
 }
 
+// bindExports checks the module against the policy ABI so a mismatch is
+// reported when the policy loads, naming what is missing.
 func (e *WASMEvaluator) bindExports() error {
-	memExport := e.instance.GetExport(e.store, "memory")
-	if memExport == nil {
-		return fmt.Errorf("memory export not found")
+	for _, required := range requiredExports {
+		export := e.instance.GetExport(e.store, required.name)
+		if export == nil {
+			return fmt.Errorf("%w: %q not found; policies must export %s", ErrMissingExport, required.name, required.abi)
+		}
+
+		if required.params < 0 {
+			if e.memory = export.Memory(); e.memory == nil {
+				return fmt.Errorf("%w: %q is not a memory; policies must export %s", ErrMissingExport, required.name, required.abi)
+			}
+			continue
+		}
+
+		fn := export.Func()
+		if fn == nil {
+			return fmt.Errorf("%w: %q is not a function; policies must export %s", ErrMissingExport, required.name, required.abi)
+		}
+		if !matchesI32Signature(fn.Type(e.store), required.params, required.results) {
+			return fmt.Errorf("%w: %q has signature %s; policies must export %s",
+				ErrMissingExport, required.name, describeFuncType(fn.Type(e.store)), required.abi)
+		}
+
+		switch required.name {
+		case "allocate":
+			e.allocate = fn
+		case "evaluate":
+			e.evaluate = fn
+		}
 	}
-	e.memory = memExport.Memory()
 
-	evalExport := e.instance.GetExport(e.store, "evaluate")
-	if evalExport == nil {
-		return fmt.Errorf("evaluate export not found")
+	return e.bindInputVersion()
+}
+
+func matchesI32Signature(ty *wasmtime.FuncType, params, results int) bool {
+	if len(ty.Params()) != params || len(ty.Results()) != results {
+		return false
+	}
+	for _, vt := range append(ty.Params(), ty.Results()...) {
+		if vt.Kind() != wasmtime.KindI32 {
+			return false
+		}
 	}
-	e.evaluate = evalExport.Func()
+	return true
+}
 
-	return nil
+func describeFuncType(ty *wasmtime.FuncType) string {
+	kinds := func(types []*wasmtime.ValType) string {
+		names := make([]string, len(types))
+		for i, vt := range types {
+			names[i] = vt.Kind().String()
+		}
+		return strings.Join(names, ", ")
+	}
+	return fmt.Sprintf("(%s) -> (%s)", kinds(ty.Params()), kinds(ty.Results()))
 }
 
-func (e *WASMEvaluator) allocateMemory(size int) (int32, error) {
-	allocExport := e.instance.GetExport(e.store, "allocate")
-	if allocExport == nil {
-		return 0, fmt.Errorf("allocate export not found")
+// bindInputVersion reads the optional input_version export. Policies that
+// don't declare one receive the current input schema.
+func (e *WASMEvaluator) bindInputVersion() error {
+	e.inputVersion = CurrentInputVersion
+
+	versionExport := e.instance.GetExport(e.store, "input_version")
+	if versionExport == nil || versionExport.Func() == nil {
+		return nil
+	}
+
+	result, err := versionExport.Func().Call(e.store)
+	if err != nil {
+		return fmt.Errorf("call input_version: %w", err)
+	}
+
+	version, ok := result.(int32)
+	if !ok {
+		return fmt.Errorf("input_version must return i32")
+	}
+	if version < InputVersion1 || version > CurrentInputVersion {
+		return fmt.Errorf("unsupported input_version %d", version)
 	}
 
-	result, err := allocExport.Func().Call(e.store, size)
+	e.inputVersion = int(version)
+	return nil
+}
+
+func (e *WASMEvaluator) allocateMemory(size int) (int32, error) {
+	result, err := e.allocate.Call(e.store, size)
 	if err != nil {
 		return 0, err
 	}
@@ -206,3 +385,89 @@ func (e *WASMEvaluator) hostGetEnv(caller *wasmtime.Caller, args []wasmtime.Val)
 	copy(mem[outPtr:], valueBytes)
 	return []wasmtime.Val{wasmtime.ValI32(int32(len(valueBytes)))}, nil
 }
+
+// hostIncrCounter counts a call against key in the current window of
+// window_secs seconds and returns the new count, or -1 on error. In a
+// read-only evaluation it returns the count the call would reach and
+// leaves the counter alone.
+func (e *WASMEvaluator) hostIncrCounter(caller *wasmtime.Caller, args []wasmtime.Val) ([]wasmtime.Val, *wasmtime.Trap) {
+	return e.counterCall(caller, args, func(key string, window time.Duration) (int64, error) {
+		if e.readOnly {
+			count, err := e.counters.Get(key, window)
+			return count + 1, err
+		}
+		return e.counters.Increment(key, window)
+	})
+}
+
+// hostGetCounter returns the count for key in the current window without
+// changing it, or -1 on error.
+func (e *WASMEvaluator) hostGetCounter(caller *wasmtime.Caller, args []wasmtime.Val) ([]wasmtime.Val, *wasmtime.Trap) {
+	return e.counterCall(caller, args, func(key string, window time.Duration) (int64, error) {
+		return e.counters.Get(key, window)
+	})
+}
+
+func (e *WASMEvaluator) counterCall(caller *wasmtime.Caller, args []wasmtime.Val, op func(string, time.Duration) (int64, error)) ([]wasmtime.Val, *wasmtime.Trap) {
+	keyPtr := args[0].I32()
+	keyLen := args[1].I32()
+	windowSecs := args[2].I32()
+
+	mem := caller.GetExport("memory").Memory().UnsafeData(caller)
+	if e.counters == nil || windowSecs <= 0 || keyLen <= 0 || !inBounds(mem, keyPtr, keyLen) {
+		return []wasmtime.Val{wasmtime.ValI32(-1)}, nil
+	}
+	key := string(mem[keyPtr : keyPtr+keyLen])
+
+	count, err := op(key, time.Duration(windowSecs)*time.Second)
+	if err != nil {
+		log.Warn().Err(err).Str("key", key).Msg("policy counter failed")
+		return []wasmtime.Val{wasmtime.ValI32(-1)}, nil
+	}
+	if count > math.MaxInt32 {
+		count = math.MaxInt32
+	}
+	return []wasmtime.Val{wasmtime.ValI32(int32(count))}, nil
+}
+
+// hostAuditHistory writes a JSON summary of the current caller's decisions
+// on a tool over the last window_secs seconds and returns its length, or -1
+// on error. An empty tool means the tool being evaluated.
+func (e *WASMEvaluator) hostAuditHistory(caller *wasmtime.Caller, args []wasmtime.Val) ([]wasmtime.Val, *wasmtime.Trap) {
+	toolPtr := args[0].I32()
+	toolLen := args[1].I32()
+	windowSecs := args[2].I32()
+	outPtr := args[3].I32()
+	outMaxLen := args[4].I32()
+
+	fail := []wasmtime.Val{wasmtime.ValI32(-1)}
+
+	mem := caller.GetExport("memory").Memory().UnsafeData(caller)
+	if e.history == nil || windowSecs <= 0 || !inBounds(mem, toolPtr, toolLen) || !inBounds(mem, outPtr, outMaxLen) {
+		return fail, nil
+	}
+
+	tool := e.call.tool
+	if toolLen > 0 {
+		tool = string(mem[toolPtr : toolPtr+toolLen])
+	}
+
+	summary, err := e.history.summary(e.call.actor, tool, time.Duration(windowSecs)*time.Second)
+	if err != nil {
+		log.Warn().Err(err).Str("tool", tool).Msg("policy audit history failed")
+		return fail, nil
+	}
+
+	out, err := json.Marshal(summary)
+	if err != nil || len(out) > int(outMaxLen) {
+		return fail, nil
+	}
+
+	copy(mem[outPtr:], out)
+	return []wasmtime.Val{wasmtime.ValI32(int32(len(out)))}, nil
+}
+
+// inBounds reports whether length bytes at ptr lie inside mem
+func inBounds(mem []byte, ptr, length int32) bool {
+	return ptr >= 0 && length >= 0 && int64(ptr)+int64(length) <= int64(len(mem))
+}
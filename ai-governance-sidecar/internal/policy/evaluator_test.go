@@ -0,0 +1,120 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	wasmtime "github.com/bytecodealliance/wasmtime-go/v3"
+)
+
+// echoPolicyWAT copies its input to the output buffer so tests can inspect
+// exactly what the evaluator sends to a policy.
+const echoPolicyWAT = `
+(module
+  (memory (export "memory") 1)
+  (global $next (mut i32) (i32.const 1024))
+  (func (export "allocate") (param $size i32) (result i32)
+    (local $ptr i32)
+    (local.set $ptr (global.get $next))
+    (global.set $next (i32.add (global.get $next) (local.get $size)))
+    (local.get $ptr))
+  (func (export "evaluate") (param $in i32) (param $len i32) (param $out i32) (param $max i32) (result i32)
+    (memory.copy (local.get $out) (local.get $in) (local.get $len))
+    (i32.const 0))
+  %s)
+`
+
+func newEchoEvaluator(t *testing.T, extra string) *WASMEvaluator {
+	t.Helper()
+
+	wasm, err := wasmtime.Wat2Wasm(fmt.Sprintf(echoPolicyWAT, extra))
+	if err != nil {
+		t.Fatalf("compile wat: %v", err)
+	}
+
+	engine := wasmtime.NewEngine()
+	module, err := wasmtime.NewModule(engine, wasm)
+	if err != nil {
+		t.Fatalf("create module: %v", err)
+	}
+
+	eval, err := NewWASMEvaluator(engine, module)
+	if err != nil {
+		t.Fatalf("create evaluator: %v", err)
+	}
+	return eval
+}
+
+func echoInput(t *testing.T, eval *WASMEvaluator, req Request) map[string]any {
+	t.Helper()
+
+	input, err := json.Marshal(req.Input(eval.inputVersion))
+	if err != nil {
+		t.Fatalf("marshal input: %v", err)
+	}
+
+	output, err := eval.callEvaluate(input)
+	if err != nil {
+		t.Fatalf("call evaluate: %v", err)
+	}
+
+	var seen map[string]any
+	if err := json.Unmarshal(output, &seen); err != nil {
+		t.Fatalf("unmarshal echoed input %q: %v", output, err)
+	}
+	return seen
+}
+
+func TestPolicyInputVersions(t *testing.T) {
+	req := Request{
+		ToolName: "deploy",
+		Args:     json.RawMessage(`{"env":"prod"}`),
+		Metadata: map[string]any{"upstream": "http://tools"},
+		Headers:  map[string]string{"X-Environment": "production"},
+	}
+
+	t.Run("v1 policy sees original shape", func(t *testing.T) {
+		eval := newEchoEvaluator(t, `(func (export "input_version") (result i32) (i32.const 1))`)
+
+		seen := echoInput(t, eval, req)
+		if len(seen) != 3 {
+			t.Errorf("expected 3 fields, got %v", seen)
+		}
+		for _, key := range []string{"tool_name", "args", "metadata"} {
+			if _, ok := seen[key]; !ok {
+				t.Errorf("expected field %s in v1 input", key)
+			}
+		}
+	})
+
+	t.Run("undeclared policy sees enriched shape", func(t *testing.T) {
+		eval := newEchoEvaluator(t, "")
+
+		seen := echoInput(t, eval, req)
+		if seen["_version"] != float64(CurrentInputVersion) {
+			t.Errorf("expected _version %d, got %v", CurrentInputVersion, seen["_version"])
+		}
+		headers, _ := seen["headers"].(map[string]any)
+		if headers["X-Environment"] != "production" {
+			t.Errorf("expected headers in v2 input, got %v", seen["headers"])
+		}
+	})
+}
+
+func TestUnsupportedInputVersion(t *testing.T) {
+	wasm, err := wasmtime.Wat2Wasm(fmt.Sprintf(echoPolicyWAT, `(func (export "input_version") (result i32) (i32.const 99))`))
+	if err != nil {
+		t.Fatalf("compile wat: %v", err)
+	}
+
+	engine := wasmtime.NewEngine()
+	module, err := wasmtime.NewModule(engine, wasm)
+	if err != nil {
+		t.Fatalf("create module: %v", err)
+	}
+
+	if _, err := NewWASMEvaluator(engine, module); err == nil {
+		t.Error("expected error for unsupported input version")
+	}
+}
@@ -0,0 +1,121 @@
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// fieldPath is a parsed extraction path: object keys, or array indexes
+// where a segment is a number
+type fieldPath []string
+
+// metadataExtractor lifts configured argument fields into request metadata
+// before evaluation, so policies can match them without walking args
+type metadataExtractor struct {
+	keys  []string
+	paths map[string]fieldPath
+}
+
+// newMetadataExtractor parses fields, metadata key to path. Invalid paths
+// are skipped with a warning. It returns nil when nothing is configured.
+func newMetadataExtractor(fields map[string]string) *metadataExtractor {
+	x := &metadataExtractor{paths: make(map[string]fieldPath, len(fields))}
+	for key, raw := range fields {
+		path, err := parseFieldPath(raw)
+		if err != nil {
+			log.Warn().Err(err).Str("key", key).Msg("invalid metadata field path, skipping")
+			continue
+		}
+		x.keys = append(x.keys, key)
+		x.paths[key] = path
+	}
+	if len(x.keys) == 0 {
+		return nil
+	}
+	sort.Strings(x.keys)
+	return x
+}
+
+// parseFieldPath accepts "args.database", "$.database" or "database", with
+// dots between keys and [n] for array elements, e.g. "args.hosts[0].name"
+func parseFieldPath(raw string) (fieldPath, error) {
+	trimmed := strings.TrimSpace(raw)
+	for _, root := range []string{"$.", "args."} {
+		trimmed = strings.TrimPrefix(trimmed, root)
+	}
+	trimmed = strings.ReplaceAll(trimmed, "[", ".[")
+
+	var path fieldPath
+	for _, segment := range strings.Split(trimmed, ".") {
+		if strings.HasPrefix(segment, "[") {
+			index := strings.TrimSuffix(strings.TrimPrefix(segment, "["), "]")
+			if _, err := strconv.Atoi(index); err != nil || !strings.HasSuffix(segment, "]") {
+				return nil, fmt.Errorf("bad array index %q in %q", segment, raw)
+			}
+			segment = index
+		}
+		if segment == "" {
+			return nil, fmt.Errorf("empty segment in %q", raw)
+		}
+		path = append(path, segment)
+	}
+	return path, nil
+}
+
+// apply returns req with each configured field found in its args set in
+// metadata, overriding any value the caller sent under the same key.
+// Fields missing from args are left unset. The caller's metadata map is
+// not modified.
+func (x *metadataExtractor) apply(req Request) Request {
+	if x == nil || len(req.Args) == 0 {
+		return req
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(req.Args))
+	decoder.UseNumber()
+	var args any
+	if err := decoder.Decode(&args); err != nil {
+		return req
+	}
+
+	metadata := make(map[string]any, len(req.Metadata)+len(x.keys))
+	for key, value := range req.Metadata {
+		metadata[key] = value
+	}
+	for _, key := range x.keys {
+		if value, ok := x.paths[key].lookup(args); ok {
+			metadata[key] = value
+		}
+	}
+
+	req.Metadata = metadata
+	return req
+}
+
+func (p fieldPath) lookup(value any) (any, bool) {
+	for _, segment := range p {
+		switch v := value.(type) {
+		case map[string]any:
+			next, ok := v[segment]
+			if !ok {
+				return nil, false
+			}
+			value = next
+		case []any:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(v) {
+				return nil, false
+			}
+			value = v[index]
+		default:
+			return nil, false
+		}
+	}
+	return value, true
+}
@@ -0,0 +1,92 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+// capturingEvaluator records the request it was asked to evaluate
+type capturingEvaluator struct {
+	seen Request
+}
+
+func (c *capturingEvaluator) Evaluate(ctx context.Context, req Request) (Response, error) {
+	c.seen = req
+	return Response{Allow: true}, nil
+}
+
+func (c *capturingEvaluator) Close() error { return nil }
+
+func TestEngineExtractsMetadataFields(t *testing.T) {
+	capture := &capturingEvaluator{}
+	engine := &Engine{
+		evaluators: map[string]moduleEvaluator{"capture": capture},
+		extractor: newMetadataExtractor(map[string]string{
+			"database": "args.connection.database",
+			"region":   "$.hosts[1].region",
+			"missing":  "args.connection.schema",
+			"cost":     "cost_center",
+			"bad":      "args.hosts[x]",
+		}),
+	}
+
+	callerMetadata := map[string]any{"cost": "caller-supplied", "team": "payments"}
+	req := Request{
+		ToolName: "db.query",
+		Args:     json.RawMessage(`{"connection":{"database":"orders"},"hosts":[{"region":"eu"},{"region":"us"}],"cost_center":42}`),
+		Metadata: callerMetadata,
+	}
+
+	if _, err := engine.Evaluate(context.Background(), req); err != nil {
+		t.Fatalf("evaluation failed: %v", err)
+	}
+
+	got := capture.seen.Metadata
+	want := map[string]string{"database": "orders", "region": "us", "cost": "42", "team": "payments"}
+	for key, value := range want {
+		if raw, ok := got[key]; !ok || jsonString(raw) != value {
+			t.Errorf("expected metadata %s=%s, got %v", key, value, got[key])
+		}
+	}
+	for _, key := range []string{"missing", "bad"} {
+		if _, ok := got[key]; ok {
+			t.Errorf("expected no metadata for %s, got %v", key, got[key])
+		}
+	}
+
+	if callerMetadata["cost"] != "caller-supplied" || len(callerMetadata) != 2 {
+		t.Errorf("expected the caller's metadata map to be left alone, got %v", callerMetadata)
+	}
+}
+
+func TestEvaluateTraceExtractsMetadataFields(t *testing.T) {
+	capture := &capturingEvaluator{}
+	engine := &Engine{
+		evaluators: map[string]moduleEvaluator{"capture": capture},
+		extractor:  newMetadataExtractor(map[string]string{"database": "args.connection.database"}),
+	}
+
+	trace, err := engine.EvaluateTrace(context.Background(), Request{
+		ToolName: "db.query",
+		Args:     json.RawMessage(`{"connection":{"database":"orders"}}`),
+	})
+	if err != nil {
+		t.Fatalf("trace failed: %v", err)
+	}
+
+	if capture.seen.Metadata["database"] != "orders" {
+		t.Errorf("expected the traced policy to see extracted metadata, got %v", capture.seen.Metadata)
+	}
+	if input, ok := trace.Input.(inputV2); !ok || input.Metadata["database"] != "orders" {
+		t.Errorf("expected the trace input to show extracted metadata, got %+v", trace.Input)
+	}
+}
+
+func jsonString(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
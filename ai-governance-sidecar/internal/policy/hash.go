@@ -0,0 +1,77 @@
+package policy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrPolicyHashMismatch means the loaded policy set is not the pinned one
+var ErrPolicyHashMismatch = errors.New("policy set hash does not match EXPECTED_POLICY_HASH")
+
+// PolicyInfo describes one loaded policy module
+type PolicyInfo struct {
+	Name     string `json:"name"`
+	Digest   string `json:"sha256,omitempty"`
+	Disabled bool   `json:"disabled,omitempty"`
+}
+
+// digester is implemented by evaluators that know the hash of their module
+type digester interface {
+	Digest() string
+}
+
+func policyInfos[T moduleEvaluator](evaluators map[string]T) []PolicyInfo {
+	infos := make([]PolicyInfo, 0, len(evaluators))
+	for name, eval := range evaluators {
+		info := PolicyInfo{Name: name}
+		if d, ok := any(eval).(digester); ok {
+			info.Digest = d.Digest()
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// policySetHash is a sha256 over "name:digest" lines for every policy,
+// sorted by name, so it changes when any module is added, removed, renamed
+// or edited
+func policySetHash(infos []PolicyInfo) string {
+	h := sha256.New()
+	for _, info := range infos {
+		fmt.Fprintf(h, "%s:%s\n", info.Name, info.Digest)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Policies lists the loaded policies with their module digests
+func (e *Engine) Policies() []PolicyInfo {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	infos := policyInfos(e.evaluators)
+	for i := range infos {
+		infos[i].Disabled = e.disabled[infos[i].Name]
+	}
+	return infos
+}
+
+// PolicyHash identifies the loaded policy set; pin it with
+// EXPECTED_POLICY_HASH
+func (e *Engine) PolicyHash() string {
+	return policySetHash(e.Policies())
+}
+
+// checkHash refuses a policy set that doesn't match the pinned hash
+func checkHash[T moduleEvaluator](expected string, evaluators map[string]T) error {
+	if expected == "" {
+		return nil
+	}
+	if actual := policySetHash(policyInfos(evaluators)); actual != expected {
+		return fmt.Errorf("%w: loaded %s", ErrPolicyHashMismatch, actual)
+	}
+	return nil
+}
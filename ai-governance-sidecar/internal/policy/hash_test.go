@@ -0,0 +1,81 @@
+package policy
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bytecodealliance/wasmtime-go/v3"
+)
+
+func writePolicyFiles(t *testing.T, dir string, names ...string) {
+	t.Helper()
+
+	wasm, err := wasmtime.Wat2Wasm(bundlePolicyWAT)
+	if err != nil {
+		t.Fatalf("compile wat: %v", err)
+	}
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), wasm, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestExpectedPolicyHash(t *testing.T) {
+	dir := t.TempDir()
+	writePolicyFiles(t, dir, "alpha.wasm", "beta.wasm")
+
+	unpinned, err := NewEngine(Config{Dir: dir})
+	if err != nil {
+		t.Fatalf("new engine: %v", err)
+	}
+	hash := unpinned.PolicyHash()
+	policies := unpinned.Policies()
+	unpinned.Close()
+
+	if len(policies) != 2 || policies[0].Name != "alpha" || len(policies[0].Digest) != 64 {
+		t.Fatalf("unexpected policy listing: %+v", policies)
+	}
+
+	t.Run("matching hash starts", func(t *testing.T) {
+		engine, err := NewEngine(Config{Dir: dir, ExpectedHash: hash})
+		if err != nil {
+			t.Fatalf("expected pinned engine to start, got %v", err)
+		}
+		defer engine.Close()
+
+		if engine.PolicyHash() != hash {
+			t.Errorf("expected hash %s, got %s", hash, engine.PolicyHash())
+		}
+	})
+
+	t.Run("mismatched hash refuses to start", func(t *testing.T) {
+		_, err := NewEngine(Config{Dir: dir, ExpectedHash: "0000"})
+		if !errors.Is(err, ErrPolicyHashMismatch) {
+			t.Fatalf("expected ErrPolicyHashMismatch, got %v", err)
+		}
+	})
+
+	t.Run("drifted reload is refused", func(t *testing.T) {
+		engine, err := NewEngine(Config{Dir: dir, ExpectedHash: hash})
+		if err != nil {
+			t.Fatalf("new engine: %v", err)
+		}
+		defer engine.Close()
+
+		reloadDir := t.TempDir()
+		writePolicyFiles(t, reloadDir, "alpha.wasm", "beta.wasm", "unreviewed.wasm")
+		engine.mu.Lock()
+		engine.dir = reloadDir
+		engine.mu.Unlock()
+
+		if err := engine.Reload(); !errors.Is(err, ErrPolicyHashMismatch) {
+			t.Fatalf("expected ErrPolicyHashMismatch, got %v", err)
+		}
+		if engine.PolicyHash() != hash || !engine.Stale() {
+			t.Errorf("expected the pinned set to keep serving, got %+v", engine.Policies())
+		}
+	})
+}
@@ -0,0 +1,92 @@
+package policy
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/audit"
+	"github.com/dagbolade/ai-governance-sidecar/internal/clock"
+)
+
+// historyCacheTTL bounds how stale an audit summary seen by a policy can
+// be. Bursts of calls from one caller share a single query.
+const historyCacheTTL = 5 * time.Second
+
+// historyQueryTimeout bounds a summary query made during evaluation
+const historyQueryTimeout = 2 * time.Second
+
+type historyKey struct {
+	actor  string
+	tool   string
+	window time.Duration
+}
+
+type cachedSummary struct {
+	summary   audit.DecisionSummary
+	fetchedAt time.Time
+}
+
+// historyCache answers audit_history calls from recent results so policies
+// that consult history don't query the audit log on every evaluation
+type historyCache struct {
+	history audit.DecisionHistorian
+	clock   clock.Clock
+
+	mu      sync.Mutex
+	entries map[historyKey]cachedSummary
+}
+
+func newHistoryCache(history audit.DecisionHistorian, clk clock.Clock) *historyCache {
+	return &historyCache{history: history, clock: clk, entries: make(map[historyKey]cachedSummary)}
+}
+
+// summary returns actor's decisions on tool over the last window
+func (c *historyCache) summary(actor, tool string, window time.Duration) (audit.DecisionSummary, error) {
+	key := historyKey{actor: actor, tool: tool, window: window}
+	now := c.clock.Now()
+
+	c.mu.Lock()
+	cached, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && now.Sub(cached.fetchedAt) < historyCacheTTL {
+		return cached.summary, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), historyQueryTimeout)
+	defer cancel()
+
+	summary, err := c.history.DecisionHistory(ctx, actor, tool, now.Add(-window))
+	if err != nil {
+		return audit.DecisionSummary{}, err
+	}
+
+	c.mu.Lock()
+	for k, entry := range c.entries {
+		if now.Sub(entry.fetchedAt) >= historyCacheTTL {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = cachedSummary{summary: summary, fetchedAt: now}
+	c.mu.Unlock()
+
+	return summary, nil
+}
+
+// SetHistory lets policies read a summary of past audit decisions through
+// the audit_history host function
+func (e *Engine) SetHistory(history audit.DecisionHistorian) {
+	cache := newHistoryCache(history, clock.Real{})
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if loader, ok := e.loader.(*WASMLoader); ok {
+		loader.history = cache
+	}
+	for _, eval := range e.evaluators {
+		if wasm, ok := eval.(*WASMEvaluator); ok {
+			wasm.history = cache
+		}
+	}
+}
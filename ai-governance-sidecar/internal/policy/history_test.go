@@ -0,0 +1,118 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	wasmtime "github.com/bytecodealliance/wasmtime-go/v3"
+	"github.com/dagbolade/ai-governance-sidecar/internal/audit"
+	"github.com/dagbolade/ai-governance-sidecar/internal/clock"
+)
+
+// repeatCallPolicyWAT denies a caller who already called the tool in the
+// last day. An empty summary, {"allow":0,"deny":0}, is 20 bytes; any
+// recorded decision makes it longer.
+const repeatCallPolicyWAT = `
+(module
+  (import "env" "audit_history" (func $history (param i32 i32 i32 i32 i32) (result i32)))
+  (memory (export "memory") 1)
+  (data (i32.const 16) "{\"allow\":true,\"reason\":\"first call\"}\00")
+  (data (i32.const 64) "{\"allow\":false,\"reason\":\"already called today\"}\00")
+  (global $next (mut i32) (i32.const 1024))
+  (func (export "allocate") (param $size i32) (result i32)
+    (local $ptr i32)
+    (local.set $ptr (global.get $next))
+    (global.set $next (i32.add (global.get $next) (local.get $size)))
+    (local.get $ptr))
+  (func (export "evaluate") (param $in i32) (param $len i32) (param $out i32) (param $max i32) (result i32)
+    (if (i32.gt_s (call $history (i32.const 0) (i32.const 0) (i32.const 86400) (i32.const 512) (i32.const 256)) (i32.const 20))
+      (then (memory.copy (local.get $out) (i32.const 64) (i32.const 48)))
+      (else (memory.copy (local.get $out) (i32.const 16) (i32.const 40))))
+    (i32.const 0)))
+`
+
+func TestHistoryPolicyDeniesAfterPriorDecision(t *testing.T) {
+	store, err := audit.NewSQLiteStore(filepath.Join(t.TempDir(), "audit.db"))
+	if err != nil {
+		t.Fatalf("open audit store: %v", err)
+	}
+	defer store.Close()
+
+	wasm, err := wasmtime.Wat2Wasm(repeatCallPolicyWAT)
+	if err != nil {
+		t.Fatalf("compile wat: %v", err)
+	}
+	engine := wasmtime.NewEngine()
+	module, err := wasmtime.NewModule(engine, wasm)
+	if err != nil {
+		t.Fatalf("create module: %v", err)
+	}
+	eval, err := NewWASMEvaluator(engine, module)
+	if err != nil {
+		t.Fatalf("create evaluator: %v", err)
+	}
+	eval.history = newHistoryCache(store, clock.Real{})
+
+	bob := audit.WithActor(context.Background(), audit.Actor{Email: "bob@example.com"})
+	eve := audit.WithActor(context.Background(), audit.Actor{Email: "eve@example.com"})
+
+	if err := store.Log(bob, json.RawMessage(`{"tool_name":"drop_table"}`), audit.DecisionAllow, "ok"); err != nil {
+		t.Fatalf("log failed: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		tool   string
+		expect bool
+	}{
+		{name: "caller with a prior call", ctx: bob, tool: "drop_table", expect: false},
+		{name: "other caller", ctx: eve, tool: "drop_table", expect: true},
+		{name: "other tool", ctx: bob, tool: "read_file", expect: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := eval.Evaluate(tt.ctx, Request{ToolName: tt.tool, Args: json.RawMessage(`{}`)})
+			if err != nil {
+				t.Fatalf("evaluate: %v", err)
+			}
+			if resp.Allow != tt.expect {
+				t.Errorf("expected allow=%v, got %+v", tt.expect, resp)
+			}
+		})
+	}
+}
+
+type countingHistorian struct {
+	calls int
+}
+
+func (h *countingHistorian) DecisionHistory(ctx context.Context, actor, tool string, since time.Time) (audit.DecisionSummary, error) {
+	h.calls++
+	return audit.DecisionSummary{Deny: h.calls}, nil
+}
+
+func TestHistoryCacheReusesRecentSummaries(t *testing.T) {
+	historian := &countingHistorian{}
+	clk := clock.NewFake(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	cache := newHistoryCache(historian, clk)
+
+	for i := 0; i < 3; i++ {
+		if _, err := cache.summary("bob", "deploy", time.Hour); err != nil {
+			t.Fatalf("summary: %v", err)
+		}
+	}
+	if historian.calls != 1 {
+		t.Fatalf("expected one query within the TTL, got %d", historian.calls)
+	}
+
+	clk.Advance(historyCacheTTL)
+	summary, _ := cache.summary("bob", "deploy", time.Hour)
+	if historian.calls != 2 || summary.Deny != 2 {
+		t.Errorf("expected a fresh query after the TTL, got %d calls and %+v", historian.calls, summary)
+	}
+}
@@ -0,0 +1,41 @@
+package policy
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// DecisionKey identifies a call made by caller in tenant: its tool,
+// canonical args, metadata (which carries upstream and method) and files.
+// Requests that differ only in key order or whitespace share a key, while
+// the same call from another caller, tenant or upstream does not, so one
+// caller's approval is never reused for or merged with another's.
+func (r Request) DecisionKey(caller, tenant string) string {
+	metadata, _ := json.Marshal(r.Metadata)
+	files, _ := json.Marshal(r.Files)
+
+	hash := sha256.New()
+	for _, part := range [][]byte{[]byte(caller), []byte(tenant), []byte(r.ToolName), canonicalArgs(r.Args), metadata, files} {
+		hash.Write(part)
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// canonicalArgs re-encodes args with object keys sorted and whitespace
+// removed, keeping numbers' text. Invalid JSON is returned as is.
+func canonicalArgs(args json.RawMessage) []byte {
+	dec := json.NewDecoder(bytes.NewReader(args))
+	dec.UseNumber()
+	var value any
+	if dec.Decode(&value) != nil {
+		return args
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return args
+	}
+	return b
+}
@@ -1,18 +1,34 @@
 package policy
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	wasmtime "github.com/bytecodealliance/wasmtime-go/v3"
 	"github.com/rs/zerolog/log"
 )
 
+// ErrNoPolicies is returned when a policy directory is readable but contains no loadable policies.
+var ErrNoPolicies = errors.New("no WASM policies found")
+
+// ErrTooManyPolicies is returned when a policy directory holds more modules than allowed.
+var ErrTooManyPolicies = errors.New("too many policies")
+
 type WASMLoader struct {
-	engine *wasmtime.Engine
-	config *wasmtime.Config
+	engine      *wasmtime.Engine
+	config      *wasmtime.Config
+	maxPolicies int
+	counters    Counters
+	history     *historyCache
+
+	mu         sync.Mutex
+	loadErrors []LoadError
 }
 
 func NewWASMLoader() *WASMLoader {
@@ -33,7 +49,12 @@ func (l *WASMLoader) LoadFromDir(dir string) (map[string]*WASMEvaluator, error)
 		return nil, fmt.Errorf("read directory: %w", err)
 	}
 
+	if err := l.checkPolicyCount(entries); err != nil {
+		return nil, fmt.Errorf("%w in %s", err, dir)
+	}
+
 	evaluators := make(map[string]*WASMEvaluator)
+	var loadErrors []LoadError
 
 	for _, entry := range entries {
 		if entry.IsDir() || !l.isWASMFile(entry.Name()) {
@@ -44,6 +65,10 @@ func (l *WASMLoader) LoadFromDir(dir string) (map[string]*WASMEvaluator, error)
 		eval, err := l.loadFile(path)
 		if err != nil {
 			log.Warn().Err(err).Str("file", entry.Name()).Msg("failed to load policy")
+			var loadErr *LoadError
+			if errors.As(err, &loadErr) {
+				loadErrors = append(loadErrors, *loadErr)
+			}
 			continue
 		}
 
@@ -51,25 +76,68 @@ func (l *WASMLoader) LoadFromDir(dir string) (map[string]*WASMEvaluator, error)
 		evaluators[name] = eval
 	}
 
+	l.mu.Lock()
+	l.loadErrors = loadErrors
+	l.mu.Unlock()
+
 	if len(evaluators) == 0 {
-		return nil, fmt.Errorf("no WASM policies found in %s", dir)
+		return nil, fmt.Errorf("%w in %s", ErrNoPolicies, dir)
 	}
 
 	return evaluators, nil
 }
 
+// checkPolicyCount refuses the whole directory rather than loading an
+// arbitrary subset when the limit is exceeded.
+func (l *WASMLoader) checkPolicyCount(entries []os.DirEntry) error {
+	if l.maxPolicies <= 0 {
+		return nil
+	}
+
+	count := 0
+	for _, entry := range entries {
+		if !entry.IsDir() && l.isWASMFile(entry.Name()) {
+			count++
+		}
+	}
+
+	if count > l.maxPolicies {
+		return fmt.Errorf("%w: found %d, limit is %d", ErrTooManyPolicies, count, l.maxPolicies)
+	}
+	return nil
+}
+
+// LoadErrors returns the files skipped by the last LoadFromDir
+func (l *WASMLoader) LoadErrors() []LoadError {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]LoadError(nil), l.loadErrors...)
+}
+
 func (l *WASMLoader) loadFile(path string) (*WASMEvaluator, error) {
+	file := filepath.Base(path)
+	name := l.extractPolicyName(file)
+
 	wasmBytes, err := os.ReadFile(path)
 	if err != nil {
-		return nil, fmt.Errorf("read file: %w", err)
+		return nil, newLoadError(name, file, LoadStageRead, err)
 	}
 
 	module, err := wasmtime.NewModule(l.engine, wasmBytes)
 	if err != nil {
-		return nil, fmt.Errorf("compile module: %w", err)
+		return nil, newLoadError(name, file, LoadStageCompile, err)
+	}
+
+	eval, err := NewWASMEvaluator(l.engine, module)
+	if err != nil {
+		return nil, newLoadError(name, file, LoadStageInstantiate, err)
 	}
 
-	return NewWASMEvaluator(l.engine, module)
+	sum := sha256.Sum256(wasmBytes)
+	eval.digest = hex.EncodeToString(sum[:])
+	eval.counters = l.counters
+	eval.history = l.history
+	return eval, nil
 }
 
 func (l *WASMLoader) isWASMFile(filename string) bool {
@@ -1,9 +1,13 @@
 package policy
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	wasmtime "github.com/bytecodealliance/wasmtime-go/v3"
 )
 
 func TestLoaderFileDetection(t *testing.T) {
@@ -76,4 +80,146 @@ func TestLoaderInvalidWASM(t *testing.T) {
 	if err == nil {
 		t.Error("expected error when loading invalid WASM")
 	}
-}
\ No newline at end of file
+}
+func TestLoadFromDirExceedsMaxPolicies(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.wasm", "b.wasm", "c.wasm"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("not wasm"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	loader := NewWASMLoader()
+	loader.maxPolicies = 2
+
+	_, err := loader.LoadFromDir(dir)
+	if !errors.Is(err, ErrTooManyPolicies) {
+		t.Fatalf("expected ErrTooManyPolicies, got %v", err)
+	}
+
+	if !strings.Contains(err.Error(), "found 3, limit is 2") {
+		t.Errorf("expected counts in error, got %v", err)
+	}
+}
+
+func TestLoaderReportsMissingExports(t *testing.T) {
+	const (
+		memory   = `(memory (export "memory") 1)`
+		allocate = `(func (export "allocate") (param i32) (result i32) (i32.const 1024))`
+		evaluate = `(func (export "evaluate") (param i32 i32 i32 i32) (result i32) (i32.const 0))`
+	)
+
+	tests := []struct {
+		name    string
+		exports string
+		missing string
+	}{
+		{"no_memory", allocate + evaluate, `"memory" not found`},
+		{"no_allocate", memory + evaluate, `"allocate" not found`},
+		{"no_evaluate", memory + allocate, `"evaluate" not found`},
+		{"old_abi", memory + allocate + `(func (export "evaluate") (param i32 i32) (result i32) (i32.const 0))`, `"evaluate" has signature (i32, i32) -> (i32)`},
+		{"evaluate_global", memory + allocate + `(global (export "evaluate") i32 (i32.const 0))`, `"evaluate" is not a function`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wasm, err := wasmtime.Wat2Wasm("(module " + tt.exports + ")")
+			if err != nil {
+				t.Fatalf("compile wat: %v", err)
+			}
+
+			path := filepath.Join(t.TempDir(), tt.name+".wasm")
+			if err := os.WriteFile(path, wasm, 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = NewWASMLoader().loadFile(path)
+			if !errors.Is(err, ErrMissingExport) {
+				t.Fatalf("expected ErrMissingExport, got %v", err)
+			}
+			for _, want := range []string{tt.name + ".wasm", tt.missing, "policies must export"} {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("expected error to mention %q, got %q", want, err)
+				}
+			}
+		})
+	}
+}
+
+func TestLoaderReportsCompileErrorOffset(t *testing.T) {
+	dir := t.TempDir()
+	writePolicyFiles(t, dir, "good.wasm")
+
+	// Returns an i64 where the signature promises an i32
+	broken, err := wasmtime.Wat2Wasm(`(module (memory (export "memory") 1) (func (result i32) (i64.const 1)))`)
+	if err != nil {
+		t.Fatalf("compile wat: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "broken.wasm"), broken, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	loader := NewWASMLoader()
+	evaluators, err := loader.LoadFromDir(dir)
+	if err != nil {
+		t.Fatalf("expected the good policy to load, got %v", err)
+	}
+	if len(evaluators) != 1 {
+		t.Errorf("expected 1 loaded policy, got %d", len(evaluators))
+	}
+
+	loadErrors := loader.LoadErrors()
+	if len(loadErrors) != 1 {
+		t.Fatalf("expected 1 load error, got %v", loadErrors)
+	}
+
+	got := loadErrors[0]
+	if got.Policy != "broken" || got.File != "broken.wasm" || got.Stage != LoadStageCompile {
+		t.Errorf("unexpected load error %+v", got)
+	}
+	// The validator points into the function body
+	if got.Offset == nil || *got.Offset <= 8 || *got.Offset >= int64(len(broken)) {
+		t.Errorf("expected an offset inside the module body, got %v", got.Offset)
+	}
+	if !strings.Contains(got.Message, "type mismatch") {
+		t.Errorf("expected the validator's cause, got %q", got.Message)
+	}
+}
+
+func TestLoadedPolicyRunsWithinFuelBudget(t *testing.T) {
+	const (
+		module   = `(module (memory (export "memory") 4) (data (i32.const 0) "{\"allow\":true}\00") %s)`
+		allocate = `(func (export "allocate") (param i32) (result i32) (i32.const 1024))`
+		respond  = `(func (export "evaluate") (param i32 i32 i32 i32) (result i32) (memory.copy (local.get 2) (i32.const 0) (i32.const 15)) (i32.const 0))`
+		spin     = `(func (export "evaluate") (param i32 i32 i32 i32) (result i32) (loop $spin (br $spin)) (i32.const 0))`
+	)
+
+	load := func(t *testing.T, evaluate string) *WASMEvaluator {
+		t.Helper()
+		wasm, err := wasmtime.Wat2Wasm(strings.Replace(module, "%s", allocate+evaluate, 1))
+		if err != nil {
+			t.Fatalf("compile wat: %v", err)
+		}
+		path := filepath.Join(t.TempDir(), "policy.wasm")
+		if err := os.WriteFile(path, wasm, 0644); err != nil {
+			t.Fatal(err)
+		}
+		eval, err := NewWASMLoader().loadFile(path)
+		if err != nil {
+			t.Fatalf("load: %v", err)
+		}
+		return eval
+	}
+
+	eval := load(t, respond)
+	for i := 0; i < 3; i++ {
+		resp, err := eval.Evaluate(t.Context(), Request{ToolName: "read"})
+		if err != nil || !resp.Allow {
+			t.Fatalf("evaluation %d: expected allow, got %+v (%v)", i, resp, err)
+		}
+	}
+
+	if _, err := load(t, spin).Evaluate(t.Context(), Request{ToolName: "read"}); err == nil || !strings.Contains(err.Error(), "fuel") {
+		t.Errorf("expected a runaway policy to run out of fuel, got %v", err)
+	}
+}
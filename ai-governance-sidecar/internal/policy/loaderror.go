@@ -0,0 +1,78 @@
+package policy
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Load stages a policy file can fail in
+const (
+	LoadStageRead        = "read"
+	LoadStageCompile     = "compile"
+	LoadStageInstantiate = "instantiate"
+)
+
+// LoadError describes why one policy file was skipped, precisely enough
+// for its author to find the problem. Offset is the byte offset in the
+// module that the WASM validator reported, when it gave one.
+type LoadError struct {
+	Policy  string `json:"policy"`
+	File    string `json:"file"`
+	Stage   string `json:"stage"`
+	Offset  *int64 `json:"offset,omitempty"`
+	Message string `json:"message"`
+
+	err error
+}
+
+func (e *LoadError) Unwrap() error { return e.err }
+
+func (e *LoadError) Error() string {
+	if e.Offset != nil {
+		return "policy " + e.File + ": " + e.Stage + " at offset " + strconv.FormatInt(*e.Offset, 10) + ": " + e.Message
+	}
+	return "policy " + e.File + ": " + e.Stage + ": " + e.Message
+}
+
+// wasmOffset matches wasmtime's "... at offset 24: type mismatch ..." cause
+var wasmOffset = regexp.MustCompile(`at offset (\d+): (.+)`)
+
+// newLoadError pulls the innermost cause, and its offset if present, out of
+// a wasmtime error, which otherwise leads with a generic summary
+func newLoadError(policy, file, stage string, err error) *LoadError {
+	loadErr := &LoadError{Policy: policy, File: file, Stage: stage, Message: lastCause(err.Error()), err: err}
+
+	if m := wasmOffset.FindStringSubmatch(loadErr.Message); m != nil {
+		if offset, parseErr := strconv.ParseInt(m[1], 10, 64); parseErr == nil {
+			loadErr.Offset = &offset
+			loadErr.Message = m[2]
+		}
+	}
+	return loadErr
+}
+
+func lastCause(msg string) string {
+	lines := strings.Split(strings.TrimSpace(msg), "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		if line := strings.TrimSpace(lines[i]); line != "" && line != "Caused by:" {
+			return line
+		}
+	}
+	return msg
+}
+
+// loadErrorReporter is implemented by loaders that keep the files skipped
+// on their last load
+type loadErrorReporter interface {
+	LoadErrors() []LoadError
+}
+
+// LoadErrors returns the policy files the last load or reload skipped
+func (e *Engine) LoadErrors() []LoadError {
+	reporter, ok := e.loader.(loadErrorReporter)
+	if !ok {
+		return nil
+	}
+	return reporter.LoadErrors()
+}
@@ -0,0 +1,28 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ErrPolicyPanic wraps a panic raised while a policy module was evaluating
+var ErrPolicyPanic = errors.New("policy panicked")
+
+// evaluateModule runs one policy and turns a panic, from an unexpected trap
+// or a host function, into an error so it is handled like any other policy
+// failure instead of taking down the request.
+func evaluateModule(ctx context.Context, name string, eval moduleEvaluator, req Request) (resp Response, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Error().Str("policy", name).Interface("panic", r).Bytes("stack", debug.Stack()).
+				Msg("policy evaluation panicked")
+			resp, err = Response{}, fmt.Errorf("%w: %v", ErrPolicyPanic, r)
+		}
+	}()
+
+	return eval.Evaluate(ctx, req)
+}
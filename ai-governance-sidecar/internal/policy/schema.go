@@ -0,0 +1,105 @@
+package policy
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+var rawMessageType = reflect.TypeOf(json.RawMessage{})
+
+// Schema describes the policy contract as JSON Schema, generated from the
+// structs policies actually receive and return so it cannot drift.
+func Schema() map[string]any {
+	input := schemaFor(reflect.TypeOf(inputV2{}))
+	input["$schema"] = jsonSchemaDraft
+	input["title"] = "Policy input"
+
+	// The proxy always fills these metadata keys
+	if props, ok := input["properties"].(map[string]any); ok {
+		props["metadata"] = map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"upstream": map[string]any{"type": "string"},
+				"method":   map[string]any{"type": "string"},
+			},
+			"additionalProperties": true,
+		}
+	}
+
+	output := schemaFor(reflect.TypeOf(Response{}))
+	output["$schema"] = jsonSchemaDraft
+	output["title"] = "Policy output"
+
+	return map[string]any{
+		"input_version": CurrentInputVersion,
+		"input":         input,
+		"output":        output,
+	}
+}
+
+func schemaFor(t reflect.Type) map[string]any {
+	if t == rawMessageType {
+		// Any JSON value
+		return map[string]any{}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return schemaFor(t.Elem())
+	case reflect.Struct:
+		return structSchema(t)
+	case reflect.Map:
+		return map[string]any{
+			"type":                 "object",
+			"additionalProperties": schemaFor(t.Elem()),
+		}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{
+			"type":  "array",
+			"items": schemaFor(t.Elem()),
+		}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	default:
+		return map[string]any{}
+	}
+}
+
+// structSchema treats fields without omitempty as required
+func structSchema(t reflect.Type) map[string]any {
+	properties := make(map[string]any)
+	required := []string{}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" || (!field.IsExported() && name == "") {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		properties[name] = schemaFor(field.Type)
+		if !strings.Contains(opts, "omitempty") {
+			required = append(required, name)
+		}
+	}
+
+	return map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
@@ -0,0 +1,56 @@
+package policy
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSchemaRequiredFields(t *testing.T) {
+	data, err := json.Marshal(Schema())
+	if err != nil {
+		t.Fatalf("marshal schema: %v", err)
+	}
+
+	var schema struct {
+		Input struct {
+			Required   []string       `json:"required"`
+			Properties map[string]any `json:"properties"`
+		} `json:"input"`
+		Output struct {
+			Required   []string       `json:"required"`
+			Properties map[string]any `json:"properties"`
+		} `json:"output"`
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("unmarshal schema: %v", err)
+	}
+
+	for _, field := range []string{"_version", "tool_name", "args"} {
+		if !contains(schema.Input.Required, field) {
+			t.Errorf("expected input field %q to be required, got %v", field, schema.Input.Required)
+		}
+	}
+	for _, field := range []string{"metadata", "headers", "files", "user"} {
+		if _, ok := schema.Input.Properties[field]; !ok {
+			t.Errorf("expected input property %q", field)
+		}
+	}
+
+	for _, field := range []string{"allow", "reason", "human_required"} {
+		if !contains(schema.Output.Required, field) {
+			t.Errorf("expected output field %q to be required, got %v", field, schema.Output.Required)
+		}
+	}
+	if contains(schema.Output.Required, "risk") {
+		t.Error("expected optional risk to not be required")
+	}
+}
+
+func contains(values []string, want string) bool {
+	for _, v := range values {
+		if v == want {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ErrPolicyNotLoaded means a policy name does not match any loaded module
+var ErrPolicyNotLoaded = errors.New("policy not loaded")
+
+// SetPolicyEnabled turns a loaded policy on or off without touching its
+// module file. A disabled policy is skipped during evaluation until it is
+// enabled again or the next reload replaces the policy set.
+func (e *Engine) SetPolicyEnabled(name string, enabled bool) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, ok := e.evaluators[name]; !ok {
+		return fmt.Errorf("%w: %s", ErrPolicyNotLoaded, name)
+	}
+
+	if enabled {
+		delete(e.disabled, name)
+	} else {
+		if e.disabled == nil {
+			e.disabled = make(map[string]bool)
+		}
+		e.disabled[name] = true
+	}
+
+	log.Info().Str("policy", name).Bool("enabled", enabled).Msg("policy toggled")
+	return nil
+}
+
+// withoutDisabled drops disabled policies from a selection
+func (e *Engine) withoutDisabled(evaluators map[string]moduleEvaluator) map[string]moduleEvaluator {
+	if len(e.disabled) == 0 {
+		return evaluators
+	}
+
+	enabled := make(map[string]moduleEvaluator, len(evaluators))
+	for name, eval := range evaluators {
+		if !e.disabled[name] {
+			enabled[name] = eval
+		}
+	}
+	return enabled
+}
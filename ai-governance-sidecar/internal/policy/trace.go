@@ -0,0 +1,74 @@
+package policy
+
+import (
+	"context"
+	"time"
+)
+
+// Tracer is implemented by evaluators that can explain a decision
+type Tracer interface {
+	EvaluateTrace(ctx context.Context, req Request) (Trace, error)
+}
+
+// Trace records every step of a single evaluation
+type Trace struct {
+	Input      any             `json:"input"`
+	Policies   []PolicyVerdict `json:"policies"`
+	Decision   Response        `json:"decision"`
+	DurationUS int64           `json:"duration_us"`
+}
+
+// PolicyVerdict is one policy's result within a trace
+type PolicyVerdict struct {
+	Name          string   `json:"name"`
+	Allow         bool     `json:"allow"`
+	HumanRequired bool     `json:"human_required"`
+	Reason        string   `json:"reason"`
+	Risk          *float64 `json:"risk,omitempty"`
+	// OverrideAllowed marks a deny a human may override
+	OverrideAllowed bool     `json:"override_allowed,omitempty"`
+	Warnings        []string `json:"warnings,omitempty"`
+	Error           string   `json:"error,omitempty"`
+	DurationUS      int64    `json:"duration_us"`
+}
+
+// EvaluateTrace runs every selected policy and reports each verdict
+// alongside the decision Evaluate would return.
+func (e *Engine) EvaluateTrace(ctx context.Context, req Request) (Trace, error) {
+	start := time.Now()
+	trace := Trace{Policies: []PolicyVerdict{}}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if len(e.evaluators) == 0 {
+		trace.Input = req.Input(CurrentInputVersion)
+		trace.Decision = e.denyResponse("no policies loaded")
+		trace.DurationUS = time.Since(start).Microseconds()
+		return trace, nil
+	}
+
+	req, results := e.runSelected(ctx, req)
+	trace.Input = req.Input(CurrentInputVersion)
+	for _, result := range results {
+		verdict := PolicyVerdict{
+			Name:            result.name,
+			Allow:           result.resp.Allow,
+			HumanRequired:   result.resp.HumanRequired,
+			Reason:          result.resp.Reason,
+			Risk:            result.resp.Risk,
+			OverrideAllowed: result.resp.OverrideAllowed,
+			Warnings:        result.resp.Warnings,
+			DurationUS:      result.duration.Microseconds(),
+		}
+		if result.err != nil {
+			verdict.Allow = false
+			verdict.Error = result.err.Error()
+		}
+		trace.Policies = append(trace.Policies, verdict)
+	}
+
+	trace.Decision = e.combine(req.ToolName, results)
+	trace.DurationUS = time.Since(start).Microseconds()
+	return trace, nil
+}
@@ -10,13 +10,107 @@ type Request struct {
 	ToolName string          `json:"tool_name"`
 	Args     json.RawMessage `json:"args"`
 	Metadata map[string]any  `json:"metadata,omitempty"`
+	// Headers holds the configured request headers, keyed by canonical name
+	Headers map[string]string `json:"headers,omitempty"`
+	// Files describes uploaded files; bodies are never passed to policies
+	Files []FileInfo `json:"files,omitempty"`
+	// Response is the upstream result, set only when egress policies
+	// evaluate it
+	Response json.RawMessage `json:"response,omitempty"`
+	// User is the authenticated caller; nil for anonymous calls
+	User *User `json:"user,omitempty"`
+}
+
+// User describes the authenticated caller of a tool
+type User struct {
+	ID     string   `json:"id"`
+	Email  string   `json:"email,omitempty"`
+	Roles  []string `json:"roles,omitempty"`
+	Tenant string   `json:"tenant,omitempty"`
+}
+
+// FileInfo describes a file uploaded with a multipart tool call
+type FileInfo struct {
+	Field       string `json:"field"`
+	Name        string `json:"name"`
+	Size        int64  `json:"size"`
+	ContentType string `json:"content_type"`
+}
+
+// Policy input schema versions. Version 1 is the original
+// tool_name/args/metadata shape; version 2 adds _version, headers, files and
+// user, and response for egress policies.
+// Policies opt into an older shape by exporting input_version() -> i32.
+const (
+	InputVersion1       = 1
+	InputVersion2       = 2
+	CurrentInputVersion = InputVersion2
+)
+
+type inputV1 struct {
+	ToolName string          `json:"tool_name"`
+	Args     json.RawMessage `json:"args"`
+	Metadata map[string]any  `json:"metadata,omitempty"`
+}
+
+type inputV2 struct {
+	Version  int               `json:"_version"`
+	ToolName string            `json:"tool_name"`
+	Args     json.RawMessage   `json:"args"`
+	Metadata map[string]any    `json:"metadata,omitempty"`
+	Headers  map[string]string `json:"headers,omitempty"`
+	Files    []FileInfo        `json:"files,omitempty"`
+	Response json.RawMessage   `json:"response,omitempty"`
+	User     *User             `json:"user,omitempty"`
+}
+
+// Input returns the policy input for the given schema version. Unknown
+// versions get the current schema.
+func (r Request) Input(version int) any {
+	if version == InputVersion1 {
+		return inputV1{ToolName: r.ToolName, Args: r.Args, Metadata: r.Metadata}
+	}
+
+	return inputV2{
+		Version:  CurrentInputVersion,
+		ToolName: r.ToolName,
+		Args:     r.Args,
+		Metadata: r.Metadata,
+		Headers:  r.Headers,
+		Files:    r.Files,
+		Response: r.Response,
+		User:     r.User,
+	}
 }
 
 // Response represents the policy decision
 type Response struct {
-	Allow          bool   `json:"allow"`
-	Reason         string `json:"reason"`
-	HumanRequired  bool   `json:"human_required"`
+	Allow         bool   `json:"allow"`
+	Reason        string `json:"reason"`
+	HumanRequired bool   `json:"human_required"`
+	// Risk is an optional 0-1 score used to auto-approve low-risk calls
+	Risk *float64 `json:"risk,omitempty"`
+	// OverrideAllowed lets a human grant a one-time exception to a deny
+	OverrideAllowed bool `json:"override_allowed,omitempty"`
+	// JustificationRequired marks a sensitive call that must carry
+	// justification text before it proceeds
+	JustificationRequired bool `json:"justification_required,omitempty"`
+	// Warnings are non-blocking notes such as "this tool is deprecated".
+	// They are passed back to the caller and audited but never change
+	// the decision.
+	Warnings []string `json:"warnings,omitempty"`
+	// DenyCategory classifies a deny for reporting, such as "policy" or
+	// "rate_limit"; the sidecar uses "policy" when it is empty
+	DenyCategory string `json:"deny_category,omitempty"`
+	// Redact lists fields of the upstream response an egress policy wants
+	// masked before it is returned, e.g. "response.customer.ssn". Request
+	// policies leave it empty.
+	Redact []string `json:"redact,omitempty"`
+	// Policy names the policy that decided; set by the engine, not policies
+	Policy string `json:"-"`
+	// Shadow is the deny or human_required a shadow-mode policy would have
+	// returned; set by the engine and never enforced
+	Shadow *Response `json:"-"`
 }
 
 // Evaluator evaluates tool call requests against policies
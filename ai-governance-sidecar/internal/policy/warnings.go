@@ -0,0 +1,26 @@
+package policy
+
+import "sort"
+
+// mergeWarnings adds a policy's warnings to those already collected. The
+// result is sorted and free of duplicates, so it does not depend on the
+// order policies ran in.
+func mergeWarnings(collected, warnings []string) []string {
+	if len(warnings) == 0 {
+		return collected
+	}
+
+	seen := make(map[string]bool, len(collected))
+	for _, w := range collected {
+		seen[w] = true
+	}
+	for _, w := range warnings {
+		if w != "" && !seen[w] {
+			seen[w] = true
+			collected = append(collected, w)
+		}
+	}
+
+	sort.Strings(collected)
+	return collected
+}
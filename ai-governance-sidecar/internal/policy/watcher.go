@@ -3,22 +3,55 @@ package policy
 import (
 	"fmt"
 	"path/filepath"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/rs/zerolog/log"
 )
 
+// watchRetryInterval is how often a failed watch is re-established
+const watchRetryInterval = 2 * time.Second
+
 type ChangeHandler func(path string)
 
 type FileWatcher struct {
+	mu      sync.Mutex
 	watcher *fsnotify.Watcher
 	dir     string
 	handler ChangeHandler
 	done    chan struct{}
+
+	healthy       atomic.Bool
+	retryInterval time.Duration
 }
 
 func NewFileWatcher(dir string, handler ChangeHandler) (*FileWatcher, error) {
+	return newFileWatcher(dir, handler, watchRetryInterval)
+}
+
+func newFileWatcher(dir string, handler ChangeHandler, retryInterval time.Duration) (*FileWatcher, error) {
+	watcher, err := newDirWatcher(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	fw := &FileWatcher{
+		watcher:       watcher,
+		dir:           dir,
+		handler:       handler,
+		done:          make(chan struct{}),
+		retryInterval: retryInterval,
+	}
+	fw.healthy.Store(true)
+
+	go fw.run()
+
+	return fw, nil
+}
+
+func newDirWatcher(dir string) (*fsnotify.Watcher, error) {
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
 		return nil, fmt.Errorf("create watcher: %w", err)
@@ -28,33 +61,56 @@ func NewFileWatcher(dir string, handler ChangeHandler) (*FileWatcher, error) {
 		watcher.Close()
 		return nil, fmt.Errorf("watch directory: %w", err)
 	}
+	return watcher, nil
+}
 
-	fw := &FileWatcher{
-		watcher: watcher,
-		dir:     dir,
-		handler: handler,
-		done:    make(chan struct{}),
-	}
-
-	go fw.watch()
-
-	return fw, nil
+// Healthy reports whether the policy directory is currently being watched.
+// It is false between a watch failure and its recovery.
+func (fw *FileWatcher) Healthy() bool {
+	return fw.healthy.Load()
 }
 
 func (fw *FileWatcher) Close() error {
 	close(fw.done)
+
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
 	return fw.watcher.Close()
 }
 
-func (fw *FileWatcher) watch() {
+// run watches until closed, re-establishing the watch whenever it fails
+func (fw *FileWatcher) run() {
+	for {
+		if !fw.watch() {
+			return
+		}
+
+		fw.healthy.Store(false)
+		if !fw.recover() {
+			return
+		}
+	}
+}
+
+// watch handles events until the watch fails, returning true, or the
+// watcher is closed, returning false.
+func (fw *FileWatcher) watch() bool {
+	fw.mu.Lock()
+	watcher := fw.watcher
+	fw.mu.Unlock()
+
 	debounce := time.NewTimer(0)
 	<-debounce.C // Drain initial timer
 
 	for {
 		select {
-		case event, ok := <-fw.watcher.Events:
+		case event, ok := <-watcher.Events:
 			if !ok {
-				return
+				return fw.fail(watcher, "watcher events channel closed")
+			}
+
+			if fw.dirRemoved(event) {
+				return fw.fail(watcher, "policy directory removed")
 			}
 
 			if fw.shouldHandle(event) {
@@ -63,18 +119,76 @@ func (fw *FileWatcher) watch() {
 				go fw.waitAndHandle(debounce, event.Name)
 			}
 
-		case err, ok := <-fw.watcher.Errors:
+		case err, ok := <-watcher.Errors:
 			if !ok {
-				return
+				return fw.fail(watcher, "watcher errors channel closed")
 			}
 			log.Error().Err(err).Msg("watcher error")
 
 		case <-fw.done:
-			return
+			return false
 		}
 	}
 }
 
+// fail closes a broken watcher and reports whether recovery should run
+func (fw *FileWatcher) fail(watcher *fsnotify.Watcher, reason string) bool {
+	select {
+	case <-fw.done:
+		return false
+	default:
+	}
+
+	log.Error().Str("dir", fw.dir).Str("reason", reason).Msg("policy watcher failed, hot reload paused")
+	watcher.Close()
+	return true
+}
+
+// recover retries the watch until it succeeds, then reloads so changes made
+// while the watch was down are picked up. It returns false if the watcher is
+// closed first.
+func (fw *FileWatcher) recover() bool {
+	ticker := time.NewTicker(fw.retryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-fw.done:
+			return false
+		case <-ticker.C:
+		}
+
+		watcher, err := newDirWatcher(fw.dir)
+		if err != nil {
+			log.Debug().Err(err).Str("dir", fw.dir).Msg("policy watcher still down")
+			continue
+		}
+
+		fw.mu.Lock()
+		select {
+		case <-fw.done:
+			fw.mu.Unlock()
+			watcher.Close()
+			return false
+		default:
+		}
+		fw.watcher = watcher
+		fw.mu.Unlock()
+
+		fw.healthy.Store(true)
+		log.Info().Str("dir", fw.dir).Msg("policy watcher recovered")
+		fw.handler(fw.dir)
+		return true
+	}
+}
+
+func (fw *FileWatcher) dirRemoved(event fsnotify.Event) bool {
+	if !event.Has(fsnotify.Remove) && !event.Has(fsnotify.Rename) {
+		return false
+	}
+	return filepath.Clean(event.Name) == filepath.Clean(fw.dir)
+}
+
 func (fw *FileWatcher) shouldHandle(event fsnotify.Event) bool {
 	if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) {
 		return false
@@ -87,4 +201,4 @@ func (fw *FileWatcher) shouldHandle(event fsnotify.Event) bool {
 func (fw *FileWatcher) waitAndHandle(timer *time.Timer, path string) {
 	<-timer.C
 	fw.handler(path)
-}
\ No newline at end of file
+}
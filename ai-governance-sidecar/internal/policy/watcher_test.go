@@ -83,4 +83,67 @@ func TestWatcherIgnoresNonWASM(t *testing.T) {
 	case <-time.After(1 * time.Second):
 		// Expected - no change should be detected
 	}
-}
\ No newline at end of file
+}
+func TestWatcherRecoversAfterDirRecreated(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "policies")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	changeChan := make(chan string, 4)
+	handler := func(path string) {
+		changeChan <- path
+	}
+
+	watcher, err := newFileWatcher(dir, handler, 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("failed to create watcher: %v", err)
+	}
+	defer watcher.Close()
+
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return !watcher.Healthy() }, "watcher to report failure")
+
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, watcher.Healthy, "watcher to recover")
+
+	// Recovery reloads once to catch changes made while the watch was down
+	select {
+	case path := <-changeChan:
+		if path != dir {
+			t.Errorf("expected recovery reload for %s, got %s", dir, path)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for recovery reload")
+	}
+
+	testFile := filepath.Join(dir, "test.wasm")
+	if err := os.WriteFile(testFile, []byte("test"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case path := <-changeChan:
+		if path != testFile {
+			t.Errorf("expected change for %s, got %s", testFile, path)
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("timeout waiting for file change after recovery")
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool, what string) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,59 @@
+package proxy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/audit"
+	"github.com/dagbolade/ai-governance-sidecar/internal/auth"
+	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
+	"github.com/labstack/echo/v4"
+)
+
+func TestHandleToolCall_RecordsAuditActor(t *testing.T) {
+	tests := []struct {
+		name       string
+		user       *auth.User
+		wantActor  string
+		wantTenant string
+	}{
+		{name: "authenticated", user: &auth.User{ID: "alice", Email: "alice@example.com", Tenant: "acme"}, wantActor: "alice@example.com", wantTenant: "acme"},
+		{name: "auth disabled"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := audit.NewSQLiteStore(filepath.Join(t.TempDir(), "audit.db"))
+			if err != nil {
+				t.Fatalf("failed to open audit store: %v", err)
+			}
+			defer store.Close()
+
+			evaluator := &mockPolicyEvaluator{response: policy.Response{Allow: false, Reason: "blocked"}}
+			handler := NewHandler(ProxyConfig{DefaultUpstream: "http://localhost:9000"}, evaluator, store, &mockApprovalQueue{})
+
+			req := httptest.NewRequest(http.MethodPost, "/tool/call", strings.NewReader(`{"tool_name":"deploy","args":{}}`))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			if tt.user != nil {
+				req = req.WithContext(auth.WithUser(context.Background(), tt.user))
+			}
+			rec := httptest.NewRecorder()
+
+			if err := handler.HandleToolCall(echo.New().NewContext(req, rec)); err != nil {
+				t.Fatalf("handler failed: %v", err)
+			}
+
+			entries, err := store.GetAll(context.Background())
+			if err != nil || len(entries) != 1 {
+				t.Fatalf("expected 1 audit entry, got %d (%v)", len(entries), err)
+			}
+			if entries[0].Actor != tt.wantActor || entries[0].Tenant != tt.wantTenant {
+				t.Errorf("expected actor %q tenant %q, got %q %q", tt.wantActor, tt.wantTenant, entries[0].Actor, entries[0].Tenant)
+			}
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/audit"
+	"github.com/dagbolade/ai-governance-sidecar/internal/auth"
+	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// AppealRequest is the optional body of POST /tool/call/:audit_id/appeal
+type AppealRequest struct {
+	// Reason is the caller's case for an exception, shown to the approver
+	Reason string `json:"reason,omitempty"`
+}
+
+// HandleAppeal sends a call denied by an overridable policy back through
+// human review. The audited request goes through today's pipeline again,
+// quota, metadata, policy and justification, and is queued only if policy
+// still denies it with an override allowed; if an approver grants the
+// appeal the call is forwarded and its result returned, as with an
+// overridable deny. Only the original caller or an admin may appeal, and
+// each entry can be appealed once.
+func (h *Handler) HandleAppeal(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := strconv.ParseInt(c.Param("audit_id"), 10, 64)
+	if err != nil || id <= 0 {
+		return h.errorResponse(c, http.StatusBadRequest, "audit_id must be a positive entry id")
+	}
+
+	var body AppealRequest
+	if c.Request().ContentLength != 0 {
+		if err := json.NewDecoder(c.Request().Body).Decode(&body); err != nil {
+			return h.errorResponse(c, http.StatusBadRequest, "invalid request body")
+		}
+	}
+
+	entry, err := getAuditEntry(ctx, h.audit, id)
+	if errors.Is(err, audit.ErrEntryNotFound) {
+		return h.errorResponse(c, http.StatusNotFound, fmt.Sprintf("audit entry %d not found", id))
+	}
+	if err != nil {
+		log.Error().Err(err).Int64("audit_id", id).Msg("failed to read audit log for appeal")
+		return h.errorResponse(c, http.StatusInternalServerError, "failed to read audit log")
+	}
+	if entry.Decision != audit.DecisionDeny {
+		return h.errorResponse(c, http.StatusConflict, "only denied calls can be appealed")
+	}
+	if entry.DryRun {
+		return h.errorResponse(c, http.StatusConflict, "dry-run decisions cannot be appealed")
+	}
+	if !mayAppeal(auth.GetUserFromContext(c), entry) {
+		return h.errorResponse(c, http.StatusForbidden, "only the original caller or an admin can appeal this call")
+	}
+	if h.appeals.seen(id) {
+		return h.errorResponse(c, http.StatusConflict, fmt.Sprintf("audit entry %d has already been appealed", id))
+	}
+
+	req, err := h.appealedRequest(c, entry)
+	if err != nil {
+		return h.errorResponse(c, http.StatusUnprocessableEntity, err.Error())
+	}
+
+	release, err := h.acquireQuota(c, 1)
+	if err != nil {
+		log.Warn().Err(err).Str("tool", req.ToolName).Msg("user quota exceeded")
+		if auditErr := h.logAudit(ctx, req, quotaDenial(err)); auditErr != nil {
+			log.Warn().Err(auditErr).Msg("audit logging failed")
+		}
+		return h.errorResponse(c, http.StatusTooManyRequests, err.Error())
+	}
+	defer release()
+
+	if err := h.checkMetadata(req); err != nil {
+		log.Warn().Err(err).Str("tool", req.ToolName).Msg("required metadata missing")
+		if auditErr := h.logAudit(ctx, req, metadataDenial(err)); auditErr != nil {
+			log.Warn().Err(auditErr).Msg("audit logging failed")
+		}
+		return h.errorResponse(c, http.StatusUnprocessableEntity, err.Error())
+	}
+
+	decision, err := h.evaluateGated(ctx, req)
+	if err != nil {
+		return h.errorResponse(c, http.StatusInternalServerError, "policy evaluation failed")
+	}
+	decision = h.applyPatterns(req, decision)
+	decision = h.applyTimeWindows(req, decision)
+	req.warnings = decision.Warnings
+
+	if err := h.checkJustification(req, decision); err != nil {
+		log.Warn().Str("tool", req.ToolName).Msg("justification missing")
+		if auditErr := h.logAudit(ctx, req, justificationDenial(err)); auditErr != nil {
+			log.Warn().Err(auditErr).Msg("audit logging failed")
+		}
+		return h.errorResponse(c, http.StatusUnprocessableEntity, err.Error())
+	}
+
+	if decision.Allow {
+		return h.errorResponse(c, http.StatusConflict, "policy no longer denies this call; send it again instead")
+	}
+	if !decision.OverrideAllowed {
+		return h.errorResponse(c, http.StatusConflict, "only calls denied by an overridable policy can be appealed")
+	}
+
+	if !h.appeals.claim(id) {
+		return h.errorResponse(c, http.StatusConflict, fmt.Sprintf("audit entry %d has already been appealed", id))
+	}
+	return h.handleAppeal(ctx, c, req, entry.ID, decision, strings.TrimSpace(body.Reason))
+}
+
+func (h *Handler) handleAppeal(ctx context.Context, c echo.Context, req *ToolCallRequest, id int64, denial policy.Response, note string) error {
+	reason := fmt.Sprintf("appeal of denied call %d: %s", id, denial.Reason)
+	if note != "" {
+		reason += " (appellant: " + note + ")"
+	}
+	reason = TruncateReason(reason, h.config.MaxReasonLength)
+
+	start := h.now()
+	stopHeartbeat := h.startHeartbeat(c)
+	decision, err := h.approval.Enqueue(ctx, req.ToPolicyRequest(), reason)
+	stopHeartbeat()
+	if err != nil {
+		h.appeals.release(id)
+		return h.errorResponse(c, http.StatusInternalServerError, "approval queue error")
+	}
+
+	if decision.TimedOut {
+		// Nobody reviewed it, so the caller may appeal again
+		h.appeals.release(id)
+		h.logApprovalTimeout(ctx, req, start)
+		return h.approvalTimeoutResponse(c)
+	}
+
+	approver := approverName(decision)
+
+	if !decision.Approved {
+		h.logApprovalDecision(ctx, req, decision, start, fmt.Sprintf("appeal of %d rejected by %s: %s", id, approver, denial.Reason))
+		return h.denyResponse(c, denial.Reason)
+	}
+
+	log.Info().Str("tool", req.ToolName).Int64("audit_id", id).Str("approver", approver).Msg("denial appeal granted")
+	h.logApprovalDecision(ctx, req, decision, start, fmt.Sprintf("appeal of %d granted by %s: %s", id, approver, denial.Reason))
+
+	return h.forwardRequest(ctx, c, req)
+}
+
+// appealedRequest rebuilds the tool call recorded in a denied entry. It is
+// normalized again so routing and upstream checks apply as they do today;
+// headers come from the appeal itself since they are never audited.
+func (h *Handler) appealedRequest(c echo.Context, entry audit.Entry) (*ToolCallRequest, error) {
+	if audit.IsTruncated(entry.ToolInput) {
+		return nil, fmt.Errorf("audit entry %d was truncated for size and cannot be replayed", entry.ID)
+	}
+
+	var req ToolCallRequest
+	if err := json.Unmarshal(entry.ToolInput, &req); err != nil || req.ToolName == "" {
+		return nil, fmt.Errorf("audit entry %d does not record a tool call", entry.ID)
+	}
+
+	if err := h.normalizeRequest(c.Request().Context(), &req, c.Request().Header); err != nil {
+		return nil, err
+	}
+	if err := h.checkUpstream(&req); err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+// mayAppeal allows the caller recorded on the entry and admins. Entries
+// written without auth record no caller and can be appealed by anyone.
+func mayAppeal(user *auth.User, entry audit.Entry) bool {
+	if entry.Actor == "" {
+		return true
+	}
+	if user == nil {
+		return false
+	}
+	return user.Email == entry.Actor || slices.Contains(user.Roles, auth.RoleAdmin)
+}
+
+// getAuditEntry loads one entry by id from stores that support it
+func getAuditEntry(ctx context.Context, store audit.Store, id int64) (audit.Entry, error) {
+	reader, ok := store.(audit.EntryReader)
+	if !ok {
+		return audit.Entry{}, audit.ErrStoreUnavailable
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	return reader.GetEntry(ctx, id)
+}
+
+// appealLedger remembers which entries have been appealed so a denial is
+// reviewed once. Like approval grants it is kept in memory.
+type appealLedger struct {
+	mu       sync.Mutex
+	appealed map[int64]bool
+}
+
+func newAppealLedger() *appealLedger {
+	return &appealLedger{appealed: make(map[int64]bool)}
+}
+
+func (l *appealLedger) seen(id int64) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.appealed[id]
+}
+
+// claim marks id appealed, failing if another appeal got there first
+func (l *appealLedger) claim(id int64) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.appealed[id] {
+		return false
+	}
+	l.appealed[id] = true
+	return true
+}
+
+// release lets id be appealed again after an appeal nobody decided
+func (l *appealLedger) release(id int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.appealed, id)
+}
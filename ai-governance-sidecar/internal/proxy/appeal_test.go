@@ -0,0 +1,272 @@
+package proxy
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/approval"
+	"github.com/dagbolade/ai-governance-sidecar/internal/audit"
+	"github.com/dagbolade/ai-governance-sidecar/internal/auth"
+	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
+	"github.com/labstack/echo/v4"
+)
+
+// deniedCall runs a call the policy denies, with any override rejected,
+// and returns its policy audit entry. queue is left as it was found.
+func deniedCall(t *testing.T, handler *Handler, queue *recordingApprovalQueue, store audit.Store, user *auth.User) audit.Entry {
+	t.Helper()
+
+	decision := queue.decision
+	queue.decision = approval.Decision{Approved: false, DecidedBy: "carol"}
+	defer func() {
+		queue.decision = decision
+		queue.reasons = nil
+	}()
+
+	req := httptest.NewRequest(http.MethodPost, "/tool/call", strings.NewReader(`{"tool_name":"deploy","args":{"env":"prod"}}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if user != nil {
+		req = req.WithContext(auth.WithUser(context.Background(), user))
+	}
+	rec := httptest.NewRecorder()
+
+	if err := handler.HandleToolCall(echo.New().NewContext(req, rec)); err != nil {
+		t.Fatalf("handler failed: %v", err)
+	}
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected denied call, got status %d", rec.Code)
+	}
+
+	entries, err := store.GetAll(context.Background())
+	if err != nil || len(entries) == 0 {
+		t.Fatalf("expected an audit entry, got %d (%v)", len(entries), err)
+	}
+	first := entries[0]
+	for _, entry := range entries {
+		if entry.ID < first.ID {
+			first = entry
+		}
+	}
+	return first
+}
+
+func appeal(t *testing.T, handler *Handler, id int64, body string, user *auth.User) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/tool/call/"+strconv.FormatInt(id, 10)+"/appeal", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+
+	c := echo.New().NewContext(req, rec)
+	c.SetParamNames("audit_id")
+	c.SetParamValues(strconv.FormatInt(id, 10))
+	if user != nil {
+		c.Set("user", user)
+	}
+
+	if err := handler.HandleAppeal(c); err != nil {
+		t.Fatalf("appeal failed: %v", err)
+	}
+	return rec
+}
+
+func TestHandleAppeal(t *testing.T) {
+	var forwarded string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		forwarded = string(body)
+		w.Write([]byte(`{"status":"deployed"}`))
+	}))
+	defer upstream.Close()
+
+	tests := []struct {
+		name         string
+		decision     approval.Decision
+		expectStatus int
+		expectAudit  audit.Decision
+		expectReason string
+	}{
+		{
+			name:         "granted",
+			decision:     approval.Decision{Approved: true, DecidedBy: "alice"},
+			expectStatus: http.StatusOK,
+			expectAudit:  audit.DecisionAllow,
+			expectReason: "granted by alice",
+		},
+		{
+			name:         "rejected",
+			decision:     approval.Decision{Approved: false, DecidedBy: "alice"},
+			expectStatus: http.StatusForbidden,
+			expectAudit:  audit.DecisionDeny,
+			expectReason: "rejected by alice",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forwarded = ""
+			store, err := audit.NewSQLiteStore(filepath.Join(t.TempDir(), "audit.db"))
+			if err != nil {
+				t.Fatalf("failed to open audit store: %v", err)
+			}
+			defer store.Close()
+
+			evaluator := &mockPolicyEvaluator{response: policy.Response{Allow: false, OverrideAllowed: true, Reason: "outside change window"}}
+			queue := &recordingApprovalQueue{decision: tt.decision}
+			handler := NewHandler(ProxyConfig{DefaultUpstream: upstream.URL, Timeout: 10}, evaluator, store, queue)
+
+			denied := deniedCall(t, handler, queue, store, nil)
+			before, err := store.GetAll(context.Background())
+			if err != nil {
+				t.Fatalf("get all failed: %v", err)
+			}
+			forwarded = ""
+
+			rec := appeal(t, handler, denied.ID, `{"reason":"hotfix for incident 42"}`, nil)
+			if rec.Code != tt.expectStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectStatus, rec.Code, rec.Body.String())
+			}
+
+			if len(queue.reasons) != 1 {
+				t.Fatalf("expected one approval request, got %v", queue.reasons)
+			}
+			for _, want := range []string{"outside change window", "hotfix for incident 42", strconv.FormatInt(denied.ID, 10)} {
+				if !strings.Contains(queue.reasons[0], want) {
+					t.Errorf("expected approval reason to contain %q, got %q", want, queue.reasons[0])
+				}
+			}
+
+			if tt.decision.Approved != strings.Contains(forwarded, `"deploy"`) {
+				t.Errorf("expected forwarded=%v, got body %q", tt.decision.Approved, forwarded)
+			}
+
+			entries, err := store.GetAll(context.Background())
+			if err != nil || len(entries) != len(before)+1 {
+				t.Fatalf("expected the appeal to be audited once, got %d entries after %d (%v)", len(entries), len(before), err)
+			}
+			var last audit.Entry
+			for _, entry := range entries {
+				if entry.ID > last.ID {
+					last = entry
+				}
+			}
+			if last.Decision != tt.expectAudit || !strings.Contains(last.Reason, tt.expectReason) {
+				t.Errorf("expected %s audit containing %q, got %s %q", tt.expectAudit, tt.expectReason, last.Decision, last.Reason)
+			}
+
+			if rec := appeal(t, handler, denied.ID, "", nil); rec.Code != http.StatusConflict {
+				t.Errorf("expected a second appeal to be refused with 409, got %d", rec.Code)
+			}
+			if len(queue.reasons) != 1 {
+				t.Errorf("expected the second appeal never to reach the queue, got %v", queue.reasons)
+			}
+		})
+	}
+}
+
+func TestHandleAppealRejectsInvalidAppeals(t *testing.T) {
+	store, err := audit.NewSQLiteStore(filepath.Join(t.TempDir(), "audit.db"))
+	if err != nil {
+		t.Fatalf("failed to open audit store: %v", err)
+	}
+	defer store.Close()
+
+	evaluator := &mockPolicyEvaluator{response: policy.Response{Allow: false, OverrideAllowed: true, Reason: "blocked"}}
+	queue := &recordingApprovalQueue{decision: approval.Decision{Approved: true}}
+	handler := NewHandler(ProxyConfig{DefaultUpstream: "http://localhost:9000"}, evaluator, store, queue)
+
+	owner := &auth.User{ID: "bob", Email: "bob@example.com"}
+	denied := deniedCall(t, handler, queue, store, owner)
+
+	if err := store.Log(context.Background(), []byte(`{"tool_name":"read"}`), audit.DecisionAllow, "ok"); err != nil {
+		t.Fatalf("log failed: %v", err)
+	}
+	if err := store.Log(audit.WithDryRun(context.Background()), []byte(`{"tool_name":"deploy"}`), audit.DecisionDeny, "dry_run: blocked"); err != nil {
+		t.Fatalf("log failed: %v", err)
+	}
+	entries, _ := store.GetAll(context.Background())
+	var allowed, dryRun audit.Entry
+	for _, entry := range entries {
+		switch {
+		case entry.Decision == audit.DecisionAllow:
+			allowed = entry
+		case entry.DryRun:
+			dryRun = entry
+		}
+	}
+
+	tests := []struct {
+		name         string
+		id           int64
+		user         *auth.User
+		expectStatus int
+	}{
+		{name: "unknown entry", id: 999, user: owner, expectStatus: http.StatusNotFound},
+		{name: "allowed entry", id: allowed.ID, user: owner, expectStatus: http.StatusConflict},
+		{name: "dry-run entry", id: dryRun.ID, user: &auth.User{ID: "alice", Roles: []string{auth.RoleAdmin}}, expectStatus: http.StatusConflict},
+		{name: "other caller", id: denied.ID, user: &auth.User{Email: "eve@example.com", Roles: []string{auth.RoleViewer}}, expectStatus: http.StatusForbidden},
+		{name: "anonymous caller", id: denied.ID, expectStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := appeal(t, handler, tt.id, "", tt.user)
+			if rec.Code != tt.expectStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.expectStatus, rec.Code, rec.Body.String())
+			}
+		})
+	}
+
+	if len(queue.reasons) != 0 {
+		t.Errorf("expected invalid appeals never to reach the queue, got %v", queue.reasons)
+	}
+}
+
+func TestHandleAppealRerunsPipeline(t *testing.T) {
+	overridable := policy.Response{Allow: false, OverrideAllowed: true, Reason: "blocked"}
+
+	tests := []struct {
+		name         string
+		now          policy.Response
+		metadata     []string
+		expectStatus int
+	}{
+		{name: "now a hard deny", now: policy.Response{Allow: false, Reason: "blocked for good"}, expectStatus: http.StatusConflict},
+		{name: "now allowed", now: policy.Response{Allow: true, Reason: "ok"}, expectStatus: http.StatusConflict},
+		{name: "now needs justification", now: policy.Response{Allow: false, OverrideAllowed: true, JustificationRequired: true, Reason: "blocked"}, expectStatus: http.StatusUnprocessableEntity},
+		{name: "now needs metadata", now: overridable, metadata: []string{"ticket"}, expectStatus: http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := audit.NewSQLiteStore(filepath.Join(t.TempDir(), "audit.db"))
+			if err != nil {
+				t.Fatalf("failed to open audit store: %v", err)
+			}
+			defer store.Close()
+
+			evaluator := &mockPolicyEvaluator{response: overridable}
+			queue := &recordingApprovalQueue{decision: approval.Decision{Approved: true}}
+			handler := NewHandler(ProxyConfig{DefaultUpstream: "http://localhost:9000"}, evaluator, store, queue)
+
+			denied := deniedCall(t, handler, queue, store, nil)
+
+			evaluator.response = tt.now
+			handler.config.RequiredMetadata = tt.metadata
+
+			rec := appeal(t, handler, denied.ID, "", nil)
+			if rec.Code != tt.expectStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.expectStatus, rec.Code, rec.Body.String())
+			}
+			if len(queue.reasons) != 0 {
+				t.Errorf("expected the appeal never to reach the queue, got %v", queue.reasons)
+			}
+		})
+	}
+}
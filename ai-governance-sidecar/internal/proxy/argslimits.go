@@ -0,0 +1,76 @@
+package proxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+var (
+	ErrArgsTooLarge = errors.New("args too large")
+	ErrArgsTooDeep  = errors.New("args nested too deeply")
+)
+
+// checkArgsLimits rejects args that would be slow to evaluate or overflow a
+// policy's output buffer. Zero limits are not enforced.
+func (h *Handler) checkArgsLimits(args json.RawMessage) error {
+	if max := h.config.MaxArgsBytes; max > 0 && len(args) > max {
+		return fmt.Errorf("%w: %d bytes exceeds the limit of %d", ErrArgsTooLarge, len(args), max)
+	}
+
+	if max := h.config.MaxArgsDepth; max > 0 && len(args) > 0 {
+		depth, err := jsonDepth(args, max)
+		if err != nil {
+			return fmt.Errorf("args must be valid JSON")
+		}
+		if depth > max {
+			return fmt.Errorf("%w: depth exceeds the limit of %d", ErrArgsTooDeep, max)
+		}
+	}
+
+	return nil
+}
+
+// jsonDepth returns the deepest object or array nesting in data, stopping
+// as soon as it passes limit
+func jsonDepth(data []byte, limit int) (int, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+
+	depth, deepest := 0, 0
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return deepest, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+			if depth > deepest {
+				deepest = depth
+			}
+			if deepest > limit {
+				return deepest, nil
+			}
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+	}
+}
+
+// parseErrorStatus maps a request validation error to its HTTP status
+func parseErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrArgsTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, ErrArgsTooDeep):
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusBadRequest
+}
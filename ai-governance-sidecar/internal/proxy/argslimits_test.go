@@ -0,0 +1,59 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
+	"github.com/labstack/echo/v4"
+)
+
+func TestHandleToolCall_ArgsLimits(t *testing.T) {
+	nested := func(depth int) string {
+		return strings.Repeat(`{"a":`, depth) + `1` + strings.Repeat(`}`, depth)
+	}
+
+	tests := []struct {
+		name           string
+		args           string
+		expectedStatus int
+	}{
+		{name: "depth at limit", args: nested(4), expectedStatus: http.StatusOK},
+		{name: "too deep", args: nested(5), expectedStatus: http.StatusUnprocessableEntity},
+		{name: "too deep in array", args: `[[[[[1]]]]]`, expectedStatus: http.StatusUnprocessableEntity},
+		{name: "too large", args: `{"data":"` + strings.Repeat("x", 100) + `"}`, expectedStatus: http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"ok":true}`))
+			}))
+			defer upstream.Close()
+
+			mockPolicy := &mockPolicyEvaluator{response: policy.Response{Allow: true}}
+			handler := NewHandler(ProxyConfig{
+				DefaultUpstream: upstream.URL,
+				Timeout:         5,
+				MaxArgsBytes:    64,
+				MaxArgsDepth:    4,
+			}, mockPolicy, &mockAuditStore{}, &mockApprovalQueue{})
+
+			e := echo.New()
+			body := `{"tool_name":"test_tool","args":` + tt.args + `}`
+			req := httptest.NewRequest(http.MethodPost, "/tool/call", strings.NewReader(body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+
+			if err := handler.HandleToolCall(e.NewContext(req, rec)); err != nil {
+				t.Fatalf("handler failed: %v", err)
+			}
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package proxy
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
+	"github.com/rs/zerolog/log"
+)
+
+// Audit detail levels. Lean keeps only the reason string; full adds the
+// deciding policy and input snapshot to denied entries.
+const (
+	AuditDetailLean = "lean"
+	AuditDetailFull = "full"
+)
+
+// upstreamGuardPolicy names upstream allowlist denials in audit detail
+const upstreamGuardPolicy = "upstream_allowlist"
+
+type denialDetail struct {
+	Policy          string   `json:"policy,omitempty"`
+	CombineMode     string   `json:"combine_mode"`
+	Reason          string   `json:"reason"`
+	OverrideAllowed bool     `json:"override_allowed,omitempty"`
+	Risk            *float64 `json:"risk,omitempty"`
+	Input           any      `json:"input"`
+}
+
+func validateAuditDetail(level string) error {
+	switch level {
+	case "", AuditDetailLean, AuditDetailFull:
+		return nil
+	default:
+		return fmt.Errorf("invalid AUDIT_DETAIL %q: must be %s or %s", level, AuditDetailLean, AuditDetailFull)
+	}
+}
+
+// auditDetail returns nil unless full detail is enabled and the request
+// was denied.
+func (h *Handler) auditDetail(req *ToolCallRequest, decision policy.Response) json.RawMessage {
+	if h.config.AuditDetail != AuditDetailFull || decision.Allow {
+		return nil
+	}
+
+	detail, err := json.Marshal(denialDetail{
+		Policy:          decision.Policy,
+		CombineMode:     policy.CombineDenyOverrides,
+		Reason:          decision.Reason,
+		OverrideAllowed: decision.OverrideAllowed,
+		Risk:            decision.Risk,
+		Input:           req.ToPolicyRequest().Input(policy.CurrentInputVersion),
+	})
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to encode audit detail")
+		return nil
+	}
+	return detail
+}
+
+func upstreamDenial(err error) policy.Response {
+	return policy.Response{Allow: false, Reason: err.Error(), Policy: upstreamGuardPolicy}
+}
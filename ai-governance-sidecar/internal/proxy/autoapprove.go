@@ -0,0 +1,75 @@
+package proxy
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
+)
+
+// AutoApproveConfig lets low-risk human_required decisions resolve without
+// waiting on an approver.
+type AutoApproveConfig struct {
+	Enabled bool
+	MaxRisk float64     // decisions with risk at or below this are auto-approved
+	Window  *TimeWindow // nil means any time of day
+}
+
+// TimeWindow is a daily window in local time. Windows that wrap midnight
+// (e.g. 22:00-06:00) are supported.
+type TimeWindow struct {
+	Start time.Duration // offset from midnight
+	End   time.Duration
+}
+
+// ParseTimeWindow parses "HH:MM-HH:MM".
+func ParseTimeWindow(value string) (*TimeWindow, error) {
+	parts := strings.Split(value, "-")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("time window must be HH:MM-HH:MM, got %q", value)
+	}
+
+	start, err := parseClock(parts[0])
+	if err != nil {
+		return nil, err
+	}
+	end, err := parseClock(parts[1])
+	if err != nil {
+		return nil, err
+	}
+
+	return &TimeWindow{Start: start, End: end}, nil
+}
+
+func parseClock(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q: %w", value, err)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// Contains reports whether t falls inside the window
+func (w *TimeWindow) Contains(t time.Time) bool {
+	offset := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+
+	if w.Start <= w.End {
+		return offset >= w.Start && offset < w.End
+	}
+	return offset >= w.Start || offset < w.End
+}
+
+// allows reports whether decision may skip the approval queue at time now.
+// Decisions without a risk score always go to a human.
+func (c AutoApproveConfig) allows(decision policy.Response, now time.Time) bool {
+	if !c.Enabled || decision.Risk == nil {
+		return false
+	}
+
+	if *decision.Risk > c.MaxRisk {
+		return false
+	}
+
+	return c.Window == nil || c.Window.Contains(now)
+}
@@ -0,0 +1,120 @@
+package proxy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/approval"
+	"github.com/dagbolade/ai-governance-sidecar/internal/audit"
+	"github.com/dagbolade/ai-governance-sidecar/internal/clock"
+	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
+	"github.com/labstack/echo/v4"
+)
+
+type countingApprovalQueue struct {
+	mockApprovalQueue
+	enqueued int
+}
+
+func (m *countingApprovalQueue) Enqueue(ctx context.Context, req policy.Request, reason string) (approval.Decision, error) {
+	m.enqueued++
+	return approval.Decision{Approved: false, Reason: "denied by approver"}, nil
+}
+
+func risk(v float64) *float64 { return &v }
+
+func TestHandleToolCall_AutoApprove(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"status":"success"}`))
+	}))
+	defer upstream.Close()
+
+	window, err := ParseTimeWindow("09:00-17:00")
+	if err != nil {
+		t.Fatalf("parse window: %v", err)
+	}
+
+	businessHours := time.Date(2025, 1, 6, 10, 0, 0, 0, time.Local)
+	afterHours := time.Date(2025, 1, 6, 20, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name          string
+		risk          *float64
+		now           time.Time
+		expectStatus  int
+		expectQueued  int
+		expectAudited string
+	}{
+		{name: "low risk auto-approved", risk: risk(0.1), now: businessHours, expectStatus: http.StatusOK, expectAudited: "auto-approved: needs review"},
+		{name: "high risk goes to human", risk: risk(0.9), now: businessHours, expectStatus: http.StatusForbidden, expectQueued: 1},
+		{name: "outside window goes to human", risk: risk(0.1), now: afterHours, expectStatus: http.StatusForbidden, expectQueued: 1},
+		{name: "missing risk goes to human", now: businessHours, expectStatus: http.StatusForbidden, expectQueued: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPolicy := &mockPolicyEvaluator{
+				response: policy.Response{Allow: true, HumanRequired: true, Reason: "needs review", Risk: tt.risk},
+			}
+			mockAudit := &mockAuditStore{}
+			queue := &countingApprovalQueue{}
+
+			config := ProxyConfig{
+				DefaultUpstream: upstream.URL,
+				Timeout:         10,
+				AutoApprove:     AutoApproveConfig{Enabled: true, MaxRisk: 0.3, Window: window},
+			}
+			handler := NewHandler(config, mockPolicy, mockAudit, queue)
+			handler.SetClock(clock.NewFake(tt.now))
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/tool/call", strings.NewReader(`{"tool_name":"test_tool","args":{}}`))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			if err := handler.HandleToolCall(c); err != nil {
+				t.Fatalf("handler failed: %v", err)
+			}
+
+			if rec.Code != tt.expectStatus {
+				t.Errorf("expected status %d, got %d", tt.expectStatus, rec.Code)
+			}
+			if queue.enqueued != tt.expectQueued {
+				t.Errorf("expected %d enqueued, got %d", tt.expectQueued, queue.enqueued)
+			}
+
+			if tt.expectAudited != "" {
+				last := mockAudit.entries[len(mockAudit.entries)-1]
+				if last.Reason != tt.expectAudited || last.Decision != audit.DecisionAutoApprove {
+					t.Errorf("expected %s audit %q, got %s %q", audit.DecisionAutoApprove, tt.expectAudited, last.Decision, last.Reason)
+				}
+			}
+		})
+	}
+}
+
+func TestTimeWindowContains(t *testing.T) {
+	overnight, err := ParseTimeWindow("22:00-06:00")
+	if err != nil {
+		t.Fatalf("parse window: %v", err)
+	}
+
+	at := func(hour int) time.Time { return time.Date(2025, 1, 6, hour, 0, 0, 0, time.Local) }
+
+	if !overnight.Contains(at(23)) || !overnight.Contains(at(3)) {
+		t.Error("expected overnight window to contain late and early hours")
+	}
+	if overnight.Contains(at(12)) {
+		t.Error("expected overnight window to exclude midday")
+	}
+
+	if _, err := ParseTimeWindow("9am-5pm"); err == nil {
+		t.Error("expected error for malformed window")
+	}
+}
@@ -0,0 +1,259 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/approval"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	maxBatchSize       = 50
+	defaultItemTimeout = 30 * time.Second
+)
+
+// BatchItemStatus is the outcome of one call in a batch
+type BatchItemStatus string
+
+const (
+	BatchDone    BatchItemStatus = "done"
+	BatchPending BatchItemStatus = "pending"
+	BatchTimeout BatchItemStatus = "timeout"
+	BatchDenied  BatchItemStatus = "denied"
+	BatchError   BatchItemStatus = "error"
+)
+
+// BatchItem is a tool call; its timeout_ms is also the item's deadline
+type BatchItem struct {
+	ToolCallRequest
+}
+
+type BatchRequest struct {
+	Calls []BatchItem `json:"calls"`
+}
+
+type BatchResult struct {
+	Index    int             `json:"index"`
+	ToolName string          `json:"tool_name"`
+	Status   BatchItemStatus `json:"status"`
+	Result   json.RawMessage `json:"result,omitempty"`
+	Error    string          `json:"error,omitempty"`
+	Warnings []string        `json:"warnings,omitempty"`
+}
+
+type BatchResponse struct {
+	Results []BatchResult `json:"results"`
+}
+
+// HandleBatch runs each call concurrently under its own timeout. The
+// response is sent once every call has finished or hit its deadline.
+func (h *Handler) HandleBatch(c echo.Context) error {
+	var batch BatchRequest
+	if err := c.Bind(&batch); err != nil {
+		return h.errorResponse(c, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+	}
+
+	if len(batch.Calls) == 0 {
+		return h.errorResponse(c, http.StatusBadRequest, "calls is required")
+	}
+	if len(batch.Calls) > maxBatchSize {
+		return h.errorResponse(c, http.StatusBadRequest, fmt.Sprintf("batch exceeds maximum of %d calls", maxBatchSize))
+	}
+	// Refused rather than ignored, so nobody mistakes a batch for a rehearsal
+	if isDryRun(c) {
+		return h.errorResponse(c, http.StatusBadRequest, HeaderDryRun+" is not supported on batch calls")
+	}
+
+	ctx := c.Request().Context()
+
+	// A batch holds one concurrent slot and is charged per call
+	release, err := h.acquireQuota(c, len(batch.Calls))
+	if err != nil {
+		log.Warn().Err(err).Int("calls", len(batch.Calls)).Msg("user quota exceeded")
+		for i := range batch.Calls {
+			if auditErr := h.logAudit(ctx, &batch.Calls[i].ToolCallRequest, quotaDenial(err)); auditErr != nil {
+				log.Warn().Err(auditErr).Msg("audit logging failed")
+			}
+		}
+		return h.errorResponse(c, http.StatusTooManyRequests, err.Error())
+	}
+	defer release()
+
+	header := c.Request().Header
+	results := make([]BatchResult, len(batch.Calls))
+
+	var wg sync.WaitGroup
+	for i := range batch.Calls {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = h.runBatchItem(ctx, i, &batch.Calls[i], header)
+		}(i)
+	}
+	wg.Wait()
+
+	return c.JSON(http.StatusOK, BatchResponse{Results: results})
+}
+
+func (h *Handler) runBatchItem(parent context.Context, index int, item *BatchItem, header http.Header) BatchResult {
+	result := BatchResult{Index: index, ToolName: item.ToolName}
+
+	req := &item.ToolCallRequest
+	req.Files = nil
+	if err := h.normalizeRequest(parent, req, header); err != nil {
+		return result.fail(BatchError, err.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(parent, h.itemTimeout(req))
+	defer cancel()
+
+	if err := h.checkMetadata(req); err != nil {
+		if auditErr := h.logAudit(ctx, req, metadataDenial(err)); auditErr != nil {
+			log.Warn().Err(auditErr).Msg("audit logging failed")
+		}
+		return result.fail(BatchDenied, err.Error())
+	}
+
+	if err := h.checkUpstream(req); err != nil {
+		if auditErr := h.logAudit(ctx, req, upstreamDenial(err)); auditErr != nil {
+			log.Warn().Err(auditErr).Msg("audit logging failed")
+		}
+		return result.fail(BatchDenied, err.Error())
+	}
+
+	decision, err := h.evaluateGated(ctx, req)
+	if err != nil {
+		return result.fail(BatchError, "policy evaluation failed")
+	}
+	decision = h.applyPatterns(req, decision)
+	decision = h.applyTimeWindows(req, decision)
+	result.Warnings = decision.Warnings
+
+	if err := h.checkJustification(req, decision); err != nil {
+		if auditErr := h.logAudit(ctx, req, justificationDenial(err)); auditErr != nil {
+			log.Warn().Err(auditErr).Msg("audit logging failed")
+		}
+		return result.fail(BatchDenied, err.Error())
+	}
+
+	if err := h.auditPolicyDecision(ctx, req, decision); err != nil {
+		log.Warn().Err(err).Msg("audit logging failed")
+	}
+
+	if !decision.Allow {
+		return result.fail(BatchDenied, decision.Reason)
+	}
+
+	if decision.HumanRequired {
+		if h.config.AutoApprove.allows(decision, h.now()) {
+			h.recordAutoApproval(ctx, req, decision)
+		} else if status, reason := h.awaitBatchApproval(ctx, req, decision.Reason); status != "" {
+			return result.fail(status, reason)
+		}
+	}
+
+	output, err := h.forward(ctx, req)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return result.fail(BatchTimeout, "upstream did not respond in time")
+		}
+		log.Error().Err(err).Str("upstream", req.Upstream).Msg("batch forward failed")
+		return result.fail(BatchError, "upstream request failed")
+	}
+
+	output, denial := h.checkEgress(ctx, req, output)
+	if denial != nil {
+		return result.fail(BatchDenied, denial.Reason)
+	}
+
+	result.Status = BatchDone
+	result.Result = output
+	return result
+}
+
+type approvalOutcome struct {
+	decision approval.Decision
+	err      error
+}
+
+// awaitBatchApproval returns an empty status when the call was approved.
+// If the item deadline passes first the request stays queued; an eventual
+// decision is audited, and an approval becomes a grant for the caller's
+// retry, but the call is never forwarded since nobody waits for its result.
+func (h *Handler) awaitBatchApproval(ctx context.Context, req *ToolCallRequest, reason string) (BatchItemStatus, string) {
+	if h.useGrant(ctx, req) {
+		return "", ""
+	}
+
+	start := h.now()
+	outcomeCh := make(chan approvalOutcome, 1)
+	go func() {
+		decision, err := h.approval.Enqueue(context.WithoutCancel(ctx), req.ToPolicyRequest(), reason)
+		outcomeCh <- approvalOutcome{decision: decision, err: err}
+	}()
+
+	select {
+	case outcome := <-outcomeCh:
+		switch {
+		case outcome.err != nil:
+			return BatchError, "approval queue error"
+		case outcome.decision.TimedOut:
+			h.logApprovalTimeout(ctx, req, start)
+			return BatchTimeout, outcome.decision.Reason
+		}
+		h.logApprovalDecision(ctx, req, outcome.decision, start, approvalReason(outcome.decision))
+		if !outcome.decision.Approved {
+			return BatchDenied, outcome.decision.Reason
+		}
+		h.recordGrant(ctx, req, outcome.decision)
+		return "", ""
+	case <-ctx.Done():
+		go h.recordLateApproval(context.WithoutCancel(ctx), outcomeCh, req, start)
+		return BatchPending, "awaiting human approval"
+	}
+}
+
+// recordLateApproval audits a decision made after the item's deadline.
+// ctx keeps the caller's identity so the audit entry and any grant are
+// theirs.
+func (h *Handler) recordLateApproval(ctx context.Context, outcomeCh <-chan approvalOutcome, req *ToolCallRequest, start time.Time) {
+	outcome := <-outcomeCh
+	if outcome.err != nil {
+		return
+	}
+	if outcome.decision.TimedOut {
+		h.logApprovalTimeout(ctx, req, start)
+		return
+	}
+
+	h.logApprovalDecision(ctx, req, outcome.decision, start, approvalReason(outcome.decision))
+	if !outcome.decision.Approved {
+		return
+	}
+
+	h.recordGrant(ctx, req, outcome.decision)
+	log.Info().Str("tool", req.ToolName).Msg("late batch approval recorded; the call was not forwarded")
+}
+
+// itemTimeout is a call's deadline within a batch: its timeout_ms capped
+// by UPSTREAM_MAX_TIMEOUT, as for a single call, or the upstream timeout
+func (h *Handler) itemTimeout(req *ToolCallRequest) time.Duration {
+	def := defaultItemTimeout
+	if h.config.Timeout > 0 {
+		def = time.Duration(h.config.Timeout) * time.Second
+	}
+	return requestTimeout(req, def, h.forwarder.maxTimeout)
+}
+
+func (r BatchResult) fail(status BatchItemStatus, reason string) BatchResult {
+	r.Status = status
+	r.Error = reason
+	return r
+}
@@ -0,0 +1,299 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/approval"
+	"github.com/dagbolade/ai-governance-sidecar/internal/audit"
+	"github.com/dagbolade/ai-governance-sidecar/internal/auth"
+	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
+	"github.com/labstack/echo/v4"
+)
+
+// toolPolicyEvaluator returns a fixed response per tool name
+type toolPolicyEvaluator struct {
+	mockPolicyEvaluator
+	responses map[string]policy.Response
+}
+
+func (m *toolPolicyEvaluator) Evaluate(ctx context.Context, req policy.Request) (policy.Response, error) {
+	if resp, ok := m.responses[req.ToolName]; ok {
+		return resp, nil
+	}
+	return policy.Response{Allow: true, Reason: "default allow"}, nil
+}
+
+// lockedAuditStore is safe for the concurrent writes a batch produces
+type lockedAuditStore struct {
+	mu sync.Mutex
+	mockAuditStore
+}
+
+func (m *lockedAuditStore) Log(ctx context.Context, toolInput json.RawMessage, decision audit.Decision, reason string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.mockAuditStore.Log(ctx, toolInput, decision, reason)
+}
+
+// blockingApprovalQueue holds every request until release is closed
+type blockingApprovalQueue struct {
+	mockApprovalQueue
+	release chan struct{}
+}
+
+func (m *blockingApprovalQueue) Enqueue(ctx context.Context, req policy.Request, reason string) (approval.Decision, error) {
+	<-m.release
+	return approval.Decision{Approved: false, Reason: "released"}, nil
+}
+
+func TestHandleBatch_MixedResults(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			select {
+			case <-time.After(time.Second):
+			case <-r.Context().Done():
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"status":"success"}`))
+	}))
+	defer upstream.Close()
+
+	mockPolicy := &toolPolicyEvaluator{responses: map[string]policy.Response{
+		"blocked_tool": {Allow: false, Reason: "blocked by policy"},
+		"review_tool":  {Allow: true, HumanRequired: true, Reason: "needs review"},
+	}}
+	mockAudit := &lockedAuditStore{}
+	queue := &blockingApprovalQueue{release: make(chan struct{})}
+	defer close(queue.release)
+
+	handler := NewHandler(ProxyConfig{DefaultUpstream: upstream.URL, Timeout: 10}, mockPolicy, mockAudit, queue)
+
+	body := `{"calls":[
+		{"tool_name":"fast_tool","args":{}},
+		{"tool_name":"slow_tool","args":{},"upstream":"` + upstream.URL + `/slow","timeout_ms":100},
+		{"tool_name":"blocked_tool","args":{}},
+		{"tool_name":"review_tool","args":{},"timeout_ms":100}
+	]}`
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/tool/call/batch", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	start := time.Now()
+	if err := handler.HandleBatch(c); err != nil {
+		t.Fatalf("handler failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("batch waited for slow items: took %v", elapsed)
+	}
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+
+	var resp BatchResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+
+	want := []BatchItemStatus{BatchDone, BatchTimeout, BatchDenied, BatchPending}
+	if len(resp.Results) != len(want) {
+		t.Fatalf("expected %d results, got %d", len(want), len(resp.Results))
+	}
+	for i, status := range want {
+		if resp.Results[i].Index != i {
+			t.Errorf("result %d: expected index %d, got %d", i, i, resp.Results[i].Index)
+		}
+		if resp.Results[i].Status != status {
+			t.Errorf("result %d: expected status %s, got %s (%s)", i, status, resp.Results[i].Status, resp.Results[i].Error)
+		}
+	}
+
+	if string(resp.Results[0].Result) != `{"status":"success"}` {
+		t.Errorf("expected upstream result for fast call, got %s", resp.Results[0].Result)
+	}
+	if resp.Results[2].Error != "blocked by policy" {
+		t.Errorf("expected policy reason, got %q", resp.Results[2].Error)
+	}
+}
+
+func TestHandleBatch_InvalidRequests(t *testing.T) {
+	handler := NewHandler(ProxyConfig{DefaultUpstream: "http://localhost:9000"}, &mockPolicyEvaluator{}, &mockAuditStore{}, &mockApprovalQueue{})
+
+	calls := make([]string, maxBatchSize+1)
+	for i := range calls {
+		calls[i] = `{"tool_name":"t"}`
+	}
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"empty", `{"calls":[]}`},
+		{"too large", `{"calls":[` + strings.Join(calls, ",") + `]}`},
+		{"malformed", `{"calls":`},
+	}
+
+	e := echo.New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/tool/call/batch", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+
+			if err := handler.HandleBatch(e.NewContext(req, rec)); err != nil {
+				t.Fatalf("handler failed: %v", err)
+			}
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("expected status 400, got %d", rec.Code)
+			}
+		})
+	}
+}
+
+func TestHandleBatch_RejectsDryRun(t *testing.T) {
+	forwarded := false
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		forwarded = true
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer upstream.Close()
+
+	mockAudit := &mockAuditStore{}
+	handler := NewHandler(ProxyConfig{DefaultUpstream: upstream.URL, Timeout: 5}, &mockPolicyEvaluator{response: policy.Response{Allow: true}}, mockAudit, &mockApprovalQueue{})
+
+	req := httptest.NewRequest(http.MethodPost, "/tool/call/batch", strings.NewReader(`{"calls":[{"tool_name":"t","args":{}}]}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(HeaderDryRun, "true")
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+	c.Set("user", &auth.User{ID: "u", Roles: []string{auth.RoleAdmin}})
+
+	if err := handler.HandleBatch(c); err != nil {
+		t.Fatalf("handler failed: %v", err)
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", rec.Code)
+	}
+	if forwarded || len(mockAudit.entries) != 0 {
+		t.Errorf("expected nothing forwarded or audited, got forwarded=%v and %d entries", forwarded, len(mockAudit.entries))
+	}
+}
+
+func TestBatchItemTimeout(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     ProxyConfig
+		timeoutMs  int
+		expectWait time.Duration
+	}{
+		{name: "upstream timeout by default", config: ProxyConfig{Timeout: 10}, expectWait: 10 * time.Second},
+		{name: "fallback without upstream timeout", expectWait: defaultItemTimeout},
+		{name: "requested", config: ProxyConfig{Timeout: 10}, timeoutMs: 500, expectWait: 500 * time.Millisecond},
+		{name: "capped by max timeout", config: ProxyConfig{Timeout: 10, MaxTimeout: 20}, timeoutMs: 60000, expectWait: 20 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewHandler(tt.config, &mockPolicyEvaluator{}, &mockAuditStore{}, &mockApprovalQueue{})
+			if got := handler.itemTimeout(&ToolCallRequest{TimeoutMs: tt.timeoutMs}); got != tt.expectWait {
+				t.Errorf("expected %v, got %v", tt.expectWait, got)
+			}
+		})
+	}
+}
+
+func TestHandleBatch_RejectsNegativeTimeout(t *testing.T) {
+	handler := NewHandler(ProxyConfig{DefaultUpstream: "http://localhost:9000"}, &mockPolicyEvaluator{}, &lockedAuditStore{}, &mockApprovalQueue{})
+
+	req := httptest.NewRequest(http.MethodPost, "/tool/call/batch", strings.NewReader(`{"calls":[{"tool_name":"t","timeout_ms":-1}]}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+
+	if err := handler.HandleBatch(echo.New().NewContext(req, rec)); err != nil {
+		t.Fatalf("handler failed: %v", err)
+	}
+
+	var resp BatchResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || len(resp.Results) != 1 {
+		t.Fatalf("unexpected response %s: %v", rec.Body.String(), err)
+	}
+	if resp.Results[0].Status != BatchError || !strings.Contains(resp.Results[0].Error, "timeout_ms") {
+		t.Errorf("expected the negative timeout to be rejected, got %+v", resp.Results[0])
+	}
+}
+
+// approvingQueue approves every request once release is closed
+type approvingQueue struct {
+	mockApprovalQueue
+	release chan struct{}
+}
+
+func (m *approvingQueue) Enqueue(ctx context.Context, req policy.Request, reason string) (approval.Decision, error) {
+	<-m.release
+	return approval.Decision{Approved: true, DecidedBy: "alice"}, nil
+}
+
+func TestHandleBatch_LateApprovalIsAuditedNotForwarded(t *testing.T) {
+	var mu sync.Mutex
+	forwarded := 0
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		forwarded++
+		mu.Unlock()
+		w.Write([]byte(`{"status":"success"}`))
+	}))
+	defer upstream.Close()
+
+	mockPolicy := &toolPolicyEvaluator{responses: map[string]policy.Response{
+		"review_tool": {Allow: true, HumanRequired: true, Reason: "needs review"},
+	}}
+	mockAudit := &lockedAuditStore{}
+	queue := &approvingQueue{release: make(chan struct{})}
+	handler := NewHandler(ProxyConfig{DefaultUpstream: upstream.URL, Timeout: 10}, mockPolicy, mockAudit, queue)
+
+	req := httptest.NewRequest(http.MethodPost, "/tool/call/batch", strings.NewReader(`{"calls":[{"tool_name":"review_tool","args":{},"timeout_ms":50}]}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	if err := handler.HandleBatch(echo.New().NewContext(req, rec)); err != nil {
+		t.Fatalf("handler failed: %v", err)
+	}
+	if !strings.Contains(rec.Body.String(), string(BatchPending)) {
+		t.Fatalf("expected the call to be pending, got %s", rec.Body.String())
+	}
+
+	close(queue.release)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		mockAudit.mu.Lock()
+		var approved bool
+		for _, entry := range mockAudit.entries {
+			approved = approved || (entry.Decision == audit.DecisionAllow && strings.Contains(entry.Reason, "alice"))
+		}
+		mockAudit.mu.Unlock()
+		if approved {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the late approval to be audited")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if forwarded != 0 {
+		t.Errorf("expected the late approval not to forward the call, got %d upstream requests", forwarded)
+	}
+}
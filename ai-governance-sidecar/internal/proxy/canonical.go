@@ -0,0 +1,53 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/auth"
+)
+
+// canonicalJSON re-encodes a JSON document with object keys sorted and
+// insignificant whitespace removed, so equivalent args compare and hash the
+// same. Numbers keep their original text.
+func canonicalJSON(raw json.RawMessage) (json.RawMessage, error) {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("unexpected data after JSON value")
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+
+	return json.RawMessage(bytes.TrimSuffix(buf.Bytes(), []byte("\n"))), nil
+}
+
+// DecisionKey identifies a call by the authenticated caller and tenant on
+// ctx, tool, upstream, method, canonical args, metadata and files.
+// Requests that differ only in key order or whitespace share a key.
+func (r *ToolCallRequest) DecisionKey(ctx context.Context) string {
+	caller, tenant := callerIdentity(ctx)
+	return r.ToPolicyRequest().DecisionKey(caller, tenant)
+}
+
+// callerIdentity returns the authenticated user's id and tenant, both empty
+// for anonymous calls
+func callerIdentity(ctx context.Context) (string, string) {
+	user, ok := auth.GetUserFromStdContext(ctx)
+	if !ok || user == nil {
+		return "", ""
+	}
+	return user.ID, user.Tenant
+}
@@ -0,0 +1,71 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
+)
+
+func TestCanonicalJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		expect string
+	}{
+		{name: "sorted keys", input: `{"b":1,"a":2}`, expect: `{"a":2,"b":1}`},
+		{name: "nested and whitespace", input: "{ \"z\": {\"y\": [3, {\"d\":1,\"c\":2}]},\n \"x\": \"<tag>\" }", expect: `{"x":"<tag>","z":{"y":[3,{"c":2,"d":1}]}}`},
+		{name: "numbers keep their text", input: `{"big":12345678901234567890,"f":1.50}`, expect: `{"big":12345678901234567890,"f":1.50}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := canonicalJSON(json.RawMessage(tt.input))
+			if err != nil {
+				t.Fatalf("canonicalize: %v", err)
+			}
+			if string(got) != tt.expect {
+				t.Errorf("expected %s, got %s", tt.expect, got)
+			}
+		})
+	}
+}
+
+func TestDecisionKeyIgnoresKeyOrder(t *testing.T) {
+	ctx := context.Background()
+	a := &ToolCallRequest{ToolName: "send_email", Method: http.MethodPost, Args: json.RawMessage(`{"to":"a@example.com","opts":{"cc":true,"bcc":false}}`)}
+	b := &ToolCallRequest{ToolName: "send_email", Method: http.MethodPost, Args: json.RawMessage(`{ "opts": {"bcc": false, "cc": true}, "to": "a@example.com" }`)}
+
+	if a.DecisionKey(ctx) != b.DecisionKey(ctx) {
+		t.Error("expected key-reordered requests to share a decision key")
+	}
+
+	c := &ToolCallRequest{ToolName: "send_email", Method: http.MethodPost, Args: json.RawMessage(`{"to":"b@example.com","opts":{"cc":true,"bcc":false}}`)}
+	if a.DecisionKey(ctx) == c.DecisionKey(ctx) {
+		t.Error("expected different args to produce different keys")
+	}
+
+	d := &ToolCallRequest{ToolName: "send_email", Method: http.MethodPost, Args: a.Args, Metadata: map[string]any{"cost_center": "eng"}}
+	if a.DecisionKey(ctx) == d.DecisionKey(ctx) {
+		t.Error("expected different metadata to produce different keys")
+	}
+
+	e := &ToolCallRequest{ToolName: "send_email", Method: http.MethodPost, Args: a.Args, Files: []policy.FileInfo{{Field: "attachment", Name: "a.pdf", Size: 10}}}
+	if a.DecisionKey(ctx) == e.DecisionKey(ctx) {
+		t.Error("expected different files to produce different keys")
+	}
+}
+
+func TestNormalizeRequestCanonicalizesArgs(t *testing.T) {
+	h := NewHandler(ProxyConfig{DefaultUpstream: "http://localhost:9000"}, &mockPolicyEvaluator{}, &mockAuditStore{}, &mockApprovalQueue{})
+
+	req := &ToolCallRequest{ToolName: "t", Args: json.RawMessage(`{"b": 1, "a": {"d": 2, "c": 3}}`)}
+	if err := h.normalizeRequest(context.Background(), req, http.Header{}); err != nil {
+		t.Fatalf("normalize: %v", err)
+	}
+	if string(req.Args) != `{"a":{"c":3,"d":2},"b":1}` {
+		t.Errorf("expected canonical args, got %s", req.Args)
+	}
+}
@@ -0,0 +1,51 @@
+package proxy
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
+	"github.com/labstack/echo/v4"
+)
+
+// HandleDebugEvaluate parses a tool call exactly as HandleToolCall would and
+// returns the full evaluation trace. Nothing is audited, queued or forwarded,
+// and policy counters are read but not incremented.
+func (h *Handler) HandleDebugEvaluate(c echo.Context) error {
+	req, err := h.parseRequest(c)
+	if err != nil {
+		return h.errorResponse(c, parseErrorStatus(err), err.Error())
+	}
+
+	policyReq := req.ToPolicyRequest()
+
+	tracer, ok := h.policy.(policy.Tracer)
+	if !ok {
+		return h.evaluateWithoutTrace(c, policyReq)
+	}
+
+	trace, err := tracer.EvaluateTrace(policy.WithReadOnlyCounters(c.Request().Context()), policyReq)
+	if err != nil {
+		return h.errorResponse(c, http.StatusInternalServerError, "policy evaluation failed")
+	}
+
+	return c.JSON(http.StatusOK, trace)
+}
+
+// evaluateWithoutTrace reports only the final decision for evaluators that
+// cannot explain individual policies.
+func (h *Handler) evaluateWithoutTrace(c echo.Context, req policy.Request) error {
+	start := time.Now()
+
+	decision, err := h.policy.Evaluate(policy.WithReadOnlyCounters(c.Request().Context()), req)
+	if err != nil {
+		return h.errorResponse(c, http.StatusInternalServerError, "policy evaluation failed")
+	}
+
+	return c.JSON(http.StatusOK, policy.Trace{
+		Input:      req.Input(policy.CurrentInputVersion),
+		Policies:   []policy.PolicyVerdict{},
+		Decision:   decision,
+		DurationUS: time.Since(start).Microseconds(),
+	})
+}
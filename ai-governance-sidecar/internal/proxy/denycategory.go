@@ -0,0 +1,54 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/audit"
+	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
+	"github.com/rs/zerolog/log"
+)
+
+// denyCategory classifies a denied decision for the audit log. A policy
+// may name its own category; the sidecar's built-in checks are recognized
+// by the policy name they deny under.
+func denyCategory(decision policy.Response) audit.DenyCategory {
+	if decision.DenyCategory != "" {
+		return audit.DenyCategory(decision.DenyCategory)
+	}
+
+	switch name := decision.Policy; {
+	case name == quotaPolicy:
+		return audit.DenyRateLimit
+	case name == upstreamGuardPolicy:
+		return audit.DenySSRFBlock
+	case name == requiredMetadataPolicy, name == justificationPolicy:
+		return audit.DenySchemaViolation
+	case strings.HasPrefix(name, toolNamePatternPrefix):
+		return audit.DenyToolBlocked
+	case strings.HasPrefix(name, sensitivePatternPrefix):
+		return audit.DenySensitiveData
+	case strings.HasPrefix(name, timeWindowPrefix):
+		return audit.DenyTimeWindow
+	default:
+		return audit.DenyPolicy
+	}
+}
+
+// logApprovalTimeout audits a call denied because nobody decided its
+// approval in time
+func (h *Handler) logApprovalTimeout(ctx context.Context, req *ToolCallRequest, start time.Time) {
+	toolInput, err := json.Marshal(req)
+	if err != nil {
+		log.Warn().Err(err).Msg("audit logging failed")
+		return
+	}
+
+	ctx = audit.WithDenyCategory(withAuditActor(ctx), audit.DenyApprovalTimeout)
+	reason := "approval timed out after " + h.now().Sub(start).Round(time.Second).String()
+	if err := h.audit.Log(ctx, toolInput, audit.DecisionDeny, reason); err != nil {
+		log.Warn().Err(err).Msg("audit logging failed")
+	}
+}
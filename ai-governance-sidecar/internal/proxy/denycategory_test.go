@@ -0,0 +1,128 @@
+package proxy
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/approval"
+	"github.com/dagbolade/ai-governance-sidecar/internal/audit"
+	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
+	"github.com/labstack/echo/v4"
+)
+
+func TestDenyCategory(t *testing.T) {
+	err := errors.New("rejected")
+
+	tests := []struct {
+		name     string
+		decision policy.Response
+		expect   audit.DenyCategory
+	}{
+		{name: "policy", decision: policy.Response{Policy: "sensitive_data"}, expect: audit.DenyPolicy},
+		{name: "policy category", decision: policy.Response{Policy: "pii", DenyCategory: "pii_leak"}, expect: "pii_leak"},
+		{name: "quota", decision: quotaDenial(err), expect: audit.DenyRateLimit},
+		{name: "upstream", decision: upstreamDenial(err), expect: audit.DenySSRFBlock},
+		{name: "metadata", decision: metadataDenial(err), expect: audit.DenySchemaViolation},
+		{name: "justification", decision: justificationDenial(err), expect: audit.DenySchemaViolation},
+		{name: "tool name", decision: policy.Response{Policy: toolNamePatternPrefix + "shell"}, expect: audit.DenyToolBlocked},
+		{name: "sensitive pattern", decision: policy.Response{Policy: sensitivePatternPrefix + "ssn"}, expect: audit.DenySensitiveData},
+		{name: "time window", decision: policy.Response{Policy: timeWindowPrefix + "freeze"}, expect: audit.DenyTimeWindow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := denyCategory(tt.decision); got != tt.expect {
+				t.Errorf("expected %q, got %q", tt.expect, got)
+			}
+		})
+	}
+}
+
+func TestHandleToolCall_RecordsDenyCategory(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   ProxyConfig
+		response policy.Response
+		decision approval.Decision
+		body     string
+		expect   audit.DenyCategory
+	}{
+		{
+			name:     "policy deny",
+			response: policy.Response{Allow: false, Reason: "blocked"},
+			expect:   audit.DenyPolicy,
+		},
+		{
+			name:     "policy names its category",
+			response: policy.Response{Allow: false, Reason: "blocked", DenyCategory: "pii_leak"},
+			expect:   "pii_leak",
+		},
+		{
+			name:     "missing metadata",
+			config:   ProxyConfig{RequiredMetadata: []string{"cost_center"}},
+			response: policy.Response{Allow: true},
+			expect:   audit.DenySchemaViolation,
+		},
+		{
+			name:     "blocked upstream",
+			config:   ProxyConfig{UpstreamAllowlist: []string{"tools.internal"}},
+			response: policy.Response{Allow: true},
+			body:     `{"tool_name":"deploy","args":{},"upstream":"http://169.254.169.254/latest"}`,
+			expect:   audit.DenySSRFBlock,
+		},
+		{
+			name:     "blocked tool name",
+			config:   ProxyConfig{ToolNamePatterns: testToolNamePatterns},
+			response: policy.Response{Allow: true},
+			body:     `{"tool_name":"exec","args":{}}`,
+			expect:   audit.DenyToolBlocked,
+		},
+		{
+			name:     "approval rejected",
+			response: policy.Response{Allow: true, HumanRequired: true},
+			decision: approval.Decision{Approved: false, DecidedBy: "alice"},
+			expect:   audit.DenyApprovalRejected,
+		},
+		{
+			name:     "approval timeout",
+			response: policy.Response{Allow: true, HumanRequired: true},
+			decision: approval.Decision{TimedOut: true},
+			expect:   audit.DenyApprovalTimeout,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAudit := &mockAuditStore{}
+			config := tt.config
+			config.DefaultUpstream = "http://localhost:9000"
+			handler := NewHandler(config, &mockPolicyEvaluator{response: tt.response}, mockAudit, &recordingApprovalQueue{decision: tt.decision})
+
+			body := tt.body
+			if body == "" {
+				body = `{"tool_name":"deploy","args":{}}`
+			}
+			req := httptest.NewRequest(http.MethodPost, "/tool/call", strings.NewReader(body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+
+			if err := handler.HandleToolCall(echo.New().NewContext(req, rec)); err != nil {
+				t.Fatalf("handler failed: %v", err)
+			}
+			if rec.Code == http.StatusOK {
+				t.Fatalf("expected the call to be refused")
+			}
+
+			if len(mockAudit.entries) == 0 {
+				t.Fatal("expected the denial to be audited")
+			}
+			last := mockAudit.entries[len(mockAudit.entries)-1]
+			if last.Decision != audit.DecisionDeny || last.DenyCategory != tt.expect {
+				t.Errorf("expected deny with category %q, got %s %q (%s)", tt.expect, last.Decision, last.DenyCategory, last.Reason)
+			}
+		})
+	}
+}
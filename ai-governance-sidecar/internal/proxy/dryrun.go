@@ -0,0 +1,71 @@
+package proxy
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/audit"
+	"github.com/dagbolade/ai-governance-sidecar/internal/auth"
+	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// HeaderDryRun asks for a policy decision without forwarding or queueing
+const HeaderDryRun = "X-Dry-Run"
+
+// dryRunTag prefixes the audit reason of dry-run evaluations
+const dryRunTag = "dry_run"
+
+// DryRunResponse is the decision a call would have received
+type DryRunResponse struct {
+	DryRun   bool            `json:"dry_run"`
+	Decision policy.Response `json:"decision"`
+	Policy   string          `json:"policy,omitempty"`
+}
+
+// isDryRun reports whether the caller asked for a dry run and may have one.
+// The header is ignored for callers without the admin or approver role, who
+// get a normal request.
+func isDryRun(c echo.Context) bool {
+	requested, err := strconv.ParseBool(c.Request().Header.Get(HeaderDryRun))
+	if err != nil || !requested {
+		return false
+	}
+
+	user := auth.GetUserFromContext(c)
+	if user == nil {
+		return false
+	}
+	for _, role := range user.Roles {
+		if role == auth.RoleAdmin || role == auth.RoleApprover {
+			return true
+		}
+	}
+	return false
+}
+
+// handleDryRun audits the decision as a dry run and returns it. Nothing is
+// queued for approval or forwarded.
+func (h *Handler) handleDryRun(ctx context.Context, c echo.Context, req *ToolCallRequest, decision policy.Response) error {
+	ctx = audit.WithDryRun(ctx)
+
+	if err := h.checkJustification(req, decision); err != nil {
+		decision = justificationDenial(err)
+	}
+
+	audited := decision
+	audited.Reason = dryRunTag + ": " + decision.Reason
+	if err := h.logAudit(ctx, req, audited); err != nil {
+		log.Warn().Err(err).Msg("audit logging failed")
+	}
+
+	log.Info().Str("tool", req.ToolName).Bool("allow", decision.Allow).Msg("dry-run evaluated")
+
+	return c.JSON(http.StatusOK, DryRunResponse{
+		DryRun:   true,
+		Decision: decision,
+		Policy:   decision.Policy,
+	})
+}
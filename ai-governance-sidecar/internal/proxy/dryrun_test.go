@@ -0,0 +1,81 @@
+package proxy
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/auth"
+	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
+	"github.com/labstack/echo/v4"
+)
+
+func TestHandleToolCall_DryRun(t *testing.T) {
+	tests := []struct {
+		name          string
+		roles         []string
+		expectDryRun  bool
+		expectForward bool
+	}{
+		{name: "admin gets dry run", roles: []string{auth.RoleAdmin}, expectDryRun: true},
+		{name: "approver gets dry run", roles: []string{auth.RoleApprover}, expectDryRun: true},
+		{name: "viewer header ignored", roles: []string{auth.RoleViewer}, expectForward: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forwarded := false
+			upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				forwarded = true
+				w.Write([]byte(`{"ok":true}`))
+			}))
+			defer upstream.Close()
+
+			mockAudit := &mockAuditStore{}
+			queue := &recordingApprovalQueue{}
+			evaluator := &mockPolicyEvaluator{response: policy.Response{Allow: true, HumanRequired: true, Reason: "needs review"}}
+			handler := NewHandler(ProxyConfig{DefaultUpstream: upstream.URL, Timeout: 5}, evaluator, mockAudit, queue)
+			queue.decision.Approved = true
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/tool/call", strings.NewReader(`{"tool_name":"deploy","args":{}}`))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(HeaderDryRun, "true")
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.Set("user", &auth.User{ID: "u", Roles: tt.roles})
+
+			if err := handler.HandleToolCall(c); err != nil {
+				t.Fatalf("handler failed: %v", err)
+			}
+
+			if forwarded != tt.expectForward {
+				t.Errorf("expected forwarded=%v, got %v", tt.expectForward, forwarded)
+			}
+
+			if !tt.expectDryRun {
+				if len(queue.reasons) != 1 {
+					t.Errorf("expected a normal request to be queued, got %d enqueues", len(queue.reasons))
+				}
+				return
+			}
+
+			if len(queue.reasons) != 0 {
+				t.Errorf("expected dry run to skip approval, got %d enqueues", len(queue.reasons))
+			}
+
+			var resp DryRunResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if !resp.DryRun || !resp.Decision.HumanRequired || resp.Decision.Reason != "needs review" {
+				t.Errorf("expected the would-be decision, got %+v", resp)
+			}
+			if len(mockAudit.entries) != 1 || !mockAudit.entries[0].DryRun || !strings.HasPrefix(mockAudit.entries[0].Reason, "dry_run: ") {
+				t.Errorf("expected one dry-run audit entry, got %+v", mockAudit.entries)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/audit"
+	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
+	"github.com/rs/zerolog/log"
+)
+
+// checkEgress runs an upstream result through the egress policies before
+// it reaches the caller. It returns the result with any fields the
+// policies asked to hide masked, or the denial when a policy blocks it.
+// The egress decision is audited as its own entry. Without egress
+// policies the result is returned untouched and nothing is audited.
+func (h *Handler) checkEgress(ctx context.Context, req *ToolCallRequest, result json.RawMessage) (json.RawMessage, *policy.Response) {
+	evaluator, ok := h.policy.(policy.ResponseEvaluator)
+	if !ok {
+		return result, nil
+	}
+
+	input := req.ToPolicyRequest()
+	input.Response = result
+
+	evalCtx, cancel := context.WithTimeout(withAuditActor(ctx), 5*time.Second)
+	defer cancel()
+
+	decision, err := evaluator.EvaluateResponse(evalCtx, input)
+	if errors.Is(err, policy.ErrNoEgressPolicies) {
+		return result, nil
+	}
+	if err != nil {
+		// Fail closed: an unchecked response may be exactly what the
+		// policies exist to stop
+		log.Error().Err(err).Str("tool", req.ToolName).Msg("egress policy evaluation failed")
+		decision = policy.Response{Allow: false, Reason: "egress policy evaluation failed"}
+	}
+
+	if !decision.Allow {
+		log.Warn().Str("tool", req.ToolName).Str("policy", decision.Policy).Str("reason", decision.Reason).Msg("upstream response blocked")
+		h.logEgress(ctx, req, decision, "egress denied: "+auditReason(decision))
+		return nil, &decision
+	}
+
+	filtered, redacted := policy.RedactResponse(result, decision.Redact)
+	if len(redacted) == 0 {
+		h.logEgress(ctx, req, decision, "egress allowed: "+auditReason(decision))
+		return result, nil
+	}
+
+	log.Info().Str("tool", req.ToolName).Strs("fields", redacted).Msg("upstream response redacted")
+	h.logEgress(ctx, req, decision, "egress redacted "+strings.Join(redacted, ", ")+": "+auditReason(decision))
+	return filtered, nil
+}
+
+// checkRawEgress applies checkEgress to an upstream body relayed verbatim.
+// A body that is not JSON is shown to policies as a JSON string; it can be
+// blocked but not redacted.
+func (h *Handler) checkRawEgress(ctx context.Context, req *ToolCallRequest, body []byte) ([]byte, *policy.Response) {
+	if json.Valid(body) {
+		return h.checkEgress(ctx, req, body)
+	}
+
+	quoted, err := json.Marshal(string(body))
+	if err != nil {
+		return nil, &policy.Response{Allow: false, Reason: "egress policy evaluation failed"}
+	}
+	if _, denial := h.checkEgress(ctx, req, quoted); denial != nil {
+		return nil, denial
+	}
+	return body, nil
+}
+
+// logEgress audits the egress decision for req. The response itself is
+// not stored, only the call that produced it.
+func (h *Handler) logEgress(ctx context.Context, req *ToolCallRequest, decision policy.Response, reason string) {
+	toolInput, err := json.Marshal(req)
+	if err != nil {
+		log.Warn().Err(err).Msg("audit logging failed")
+		return
+	}
+
+	auditDecision := audit.DecisionAllow
+	ctx = withAuditActor(ctx)
+	if !decision.Allow {
+		auditDecision = audit.DecisionDeny
+		ctx = audit.WithDenyCategory(ctx, denyCategory(decision))
+	}
+
+	reason = TruncateReason(reason, h.config.MaxReasonLength)
+	if err := h.audit.Log(ctx, toolInput, auditDecision, reason); err != nil {
+		log.Warn().Err(err).Msg("audit logging failed")
+	}
+}
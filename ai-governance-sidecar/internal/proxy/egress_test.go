@@ -0,0 +1,111 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/audit"
+	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
+	"github.com/labstack/echo/v4"
+)
+
+// egressPolicyEvaluator allows every request and answers response checks
+// with egress, recording the response it was shown
+type egressPolicyEvaluator struct {
+	mockPolicyEvaluator
+	egress policy.Response
+	seen   json.RawMessage
+}
+
+func (m *egressPolicyEvaluator) EvaluateResponse(ctx context.Context, req policy.Request) (policy.Response, error) {
+	m.seen = req.Response
+	return m.egress, nil
+}
+
+func callWithEgress(t *testing.T, egress policy.Response, format string) (*httptest.ResponseRecorder, *mockAuditStore, *egressPolicyEvaluator) {
+	t.Helper()
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		w.Write([]byte(`{"name":"Ada","ssn":"078-05-1120"}`))
+	}))
+	t.Cleanup(upstream.Close)
+
+	evaluator := &egressPolicyEvaluator{
+		mockPolicyEvaluator: mockPolicyEvaluator{response: policy.Response{Allow: true, Reason: "approved"}},
+		egress:              egress,
+	}
+	store := &mockAuditStore{}
+	handler := NewHandler(ProxyConfig{DefaultUpstream: upstream.URL, Timeout: 10}, evaluator, store, &mockApprovalQueue{})
+
+	req := httptest.NewRequest(http.MethodPost, "/tool/call", strings.NewReader(`{"tool_name":"get_customer","args":{"id":7}}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if format != "" {
+		req.Header.Set(HeaderResponseFormat, format)
+	}
+	rec := httptest.NewRecorder()
+	if err := handler.HandleToolCall(echo.New().NewContext(req, rec)); err != nil {
+		t.Fatalf("handler failed: %v", err)
+	}
+	return rec, store, evaluator
+}
+
+func TestEgressPolicyRedactsResponse(t *testing.T) {
+	rec, store, evaluator := callWithEgress(t, policy.Response{Allow: true, Reason: "masked PII", Redact: []string{"response.ssn"}}, "")
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if !strings.Contains(string(evaluator.seen), "078-05-1120") {
+		t.Errorf("expected the egress policy to see the upstream response, got %s", evaluator.seen)
+	}
+
+	var resp ToolCallResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if want := `{"name":"Ada","ssn":"[REDACTED]"}`; string(resp.Result) != want {
+		t.Errorf("expected result %s, got %s", want, resp.Result)
+	}
+
+	if len(store.entries) != 2 {
+		t.Fatalf("expected request and egress audit entries, got %d", len(store.entries))
+	}
+	egress := store.entries[1]
+	if egress.Decision != audit.DecisionAllow || egress.Reason != "egress redacted response.ssn: masked PII" {
+		t.Errorf("unexpected egress audit entry %+v", egress)
+	}
+	if strings.Contains(string(egress.ToolInput), "078-05-1120") {
+		t.Error("expected the response to be kept out of the audit log")
+	}
+}
+
+func TestEgressPolicyRedactsRawResponse(t *testing.T) {
+	rec, _, _ := callWithEgress(t, policy.Response{Allow: true, Redact: []string{"response.ssn"}}, ResponseFormatRaw)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if strings.Contains(rec.Body.String(), "078-05-1120") {
+		t.Errorf("expected the raw body to be redacted, got %s", rec.Body.String())
+	}
+}
+
+func TestEgressPolicyBlocksResponse(t *testing.T) {
+	rec, store, _ := callWithEgress(t, policy.Response{Allow: false, Reason: "SSN in output"}, "")
+
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", rec.Code)
+	}
+	if strings.Contains(rec.Body.String(), "078-05-1120") {
+		t.Errorf("expected the blocked response to be withheld, got %s", rec.Body.String())
+	}
+
+	egress := store.entries[len(store.entries)-1]
+	if egress.Decision != audit.DecisionDeny || egress.Reason != "egress denied: SSN in output" || egress.DenyCategory != audit.DenyPolicy {
+		t.Errorf("unexpected egress audit entry %+v", egress)
+	}
+}
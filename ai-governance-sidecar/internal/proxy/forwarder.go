@@ -6,33 +6,175 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
+	"net/textproto"
+	"net/url"
 	"time"
+
+	"golang.org/x/net/http/httpproxy"
 )
 
 type Forwarder struct {
-	client *http.Client
+	client  *http.Client
+	timeout time.Duration
+	// maxTimeout caps a request's timeout_ms; zero caps it at timeout
+	maxTimeout time.Duration
+	// upstreams, when set, also vets redirects to another host
+	upstreams *upstreamGuard
 }
 
+// NewForwarder forwards over a copy of the default transport whose dialer
+// refuses link-local and metadata addresses, as every forwarder does, and
+// whose redirects are checked like upstreams
 func NewForwarder(timeoutSec int) *Forwarder {
-	return &Forwarder{
-		client: &http.Client{
-			Timeout: time.Duration(timeoutSec) * time.Second,
-		},
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = guardedDialContext()
+
+	f := &Forwarder{timeout: time.Duration(timeoutSec) * time.Second}
+	f.client = &http.Client{Transport: transport, CheckRedirect: f.checkRedirect}
+	return f
+}
+
+// requestTimeout is the deadline for one upstream call: the request's
+// timeout_ms capped at limit, or def when the request sets none. Zero means
+// no deadline.
+func requestTimeout(req *ToolCallRequest, def, limit time.Duration) time.Duration {
+	if req.TimeoutMs <= 0 {
+		return def
+	}
+
+	requested := time.Duration(req.TimeoutMs) * time.Millisecond
+	if limit <= 0 {
+		limit = def
+	}
+	if limit > 0 && requested > limit {
+		return limit
 	}
+	return requested
 }
 
-func (f *Forwarder) Forward(ctx context.Context, upstream string, req *ToolCallRequest) (json.RawMessage, error) {
-	payload, err := f.buildPayload(req)
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// TransportOptions customizes how the forwarder reaches upstreams
+type TransportOptions struct {
+	CAFile             string
+	InsecureSkipVerify bool
+	// ProxyURL routes upstream calls through an HTTP proxy. When empty the
+	// HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables apply.
+	ProxyURL string
+}
+
+// NewForwarderWithTransport builds a forwarder whose transport honors opts.
+func NewForwarderWithTransport(timeoutSec int, opts TransportOptions) (*Forwarder, error) {
+	f := NewForwarder(timeoutSec)
+
+	tlsConfig, err := buildTLSConfig(opts.CAFile, opts.InsecureSkipVerify)
+	if err != nil {
+		return nil, err
+	}
+
+	proxyFunc, err := buildProxyFunc(opts.ProxyURL)
 	if err != nil {
 		return nil, err
 	}
 
-	httpReq, err := f.buildRequest(ctx, upstream, payload)
+	transport := f.client.Transport.(*http.Transport)
+	transport.Proxy = guardProxied(proxyFunc)
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	return f, nil
+}
+
+// buildProxyFunc applies NO_PROXY to an explicit proxy URL as well
+func buildProxyFunc(proxyURL string) (func(*http.Request) (*url.URL, error), error) {
+	env := httpproxy.FromEnvironment()
+	if proxyURL != "" {
+		parsed, err := url.Parse(proxyURL)
+		if err != nil || parsed.Host == "" {
+			return nil, fmt.Errorf("invalid upstream proxy URL %q", proxyURL)
+		}
+		env.HTTPProxy = proxyURL
+		env.HTTPSProxy = proxyURL
+	}
+
+	proxyFunc := env.ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return proxyFunc(req.URL)
+	}, nil
+}
+
+// UpstreamResponse is an upstream reply as it was received
+type UpstreamResponse struct {
+	Status      int
+	ContentType string
+	Body        []byte
+}
+
+func (f *Forwarder) Forward(ctx context.Context, upstream string, req *ToolCallRequest) (json.RawMessage, error) {
+	resp, err := f.ForwardRaw(ctx, upstream, req)
 	if err != nil {
 		return nil, err
 	}
 
+	if resp.Status != http.StatusOK {
+		return nil, fmt.Errorf("upstream returned %d", resp.Status)
+	}
+
+	return json.RawMessage(resp.Body), nil
+}
+
+// ForwardRaw returns the upstream reply whatever its status
+func (f *Forwarder) ForwardRaw(ctx context.Context, upstream string, req *ToolCallRequest) (UpstreamResponse, error) {
+	ctx, cancel := withTimeout(ctx, requestTimeout(req, f.timeout, f.maxTimeout))
+	defer cancel()
+
+	payload, contentType, err := f.buildPayload(req)
+	if err != nil {
+		return UpstreamResponse{}, err
+	}
+
+	httpReq, err := f.buildRequest(ctx, upstream, req.Method, contentType, payload)
+	if err != nil {
+		return UpstreamResponse{}, err
+	}
+
+	resp, err := f.client.Do(httpReq)
+	if err != nil {
+		return UpstreamResponse{}, fmt.Errorf("http request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := f.readResponse(resp.Body)
+	if err != nil {
+		return UpstreamResponse{}, err
+	}
+
+	return UpstreamResponse{
+		Status:      resp.StatusCode,
+		ContentType: resp.Header.Get("Content-Type"),
+		Body:        body,
+	}, nil
+}
+
+// Fetch GETs a JSON document from an upstream
+func (f *Forwarder) Fetch(ctx context.Context, target string) (json.RawMessage, error) {
+	ctx, cancel := withTimeout(ctx, f.timeout)
+	defer cancel()
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	httpReq.Header.Set("Accept", "application/json")
+
 	resp, err := f.client.Do(httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("http request: %w", err)
@@ -46,22 +188,82 @@ func (f *Forwarder) Forward(ctx context.Context, upstream string, req *ToolCallR
 	return f.readResponse(resp.Body)
 }
 
-func (f *Forwarder) buildPayload(req *ToolCallRequest) ([]byte, error) {
+func (f *Forwarder) buildPayload(req *ToolCallRequest) ([]byte, string, error) {
+	if req.upload != nil {
+		return f.buildMultipartPayload(req)
+	}
+
 	payload := map[string]interface{}{
 		"tool_name": req.ToolName,
 		"args":      json.RawMessage(req.Args),
 	}
 
-	return json.Marshal(payload)
+	data, err := json.Marshal(payload)
+	return data, "application/json", err
+}
+
+// buildMultipartPayload re-encodes an uploaded form, files included
+func (f *Forwarder) buildMultipartPayload(req *ToolCallRequest) ([]byte, string, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	if err := writer.WriteField("tool_name", req.ToolName); err != nil {
+		return nil, "", fmt.Errorf("write tool_name: %w", err)
+	}
+	if err := writer.WriteField("args", string(req.Args)); err != nil {
+		return nil, "", fmt.Errorf("write args: %w", err)
+	}
+
+	for field, files := range req.upload.File {
+		for _, file := range files {
+			if err := copyFormFile(writer, field, file); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, "", fmt.Errorf("close multipart: %w", err)
+	}
+
+	return buf.Bytes(), writer.FormDataContentType(), nil
+}
+
+func copyFormFile(writer *multipart.Writer, field string, file *multipart.FileHeader) error {
+	src, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("open upload %s: %w", file.Filename, err)
+	}
+	defer src.Close()
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name=%q; filename=%q`, field, file.Filename))
+	if contentType := file.Header.Get("Content-Type"); contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+
+	dst, err := writer.CreatePart(header)
+	if err != nil {
+		return fmt.Errorf("create part %s: %w", file.Filename, err)
+	}
+
+	if _, err := io.Copy(dst, src); err != nil {
+		return fmt.Errorf("copy upload %s: %w", file.Filename, err)
+	}
+	return nil
 }
 
-func (f *Forwarder) buildRequest(ctx context.Context, upstream string, payload []byte) (*http.Request, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, upstream, bytes.NewReader(payload))
+func (f *Forwarder) buildRequest(ctx context.Context, upstream, method, contentType string, payload []byte) (*http.Request, error) {
+	if method == "" {
+		method = http.MethodPost
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, upstream, bytes.NewReader(payload))
 	if err != nil {
 		return nil, fmt.Errorf("create request: %w", err)
 	}
 
-	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Type", contentType)
 	return req, nil
 }
 
@@ -3,8 +3,11 @@ package proxy
 import (
 	"context"
 	"encoding/json"
+	"encoding/pem"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 )
@@ -74,4 +77,210 @@ func TestForwarder_Timeout(t *testing.T) {
 	if err == nil {
 		t.Error("expected timeout error")
 	}
-}
\ No newline at end of file
+}
+func TestForwarder_Methods(t *testing.T) {
+	for _, method := range []string{http.MethodPut, http.MethodDelete} {
+		t.Run(method, func(t *testing.T) {
+			var gotMethod string
+			var gotBody map[string]interface{}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotMethod = r.Method
+				json.NewDecoder(r.Body).Decode(&gotBody)
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{"result":"ok"}`))
+			}))
+			defer server.Close()
+
+			forwarder := NewForwarder(10)
+			req := &ToolCallRequest{
+				ToolName: "records",
+				Args:     json.RawMessage(`{"id":"42"}`),
+				Method:   method,
+			}
+
+			if _, err := forwarder.Forward(context.Background(), server.URL, req); err != nil {
+				t.Fatalf("forward failed: %v", err)
+			}
+
+			if gotMethod != method {
+				t.Errorf("expected %s, got %s", method, gotMethod)
+			}
+			if gotBody["tool_name"] != "records" {
+				t.Errorf("expected tool_name in body, got %v", gotBody)
+			}
+		})
+	}
+}
+
+func TestForwarder_CustomCA(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"result":"ok"}`))
+	}))
+	defer server.Close()
+
+	req := &ToolCallRequest{ToolName: "test", Args: json.RawMessage(`{}`)}
+
+	// The httptest certificate is self-signed, so the system store rejects it
+	if _, err := NewForwarder(10).Forward(context.Background(), server.URL, req); err == nil {
+		t.Fatal("expected untrusted certificate to be rejected")
+	}
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, certPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	forwarder, err := NewForwarderWithTransport(10, TransportOptions{CAFile: caFile})
+	if err != nil {
+		t.Fatalf("create forwarder: %v", err)
+	}
+
+	if _, err := forwarder.Forward(context.Background(), server.URL, req); err != nil {
+		t.Errorf("expected certificate signed by configured CA to verify: %v", err)
+	}
+}
+
+func TestForwarder_InvalidCAFile(t *testing.T) {
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, []byte("not a certificate"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewForwarderWithTransport(10, TransportOptions{CAFile: caFile}); err == nil {
+		t.Error("expected error for CA file without certificates")
+	}
+
+	if err := (ProxyConfig{UpstreamCAFile: filepath.Join(t.TempDir(), "missing.pem")}).Validate(); err == nil {
+		t.Error("expected Validate to reject a missing CA file")
+	}
+}
+
+func TestForwarder_OutboundProxy(t *testing.T) {
+	var proxied []string
+	proxyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// A forward proxy receives the absolute upstream URL
+		proxied = append(proxied, r.URL.String())
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"result":"via proxy"}`))
+	}))
+	defer proxyServer.Close()
+
+	t.Setenv("NO_PROXY", "internal.example")
+
+	forwarder, err := NewForwarderWithTransport(10, TransportOptions{ProxyURL: proxyServer.URL})
+	if err != nil {
+		t.Fatalf("create forwarder: %v", err)
+	}
+
+	req := &ToolCallRequest{ToolName: "test", Args: json.RawMessage(`{}`)}
+	result, err := forwarder.Forward(context.Background(), "http://tools.example/run", req)
+	if err != nil {
+		t.Fatalf("forward failed: %v", err)
+	}
+
+	if len(proxied) != 1 || proxied[0] != "http://tools.example/run" {
+		t.Errorf("expected request routed through proxy, got %v", proxied)
+	}
+	if string(result) != `{"result":"via proxy"}` {
+		t.Errorf("unexpected result: %s", result)
+	}
+
+	// NO_PROXY hosts bypass the proxy, so this fails to resolve directly
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	forwarder.Forward(ctx, "http://internal.example/run", req)
+	if len(proxied) != 1 {
+		t.Errorf("expected NO_PROXY host to bypass proxy, got %v", proxied)
+	}
+}
+
+func TestForwarder_InvalidProxyURL(t *testing.T) {
+	if _, err := NewForwarderWithTransport(10, TransportOptions{ProxyURL: "not a url"}); err == nil {
+		t.Error("expected error for invalid proxy URL")
+	}
+}
+
+func TestForwarder_RequestTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(300 * time.Millisecond):
+			w.Write([]byte(`{"status":"slow"}`))
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+
+	forwarder := NewForwarder(5)
+	forwarder.maxTimeout = 10 * time.Second
+
+	// A short timeout_ms fails fast even though the default would wait
+	req := &ToolCallRequest{ToolName: "quick", Args: json.RawMessage(`{}`), TimeoutMs: 50}
+	start := time.Now()
+	if _, err := forwarder.Forward(context.Background(), server.URL, req); err == nil {
+		t.Error("expected per-request timeout to cut the call short")
+	}
+	if elapsed := time.Since(start); elapsed > 250*time.Millisecond {
+		t.Errorf("expected to fail within timeout_ms, took %v", elapsed)
+	}
+
+	// A longer timeout_ms outlives a short default
+	forwarder = NewForwarder(0)
+	forwarder.timeout = 100 * time.Millisecond
+	forwarder.maxTimeout = 2 * time.Second
+
+	req = &ToolCallRequest{ToolName: "long", Args: json.RawMessage(`{}`), TimeoutMs: 1000}
+	if _, err := forwarder.Forward(context.Background(), server.URL, req); err != nil {
+		t.Errorf("expected long-running call to succeed within timeout_ms, got %v", err)
+	}
+}
+
+func TestRequestTimeoutIsCapped(t *testing.T) {
+	tests := []struct {
+		name      string
+		timeoutMs int
+		def       time.Duration
+		limit     time.Duration
+		want      time.Duration
+	}{
+		{"default when unset", 0, 30 * time.Second, time.Minute, 30 * time.Second},
+		{"shorter than default", 500, 30 * time.Second, time.Minute, 500 * time.Millisecond},
+		{"longer within max", 45000, 30 * time.Second, time.Minute, 45 * time.Second},
+		{"capped at max", 600000, 30 * time.Second, time.Minute, time.Minute},
+		{"capped at default without max", 600000, 30 * time.Second, 0, 30 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := requestTimeout(&ToolCallRequest{TimeoutMs: tt.timeoutMs}, tt.def, tt.limit)
+			if got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestForwarder_RequestTimeoutCappedAtMax(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+			w.Write([]byte(`{}`))
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+
+	forwarder := NewForwarder(0)
+	forwarder.timeout = 50 * time.Millisecond
+	forwarder.maxTimeout = 100 * time.Millisecond
+
+	req := &ToolCallRequest{ToolName: "greedy", Args: json.RawMessage(`{}`), TimeoutMs: 60000}
+	start := time.Now()
+	if _, err := forwarder.Forward(context.Background(), server.URL, req); err == nil {
+		t.Fatal("expected the capped timeout to expire")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("expected timeout_ms to be capped at the maximum, took %v", elapsed)
+	}
+}
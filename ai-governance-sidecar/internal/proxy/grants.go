@@ -0,0 +1,96 @@
+package proxy
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/approval"
+	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
+	"github.com/rs/zerolog/log"
+)
+
+// approvalGrant lets an identical request skip the queue until it expires
+type approvalGrant struct {
+	approver string
+	expires  time.Time
+}
+
+// grantCache remembers human approvals by decision key for a fixed TTL.
+// After a grant expires the same request goes back to the approval queue,
+// so a single approval cannot be replayed for the life of an agent session.
+// A zero TTL disables reuse.
+type grantCache struct {
+	ttl time.Duration
+
+	mu     sync.Mutex
+	grants map[string]approvalGrant
+}
+
+func newGrantCache(ttl time.Duration) *grantCache {
+	return &grantCache{ttl: ttl, grants: make(map[string]approvalGrant)}
+}
+
+func (g *grantCache) record(key, approver string, now time.Time) {
+	if g.ttl <= 0 {
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for k, grant := range g.grants {
+		if !now.Before(grant.expires) {
+			delete(g.grants, k)
+		}
+	}
+	g.grants[key] = approvalGrant{approver: approver, expires: now.Add(g.ttl)}
+}
+
+// lookup returns the unexpired grant for key, dropping it once expired
+func (g *grantCache) lookup(key string, now time.Time) (approvalGrant, bool) {
+	if g.ttl <= 0 {
+		return approvalGrant{}, false
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	grant, ok := g.grants[key]
+	if !ok {
+		return approvalGrant{}, false
+	}
+	if !now.Before(grant.expires) {
+		delete(g.grants, key)
+		return approvalGrant{}, false
+	}
+	return grant, true
+}
+
+// recordGrant remembers a human approval for reuse by identical requests
+// from the same caller
+func (h *Handler) recordGrant(ctx context.Context, req *ToolCallRequest, decision approval.Decision) {
+	if decision.Approved {
+		h.grants.record(req.DecisionKey(ctx), approverName(decision), h.now())
+	}
+}
+
+// useGrant reports whether an unexpired approval covers req, auditing the
+// reuse when it does.
+func (h *Handler) useGrant(ctx context.Context, req *ToolCallRequest) bool {
+	grant, ok := h.grants.lookup(req.DecisionKey(ctx), h.now())
+	if !ok {
+		return false
+	}
+
+	log.Info().Str("tool", req.ToolName).Str("approver", grant.approver).Msg("reusing approval grant")
+
+	resolved := policy.Response{
+		Allow:  true,
+		Reason: "approved by " + grant.approver + ": reused grant until " + grant.expires.UTC().Format(time.RFC3339),
+	}
+	if err := h.logAudit(ctx, req, resolved); err != nil {
+		log.Warn().Err(err).Msg("audit logging failed")
+	}
+	return true
+}
@@ -0,0 +1,114 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/approval"
+	"github.com/dagbolade/ai-governance-sidecar/internal/auth"
+	"github.com/dagbolade/ai-governance-sidecar/internal/clock"
+	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
+	"github.com/labstack/echo/v4"
+)
+
+func TestApprovalGrantExpires(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer upstream.Close()
+
+	queue := &recordingApprovalQueue{decision: approval.Decision{Approved: true, DecidedBy: "alice"}}
+	mockAudit := &mockAuditStore{}
+	config := ProxyConfig{DefaultUpstream: upstream.URL, Timeout: 5, ApprovalGrantTTL: 60}
+	handler := NewHandler(config, &mockPolicyEvaluator{response: policy.Response{Allow: true, HumanRequired: true, Reason: "needs review"}}, mockAudit, queue)
+
+	fake := clock.NewFake(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	handler.SetClock(fake)
+
+	call := func(body string) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/tool/call", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		if err := handler.HandleToolCall(echo.New().NewContext(req, rec)); err != nil {
+			t.Fatalf("handler failed: %v", err)
+		}
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+	}
+
+	call(`{"tool_name":"deploy","args":{"env":"prod","version":"1.2"}}`)
+	if len(queue.reasons) != 1 {
+		t.Fatalf("expected first call to be queued, got %d enqueues", len(queue.reasons))
+	}
+
+	// Same request with reordered args reuses the grant
+	fake.Advance(30 * time.Second)
+	call(`{"tool_name":"deploy","args":{"version":"1.2","env":"prod"}}`)
+	if len(queue.reasons) != 1 {
+		t.Fatalf("expected grant reuse within TTL, got %d enqueues", len(queue.reasons))
+	}
+	last := mockAudit.entries[len(mockAudit.entries)-1]
+	if !strings.Contains(last.Reason, "reused grant") {
+		t.Errorf("expected grant reuse to be audited, got %q", last.Reason)
+	}
+
+	// After the TTL the request needs approval again
+	fake.Advance(31 * time.Second)
+	call(`{"tool_name":"deploy","args":{"env":"prod","version":"1.2"}}`)
+	if len(queue.reasons) != 2 {
+		t.Errorf("expected request to re-enter the queue after expiry, got %d enqueues", len(queue.reasons))
+	}
+}
+
+func TestApprovalGrantIsPerCaller(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer upstream.Close()
+
+	queue := &recordingApprovalQueue{decision: approval.Decision{Approved: true, DecidedBy: "alice"}}
+	config := ProxyConfig{DefaultUpstream: upstream.URL, Timeout: 5, ApprovalGrantTTL: 60}
+	handler := NewHandler(config, &mockPolicyEvaluator{response: policy.Response{Allow: true, HumanRequired: true, Reason: "needs review"}}, &mockAuditStore{}, queue)
+
+	call := func(user *auth.User) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/tool/call", strings.NewReader(`{"tool_name":"deploy","args":{"env":"prod"}}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req = req.WithContext(auth.WithUser(req.Context(), user))
+		rec := httptest.NewRecorder()
+		if err := handler.HandleToolCall(echo.New().NewContext(req, rec)); err != nil {
+			t.Fatalf("handler failed: %v", err)
+		}
+	}
+
+	call(&auth.User{ID: "bob", Tenant: "acme"})
+	call(&auth.User{ID: "carol", Tenant: "acme"})
+	if len(queue.reasons) != 2 {
+		t.Fatalf("expected another caller's identical call to be queued, got %d enqueues", len(queue.reasons))
+	}
+
+	call(&auth.User{ID: "bob", Tenant: "globex"})
+	if len(queue.reasons) != 3 {
+		t.Fatalf("expected the same caller in another tenant to be queued, got %d enqueues", len(queue.reasons))
+	}
+
+	call(&auth.User{ID: "bob", Tenant: "acme"})
+	if len(queue.reasons) != 3 {
+		t.Errorf("expected the original caller to reuse their grant, got %d enqueues", len(queue.reasons))
+	}
+}
+
+func TestGrantCacheDisabledWithoutTTL(t *testing.T) {
+	cache := newGrantCache(0)
+	now := time.Now()
+
+	cache.record("key", "alice", now)
+	if _, ok := cache.lookup("key", now); ok {
+		t.Error("expected no grant reuse with a zero TTL")
+	}
+}
@@ -0,0 +1,257 @@
+package proxy
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+var ErrInvalidGRPCRoute = errors.New("invalid gRPC route")
+
+// GRPCRoute sends a tool's calls to a unary gRPC method instead of HTTP.
+// Args are mapped onto the request message with protojson.
+type GRPCRoute struct {
+	Target string `json:"target"` // host:port
+	Method string `json:"method"` // package.Service/Method
+	// Plaintext disables TLS, for backends on the same host
+	Plaintext bool `json:"plaintext,omitempty"`
+}
+
+func (r GRPCRoute) validate() error {
+	if r.Target == "" {
+		return fmt.Errorf("%w: target is required", ErrInvalidGRPCRoute)
+	}
+	if _, _, err := r.serviceAndMethod(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r GRPCRoute) serviceAndMethod() (protoreflect.FullName, protoreflect.Name, error) {
+	service, method, ok := strings.Cut(strings.TrimPrefix(r.Method, "/"), "/")
+	if !ok || service == "" || method == "" {
+		return "", "", fmt.Errorf("%w: method %q must be package.Service/Method", ErrInvalidGRPCRoute, r.Method)
+	}
+	return protoreflect.FullName(service), protoreflect.Name(method), nil
+}
+
+// GRPCForwarder invokes gRPC methods without generated stubs. Method
+// descriptors come from server reflection, falling back to descriptors
+// compiled into the sidecar.
+type GRPCForwarder struct {
+	timeout    time.Duration
+	maxTimeout time.Duration
+	tlsConfig  *tls.Config
+
+	mu      sync.Mutex
+	conns   map[string]*grpc.ClientConn
+	methods map[GRPCRoute]protoreflect.MethodDescriptor
+}
+
+func NewGRPCForwarder(timeoutSec int, tlsConfig *tls.Config) *GRPCForwarder {
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	return &GRPCForwarder{
+		timeout:   time.Duration(timeoutSec) * time.Second,
+		tlsConfig: tlsConfig,
+		conns:     make(map[string]*grpc.ClientConn),
+		methods:   make(map[GRPCRoute]protoreflect.MethodDescriptor),
+	}
+}
+
+func (f *GRPCForwarder) Forward(ctx context.Context, route GRPCRoute, req *ToolCallRequest) (json.RawMessage, error) {
+	ctx, cancel := withTimeout(ctx, requestTimeout(req, f.timeout, f.maxTimeout))
+	defer cancel()
+
+	conn, err := f.conn(route)
+	if err != nil {
+		return nil, err
+	}
+
+	method, err := f.method(ctx, conn, route)
+	if err != nil {
+		return nil, err
+	}
+
+	in := dynamicpb.NewMessage(method.Input())
+	if len(req.Args) > 0 {
+		if err := protojson.Unmarshal(req.Args, in); err != nil {
+			return nil, fmt.Errorf("map args to %s: %w", method.Input().FullName(), err)
+		}
+	}
+
+	out := dynamicpb.NewMessage(method.Output())
+	if err := conn.Invoke(ctx, "/"+strings.TrimPrefix(route.Method, "/"), in, out); err != nil {
+		return nil, fmt.Errorf("grpc invoke: %w", err)
+	}
+
+	data, err := protojson.Marshal(out)
+	if err != nil {
+		return nil, fmt.Errorf("encode response: %w", err)
+	}
+	return json.RawMessage(data), nil
+}
+
+func (f *GRPCForwarder) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var errs []error
+	for target, conn := range f.conns {
+		errs = append(errs, conn.Close())
+		delete(f.conns, target)
+	}
+	return errors.Join(errs...)
+}
+
+func (f *GRPCForwarder) conn(route GRPCRoute) (*grpc.ClientConn, error) {
+	key := fmt.Sprintf("%s|%t", route.Target, route.Plaintext)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if conn, ok := f.conns[key]; ok {
+		return conn, nil
+	}
+
+	creds := credentials.NewTLS(f.tlsConfig)
+	if route.Plaintext {
+		creds = insecure.NewCredentials()
+	}
+
+	conn, err := grpc.NewClient(route.Target, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, fmt.Errorf("grpc dial %s: %w", route.Target, err)
+	}
+	f.conns[key] = conn
+	return conn, nil
+}
+
+func (f *GRPCForwarder) method(ctx context.Context, conn *grpc.ClientConn, route GRPCRoute) (protoreflect.MethodDescriptor, error) {
+	f.mu.Lock()
+	cached, ok := f.methods[route]
+	f.mu.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	serviceName, methodName, err := route.serviceAndMethod()
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := reflectFiles(ctx, conn, serviceName)
+	if err != nil {
+		log.Debug().Err(err).Str("target", route.Target).Msg("grpc reflection unavailable, using compiled descriptors")
+		files = protoregistry.GlobalFiles
+	}
+
+	desc, err := files.FindDescriptorByName(serviceName)
+	if err != nil {
+		return nil, fmt.Errorf("find service %s: %w", serviceName, err)
+	}
+	service, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a service", serviceName)
+	}
+
+	method := service.Methods().ByName(methodName)
+	if method == nil {
+		return nil, fmt.Errorf("service %s has no method %s", serviceName, methodName)
+	}
+	if method.IsStreamingClient() || method.IsStreamingServer() {
+		return nil, fmt.Errorf("method %s is streaming; only unary methods are supported", route.Method)
+	}
+
+	f.mu.Lock()
+	f.methods[route] = method
+	f.mu.Unlock()
+	return method, nil
+}
+
+// reflectFiles fetches the file defining symbol and all of its imports
+// over the server reflection API.
+func reflectFiles(ctx context.Context, conn *grpc.ClientConn, symbol protoreflect.FullName) (*protoregistry.Files, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("open reflection stream: %w", err)
+	}
+
+	protos := make(map[string]*descriptorpb.FileDescriptorProto)
+	pending := []*reflectionpb.ServerReflectionRequest{{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: string(symbol)},
+	}}
+
+	for len(pending) > 0 {
+		if err := stream.Send(pending[0]); err != nil {
+			return nil, fmt.Errorf("reflection request: %w", err)
+		}
+		pending = pending[1:]
+
+		resp, err := stream.Recv()
+		if err != nil {
+			return nil, fmt.Errorf("reflection response: %w", err)
+		}
+		if errResp := resp.GetErrorResponse(); errResp != nil {
+			return nil, fmt.Errorf("reflection error: %s", errResp.GetErrorMessage())
+		}
+
+		for _, raw := range resp.GetFileDescriptorResponse().GetFileDescriptorProto() {
+			fd := &descriptorpb.FileDescriptorProto{}
+			if err := proto.Unmarshal(raw, fd); err != nil {
+				return nil, fmt.Errorf("decode descriptor: %w", err)
+			}
+			protos[fd.GetName()] = fd
+		}
+
+		// Servers skip files already sent on the stream; ask for any
+		// import still missing.
+		for _, fd := range protos {
+			for _, dep := range fd.GetDependency() {
+				if _, ok := protos[dep]; !ok && !containsFileRequest(pending, dep) {
+					pending = append(pending, &reflectionpb.ServerReflectionRequest{
+						MessageRequest: &reflectionpb.ServerReflectionRequest_FileByFilename{FileByFilename: dep},
+					})
+				}
+			}
+		}
+	}
+
+	set := &descriptorpb.FileDescriptorSet{}
+	for _, fd := range protos {
+		set.File = append(set.File, fd)
+	}
+	return protodesc.NewFiles(set)
+}
+
+func containsFileRequest(requests []*reflectionpb.ServerReflectionRequest, name string) bool {
+	for _, req := range requests {
+		if req.GetFileByFilename() == name {
+			return true
+		}
+	}
+	return false
+}
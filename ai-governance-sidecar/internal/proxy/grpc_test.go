@@ -0,0 +1,137 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
+	"github.com/labstack/echo/v4"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// startGRPCServer serves the standard health service with reflection on a
+// loopback port.
+func startGRPCServer(t *testing.T, reflect bool) string {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+
+	srv := grpc.NewServer()
+	healthSrv := health.NewServer()
+	healthSrv.SetServingStatus("billing", healthpb.HealthCheckResponse_NOT_SERVING)
+	healthpb.RegisterHealthServer(srv, healthSrv)
+	if reflect {
+		reflection.Register(srv)
+	}
+
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	return lis.Addr().String()
+}
+
+func TestHandleToolCall_GRPCRoute(t *testing.T) {
+	target := startGRPCServer(t, true)
+
+	cfg := ProxyConfig{
+		DefaultUpstream: "http://127.0.0.1:1",
+		Timeout:         5,
+		GRPCRoutes: map[string]GRPCRoute{
+			"service_health": {Target: target, Method: "grpc.health.v1.Health/Check", Plaintext: true},
+		},
+	}
+	pol := &mockPolicyEvaluator{response: policy.Response{Allow: true, Reason: "ok"}}
+	handler := NewHandler(cfg, pol, &mockAuditStore{}, &mockApprovalQueue{})
+	defer handler.grpc.Close()
+
+	e := echo.New()
+	body := `{"tool_name":"service_health","args":{"service":"billing"}}`
+	req := httptest.NewRequest(http.MethodPost, "/tool/call", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+
+	if err := handler.HandleToolCall(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("handler failed: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp ToolCallResponse
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+
+	var result map[string]string
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		t.Fatalf("result is not JSON: %v", err)
+	}
+	if result["status"] != "NOT_SERVING" {
+		t.Errorf("expected NOT_SERVING, got %v", result)
+	}
+}
+
+func TestGRPCForwarderWithoutReflection(t *testing.T) {
+	// The health service is compiled into the sidecar, so its descriptor
+	// resolves even when the server does not expose reflection.
+	target := startGRPCServer(t, false)
+
+	forwarder := NewGRPCForwarder(5, nil)
+	defer forwarder.Close()
+
+	route := GRPCRoute{Target: target, Method: "/grpc.health.v1.Health/Check", Plaintext: true}
+	result, err := forwarder.Forward(context.Background(), route, &ToolCallRequest{Args: json.RawMessage(`{}`)})
+	if err != nil {
+		t.Fatalf("forward failed: %v", err)
+	}
+	if string(result) != `{"status":"SERVING"}` {
+		t.Errorf("unexpected result: %s", result)
+	}
+}
+
+func TestGRPCForwarderErrors(t *testing.T) {
+	target := startGRPCServer(t, true)
+
+	forwarder := NewGRPCForwarder(5, nil)
+	defer forwarder.Close()
+
+	tests := []struct {
+		name  string
+		route GRPCRoute
+		args  string
+		want  string
+	}{
+		{"unknown method", GRPCRoute{Target: target, Method: "grpc.health.v1.Health/Nope", Plaintext: true}, `{}`, "has no method"},
+		{"streaming method", GRPCRoute{Target: target, Method: "grpc.health.v1.Health/Watch", Plaintext: true}, `{}`, "streaming"},
+		{"unknown service", GRPCRoute{Target: target, Method: "acme.Missing/Run", Plaintext: true}, `{}`, "find service"},
+		{"bad args", GRPCRoute{Target: target, Method: "grpc.health.v1.Health/Check", Plaintext: true}, `{"nope":1}`, "map args"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := forwarder.Forward(context.Background(), tt.route, &ToolCallRequest{Args: json.RawMessage(tt.args)})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestProxyConfigValidateGRPCRoutes(t *testing.T) {
+	cfg := ProxyConfig{GRPCRoutes: map[string]GRPCRoute{
+		"lookup": {Target: "users:50051", Method: "users.v1.Users"},
+	}}
+
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected error for method without service/method form")
+	}
+}
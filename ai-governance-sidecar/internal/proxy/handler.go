@@ -4,11 +4,18 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"mime/multipart"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/dagbolade/ai-governance-sidecar/internal/approval"
 	"github.com/dagbolade/ai-governance-sidecar/internal/audit"
+	"github.com/dagbolade/ai-governance-sidecar/internal/auth"
+	"github.com/dagbolade/ai-governance-sidecar/internal/clock"
 	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
 	"github.com/labstack/echo/v4"
 	"github.com/rs/zerolog/log"
@@ -20,110 +27,556 @@ type Handler struct {
 	audit     audit.Store
 	approval  approval.Queue
 	forwarder *Forwarder
+	grpc      *GRPCForwarder
+	patterns  *PatternMatcher
+	toolNames *PatternMatcher
+	groups    map[string]string
+	windows   *timeWindows
+	upstreams *upstreamGuard
+	routes    *routeTable
+	sampler   *auditSampler
+	grants    *grantCache
+	quotas    *quotaTracker
+	appeals   *appealLedger
+	clock     clock.Clock
 }
 
 func NewHandler(cfg ProxyConfig, pol policy.Evaluator, aud audit.Store, appr approval.Queue) *Handler {
+	forwarder, err := NewForwarderWithTransport(cfg.Timeout, cfg.transportOptions())
+	if err != nil {
+		// Validate should have caught this at startup; fall back to the
+		// default transport, which keeps the dial guard, rather than
+		// weakening verification.
+		log.Error().Err(err).Msg("invalid upstream transport settings, using defaults")
+		forwarder = NewForwarder(cfg.Timeout)
+	}
+	forwarder.maxTimeout = time.Duration(cfg.MaxTimeout) * time.Second
+	upstreams := newUpstreamGuard(cfg.UpstreamAllowlist)
+	forwarder.upstreams = upstreams
+
+	tlsConfig, err := buildTLSConfig(cfg.UpstreamCAFile, cfg.UpstreamInsecureSkipVerify)
+	if err != nil {
+		log.Error().Err(err).Msg("invalid upstream TLS settings, using system roots for gRPC")
+		tlsConfig = nil
+	}
+
+	grpcForwarder := NewGRPCForwarder(cfg.Timeout, tlsConfig)
+	grpcForwarder.maxTimeout = forwarder.maxTimeout
+
+	routes := newRouteTable(cfg.UpstreamRoutes, forwarder.client, cfg.UpstreamHealthPath,
+		time.Duration(cfg.UpstreamHealthInterval)*time.Second)
+	routes.start()
+
 	return &Handler{
 		config:    cfg,
 		policy:    pol,
 		audit:     aud,
 		approval:  appr,
-		forwarder: NewForwarder(cfg.Timeout),
+		forwarder: forwarder,
+		grpc:      grpcForwarder,
+		patterns:  NewPatternMatcher(cfg.SensitivePatterns),
+		toolNames: NewPatternMatcher(cfg.ToolNamePatterns),
+		groups:    newToolGroups(cfg.ToolGroups),
+		windows:   newTimeWindows(cfg.TimeWindows, cfg.TimeWindowZone),
+		upstreams: upstreams,
+		routes:    routes,
+		sampler:   newAuditSampler(cfg.AuditSampling),
+		grants:    newGrantCache(time.Duration(cfg.ApprovalGrantTTL) * time.Second),
+		quotas:    newQuotaTracker(cfg.UserQuotas),
+		appeals:   newAppealLedger(),
+		clock:     clock.Real{},
 	}
 }
 
+// SetClock replaces the wall clock used for time windows, auto-approval and
+// approval grants
+func (h *Handler) SetClock(c clock.Clock) {
+	h.clock = c
+}
+
+func (h *Handler) now() time.Time {
+	return h.clock.Now()
+}
+
 func (h *Handler) HandleToolCall(c echo.Context) error {
 	ctx := c.Request().Context()
 	
 	req, err := h.parseRequest(c)
 	if err != nil {
+		return h.errorResponse(c, parseErrorStatus(err), err.Error())
+	}
+
+	// Checked up front so a bad header fails before anything is queued
+	if _, err := h.responseFormat(c); err != nil {
 		return h.errorResponse(c, http.StatusBadRequest, err.Error())
 	}
 
-	decision, err := h.evaluatePolicy(ctx, req)
+	release, err := h.acquireQuota(c, 1)
+	if err != nil {
+		log.Warn().Err(err).Str("tool", req.ToolName).Msg("user quota exceeded")
+		if auditErr := h.logAudit(ctx, req, quotaDenial(err)); auditErr != nil {
+			log.Warn().Err(auditErr).Msg("audit logging failed")
+		}
+		return h.errorResponse(c, http.StatusTooManyRequests, err.Error())
+	}
+	defer release()
+
+	if err := h.checkMetadata(req); err != nil {
+		log.Warn().Err(err).Str("tool", req.ToolName).Msg("required metadata missing")
+		if auditErr := h.logAudit(ctx, req, metadataDenial(err)); auditErr != nil {
+			log.Warn().Err(auditErr).Msg("audit logging failed")
+		}
+		return h.errorResponse(c, http.StatusUnprocessableEntity, err.Error())
+	}
+
+	if err := h.checkUpstream(req); err != nil {
+		log.Warn().Err(err).Str("upstream", req.Upstream).Msg("upstream rejected")
+		if auditErr := h.logAudit(ctx, req, upstreamDenial(err)); auditErr != nil {
+			log.Warn().Err(auditErr).Msg("audit logging failed")
+		}
+		return h.denyResponse(c, err.Error())
+	}
+
+	dryRun := isDryRun(c)
+	evalCtx := ctx
+	if dryRun {
+		evalCtx = policy.WithReadOnlyCounters(ctx)
+	}
+
+	decision, err := h.evaluateGated(evalCtx, req)
 	if err != nil {
 		return h.errorResponse(c, http.StatusInternalServerError, "policy evaluation failed")
 	}
+	decision = h.applyPatterns(req, decision)
+	decision = h.applyTimeWindows(req, decision)
+	req.warnings = decision.Warnings
 
-	if err := h.logAudit(ctx, req, decision); err != nil {
+	if dryRun {
+		return h.handleDryRun(ctx, c, req, decision)
+	}
+
+	if err := h.checkJustification(req, decision); err != nil {
+		log.Warn().Str("tool", req.ToolName).Msg("justification missing")
+		if auditErr := h.logAudit(ctx, req, justificationDenial(err)); auditErr != nil {
+			log.Warn().Err(auditErr).Msg("audit logging failed")
+		}
+		return h.errorResponse(c, http.StatusUnprocessableEntity, err.Error())
+	}
+
+	if err := h.auditPolicyDecision(ctx, req, decision); err != nil {
 		log.Warn().Err(err).Msg("audit logging failed")
 	}
 
 	if !decision.Allow {
+		if decision.OverrideAllowed {
+			return h.handleOverride(ctx, c, req, decision)
+		}
 		return h.denyResponse(c, decision.Reason)
 	}
 
 	if decision.HumanRequired {
-		return h.handleHumanApproval(ctx, c, req, decision.Reason)
+		return h.handleHumanApproval(ctx, c, req, decision)
 	}
 
 	return h.forwardRequest(ctx, c, req)
 }
 
 func (h *Handler) parseRequest(c echo.Context) (*ToolCallRequest, error) {
-	var req ToolCallRequest
-	if err := c.Bind(&req); err != nil {
-		return nil, fmt.Errorf("invalid request body: %w", err)
+	req, err := h.bindRequest(c)
+	if err != nil {
+		return nil, err
 	}
 
+	if err := h.normalizeRequest(c.Request().Context(), req, c.Request().Header); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// normalizeRequest validates a bound request and fills in defaults and
+// the caller authenticated on ctx
+func (h *Handler) normalizeRequest(ctx context.Context, req *ToolCallRequest, header http.Header) error {
+	// Everything downstream, from policy to routing to audit, sees the
+	// normalized name
+	req.ToolName = normalizeToolName(req.ToolName, h.config.ToolNameNormalization)
 	if req.ToolName == "" {
-		return nil, fmt.Errorf("tool_name is required")
+		return fmt.Errorf("tool_name is required")
 	}
+	req.toolGroup = h.toolGroup(req.ToolName)
 
 	if req.Upstream == "" {
 		req.Upstream = h.config.DefaultUpstream
 	}
 
+	req.Method = strings.ToUpper(strings.TrimSpace(req.Method))
+	if req.Method == "" {
+		req.Method = http.MethodPost
+	}
+	if !allowedMethods[req.Method] {
+		return fmt.Errorf("method %s is not allowed", req.Method)
+	}
+
+	if req.TimeoutMs < 0 {
+		return fmt.Errorf("timeout_ms must not be negative")
+	}
+
+	if err := h.checkArgsLimits(req.Args); err != nil {
+		return err
+	}
+
+	// Policies, pattern scanning and audit all see one canonical form
+	if len(req.Args) > 0 {
+		args, err := canonicalJSON(req.Args)
+		if err != nil {
+			return fmt.Errorf("args must be valid JSON")
+		}
+		req.Args = args
+	}
+
+	req.Headers = h.captureHeaders(header)
+	req.user = policyUser(ctx)
+	return nil
+}
+
+// policyUser describes the caller authenticated on ctx to policies
+func policyUser(ctx context.Context) *policy.User {
+	user, ok := auth.GetUserFromStdContext(ctx)
+	if !ok || user == nil {
+		return nil
+	}
+	return &policy.User{ID: user.ID, Email: user.Email, Roles: user.Roles, Tenant: user.Tenant}
+}
+
+func (h *Handler) bindRequest(c echo.Context) (*ToolCallRequest, error) {
+	contentType := c.Request().Header.Get(echo.HeaderContentType)
+	if strings.HasPrefix(contentType, echo.MIMEMultipartForm) {
+		return h.parseMultipart(c)
+	}
+
+	var req ToolCallRequest
+	if err := c.Bind(&req); err != nil {
+		return nil, fmt.Errorf("invalid request body: %w", err)
+	}
+
+	// File metadata only ever comes from an actual upload
+	req.Files = nil
 	return &req, nil
 }
 
+// parseMultipart reads tool_name, args, upstream and method from form
+// fields and records metadata for each uploaded file.
+func (h *Handler) parseMultipart(c echo.Context) (*ToolCallRequest, error) {
+	form, err := c.MultipartForm()
+	if err != nil {
+		return nil, fmt.Errorf("invalid multipart body: %w", err)
+	}
+
+	req := &ToolCallRequest{
+		ToolName:      formValue(form, "tool_name"),
+		Upstream:      formValue(form, "upstream"),
+		Method:        formValue(form, "method"),
+		Args:          json.RawMessage(`{}`),
+		Justification: formValue(form, "justification"),
+		upload:        form,
+	}
+
+	if timeout := formValue(form, "timeout_ms"); timeout != "" {
+		ms, err := strconv.Atoi(timeout)
+		if err != nil {
+			return nil, fmt.Errorf("timeout_ms must be an integer")
+		}
+		req.TimeoutMs = ms
+	}
+
+	if args := formValue(form, "args"); args != "" {
+		if !json.Valid([]byte(args)) {
+			return nil, fmt.Errorf("args must be valid JSON")
+		}
+		req.Args = json.RawMessage(args)
+	}
+
+	if metadata := formValue(form, "metadata"); metadata != "" {
+		if err := json.Unmarshal([]byte(metadata), &req.Metadata); err != nil {
+			return nil, fmt.Errorf("metadata must be a JSON object")
+		}
+	}
+
+	fields := make([]string, 0, len(form.File))
+	for field := range form.File {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		for _, file := range form.File[field] {
+			req.Files = append(req.Files, policy.FileInfo{
+				Field:       field,
+				Name:        file.Filename,
+				Size:        file.Size,
+				ContentType: file.Header.Get(echo.HeaderContentType),
+			})
+		}
+	}
+
+	return req, nil
+}
+
+func formValue(form *multipart.Form, key string) string {
+	if values := form.Value[key]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// checkUpstream validates client-supplied upstreams. The configured default
+// upstream is trusted.
+func (h *Handler) checkUpstream(req *ToolCallRequest) error {
+	if req.Upstream == h.config.DefaultUpstream {
+		return nil
+	}
+	return h.upstreams.check(req.Upstream)
+}
+
+// captureHeaders copies the configured headers that are present on the request
+func (h *Handler) captureHeaders(header http.Header) map[string]string {
+	if len(h.config.PolicyHeaders) == 0 {
+		return nil
+	}
+
+	captured := make(map[string]string)
+	for _, name := range h.config.PolicyHeaders {
+		key := http.CanonicalHeaderKey(strings.TrimSpace(name))
+		if value := header.Get(key); value != "" {
+			captured[key] = value
+		}
+	}
+
+	if len(captured) == 0 {
+		return nil
+	}
+	return captured
+}
+
 func (h *Handler) evaluatePolicy(ctx context.Context, req *ToolCallRequest) (policy.Response, error) {
-	evalCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	// The actor lets policies look up the caller's audit history
+	evalCtx, cancel := context.WithTimeout(withAuditActor(ctx), 5*time.Second)
 	defer cancel()
 
-	return h.policy.Evaluate(evalCtx, req.ToPolicyRequest())
+	decision, err := h.policy.Evaluate(evalCtx, req.ToPolicyRequest())
+	if err == nil {
+		logShadowDecision(req, decision)
+	}
+	return decision, err
 }
 
 func (h *Handler) logAudit(ctx context.Context, req *ToolCallRequest, decision policy.Response) error {
+	auditDecision := audit.DecisionDeny
+	if decision.Allow {
+		auditDecision = audit.DecisionAllow
+	}
+	return h.logAuditAs(ctx, req, decision, auditDecision)
+}
+
+// logAuditAs is logAudit with the recorded decision chosen by the caller
+func (h *Handler) logAuditAs(ctx context.Context, req *ToolCallRequest, decision policy.Response, auditDecision audit.Decision) error {
 	toolInput, err := json.Marshal(req)
 	if err != nil {
 		return fmt.Errorf("marshal request: %w", err)
 	}
 
-	auditDecision := audit.DecisionDeny
-	if decision.Allow {
-		auditDecision = audit.DecisionAllow
+	ctx = withAuditActor(ctx)
+	if !decision.Allow {
+		ctx = audit.WithDenyCategory(ctx, denyCategory(decision))
 	}
 
-	return h.audit.Log(ctx, toolInput, auditDecision, decision.Reason)
+	reason := TruncateReason(auditReason(decision), h.config.MaxReasonLength)
+	return audit.LogWithDetail(ctx, h.audit, toolInput, auditDecision, reason, h.auditDetail(req, decision))
+}
+
+// withAuditActor records the authenticated caller, if any, on the entries
+// logged with ctx
+func withAuditActor(ctx context.Context) context.Context {
+	user, ok := auth.GetUserFromStdContext(ctx)
+	if !ok || user == nil {
+		return ctx
+	}
+	return audit.WithActor(ctx, audit.Actor{Email: user.Email, Tenant: user.Tenant})
 }
 
-func (h *Handler) handleHumanApproval(ctx context.Context, c echo.Context, req *ToolCallRequest, reason string) error {
-	decision, err := h.approval.Enqueue(ctx, req.ToPolicyRequest(), reason)
+func (h *Handler) handleHumanApproval(ctx context.Context, c echo.Context, req *ToolCallRequest, policyDecision policy.Response) error {
+	if h.config.AutoApprove.allows(policyDecision, h.now()) {
+		return h.autoApprove(ctx, c, req, policyDecision)
+	}
+
+	if h.useGrant(ctx, req) {
+		return h.forwardRequest(ctx, c, req)
+	}
+
+	start := h.now()
+	stopHeartbeat := h.startHeartbeat(c)
+	decision, err := h.approval.Enqueue(ctx, req.ToPolicyRequest(), policyDecision.Reason)
+	stopHeartbeat()
 	if err != nil {
 		return h.errorResponse(c, http.StatusInternalServerError, "approval queue error")
 	}
 
+	if decision.TimedOut {
+		h.logApprovalTimeout(ctx, req, start)
+		return h.approvalTimeoutResponse(c)
+	}
+
+	h.logApprovalDecision(ctx, req, decision, start, approvalReason(decision))
+
 	if !decision.Approved {
 		return h.denyResponse(c, decision.Reason)
 	}
 
+	h.recordGrant(ctx, req, decision)
 	return h.forwardRequest(ctx, c, req)
 }
 
+// handleOverride asks a human for a one-time exception to an overridable
+// deny. Auto-approval never applies, and the outcome is audited either way.
+func (h *Handler) handleOverride(ctx context.Context, c echo.Context, req *ToolCallRequest, denial policy.Response) error {
+	start := h.now()
+	stopHeartbeat := h.startHeartbeat(c)
+	decision, err := h.approval.Enqueue(ctx, req.ToPolicyRequest(), "policy denied, override requested: "+denial.Reason)
+	stopHeartbeat()
+	if err != nil {
+		return h.errorResponse(c, http.StatusInternalServerError, "approval queue error")
+	}
+
+	if decision.TimedOut {
+		h.logApprovalTimeout(ctx, req, start)
+		return h.approvalTimeoutResponse(c)
+	}
+
+	approver := approverName(decision)
+
+	if !decision.Approved {
+		h.logApprovalDecision(ctx, req, decision, start, fmt.Sprintf("override rejected by %s: %s", approver, denial.Reason))
+		return h.denyResponse(c, denial.Reason)
+	}
+
+	log.Info().Str("tool", req.ToolName).Str("approver", approver).Msg("policy deny overridden")
+	h.logApprovalDecision(ctx, req, decision, start, fmt.Sprintf("override granted by %s: %s", approver, denial.Reason))
+
+	return h.forwardRequest(ctx, c, req)
+}
+
+// logApprovalDecision audits a human decision with the approver and how
+// long the call waited for it.
+func (h *Handler) logApprovalDecision(ctx context.Context, req *ToolCallRequest, decision approval.Decision, start time.Time, reason string) {
+	toolInput, err := json.Marshal(req)
+	if err != nil {
+		log.Warn().Err(err).Msg("audit logging failed")
+		return
+	}
+
+	auditDecision := audit.DecisionDeny
+	if decision.Approved {
+		auditDecision = audit.DecisionAllow
+	}
+
+	ctx = withAuditActor(ctx)
+	if !decision.Approved {
+		ctx = audit.WithDenyCategory(ctx, audit.DenyApprovalRejected)
+	}
+
+	reason = TruncateReason(reason, h.config.MaxReasonLength)
+	if err := audit.LogApproval(ctx, h.audit, toolInput, auditDecision, reason, approverName(decision), h.now().Sub(start)); err != nil {
+		log.Warn().Err(err).Msg("audit logging failed")
+	}
+}
+
+func approverName(decision approval.Decision) string {
+	if decision.DecidedBy == "" {
+		return "approver"
+	}
+	return decision.DecidedBy
+}
+
+func approvalReason(decision approval.Decision) string {
+	verb := "rejected"
+	if decision.Approved {
+		verb = "approved"
+	}
+
+	reason := fmt.Sprintf("%s by %s", verb, approverName(decision))
+	if decision.Reason != "" {
+		reason += ": " + decision.Reason
+	}
+	return reason
+}
+
+func (h *Handler) autoApprove(ctx context.Context, c echo.Context, req *ToolCallRequest, decision policy.Response) error {
+	h.recordAutoApproval(ctx, req, decision)
+	return h.forwardRequest(ctx, c, req)
+}
+
+func (h *Handler) recordAutoApproval(ctx context.Context, req *ToolCallRequest, decision policy.Response) {
+	log.Info().Str("tool", req.ToolName).Float64("risk", *decision.Risk).Msg("approval auto-approved")
+
+	resolved := policy.Response{
+		Allow:  true,
+		Reason: "auto-approved: " + decision.Reason,
+	}
+	// Recorded as auto_approve so reports can tell it from a policy allow
+	if err := h.logAuditAs(ctx, req, resolved, audit.DecisionAutoApprove); err != nil {
+		log.Warn().Err(err).Msg("audit logging failed")
+	}
+}
+
 func (h *Handler) forwardRequest(ctx context.Context, c echo.Context, req *ToolCallRequest) error {
-	result, err := h.forwarder.Forward(ctx, req.Upstream, req)
+	if format, _ := h.responseFormat(c); format == ResponseFormatRaw {
+		return h.forwardVerbatim(ctx, c, req)
+	}
+
+	result, err := h.forward(ctx, req)
 	if err != nil {
 		log.Error().Err(err).Str("upstream", req.Upstream).Msg("forward failed")
 		return h.errorResponse(c, http.StatusBadGateway, "upstream request failed")
 	}
 
+	result, denial := h.checkEgress(ctx, req, result)
+	if denial != nil {
+		return h.denyResponse(c, denial.Reason)
+	}
+
 	return c.JSON(http.StatusOK, ToolCallResponse{
-		Success: true,
-		Result:  result,
+		Success:  true,
+		Result:   result,
+		Warnings: req.warnings,
 	})
 }
 
+// forward sends an approved call to its gRPC route if it has one, otherwise
+// over HTTP to its upstream.
+func (h *Handler) forward(ctx context.Context, req *ToolCallRequest) (json.RawMessage, error) {
+	if route, ok := h.config.GRPCRoutes[req.ToolName]; ok {
+		return h.grpc.Forward(ctx, route, req)
+	}
+	return h.forwarder.Forward(ctx, h.upstreamFor(req), req)
+}
+
+// upstreamFor picks a replica for routed tools unless the client chose its
+// own upstream
+func (h *Handler) upstreamFor(req *ToolCallRequest) string {
+	if req.Upstream != h.config.DefaultUpstream {
+		return req.Upstream
+	}
+	if replica, ok := h.routes.route(req.ToolName); ok {
+		return replica
+	}
+	return req.Upstream
+}
+
+// Close stops upstream health checks and closes gRPC connections
+func (h *Handler) Close() error {
+	h.routes.stop()
+	return h.grpc.Close()
+}
+
 func (h *Handler) denyResponse(c echo.Context, reason string) error {
 	return c.JSON(http.StatusForbidden, ToolCallResponse{
 		Success: false,
@@ -131,9 +584,41 @@ func (h *Handler) denyResponse(c echo.Context, reason string) error {
 	})
 }
 
+func (h *Handler) approvalTimeoutResponse(c echo.Context) error {
+	message := h.config.ApprovalTimeoutMessage
+	if message == "" {
+		message = defaultApprovalTimeoutMessage
+	}
+
+	return c.JSON(http.StatusRequestTimeout, ToolCallResponse{
+		Success:   false,
+		Error:     message,
+		Code:      CodeApprovalTimeout,
+		Retryable: true,
+	})
+}
+
 func (h *Handler) errorResponse(c echo.Context, status int, message string) error {
 	return c.JSON(status, ToolCallResponse{
 		Success: false,
 		Error:   message,
 	})
-}
\ No newline at end of file
+}
+
+// TruncatedMarker ends a reason that was cut to fit the length limit
+const TruncatedMarker = "...[truncated]"
+
+// TruncateReason cuts reason to maxLength runes, marker included. A limit
+// too short for the marker cuts the reason without one.
+func TruncateReason(reason string, maxLength int) string {
+	if maxLength <= 0 || utf8.RuneCountInString(reason) <= maxLength {
+		return reason
+	}
+
+	runes := []rune(reason)
+	keep := maxLength - utf8.RuneCountInString(TruncatedMarker)
+	if keep <= 0 {
+		return string(runes[:maxLength])
+	}
+	return string(runes[:keep]) + TruncatedMarker
+}
@@ -1,15 +1,21 @@
 package proxy
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
+	"unicode/utf8"
 
 	"github.com/dagbolade/ai-governance-sidecar/internal/approval"
 	"github.com/dagbolade/ai-governance-sidecar/internal/audit"
+	"github.com/dagbolade/ai-governance-sidecar/internal/auth"
 	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
 	"github.com/labstack/echo/v4"
 )
@@ -31,10 +37,13 @@ type mockAuditStore struct {
 }
 
 func (m *mockAuditStore) Log(ctx context.Context, toolInput json.RawMessage, decision audit.Decision, reason string) error {
+	category, _ := audit.DenyCategoryFromContext(ctx)
 	m.entries = append(m.entries, audit.Entry{
-		ToolInput: toolInput,
-		Decision:  decision,
-		Reason:    reason,
+		ToolInput:    toolInput,
+		Decision:     decision,
+		Reason:       reason,
+		DenyCategory: category,
+		DryRun:       audit.IsDryRun(ctx),
 	})
 	return nil
 }
@@ -153,6 +162,79 @@ func TestHandleToolCall_Denied(t *testing.T) {
 	}
 }
 
+// detailAuditStore records structured detail passed with entries
+type detailAuditStore struct {
+	mockAuditStore
+	details []json.RawMessage
+}
+
+func (m *detailAuditStore) LogDetail(ctx context.Context, toolInput json.RawMessage, decision audit.Decision, reason string, detail json.RawMessage) error {
+	m.details = append(m.details, detail)
+	return m.Log(ctx, toolInput, decision, reason)
+}
+
+func TestHandleToolCall_AuditDetail(t *testing.T) {
+	mockPolicy := &mockPolicyEvaluator{
+		response: policy.Response{Allow: false, Reason: "contains PII", Policy: "pii_guard"},
+	}
+
+	tests := []struct {
+		level        string
+		expectDetail bool
+	}{
+		{level: AuditDetailLean},
+		{level: AuditDetailFull, expectDetail: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.level, func(t *testing.T) {
+			mockAudit := &detailAuditStore{}
+			config := ProxyConfig{DefaultUpstream: "http://localhost:9000", AuditDetail: tt.level}
+			handler := NewHandler(config, mockPolicy, mockAudit, &mockApprovalQueue{})
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/tool/call", strings.NewReader(`{"tool_name":"send_email","args":{"to":"x"}}`))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+
+			if err := handler.HandleToolCall(e.NewContext(req, rec)); err != nil {
+				t.Fatalf("handler failed: %v", err)
+			}
+
+			if !tt.expectDetail {
+				if len(mockAudit.details) != 0 {
+					t.Errorf("expected lean audit to record no detail, got %s", mockAudit.details)
+				}
+				return
+			}
+
+			if len(mockAudit.details) != 1 {
+				t.Fatalf("expected 1 detailed entry, got %d", len(mockAudit.details))
+			}
+
+			var detail struct {
+				Policy      string `json:"policy"`
+				CombineMode string `json:"combine_mode"`
+				Input       struct {
+					ToolName string `json:"tool_name"`
+				} `json:"input"`
+			}
+			if err := json.Unmarshal(mockAudit.details[0], &detail); err != nil {
+				t.Fatalf("failed to parse detail: %v", err)
+			}
+			if detail.Policy != "pii_guard" {
+				t.Errorf("expected deciding policy pii_guard, got %q", detail.Policy)
+			}
+			if detail.CombineMode != policy.CombineDenyOverrides {
+				t.Errorf("expected combine mode %s, got %q", policy.CombineDenyOverrides, detail.CombineMode)
+			}
+			if detail.Input.ToolName != "send_email" {
+				t.Errorf("expected input snapshot, got %s", mockAudit.details[0])
+			}
+		})
+	}
+}
+
 func TestHandleToolCall_InvalidRequest(t *testing.T) {
 	mockPolicy := &mockPolicyEvaluator{}
 	mockAudit := &mockAuditStore{}
@@ -205,6 +287,23 @@ func TestParseRequest(t *testing.T) {
 			expectError: false,
 			expectValue: "http://default:9000",
 		},
+		{
+			name:        "defaults method to POST",
+			body:        `{"tool_name":"test","args":{}}`,
+			expectError: false,
+			expectValue: http.MethodPost,
+		},
+		{
+			name:        "normalizes method",
+			body:        `{"tool_name":"test","args":{},"method":"patch"}`,
+			expectError: false,
+			expectValue: http.MethodPatch,
+		},
+		{
+			name:        "rejects disallowed method",
+			body:        `{"tool_name":"test","args":{},"method":"TRACE"}`,
+			expectError: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -230,6 +329,537 @@ func TestParseRequest(t *testing.T) {
 					t.Errorf("expected upstream %s, got %s", tt.expectValue, result.Upstream)
 				}
 			}
+
+			if !tt.expectError && strings.Contains(tt.name, "method") {
+				if result.Method != tt.expectValue {
+					t.Errorf("expected method %s, got %s", tt.expectValue, result.Method)
+				}
+			}
+		})
+	}
+}
+func TestHandleToolCall_TruncatesLongPolicyReason(t *testing.T) {
+	longReason := strings.Repeat("x", 500)
+	mockPolicy := &mockPolicyEvaluator{
+		response: policy.Response{Allow: false, Reason: longReason},
+	}
+	mockAudit := &mockAuditStore{}
+
+	config := ProxyConfig{DefaultUpstream: "http://localhost:9000", Timeout: 10, MaxReasonLength: 100}
+	handler := NewHandler(config, mockPolicy, mockAudit, &mockApprovalQueue{})
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/tool/call", strings.NewReader(`{"tool_name":"test","args":{}}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := handler.HandleToolCall(c); err != nil {
+		t.Fatalf("handler failed: %v", err)
+	}
+
+	if len(mockAudit.entries) != 1 {
+		t.Fatalf("expected 1 audit entry, got %d", len(mockAudit.entries))
+	}
+
+	stored := mockAudit.entries[0].Reason
+	if len(stored) != 100 {
+		t.Errorf("expected stored reason of 100 chars, got %d", len(stored))
+	}
+	if !strings.HasSuffix(stored, TruncatedMarker) {
+		t.Errorf("expected truncation marker, got %q", stored)
+	}
+}
+
+func TestTruncateReason(t *testing.T) {
+	if got := TruncateReason("short", 100); got != "short" {
+		t.Errorf("expected reason unchanged, got %q", got)
+	}
+	if got := TruncateReason(strings.Repeat("a", 100), 100); len(got) != 100 || strings.HasSuffix(got, TruncatedMarker) {
+		t.Errorf("expected reason at limit unchanged, got %q", got)
+	}
+	if got := TruncateReason("anything", 0); got != "anything" {
+		t.Errorf("expected no limit when max is 0, got %q", got)
+	}
+	if got := TruncateReason(strings.Repeat("é", 100), 20); utf8.RuneCountInString(got) != 20 || !strings.HasSuffix(got, TruncatedMarker) {
+		t.Errorf("expected 20 runes ending in the marker, got %q", got)
+	}
+	for _, limit := range []int{1, 5, len(TruncatedMarker)} {
+		if got := TruncateReason(strings.Repeat("a", 100), limit); utf8.RuneCountInString(got) != limit {
+			t.Errorf("expected a limit of %d shorter than the marker to hold, got %q", limit, got)
+		}
+	}
+}
+
+type timeoutApprovalQueue struct {
+	mockApprovalQueue
+}
+
+func (m *timeoutApprovalQueue) Enqueue(ctx context.Context, req policy.Request, reason string) (approval.Decision, error) {
+	return approval.Decision{Approved: false, Reason: "approval timeout", TimedOut: true}, nil
+}
+
+func TestHandleToolCall_ApprovalTimeout(t *testing.T) {
+	mockPolicy := &mockPolicyEvaluator{
+		response: policy.Response{Allow: true, HumanRequired: true, Reason: "needs review"},
+	}
+
+	tests := []struct {
+		name          string
+		message       string
+		expectMessage string
+	}{
+		{name: "default message", expectMessage: defaultApprovalTimeoutMessage},
+		{name: "configured message", message: "nobody answered, try later", expectMessage: "nobody answered, try later"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := ProxyConfig{DefaultUpstream: "http://unused:9000", ApprovalTimeoutMessage: tt.message}
+			handler := NewHandler(config, mockPolicy, &mockAuditStore{}, &timeoutApprovalQueue{})
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/tool/call", strings.NewReader(`{"tool_name":"test_tool","args":{}}`))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			if err := handler.HandleToolCall(c); err != nil {
+				t.Fatalf("handler failed: %v", err)
+			}
+
+			if rec.Code != http.StatusRequestTimeout {
+				t.Errorf("expected status 408, got %d", rec.Code)
+			}
+
+			var resp ToolCallResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to parse response: %v", err)
+			}
+
+			if resp.Code != CodeApprovalTimeout {
+				t.Errorf("expected code %s, got %q", CodeApprovalTimeout, resp.Code)
+			}
+			if !resp.Retryable {
+				t.Error("expected timeout to be marked retryable")
+			}
+			if resp.Error != tt.expectMessage {
+				t.Errorf("expected message %q, got %q", tt.expectMessage, resp.Error)
+			}
+		})
+	}
+}
+
+// recordingApprovalQueue returns a fixed decision and remembers reasons
+type recordingApprovalQueue struct {
+	mockApprovalQueue
+	decision approval.Decision
+	reasons  []string
+}
+
+func (m *recordingApprovalQueue) Enqueue(ctx context.Context, req policy.Request, reason string) (approval.Decision, error) {
+	m.reasons = append(m.reasons, reason)
+	return m.decision, nil
+}
+
+func TestHandleToolCall_DenyOverride(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"success"}`))
+	}))
+	defer upstream.Close()
+
+	tests := []struct {
+		name            string
+		overrideAllowed bool
+		decision        approval.Decision
+		expectStatus    int
+		expectEnqueued  bool
+		expectAudit     []audit.Decision
+	}{
+		{
+			name:         "hard deny",
+			decision:     approval.Decision{Approved: true},
+			expectStatus: http.StatusForbidden,
+			expectAudit:  []audit.Decision{audit.DecisionDeny},
+		},
+		{
+			name:            "override granted",
+			overrideAllowed: true,
+			decision:        approval.Decision{Approved: true, DecidedBy: "alice"},
+			expectStatus:    http.StatusOK,
+			expectEnqueued:  true,
+			expectAudit:     []audit.Decision{audit.DecisionDeny, audit.DecisionAllow},
+		},
+		{
+			name:            "override rejected",
+			overrideAllowed: true,
+			decision:        approval.Decision{Approved: false, Reason: "no"},
+			expectStatus:    http.StatusForbidden,
+			expectEnqueued:  true,
+			expectAudit:     []audit.Decision{audit.DecisionDeny, audit.DecisionDeny},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPolicy := &mockPolicyEvaluator{
+				response: policy.Response{Allow: false, Reason: "outside change window", OverrideAllowed: tt.overrideAllowed},
+			}
+			mockAudit := &mockAuditStore{}
+			queue := &recordingApprovalQueue{decision: tt.decision}
+			handler := NewHandler(ProxyConfig{DefaultUpstream: upstream.URL, Timeout: 10}, mockPolicy, mockAudit, queue)
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/tool/call", strings.NewReader(`{"tool_name":"deploy","args":{}}`))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+
+			if err := handler.HandleToolCall(e.NewContext(req, rec)); err != nil {
+				t.Fatalf("handler failed: %v", err)
+			}
+
+			if rec.Code != tt.expectStatus {
+				t.Errorf("expected status %d, got %d", tt.expectStatus, rec.Code)
+			}
+
+			if enqueued := len(queue.reasons) == 1; enqueued != tt.expectEnqueued {
+				t.Fatalf("expected enqueued=%v, got reasons %v", tt.expectEnqueued, queue.reasons)
+			}
+			if tt.expectEnqueued && !strings.Contains(queue.reasons[0], "outside change window") {
+				t.Errorf("expected approval reason to carry the denial, got %q", queue.reasons[0])
+			}
+
+			if len(mockAudit.entries) != len(tt.expectAudit) {
+				t.Fatalf("expected %d audit entries, got %d", len(tt.expectAudit), len(mockAudit.entries))
+			}
+			for i, want := range tt.expectAudit {
+				if mockAudit.entries[i].Decision != want {
+					t.Errorf("audit entry %d: expected %s, got %s", i, want, mockAudit.entries[i].Decision)
+				}
+			}
+			if tt.decision.DecidedBy == "alice" && !strings.Contains(mockAudit.entries[1].Reason, "override granted by alice") {
+				t.Errorf("expected grant to be audited with approver, got %q", mockAudit.entries[1].Reason)
+			}
+		})
+	}
+}
+
+type headerPolicyEvaluator struct {
+	mockPolicyEvaluator
+}
+
+func (m *headerPolicyEvaluator) Evaluate(ctx context.Context, req policy.Request) (policy.Response, error) {
+	if req.Headers["X-Environment"] == "production" {
+		return policy.Response{Allow: false, Reason: "blocked in production"}, nil
+	}
+	return policy.Response{Allow: true, Reason: "ok"}, nil
+}
+
+func TestHandleToolCall_PolicyHeaders(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"status":"success"}`))
+	}))
+	defer upstream.Close()
+
+	tests := []struct {
+		name         string
+		environment  string
+		expectStatus int
+	}{
+		{name: "production denied", environment: "production", expectStatus: http.StatusForbidden},
+		{name: "staging allowed", environment: "staging", expectStatus: http.StatusOK},
+		{name: "header absent", expectStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := ProxyConfig{
+				DefaultUpstream: upstream.URL,
+				Timeout:         10,
+				PolicyHeaders:   []string{"x-environment", "X-Cost-Center"},
+			}
+			handler := NewHandler(config, &headerPolicyEvaluator{}, &mockAuditStore{}, &mockApprovalQueue{})
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/tool/call", strings.NewReader(`{"tool_name":"test_tool","args":{}}`))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			if tt.environment != "" {
+				req.Header.Set("X-Environment", tt.environment)
+			}
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			if err := handler.HandleToolCall(c); err != nil {
+				t.Fatalf("handler failed: %v", err)
+			}
+
+			if rec.Code != tt.expectStatus {
+				t.Errorf("expected status %d, got %d", tt.expectStatus, rec.Code)
+			}
+		})
+	}
+}
+
+func TestCaptureHeadersIgnoresUnconfigured(t *testing.T) {
+	handler := &Handler{config: ProxyConfig{PolicyHeaders: []string{"X-Environment"}}}
+
+	header := http.Header{}
+	header.Set("X-Environment", "production")
+	header.Set("Authorization", "Bearer secret")
+
+	captured := handler.captureHeaders(header)
+	if len(captured) != 1 || captured["X-Environment"] != "production" {
+		t.Errorf("unexpected captured headers: %v", captured)
+	}
+}
+
+type uploadSizePolicy struct {
+	mockPolicyEvaluator
+	maxSize int64
+}
+
+func (m *uploadSizePolicy) Evaluate(ctx context.Context, req policy.Request) (policy.Response, error) {
+	for _, file := range req.Files {
+		if file.Size > m.maxSize {
+			return policy.Response{Allow: false, Reason: "upload too large: " + file.Name}, nil
+		}
+	}
+	return policy.Response{Allow: true, Reason: "ok"}, nil
+}
+
+func newMultipartRequest(t *testing.T, fileName string, content []byte) *http.Request {
+	t.Helper()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	writer.WriteField("tool_name", "upload_tool")
+	writer.WriteField("args", `{"folder":"reports"}`)
+
+	part, err := writer.CreateFormFile("document", fileName)
+	if err != nil {
+		t.Fatalf("create form file: %v", err)
+	}
+	part.Write(content)
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/tool/call", &body)
+	req.Header.Set(echo.HeaderContentType, writer.FormDataContentType())
+	return req
+}
+
+func TestHandleToolCall_MultipartUpload(t *testing.T) {
+	var received struct {
+		toolName string
+		fileBody string
+	}
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("upstream expected multipart body: %v", err)
+		}
+		received.toolName = r.FormValue("tool_name")
+		if file, _, err := r.FormFile("document"); err == nil {
+			data, _ := io.ReadAll(file)
+			received.fileBody = string(data)
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"status":"stored"}`))
+	}))
+	defer upstream.Close()
+
+	tests := []struct {
+		name         string
+		content      []byte
+		expectStatus int
+	}{
+		{name: "small upload forwarded", content: []byte("hello"), expectStatus: http.StatusOK},
+		{name: "large upload denied", content: bytes.Repeat([]byte("x"), 2048), expectStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received.toolName, received.fileBody = "", ""
+			mockAudit := &mockAuditStore{}
+			config := ProxyConfig{DefaultUpstream: upstream.URL, Timeout: 10}
+			handler := NewHandler(config, &uploadSizePolicy{maxSize: 1024}, mockAudit, &mockApprovalQueue{})
+
+			e := echo.New()
+			rec := httptest.NewRecorder()
+			c := e.NewContext(newMultipartRequest(t, "report.txt", tt.content), rec)
+
+			if err := handler.HandleToolCall(c); err != nil {
+				t.Fatalf("handler failed: %v", err)
+			}
+
+			if rec.Code != tt.expectStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectStatus, rec.Code, rec.Body.String())
+			}
+
+			if tt.expectStatus == http.StatusOK {
+				if received.toolName != "upload_tool" || received.fileBody != string(tt.content) {
+					t.Errorf("upstream did not receive the upload: %+v", received)
+				}
+			} else if received.toolName != "" {
+				t.Error("denied upload must not reach upstream")
+			}
+
+			if !strings.Contains(string(mockAudit.entries[0].ToolInput), `"report.txt"`) {
+				t.Errorf("expected file metadata in audit, got %s", mockAudit.entries[0].ToolInput)
+			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestParseRequest_IgnoresFilesInJSONBody(t *testing.T) {
+	handler := &Handler{config: ProxyConfig{DefaultUpstream: "http://default:9000"}}
+
+	e := echo.New()
+	body := `{"tool_name":"test","args":{},"files":[{"name":"fake.txt","size":1}]}`
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	c := e.NewContext(req, httptest.NewRecorder())
+
+	result, err := handler.parseRequest(c)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Files) != 0 {
+		t.Errorf("expected files from JSON body to be ignored, got %v", result.Files)
+	}
+}
+
+func TestHandleDebugEvaluate(t *testing.T) {
+	mockPolicy := &mockPolicyEvaluator{
+		response: policy.Response{Allow: false, Reason: "blocked by policy"},
+	}
+	mockAudit := &mockAuditStore{}
+	config := ProxyConfig{
+		DefaultUpstream: "http://tools:9000",
+		PolicyHeaders:   []string{"X-Environment"},
+	}
+	handler := NewHandler(config, mockPolicy, mockAudit, &mockApprovalQueue{})
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/debug/evaluate", strings.NewReader(`{"tool_name":"deploy","args":{"env":"prod"}}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set("X-Environment", "production")
+	req = req.WithContext(auth.WithUser(req.Context(), &auth.User{ID: "alice", Email: "alice@example.com", Roles: []string{auth.RoleAdmin}, Tenant: "acme"}))
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := handler.HandleDebugEvaluate(c); err != nil {
+		t.Fatalf("handler failed: %v", err)
+	}
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+
+	var trace struct {
+		Input    map[string]interface{} `json:"input"`
+		Decision policy.Response        `json:"decision"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &trace); err != nil {
+		t.Fatalf("failed to parse trace: %v", err)
+	}
+
+	headers, _ := trace.Input["headers"].(map[string]interface{})
+	if headers["X-Environment"] != "production" {
+		t.Errorf("expected headers in traced input, got %v", trace.Input)
+	}
+	metadata, _ := trace.Input["metadata"].(map[string]interface{})
+	if metadata["upstream"] != "http://tools:9000" || metadata["method"] != http.MethodPost {
+		t.Errorf("expected metadata in traced input, got %v", trace.Input)
+	}
+	user, _ := trace.Input["user"].(map[string]interface{})
+	if user["id"] != "alice" || user["email"] != "alice@example.com" || user["tenant"] != "acme" {
+		t.Errorf("expected the caller in traced input, got %v", trace.Input)
+	}
+	if trace.Decision.Reason != "blocked by policy" {
+		t.Errorf("unexpected decision: %+v", trace.Decision)
+	}
+
+	if len(mockAudit.entries) != 0 {
+		t.Error("debug evaluation must not be audited")
+	}
+}
+
+// approvalAuditStore records approver entries
+type approvalAuditStore struct {
+	mockAuditStore
+	approvers []string
+}
+
+func (m *approvalAuditStore) LogApproval(ctx context.Context, toolInput json.RawMessage, decision audit.Decision, reason, approver string, latency time.Duration) error {
+	m.approvers = append(m.approvers, approver)
+	return m.Log(ctx, toolInput, decision, reason)
+}
+
+func TestHandleToolCall_AuditsApprover(t *testing.T) {
+	mockPolicy := &mockPolicyEvaluator{
+		response: policy.Response{Allow: true, HumanRequired: true, Reason: "payments need review"},
+	}
+	mockAudit := &approvalAuditStore{}
+	queue := &recordingApprovalQueue{decision: approval.Decision{Approved: false, DecidedBy: "alice@example.com", Reason: "wrong account"}}
+	handler := NewHandler(ProxyConfig{DefaultUpstream: "http://localhost:9000"}, mockPolicy, mockAudit, queue)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/tool/call", strings.NewReader(`{"tool_name":"send_payment","args":{}}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+
+	if err := handler.HandleToolCall(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("handler failed: %v", err)
+	}
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", rec.Code)
+	}
+
+	if len(mockAudit.approvers) != 1 || mockAudit.approvers[0] != "alice@example.com" {
+		t.Fatalf("expected one approver entry for alice, got %v", mockAudit.approvers)
+	}
+	last := mockAudit.entries[len(mockAudit.entries)-1]
+	if last.Decision != audit.DecisionDeny || last.Reason != "rejected by alice@example.com: wrong account" {
+		t.Errorf("unexpected approval entry: %+v", last)
+	}
+}
+
+func TestHandleToolCall_ReturnsPolicyWarnings(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"success"}`))
+	}))
+	defer upstream.Close()
+
+	mockPolicy := &mockPolicyEvaluator{
+		response: policy.Response{Allow: true, Reason: "all policies passed", Warnings: []string{"tool is deprecated"}},
+	}
+	mockAudit := &mockAuditStore{}
+	handler := NewHandler(ProxyConfig{DefaultUpstream: upstream.URL, Timeout: 10}, mockPolicy, mockAudit, &mockApprovalQueue{})
+
+	req := httptest.NewRequest(http.MethodPost, "/tool/call", strings.NewReader(`{"tool_name":"legacy_tool","args":{}}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+
+	if err := handler.HandleToolCall(echo.New().NewContext(req, rec)); err != nil {
+		t.Fatalf("handler failed: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+
+	var resp ToolCallResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !resp.Success || len(resp.Warnings) != 1 || resp.Warnings[0] != "tool is deprecated" {
+		t.Errorf("expected a successful response carrying the warning, got %+v", resp)
+	}
+
+	if len(mockAudit.entries) != 1 {
+		t.Fatalf("expected 1 audit entry, got %d", len(mockAudit.entries))
+	}
+	entry := mockAudit.entries[0]
+	if entry.Decision != audit.DecisionAllow || !strings.Contains(entry.Reason, "warnings: tool is deprecated") {
+		t.Errorf("expected allowed audit entry with the warning, got %s %q", entry.Decision, entry.Reason)
+	}
+}
@@ -0,0 +1,55 @@
+package proxy
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// startHeartbeat keeps a call blocked on approval alive by writing a
+// newline, which JSON ignores, every ApprovalHeartbeat seconds. The first
+// heartbeat commits a 200 status, so from then on the outcome is carried
+// only by the body's success and code fields. The returned stop function
+// waits until no more heartbeats can be written.
+func (h *Handler) startHeartbeat(c echo.Context) (stop func()) {
+	interval := time.Duration(h.config.ApprovalHeartbeat) * time.Second
+	if interval <= 0 {
+		return func() {}
+	}
+	// A raw response relays the upstream status, which a heartbeat would hide
+	if format, _ := h.responseFormat(c); format == ResponseFormatRaw {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	exited := make(chan struct{})
+
+	go func() {
+		defer close(exited)
+		for {
+			select {
+			case <-done:
+				return
+			case <-h.clock.After(interval):
+			}
+
+			res := c.Response()
+			if !res.Committed {
+				res.Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+				res.WriteHeader(http.StatusOK)
+			}
+			if _, err := res.Write([]byte("\n")); err != nil {
+				log.Debug().Err(err).Msg("approval heartbeat failed, client gone")
+				return
+			}
+			http.NewResponseController(res.Writer).Flush()
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-exited
+	}
+}
@@ -0,0 +1,111 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/approval"
+	"github.com/dagbolade/ai-governance-sidecar/internal/clock"
+	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
+	"github.com/labstack/echo/v4"
+)
+
+// signalingRecorder reports each body write while the handler is blocked
+type signalingRecorder struct {
+	*httptest.ResponseRecorder
+	writes chan string
+}
+
+func (r *signalingRecorder) Write(p []byte) (int, error) {
+	n, err := r.ResponseRecorder.Write(p)
+	r.writes <- string(p)
+	return n, err
+}
+
+// slowApprovalQueue advances the clock until it has seen the given number
+// of heartbeats, then approves
+type slowApprovalQueue struct {
+	mockApprovalQueue
+	clock      *clock.Fake
+	interval   time.Duration
+	writes     chan string
+	heartbeats int
+	seen       []string
+}
+
+func (q *slowApprovalQueue) Enqueue(ctx context.Context, req policy.Request, reason string) (approval.Decision, error) {
+	for len(q.seen) < q.heartbeats {
+		q.clock.Advance(q.interval)
+		select {
+		case write := <-q.writes:
+			q.seen = append(q.seen, write)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	return approval.Decision{Approved: true, DecidedBy: "alice"}, nil
+}
+
+func TestHandleToolCall_ApprovalHeartbeat(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"deployed"}`))
+	}))
+	defer upstream.Close()
+
+	fake := clock.NewFake(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	rec := &signalingRecorder{ResponseRecorder: httptest.NewRecorder(), writes: make(chan string, 10)}
+	queue := &slowApprovalQueue{clock: fake, interval: 15 * time.Second, writes: rec.writes, heartbeats: 2}
+
+	config := ProxyConfig{DefaultUpstream: upstream.URL, Timeout: 5, ApprovalHeartbeat: 15}
+	handler := NewHandler(config, &mockPolicyEvaluator{response: policy.Response{Allow: true, HumanRequired: true}}, &mockAuditStore{}, queue)
+	handler.SetClock(fake)
+
+	req := httptest.NewRequest(http.MethodPost, "/tool/call", strings.NewReader(`{"tool_name":"deploy","args":{}}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	if err := handler.HandleToolCall(echo.New().NewContext(req, rec)); err != nil {
+		t.Fatalf("handler failed: %v", err)
+	}
+
+	for _, write := range queue.seen {
+		if write != "\n" {
+			t.Errorf("expected a newline heartbeat during the wait, got %q", write)
+		}
+	}
+	if !rec.Flushed {
+		t.Error("expected heartbeats to be flushed")
+	}
+
+	body := rec.Body.String()
+	if !strings.HasPrefix(body, "\n\n") {
+		t.Errorf("expected the body to start with the heartbeats, got %q", body)
+	}
+	var resp ToolCallResponse
+	if err := json.Unmarshal([]byte(body), &resp); err != nil {
+		t.Fatalf("expected heartbeats to leave valid JSON, got %q: %v", body, err)
+	}
+	if !resp.Success {
+		t.Errorf("expected the approved call to succeed, got %+v", resp)
+	}
+}
+
+func TestHandleToolCall_NoHeartbeatByDefault(t *testing.T) {
+	handler := NewHandler(ProxyConfig{DefaultUpstream: "http://localhost:9000"},
+		&mockPolicyEvaluator{response: policy.Response{Allow: true, HumanRequired: true}}, &mockAuditStore{},
+		&recordingApprovalQueue{decision: approval.Decision{Approved: false, DecidedBy: "alice"}})
+
+	req := httptest.NewRequest(http.MethodPost, "/tool/call", strings.NewReader(`{"tool_name":"deploy","args":{}}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+
+	if err := handler.HandleToolCall(echo.New().NewContext(req, rec)); err != nil {
+		t.Fatalf("handler failed: %v", err)
+	}
+	if rec.Code != http.StatusForbidden || strings.HasPrefix(rec.Body.String(), "\n") {
+		t.Errorf("expected a plain 403 without heartbeats, got %d %q", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,41 @@
+package proxy
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
+	"github.com/dagbolade/ai-governance-sidecar/internal/toolmatch"
+)
+
+// justificationPolicy names missing-justification denials in audit detail
+const justificationPolicy = "justification_required"
+
+// ErrJustificationRequired is returned when a sensitive call has no
+// justification text
+var ErrJustificationRequired = errors.New("justification is required for this tool")
+
+// checkJustification rejects calls to sensitive tools that carry no
+// justification. A tool is sensitive when it is listed in JustificationTools
+// or a policy flags the call. Calls that are denied outright are left to the
+// deny path.
+func (h *Handler) checkJustification(req *ToolCallRequest, decision policy.Response) error {
+	if !decision.Allow && !decision.OverrideAllowed {
+		return nil
+	}
+	if !decision.JustificationRequired && !h.justificationTool(req.ToolName) {
+		return nil
+	}
+	if strings.TrimSpace(req.Justification) == "" {
+		return ErrJustificationRequired
+	}
+	return nil
+}
+
+func (h *Handler) justificationTool(toolName string) bool {
+	return toolmatch.MatchAny(h.config.JustificationTools, toolName)
+}
+
+func justificationDenial(err error) policy.Response {
+	return policy.Response{Allow: false, Reason: err.Error(), Policy: justificationPolicy}
+}
@@ -0,0 +1,67 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/audit"
+	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
+	"github.com/labstack/echo/v4"
+)
+
+func TestHandleToolCall_Justification(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer upstream.Close()
+
+	tests := []struct {
+		name          string
+		toolName      string
+		justification string
+		policyFlag    bool
+		expectStatus  int
+		expectAudit   audit.Decision
+	}{
+		{name: "sensitive tool without justification", toolName: "drop_table", expectStatus: http.StatusUnprocessableEntity, expectAudit: audit.DecisionDeny},
+		{name: "sensitive tool with blank justification", toolName: "drop_table", justification: "   ", expectStatus: http.StatusUnprocessableEntity, expectAudit: audit.DecisionDeny},
+		{name: "sensitive tool with justification", toolName: "drop_table", justification: "INC-204 cleanup", expectStatus: http.StatusOK, expectAudit: audit.DecisionAllow},
+		{name: "normal tool without justification", toolName: "read_file", expectStatus: http.StatusOK, expectAudit: audit.DecisionAllow},
+		{name: "policy-flagged tool without justification", toolName: "read_file", policyFlag: true, expectStatus: http.StatusUnprocessableEntity, expectAudit: audit.DecisionDeny},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAudit := &mockAuditStore{}
+			config := ProxyConfig{
+				DefaultUpstream:    upstream.URL,
+				Timeout:            5,
+				JustificationTools: []string{"drop_table"},
+			}
+			evaluator := &mockPolicyEvaluator{response: policy.Response{Allow: true, JustificationRequired: tt.policyFlag}}
+			handler := NewHandler(config, evaluator, mockAudit, &mockApprovalQueue{})
+
+			e := echo.New()
+			body := `{"tool_name":"` + tt.toolName + `","args":{},"justification":"` + tt.justification + `"}`
+			req := httptest.NewRequest(http.MethodPost, "/tool/call", strings.NewReader(body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+
+			if err := handler.HandleToolCall(e.NewContext(req, rec)); err != nil {
+				t.Fatalf("handler failed: %v", err)
+			}
+
+			if rec.Code != tt.expectStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.expectStatus, rec.Code, rec.Body.String())
+			}
+			if len(mockAudit.entries) != 1 || mockAudit.entries[0].Decision != tt.expectAudit {
+				t.Fatalf("expected one %s audit entry, got %+v", tt.expectAudit, mockAudit.entries)
+			}
+			if tt.justification != "" && !strings.Contains(string(mockAudit.entries[0].ToolInput), tt.justification) {
+				t.Errorf("expected justification in audited tool input, got %s", mockAudit.entries[0].ToolInput)
+			}
+		})
+	}
+}
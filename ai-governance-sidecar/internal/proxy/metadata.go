@@ -0,0 +1,46 @@
+package proxy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
+)
+
+// requiredMetadataPolicy names missing-metadata denials in audit detail
+const requiredMetadataPolicy = "required_metadata"
+
+// ErrMissingMetadata is returned when a call lacks a required metadata key
+var ErrMissingMetadata = errors.New("missing required metadata")
+
+// checkMetadata rejects calls that omit any RequiredMetadata key or carry it
+// with an empty value.
+func (h *Handler) checkMetadata(req *ToolCallRequest) error {
+	var missing []string
+	for _, key := range h.config.RequiredMetadata {
+		if isBlankMetadata(req.Metadata[key]) {
+			missing = append(missing, key)
+		}
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrMissingMetadata, strings.Join(missing, ", "))
+}
+
+func isBlankMetadata(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(v) == ""
+	default:
+		return false
+	}
+}
+
+func metadataDenial(err error) policy.Response {
+	return policy.Response{Allow: false, Reason: err.Error(), Policy: requiredMetadataPolicy}
+}
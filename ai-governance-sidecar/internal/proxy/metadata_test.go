@@ -0,0 +1,63 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/audit"
+	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
+	"github.com/labstack/echo/v4"
+)
+
+func TestHandleToolCall_RequiredMetadata(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer upstream.Close()
+
+	tests := []struct {
+		name         string
+		metadata     string
+		expectStatus int
+		expectAudit  audit.Decision
+	}{
+		{name: "present", metadata: `{"cost_center":"eng-42","ticket":"OPS-1"}`, expectStatus: http.StatusOK, expectAudit: audit.DecisionAllow},
+		{name: "missing", metadata: `{"ticket":"OPS-1"}`, expectStatus: http.StatusUnprocessableEntity, expectAudit: audit.DecisionDeny},
+		{name: "empty", metadata: `{"cost_center":"  ","ticket":"OPS-1"}`, expectStatus: http.StatusUnprocessableEntity, expectAudit: audit.DecisionDeny},
+		{name: "no metadata", metadata: `null`, expectStatus: http.StatusUnprocessableEntity, expectAudit: audit.DecisionDeny},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAudit := &mockAuditStore{}
+			config := ProxyConfig{
+				DefaultUpstream:  upstream.URL,
+				Timeout:          5,
+				RequiredMetadata: []string{"cost_center"},
+			}
+			handler := NewHandler(config, &mockPolicyEvaluator{response: policy.Response{Allow: true}}, mockAudit, &mockApprovalQueue{})
+
+			e := echo.New()
+			body := `{"tool_name":"deploy","args":{},"metadata":` + tt.metadata + `}`
+			req := httptest.NewRequest(http.MethodPost, "/tool/call", strings.NewReader(body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+
+			if err := handler.HandleToolCall(e.NewContext(req, rec)); err != nil {
+				t.Fatalf("handler failed: %v", err)
+			}
+
+			if rec.Code != tt.expectStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.expectStatus, rec.Code, rec.Body.String())
+			}
+			if len(mockAudit.entries) != 1 || mockAudit.entries[0].Decision != tt.expectAudit {
+				t.Fatalf("expected one %s audit entry, got %+v", tt.expectAudit, mockAudit.entries)
+			}
+			if tt.expectAudit == audit.DecisionDeny && !strings.Contains(mockAudit.entries[0].Reason, "cost_center") {
+				t.Errorf("expected reason to name the missing key, got %q", mockAudit.entries[0].Reason)
+			}
+		})
+	}
+}
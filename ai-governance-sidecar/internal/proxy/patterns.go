@@ -0,0 +1,172 @@
+package proxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
+	"github.com/rs/zerolog/log"
+)
+
+// Pattern actions
+const (
+	PatternActionDeny          = "deny"
+	PatternActionHumanRequired = "human_required"
+)
+
+// sensitivePatternPrefix and toolNamePatternPrefix, followed by the
+// pattern name, name pattern denials in audit detail
+const (
+	sensitivePatternPrefix = "sensitive_pattern:"
+	toolNamePatternPrefix  = "tool_name_pattern:"
+)
+
+// SensitivePattern flags tool calls whose argument values match Pattern
+type SensitivePattern struct {
+	Name    string `json:"name"`
+	Pattern string `json:"pattern"`
+	Action  string `json:"action"` // deny (default) or human_required
+}
+
+type compiledPattern struct {
+	name   string
+	re     *regexp.Regexp
+	action string
+}
+
+// PatternMatcher scans tool arguments for sensitive values without
+// requiring a policy module.
+type PatternMatcher struct {
+	patterns []compiledPattern
+}
+
+// NewPatternMatcher compiles patterns, skipping invalid ones with a warning.
+func NewPatternMatcher(patterns []SensitivePattern) *PatternMatcher {
+	m := &PatternMatcher{}
+
+	for _, p := range patterns {
+		re, err := regexp.Compile(p.Pattern)
+		if err != nil {
+			log.Warn().Err(err).Str("pattern", p.Name).Msg("invalid sensitive pattern, skipping")
+			continue
+		}
+
+		action := p.Action
+		if action != PatternActionHumanRequired {
+			action = PatternActionDeny
+		}
+		m.patterns = append(m.patterns, compiledPattern{name: p.Name, re: re, action: action})
+	}
+
+	return m
+}
+
+// Match returns the first pattern matching any string or number in args.
+// Deny patterns take precedence over human_required ones.
+func (m *PatternMatcher) Match(args json.RawMessage) (string, string, bool) {
+	if m == nil || len(m.patterns) == 0 || len(args) == 0 {
+		return "", "", false
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(args))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return "", "", false
+	}
+
+	var values []string
+	collectScalars(value, &values)
+	return m.matchValues(values)
+}
+
+// MatchName returns the first pattern matching a tool name, with the same
+// precedence as Match.
+func (m *PatternMatcher) MatchName(name string) (string, string, bool) {
+	if m == nil || len(m.patterns) == 0 {
+		return "", "", false
+	}
+	return m.matchValues([]string{name})
+}
+
+func (m *PatternMatcher) matchValues(values []string) (string, string, bool) {
+	var escalate string
+	for _, p := range m.patterns {
+		for _, v := range values {
+			if !p.re.MatchString(v) {
+				continue
+			}
+			if p.action == PatternActionDeny {
+				return p.name, p.action, true
+			}
+			if escalate == "" {
+				escalate = p.name
+			}
+			break
+		}
+	}
+
+	if escalate != "" {
+		return escalate, PatternActionHumanRequired, true
+	}
+	return "", "", false
+}
+
+func collectScalars(value interface{}, out *[]string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, item := range v {
+			collectScalars(item, out)
+		}
+	case []interface{}:
+		for _, item := range v {
+			collectScalars(item, out)
+		}
+	case string:
+		*out = append(*out, v)
+	case json.Number:
+		*out = append(*out, v.String())
+	}
+}
+
+// applyPatterns tightens a policy decision when arguments match a
+// sensitive pattern. It never loosens a deny.
+func (h *Handler) applyPatterns(req *ToolCallRequest, decision policy.Response) policy.Response {
+	if !decision.Allow {
+		return decision
+	}
+
+	name, action, matched := h.patterns.Match(req.Args)
+	if !matched {
+		return decision
+	}
+
+	reason := fmt.Sprintf("argument matched sensitive pattern: %s", name)
+	log.Info().Str("tool", req.ToolName).Str("pattern", name).Str("action", action).Msg("sensitive pattern matched")
+
+	if action == PatternActionDeny {
+		return policy.Response{Allow: false, Reason: reason, Policy: sensitivePatternPrefix + name}
+	}
+
+	// The policy's risk score no longer describes this call, so
+	// auto-approval must not clear it
+	decision.HumanRequired = true
+	decision.Risk = nil
+	decision.Reason = reason
+	return decision
+}
+
+// validateSensitivePatterns rejects patterns NewPatternMatcher would
+// otherwise skip, since a skipped deny pattern lets matching arguments
+// through
+func validateSensitivePatterns(patterns []SensitivePattern) error {
+	for _, p := range patterns {
+		if _, err := regexp.Compile(p.Pattern); err != nil {
+			return fmt.Errorf("sensitive pattern %s: %w", p.Name, err)
+		}
+	}
+	return nil
+}
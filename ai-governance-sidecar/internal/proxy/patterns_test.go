@@ -0,0 +1,115 @@
+package proxy
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
+	"github.com/labstack/echo/v4"
+)
+
+var testPatterns = []SensitivePattern{
+	{Name: "ssn", Pattern: `\b\d{3}-\d{2}-\d{4}\b`, Action: PatternActionDeny},
+	{Name: "credit_card", Pattern: `^(?:\d[ -]?){13,16}$`, Action: PatternActionHumanRequired},
+}
+
+func TestPatternMatcher(t *testing.T) {
+	matcher := NewPatternMatcher(testPatterns)
+
+	tests := []struct {
+		name         string
+		args         string
+		expectName   string
+		expectAction string
+	}{
+		{name: "nested ssn", args: `{"user":{"notes":["ssn is 123-45-6789"]}}`, expectName: "ssn", expectAction: PatternActionDeny},
+		{name: "card string", args: `{"card":"4111 1111 1111 1111"}`, expectName: "credit_card", expectAction: PatternActionHumanRequired},
+		{name: "card number", args: `{"card":4111111111111111}`, expectName: "credit_card", expectAction: PatternActionHumanRequired},
+		{name: "deny wins", args: `{"card":"4111111111111111","ssn":"123-45-6789"}`, expectName: "ssn", expectAction: PatternActionDeny},
+		{name: "clean args", args: `{"query":"weather in Paris","count":3}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, action, matched := matcher.Match(json.RawMessage(tt.args))
+
+			if matched != (tt.expectName != "") {
+				t.Fatalf("expected matched=%v, got %v", tt.expectName != "", matched)
+			}
+			if name != tt.expectName || action != tt.expectAction {
+				t.Errorf("expected %s/%s, got %s/%s", tt.expectName, tt.expectAction, name, action)
+			}
+		})
+	}
+}
+
+func TestPatternMatcherSkipsInvalidPattern(t *testing.T) {
+	matcher := NewPatternMatcher([]SensitivePattern{{Name: "broken", Pattern: "("}})
+
+	if _, _, matched := matcher.Match(json.RawMessage(`{"a":"("}`)); matched {
+		t.Error("invalid pattern must not match")
+	}
+}
+
+func TestHandleToolCall_SensitivePatternDenied(t *testing.T) {
+	mockPolicy := &mockPolicyEvaluator{
+		response: policy.Response{Allow: true, Reason: "approved"},
+	}
+	mockAudit := &mockAuditStore{}
+	config := ProxyConfig{
+		DefaultUpstream:   "http://localhost:9000",
+		SensitivePatterns: testPatterns,
+	}
+	handler := NewHandler(config, mockPolicy, mockAudit, &mockApprovalQueue{})
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/tool/call", strings.NewReader(`{"tool_name":"crm","args":{"ssn":"123-45-6789"}}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := handler.HandleToolCall(c); err != nil {
+		t.Fatalf("handler failed: %v", err)
+	}
+
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected status 403, got %d", rec.Code)
+	}
+
+	if len(mockAudit.entries) != 1 {
+		t.Fatalf("expected 1 audit entry, got %d", len(mockAudit.entries))
+	}
+	if mockAudit.entries[0].Reason != "argument matched sensitive pattern: ssn" {
+		t.Errorf("expected pattern name in audit reason, got %q", mockAudit.entries[0].Reason)
+	}
+}
+
+func TestHandleToolCall_SensitivePatternSkipsAutoApprove(t *testing.T) {
+	queue := &countingApprovalQueue{}
+	config := ProxyConfig{
+		DefaultUpstream:   "http://localhost:9000",
+		SensitivePatterns: testPatterns,
+		AutoApprove:       AutoApproveConfig{Enabled: true, MaxRisk: 0.5},
+	}
+	handler := NewHandler(config, &mockPolicyEvaluator{response: policy.Response{Allow: true, Risk: risk(0.1)}}, &mockAuditStore{}, queue)
+
+	req := httptest.NewRequest(http.MethodPost, "/tool/call", strings.NewReader(`{"tool_name":"pay","args":{"card":"4111 1111 1111 1111"}}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	if err := handler.HandleToolCall(echo.New().NewContext(req, rec)); err != nil {
+		t.Fatalf("handler failed: %v", err)
+	}
+
+	if queue.enqueued != 1 {
+		t.Errorf("expected the escalated call to reach a human despite its low risk, got %d enqueues", queue.enqueued)
+	}
+}
+
+func TestValidateSensitivePatterns(t *testing.T) {
+	if err := (ProxyConfig{SensitivePatterns: []SensitivePattern{{Name: "broken", Pattern: "("}}}).Validate(); err == nil {
+		t.Error("expected an invalid sensitive pattern to fail validation")
+	}
+}
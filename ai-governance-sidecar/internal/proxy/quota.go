@@ -0,0 +1,122 @@
+package proxy
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/auth"
+	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
+	"github.com/labstack/echo/v4"
+)
+
+// DefaultQuotaUser is the USER_QUOTAS key applied to users without an entry
+const DefaultQuotaUser = "*"
+
+// quotaPolicy names quota rejections in audit detail
+const quotaPolicy = "user_quota"
+
+var (
+	ErrConcurrencyLimit = errors.New("concurrent call limit reached")
+	ErrDailyQuota       = errors.New("daily call quota exhausted")
+)
+
+// UserQuota limits one user's tool calls. Zero leaves a limit unset.
+type UserQuota struct {
+	Concurrent int `json:"concurrent"`
+	Daily      int `json:"daily"`
+}
+
+type userUsage struct {
+	active int
+	day    string
+	count  int
+}
+
+// quotaTracker counts in-flight and daily calls per user id. Daily counts
+// reset at midnight UTC.
+type quotaTracker struct {
+	limits map[string]UserQuota
+
+	mu    sync.Mutex
+	users map[string]*userUsage
+}
+
+func newQuotaTracker(limits map[string]UserQuota) *quotaTracker {
+	return &quotaTracker{limits: limits, users: make(map[string]*userUsage)}
+}
+
+func (q *quotaTracker) limitFor(user string) (UserQuota, bool) {
+	if limit, ok := q.limits[user]; ok {
+		return limit, true
+	}
+	limit, ok := q.limits[DefaultQuotaUser]
+	return limit, ok
+}
+
+// acquire reserves one concurrent slot and cost daily calls for user. The
+// returned release frees the slot and must be called once the call ends.
+func (q *quotaTracker) acquire(user string, cost int, now time.Time) (func(), error) {
+	limit, ok := q.limitFor(user)
+	if !ok {
+		return func() {}, nil
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	usage := q.users[user]
+	if usage == nil {
+		usage = &userUsage{}
+		q.users[user] = usage
+	}
+
+	day := now.UTC().Format("2006-01-02")
+	if usage.day != day {
+		usage.day = day
+		usage.count = 0
+	}
+
+	if limit.Concurrent > 0 && usage.active >= limit.Concurrent {
+		return nil, fmt.Errorf("%w: %d in flight", ErrConcurrencyLimit, limit.Concurrent)
+	}
+	if limit.Daily > 0 && usage.count+cost > limit.Daily {
+		return nil, fmt.Errorf("%w: %d calls per day", ErrDailyQuota, limit.Daily)
+	}
+
+	usage.active++
+	usage.count += cost
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			q.mu.Lock()
+			usage.active--
+			q.mu.Unlock()
+		})
+	}, nil
+}
+
+func validateUserQuotas(quotas map[string]UserQuota) error {
+	for user, quota := range quotas {
+		if quota.Concurrent < 0 || quota.Daily < 0 {
+			return fmt.Errorf("invalid USER_QUOTAS entry for %s: limits must not be negative", user)
+		}
+	}
+	return nil
+}
+
+// acquireQuota applies USER_QUOTAS to the authenticated caller. Requests
+// without a user, as when auth is disabled, are not limited.
+func (h *Handler) acquireQuota(c echo.Context, cost int) (func(), error) {
+	user := auth.GetUserFromContext(c)
+	if user == nil {
+		return func() {}, nil
+	}
+	return h.quotas.acquire(user.ID, cost, h.now())
+}
+
+func quotaDenial(err error) policy.Response {
+	return policy.Response{Allow: false, Reason: err.Error(), Policy: quotaPolicy}
+}
@@ -0,0 +1,114 @@
+package proxy
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/audit"
+	"github.com/dagbolade/ai-governance-sidecar/internal/auth"
+	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
+	"github.com/labstack/echo/v4"
+)
+
+func TestHandleToolCall_UserDailyQuota(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer upstream.Close()
+
+	mockAudit := &mockAuditStore{}
+	config := ProxyConfig{
+		DefaultUpstream: upstream.URL,
+		Timeout:         5,
+		UserQuotas:      map[string]UserQuota{"alice": {Daily: 2}},
+	}
+	handler := NewHandler(config, &mockPolicyEvaluator{response: policy.Response{Allow: true}}, mockAudit, &mockApprovalQueue{})
+
+	call := func(userID string) int {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/tool/call", strings.NewReader(`{"tool_name":"search","args":{}}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("user", &auth.User{ID: userID})
+
+		if err := handler.HandleToolCall(c); err != nil {
+			t.Fatalf("handler failed: %v", err)
+		}
+		return rec.Code
+	}
+
+	for i := 0; i < 2; i++ {
+		if code := call("alice"); code != http.StatusOK {
+			t.Fatalf("call %d: expected status 200, got %d", i+1, code)
+		}
+	}
+
+	if code := call("alice"); code != http.StatusTooManyRequests {
+		t.Errorf("expected status 429 once the quota is used, got %d", code)
+	}
+	last := mockAudit.entries[len(mockAudit.entries)-1]
+	if last.Decision != audit.DecisionDeny || !strings.Contains(last.Reason, "quota") {
+		t.Errorf("expected quota rejection to be audited, got %+v", last)
+	}
+
+	// Another user is unaffected
+	for i := 0; i < 3; i++ {
+		if code := call("bob"); code != http.StatusOK {
+			t.Errorf("expected bob to be unlimited, got %d", code)
+		}
+	}
+}
+
+func TestQuotaTrackerConcurrency(t *testing.T) {
+	tracker := newQuotaTracker(map[string]UserQuota{DefaultQuotaUser: {Concurrent: 1}})
+	now := time.Now()
+
+	release, err := tracker.acquire("alice", 1, now)
+	if err != nil {
+		t.Fatalf("first acquire: %v", err)
+	}
+
+	if _, err := tracker.acquire("alice", 1, now); !errors.Is(err, ErrConcurrencyLimit) {
+		t.Errorf("expected concurrency limit, got %v", err)
+	}
+	if otherRelease, err := tracker.acquire("bob", 1, now); err != nil {
+		t.Errorf("expected another user to have their own slot, got %v", err)
+	} else {
+		otherRelease()
+	}
+
+	release()
+	release() // releasing twice must not free a second slot
+
+	second, err := tracker.acquire("alice", 1, now)
+	if err != nil {
+		t.Fatalf("expected slot after release, got %v", err)
+	}
+	if _, err := tracker.acquire("alice", 1, now); !errors.Is(err, ErrConcurrencyLimit) {
+		t.Errorf("expected double release to keep the limit, got %v", err)
+	}
+	second()
+}
+
+func TestQuotaTrackerDailyReset(t *testing.T) {
+	tracker := newQuotaTracker(map[string]UserQuota{"alice": {Daily: 1}})
+	day := time.Date(2025, 3, 1, 23, 59, 0, 0, time.UTC)
+
+	release, err := tracker.acquire("alice", 1, day)
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	release()
+
+	if _, err := tracker.acquire("alice", 1, day); !errors.Is(err, ErrDailyQuota) {
+		t.Errorf("expected daily quota, got %v", err)
+	}
+	if _, err := tracker.acquire("alice", 1, day.Add(2*time.Minute)); err != nil {
+		t.Errorf("expected quota to reset the next day, got %v", err)
+	}
+}
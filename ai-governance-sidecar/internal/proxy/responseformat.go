@@ -0,0 +1,81 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// HeaderResponseFormat picks how a forwarded call's result is returned
+const HeaderResponseFormat = "X-Response-Format"
+
+// Response formats
+const (
+	// ResponseFormatWrapped returns the upstream body as result in a
+	// ToolCallResponse
+	ResponseFormatWrapped = "wrapped"
+	// ResponseFormatRaw returns the upstream body, status and content type
+	// unchanged
+	ResponseFormatRaw = "raw"
+)
+
+// responseFormat reads the requested format, falling back to the
+// configured default and then to wrapped
+func (h *Handler) responseFormat(c echo.Context) (string, error) {
+	format := c.Request().Header.Get(HeaderResponseFormat)
+	if format == "" {
+		format = h.config.ResponseFormat
+	}
+
+	switch format {
+	case "", ResponseFormatWrapped:
+		return ResponseFormatWrapped, nil
+	case ResponseFormatRaw:
+		return ResponseFormatRaw, nil
+	default:
+		return "", fmt.Errorf("%s must be %s or %s", HeaderResponseFormat, ResponseFormatRaw, ResponseFormatWrapped)
+	}
+}
+
+// forwardVerbatim sends the call upstream and relays the reply as is,
+// including non-2xx statuses. Only a failure to reach the upstream at all
+// is reported in the sidecar's own envelope.
+func (h *Handler) forwardVerbatim(ctx context.Context, c echo.Context, req *ToolCallRequest) error {
+	resp, err := h.forwardRaw(ctx, req)
+	if err != nil {
+		log.Error().Err(err).Str("upstream", req.Upstream).Msg("forward failed")
+		return h.errorResponse(c, http.StatusBadGateway, "upstream request failed")
+	}
+
+	body, denial := h.checkRawEgress(ctx, req, resp.Body)
+	if denial != nil {
+		return h.denyResponse(c, denial.Reason)
+	}
+
+	contentType := resp.ContentType
+	if contentType == "" {
+		contentType = echo.MIMEOctetStream
+	}
+	return c.Blob(resp.Status, contentType, body)
+}
+
+func (h *Handler) forwardRaw(ctx context.Context, req *ToolCallRequest) (UpstreamResponse, error) {
+	if route, ok := h.config.GRPCRoutes[req.ToolName]; ok {
+		result, err := h.grpc.Forward(ctx, route, req)
+		if err != nil {
+			return UpstreamResponse{}, err
+		}
+		return UpstreamResponse{Status: http.StatusOK, ContentType: echo.MIMEApplicationJSON, Body: result}, nil
+	}
+	return h.forwarder.ForwardRaw(ctx, h.upstreamFor(req), req)
+}
+
+func validateResponseFormat(format string) error {
+	if format != "" && format != ResponseFormatWrapped && format != ResponseFormatRaw {
+		return fmt.Errorf("response format must be %s or %s", ResponseFormatRaw, ResponseFormatWrapped)
+	}
+	return nil
+}
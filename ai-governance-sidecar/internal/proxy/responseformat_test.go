@@ -0,0 +1,100 @@
+package proxy
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
+	"github.com/labstack/echo/v4"
+)
+
+func TestHandleToolCall_ResponseFormat(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/csv")
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte("id,name\n1,ada\n"))
+	}))
+	defer upstream.Close()
+
+	tests := []struct {
+		name          string
+		header        string
+		configured    string
+		expectStatus  int
+		expectType    string
+		expectWrapped bool
+	}{
+		{name: "raw header", header: ResponseFormatRaw, expectStatus: http.StatusAccepted, expectType: "text/csv"},
+		{name: "raw default", configured: ResponseFormatRaw, expectStatus: http.StatusAccepted, expectType: "text/csv"},
+		{name: "wrapped header overrides raw default", header: ResponseFormatWrapped, configured: ResponseFormatRaw, expectStatus: http.StatusBadGateway, expectWrapped: true},
+		{name: "invalid header", header: "xml", expectStatus: http.StatusBadRequest, expectWrapped: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := ProxyConfig{DefaultUpstream: upstream.URL, Timeout: 5, ResponseFormat: tt.configured}
+			handler := NewHandler(config, &mockPolicyEvaluator{response: policy.Response{Allow: true}}, &mockAuditStore{}, &mockApprovalQueue{})
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/tool/call", strings.NewReader(`{"tool_name":"export","args":{}}`))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			if tt.header != "" {
+				req.Header.Set(HeaderResponseFormat, tt.header)
+			}
+			rec := httptest.NewRecorder()
+
+			if err := handler.HandleToolCall(e.NewContext(req, rec)); err != nil {
+				t.Fatalf("handler failed: %v", err)
+			}
+
+			if rec.Code != tt.expectStatus {
+				t.Errorf("expected status %d, got %d", tt.expectStatus, rec.Code)
+			}
+
+			if tt.expectWrapped {
+				var resp ToolCallResponse
+				if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("expected a wrapped envelope, got %q", rec.Body.String())
+				}
+				return
+			}
+
+			if ct := rec.Header().Get(echo.HeaderContentType); ct != tt.expectType {
+				t.Errorf("expected content type %q, got %q", tt.expectType, ct)
+			}
+			if rec.Body.String() != "id,name\n1,ada\n" {
+				t.Errorf("expected the upstream body verbatim, got %q", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandleToolCall_WrappedByDefault(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"rows":1}`))
+	}))
+	defer upstream.Close()
+
+	config := ProxyConfig{DefaultUpstream: upstream.URL, Timeout: 5}
+	handler := NewHandler(config, &mockPolicyEvaluator{response: policy.Response{Allow: true}}, &mockAuditStore{}, &mockApprovalQueue{})
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/tool/call", strings.NewReader(`{"tool_name":"export","args":{}}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+
+	if err := handler.HandleToolCall(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("handler failed: %v", err)
+	}
+
+	var resp ToolCallResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if !resp.Success || string(resp.Result) != `{"rows":1}` {
+		t.Errorf("expected the upstream body wrapped as result, got %+v", resp)
+	}
+}
@@ -0,0 +1,200 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	defaultHealthInterval = 10 * time.Second
+	defaultHealthPath     = "/health"
+)
+
+// replica is one upstream a routed tool can be sent to. Replicas start out
+// healthy until a check says otherwise.
+type replica struct {
+	url     string
+	healthy atomic.Bool
+}
+
+// upstreamPool spreads a tool's calls round-robin over its replicas
+type upstreamPool struct {
+	replicas []*replica
+	next     atomic.Uint64
+}
+
+// pick returns the next healthy replica. When every replica is unhealthy it
+// falls back to all of them, since a stale check is better than no upstream.
+func (p *upstreamPool) pick() string {
+	healthy := make([]*replica, 0, len(p.replicas))
+	for _, r := range p.replicas {
+		if r.healthy.Load() {
+			healthy = append(healthy, r)
+		}
+	}
+	if len(healthy) == 0 {
+		healthy = p.replicas
+	}
+
+	n := p.next.Add(1) - 1
+	return healthy[n%uint64(len(healthy))].url
+}
+
+// routeTable maps tools to their upstream replicas and keeps replica health
+// current by polling each one's health path.
+type routeTable struct {
+	pools    map[string]*upstreamPool
+	replicas map[string]*replica
+	client   *http.Client
+	path     string
+	interval time.Duration
+
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+func newRouteTable(routes map[string][]string, client *http.Client, path string, interval time.Duration) *routeTable {
+	if path == "" {
+		path = defaultHealthPath
+	}
+	if interval <= 0 {
+		interval = defaultHealthInterval
+	}
+
+	t := &routeTable{
+		pools:    make(map[string]*upstreamPool, len(routes)),
+		replicas: make(map[string]*replica),
+		client:   client,
+		path:     path,
+		interval: interval,
+		done:     make(chan struct{}),
+	}
+
+	for tool, urls := range routes {
+		pool := &upstreamPool{}
+		for _, u := range urls {
+			r, ok := t.replicas[u]
+			if !ok {
+				r = &replica{url: u}
+				r.healthy.Store(true)
+				t.replicas[u] = r
+			}
+			pool.replicas = append(pool.replicas, r)
+		}
+		if len(pool.replicas) > 0 {
+			t.pools[tool] = pool
+		}
+	}
+
+	return t
+}
+
+// route returns the replica for a tool, or false when the tool has no route
+func (t *routeTable) route(tool string) (string, bool) {
+	pool, ok := t.pools[tool]
+	if !ok {
+		return "", false
+	}
+	return pool.pick(), true
+}
+
+// start polls replica health until stop is called
+func (t *routeTable) start() {
+	if len(t.replicas) == 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(t.interval)
+		defer ticker.Stop()
+
+		for {
+			t.checkAll()
+			select {
+			case <-ticker.C:
+			case <-t.done:
+				return
+			}
+		}
+	}()
+}
+
+func (t *routeTable) stop() {
+	t.stopOnce.Do(func() { close(t.done) })
+}
+
+func (t *routeTable) checkAll() {
+	var wg sync.WaitGroup
+	for _, r := range t.replicas {
+		wg.Add(1)
+		go func(r *replica) {
+			defer wg.Done()
+			t.check(r)
+		}(r)
+	}
+	wg.Wait()
+}
+
+func (t *routeTable) check(r *replica) {
+	err := t.probe(r.url)
+	healthy := err == nil
+
+	if r.healthy.Swap(healthy) != healthy {
+		if healthy {
+			log.Info().Str("upstream", r.url).Msg("upstream replica healthy again")
+		} else {
+			log.Warn().Err(err).Str("upstream", r.url).Msg("upstream replica unhealthy")
+		}
+	}
+}
+
+// probe GETs the health path on the replica's host; any 2xx is healthy
+func (t *routeTable) probe(rawURL string) error {
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("parse upstream: %w", err)
+	}
+	target.Path = t.path
+	target.RawQuery = ""
+
+	ctx, cancel := context.WithTimeout(context.Background(), t.interval)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("http request: %w", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("health check returned %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func validateUpstreamRoutes(routes map[string][]string) error {
+	for tool, urls := range routes {
+		if len(urls) == 0 {
+			return fmt.Errorf("upstream route for %s has no replicas", tool)
+		}
+		for _, u := range urls {
+			parsed, err := url.Parse(u)
+			if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+				return fmt.Errorf("upstream route for %s: invalid replica %q", tool, u)
+			}
+		}
+	}
+	return nil
+}
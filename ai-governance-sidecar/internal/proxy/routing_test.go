@@ -0,0 +1,110 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
+	"github.com/labstack/echo/v4"
+)
+
+// replicaServer counts tool calls and answers its health check with status
+func replicaServer(t *testing.T, status *atomic.Int32, calls *atomic.Int32) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == defaultHealthPath {
+			w.WriteHeader(int(status.Load()))
+			return
+		}
+		calls.Add(1)
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestHandleToolCall_SkipsUnhealthyReplica(t *testing.T) {
+	var healthyStatus, sickStatus atomic.Int32
+	healthyStatus.Store(http.StatusOK)
+	sickStatus.Store(http.StatusServiceUnavailable)
+
+	var healthyCalls, sickCalls atomic.Int32
+	healthy := replicaServer(t, &healthyStatus, &healthyCalls)
+	sick := replicaServer(t, &sickStatus, &sickCalls)
+
+	handler := NewHandler(ProxyConfig{
+		DefaultUpstream: "http://default.invalid",
+		Timeout:         5,
+		UpstreamRoutes:  map[string][]string{"search": {sick.URL + "/call", healthy.URL + "/call"}},
+	}, &mockPolicyEvaluator{response: policy.Response{Allow: true}}, &mockAuditStore{}, &mockApprovalQueue{})
+	defer handler.Close()
+	handler.routes.checkAll()
+
+	e := echo.New()
+	for i := 0; i < 4; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/tool/call", strings.NewReader(`{"tool_name":"search","args":{}}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+
+		if err := handler.HandleToolCall(e.NewContext(req, rec)); err != nil {
+			t.Fatalf("handler failed: %v", err)
+		}
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+	}
+
+	if healthyCalls.Load() != 4 || sickCalls.Load() != 0 {
+		t.Errorf("expected all calls on the healthy replica, got healthy=%d unhealthy=%d", healthyCalls.Load(), sickCalls.Load())
+	}
+
+	// Once it recovers the replica is back in rotation
+	sickStatus.Store(http.StatusOK)
+	handler.routes.checkAll()
+	seen := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		replica, _ := handler.routes.route("search")
+		seen[replica] = true
+	}
+	if len(seen) != 2 {
+		t.Errorf("expected both replicas after recovery, got %v", seen)
+	}
+}
+
+func TestUpstreamPoolFallsBackWhenAllUnhealthy(t *testing.T) {
+	table := newRouteTable(map[string][]string{"search": {"http://a/call", "http://b/call"}}, http.DefaultClient, "", 0)
+	for _, r := range table.replicas {
+		r.healthy.Store(false)
+	}
+
+	seen := map[string]bool{}
+	for i := 0; i < 4; i++ {
+		replica, ok := table.route("search")
+		if !ok {
+			t.Fatal("expected a route for search")
+		}
+		seen[replica] = true
+	}
+	if len(seen) != 2 {
+		t.Errorf("expected round-robin over all replicas, got %v", seen)
+	}
+
+	if _, ok := table.route("other"); ok {
+		t.Error("expected no route for an unrouted tool")
+	}
+}
+
+func TestValidateUpstreamRoutes(t *testing.T) {
+	if err := validateUpstreamRoutes(map[string][]string{"search": {"http://a/call"}}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := validateUpstreamRoutes(map[string][]string{"search": {}}); err == nil {
+		t.Error("expected error for a route without replicas")
+	}
+	if err := validateUpstreamRoutes(map[string][]string{"search": {"ftp://a"}}); err == nil {
+		t.Error("expected error for a non-http replica")
+	}
+}
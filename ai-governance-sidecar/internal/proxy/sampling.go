@@ -0,0 +1,64 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
+	"github.com/dagbolade/ai-governance-sidecar/internal/toolmatch"
+)
+
+// auditSampler thins the audit trail for chatty tools. Only calls a policy
+// allowed outright are sampled; denials, approvals, auto-approvals, shadow
+// objections and rejected requests are always written.
+//
+// Sampling is a compliance tradeoff: skipped calls leave no audit record, so
+// the log no longer proves that a given sampled call happened. Enable it only
+// for tools whose individual calls do not need to be reconstructed.
+type auditSampler struct {
+	rates    map[string]int
+	counters map[string]*atomic.Uint64
+}
+
+func newAuditSampler(rates map[string]int) *auditSampler {
+	counters := make(map[string]*atomic.Uint64, len(rates))
+	for tool := range rates {
+		counters[tool] = new(atomic.Uint64)
+	}
+	return &auditSampler{rates: rates, counters: counters}
+}
+
+// skip reports whether an allowed call should go unaudited. With a rate of
+// N the first call and every Nth after it are kept.
+func (s *auditSampler) skip(toolName string, decision policy.Response) bool {
+	if !decision.Allow || decision.HumanRequired || decision.Shadow != nil {
+		return false
+	}
+
+	// Tools matching the same glob share its counter
+	rate, key, _ := toolmatch.Lookup(s.rates, toolName)
+	if rate <= 1 {
+		return false
+	}
+
+	n := s.counters[key].Add(1)
+	return (n-1)%uint64(rate) != 0
+}
+
+func validateAuditSampling(rates map[string]int) error {
+	for tool, rate := range rates {
+		if rate < 1 {
+			return fmt.Errorf("invalid AUDIT_SAMPLING rate %d for %s: must be at least 1", rate, tool)
+		}
+	}
+	return nil
+}
+
+// auditPolicyDecision logs a policy decision unless audit sampling skips it
+func (h *Handler) auditPolicyDecision(ctx context.Context, req *ToolCallRequest, decision policy.Response) error {
+	if h.sampler.skip(req.ToolName, decision) {
+		return nil
+	}
+	return h.logAudit(ctx, req, decision)
+}
@@ -0,0 +1,76 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/audit"
+	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
+	"github.com/labstack/echo/v4"
+)
+
+func TestAuditSampling(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer upstream.Close()
+
+	tests := []struct {
+		name          string
+		toolName      string
+		response      policy.Response
+		calls         int
+		expectEntries int
+	}{
+		{name: "allowed calls are sampled", toolName: "read_file", response: policy.Response{Allow: true}, calls: 25, expectEntries: 3},
+		{name: "denials bypass sampling", toolName: "read_file", response: policy.Response{Allow: false, Reason: "blocked"}, calls: 25, expectEntries: 25},
+		{name: "approvals bypass sampling", toolName: "read_file", response: policy.Response{Allow: true, HumanRequired: true}, calls: 5, expectEntries: 10},
+		{name: "unsampled tools are fully audited", toolName: "write_file", response: policy.Response{Allow: true}, calls: 25, expectEntries: 25},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAudit := &mockAuditStore{}
+			config := ProxyConfig{
+				DefaultUpstream: upstream.URL,
+				Timeout:         5,
+				AuditSampling:   map[string]int{"read_file": 10},
+			}
+			handler := NewHandler(config, &mockPolicyEvaluator{response: tt.response}, mockAudit, &mockApprovalQueue{})
+
+			e := echo.New()
+			for i := 0; i < tt.calls; i++ {
+				body := `{"tool_name":"` + tt.toolName + `","args":{}}`
+				req := httptest.NewRequest(http.MethodPost, "/tool/call", strings.NewReader(body))
+				req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+				rec := httptest.NewRecorder()
+
+				if err := handler.HandleToolCall(e.NewContext(req, rec)); err != nil {
+					t.Fatalf("handler failed: %v", err)
+				}
+			}
+
+			if len(mockAudit.entries) != tt.expectEntries {
+				t.Errorf("expected %d audit entries, got %d", tt.expectEntries, len(mockAudit.entries))
+			}
+			if !tt.response.Allow {
+				for _, entry := range mockAudit.entries {
+					if entry.Decision != audit.DecisionDeny {
+						t.Errorf("expected only deny entries, got %s", entry.Decision)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestValidateAuditSampling(t *testing.T) {
+	if err := validateAuditSampling(map[string]int{"read_file": 100}); err != nil {
+		t.Errorf("expected valid rate, got %v", err)
+	}
+	if err := validateAuditSampling(map[string]int{"read_file": 0}); err == nil {
+		t.Error("expected error for rate 0")
+	}
+}
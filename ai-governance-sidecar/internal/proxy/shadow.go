@@ -0,0 +1,49 @@
+package proxy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
+	"github.com/rs/zerolog/log"
+)
+
+// Shadow tags prefix the audit reason of calls a shadow-mode policy would
+// have stopped. The call itself proceeds on the enforced decision.
+const (
+	ShadowDeny          = "shadow_deny"
+	ShadowHumanRequired = "shadow_human_required"
+)
+
+// auditReason is the reason recorded for a policy decision, followed by
+// any policy warnings and led by the shadow verdict when a shadow-mode
+// policy objected.
+func auditReason(decision policy.Response) string {
+	reason := decision.Reason
+	if len(decision.Warnings) > 0 {
+		reason += "; warnings: " + strings.Join(decision.Warnings, "; ")
+	}
+
+	shadow := decision.Shadow
+	if shadow == nil {
+		return reason
+	}
+
+	tag := ShadowHumanRequired
+	if !shadow.Allow {
+		tag = ShadowDeny
+	}
+	return fmt.Sprintf("%s (%s): %s; %s", tag, shadow.Policy, shadow.Reason, reason)
+}
+
+func logShadowDecision(req *ToolCallRequest, decision policy.Response) {
+	if decision.Shadow == nil {
+		return
+	}
+	log.Info().
+		Str("tool", req.ToolName).
+		Str("policy", decision.Shadow.Policy).
+		Bool("would_allow", decision.Shadow.Allow).
+		Str("reason", decision.Shadow.Reason).
+		Msg("shadow policy objected")
+}
@@ -0,0 +1,63 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/audit"
+	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
+	"github.com/labstack/echo/v4"
+)
+
+func TestHandleToolCall_ShadowDenyForwards(t *testing.T) {
+	forwarded := false
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		forwarded = true
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer upstream.Close()
+
+	tests := []struct {
+		name      string
+		shadow    policy.Response
+		expectTag string
+	}{
+		{name: "shadow deny", shadow: policy.Response{Allow: false, Reason: "contains SSN", Policy: "new_pii"}, expectTag: ShadowDeny},
+		{name: "shadow human required", shadow: policy.Response{Allow: true, HumanRequired: true, Reason: "large refund", Policy: "refunds"}, expectTag: ShadowHumanRequired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forwarded = false
+			shadow := tt.shadow
+			mockAudit := &mockAuditStore{}
+			evaluator := &mockPolicyEvaluator{response: policy.Response{Allow: true, Reason: "all policies passed", Shadow: &shadow}}
+			handler := NewHandler(ProxyConfig{DefaultUpstream: upstream.URL, Timeout: 5}, evaluator, mockAudit, &mockApprovalQueue{})
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/tool/call", strings.NewReader(`{"tool_name":"send_email","args":{}}`))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+
+			if err := handler.HandleToolCall(e.NewContext(req, rec)); err != nil {
+				t.Fatalf("handler failed: %v", err)
+			}
+
+			if rec.Code != http.StatusOK || !forwarded {
+				t.Errorf("expected request to be forwarded, got status %d", rec.Code)
+			}
+			if len(mockAudit.entries) != 1 {
+				t.Fatalf("expected one audit entry, got %d", len(mockAudit.entries))
+			}
+			entry := mockAudit.entries[0]
+			if entry.Decision != audit.DecisionAllow {
+				t.Errorf("expected enforced decision allow, got %s", entry.Decision)
+			}
+			if !strings.HasPrefix(entry.Reason, tt.expectTag+" ("+tt.shadow.Policy+"): "+tt.shadow.Reason) {
+				t.Errorf("expected would-be decision in audit reason, got %q", entry.Reason)
+			}
+		})
+	}
+}
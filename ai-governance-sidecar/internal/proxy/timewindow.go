@@ -0,0 +1,180 @@
+package proxy
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
+	"github.com/dagbolade/ai-governance-sidecar/internal/toolmatch"
+	"github.com/rs/zerolog/log"
+)
+
+// timeWindowPrefix, followed by the window name, names time window
+// denials in audit detail
+const timeWindowPrefix = "time_window:"
+
+// TimeWindowRule tightens allowed calls during a recurring window, such as
+// a change freeze or the hours outside business hours
+type TimeWindowRule struct {
+	Name   string   `json:"name"`
+	Window string   `json:"window"`          // HH:MM-HH:MM in the configured zone
+	Days   []string `json:"days,omitempty"`  // mon..sun; empty means every day
+	Tools  []string `json:"tools,omitempty"` // names or globs; empty means every tool
+	Action string   `json:"action"`          // deny (default) or human_required
+}
+
+type compiledWindow struct {
+	name   string
+	window *TimeWindow
+	days   map[time.Weekday]bool
+	tools  []string
+	action string
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// timeWindows evaluates window rules against the clock in one location
+type timeWindows struct {
+	rules    []compiledWindow
+	location *time.Location
+}
+
+// newTimeWindows compiles rules, skipping invalid ones with a warning. An
+// empty zone means the server's local time.
+func newTimeWindows(rules []TimeWindowRule, zone string) *timeWindows {
+	location, err := loadZone(zone)
+	if err != nil {
+		log.Warn().Err(err).Msg("invalid time window zone, using local time")
+		location = time.Local
+	}
+
+	w := &timeWindows{location: location}
+	for _, rule := range rules {
+		compiled, err := compileWindow(rule)
+		if err != nil {
+			log.Warn().Err(err).Str("window", rule.Name).Msg("invalid time window, skipping")
+			continue
+		}
+		w.rules = append(w.rules, compiled)
+	}
+	return w
+}
+
+func loadZone(zone string) (*time.Location, error) {
+	if zone == "" {
+		return time.Local, nil
+	}
+	return time.LoadLocation(zone)
+}
+
+func compileWindow(rule TimeWindowRule) (compiledWindow, error) {
+	window, err := ParseTimeWindow(rule.Window)
+	if err != nil {
+		return compiledWindow{}, err
+	}
+
+	compiled := compiledWindow{name: rule.Name, window: window, action: rule.Action}
+	if compiled.action != PatternActionHumanRequired {
+		compiled.action = PatternActionDeny
+	}
+
+	if len(rule.Days) > 0 {
+		compiled.days = make(map[time.Weekday]bool, len(rule.Days))
+		for _, day := range rule.Days {
+			weekday, ok := weekdays[strings.ToLower(strings.TrimSpace(day))]
+			if !ok {
+				return compiledWindow{}, fmt.Errorf("unknown day %q", day)
+			}
+			compiled.days[weekday] = true
+		}
+	}
+
+	compiled.tools = rule.Tools
+
+	return compiled, nil
+}
+
+func (w compiledWindow) matches(tool string, now time.Time) bool {
+	if len(w.tools) > 0 && !toolmatch.MatchAny(w.tools, tool) {
+		return false
+	}
+	if w.days != nil && !w.days[now.Weekday()] {
+		return false
+	}
+	return w.window.Contains(now)
+}
+
+// match returns the window in effect for tool at now. Deny windows take
+// precedence over human_required ones.
+func (w *timeWindows) match(tool string, now time.Time) (compiledWindow, bool) {
+	if w == nil {
+		return compiledWindow{}, false
+	}
+
+	now = now.In(w.location)
+
+	var escalate *compiledWindow
+	for i, rule := range w.rules {
+		if !rule.matches(tool, now) {
+			continue
+		}
+		if rule.action == PatternActionDeny {
+			return rule, true
+		}
+		if escalate == nil {
+			escalate = &w.rules[i]
+		}
+	}
+
+	if escalate != nil {
+		return *escalate, true
+	}
+	return compiledWindow{}, false
+}
+
+// applyTimeWindows turns an allowed call into a deny or an approval while a
+// matching window is open. It never loosens a deny.
+func (h *Handler) applyTimeWindows(req *ToolCallRequest, decision policy.Response) policy.Response {
+	if !decision.Allow {
+		return decision
+	}
+
+	window, ok := h.windows.match(req.ToolName, h.now())
+	if !ok {
+		return decision
+	}
+
+	reason := fmt.Sprintf("call falls inside time window: %s", window.name)
+	log.Info().Str("tool", req.ToolName).Str("window", window.name).Str("action", window.action).Msg("time window matched")
+
+	if window.action == PatternActionDeny {
+		return policy.Response{Allow: false, Reason: reason, Policy: timeWindowPrefix + window.name}
+	}
+
+	// A low policy risk must not auto-approve a call the window escalated
+	decision.HumanRequired = true
+	decision.Risk = nil
+	decision.Reason = reason
+	return decision
+}
+
+func validateTimeWindows(rules []TimeWindowRule, zone string) error {
+	if _, err := loadZone(zone); err != nil {
+		return fmt.Errorf("time window zone: %w", err)
+	}
+	for _, rule := range rules {
+		if _, err := compileWindow(rule); err != nil {
+			return fmt.Errorf("time window %s: %w", rule.Name, err)
+		}
+	}
+	return nil
+}
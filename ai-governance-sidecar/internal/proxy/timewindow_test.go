@@ -0,0 +1,116 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/clock"
+	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
+	"github.com/labstack/echo/v4"
+)
+
+func TestHandleToolCall_TimeWindows(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"success"}`))
+	}))
+	defer upstream.Close()
+
+	utc := func(day, hour int) time.Time { return time.Date(2025, 1, day, hour, 0, 0, 0, time.UTC) }
+	// 2025-01-06 is a Monday and 2025-01-10 a Friday; 16:00 UTC is 17:00
+	// in Paris in winter
+	windows := []TimeWindowRule{
+		{Name: "after_hours", Window: "17:00-09:00", Action: PatternActionHumanRequired},
+		{Name: "friday_freeze", Window: "12:00-23:59", Days: []string{"fri"}, Tools: []string{"deploy"}},
+	}
+
+	tests := []struct {
+		name         string
+		tool         string
+		zone         string
+		now          time.Time
+		expectStatus int
+		expectQueued int
+	}{
+		{name: "business hours allowed", tool: "deploy", now: utc(6, 10), expectStatus: http.StatusOK},
+		{name: "after hours needs approval", tool: "deploy", now: utc(6, 20), expectStatus: http.StatusForbidden, expectQueued: 1},
+		{name: "freeze denies deploy", tool: "deploy", now: utc(10, 18), expectStatus: http.StatusForbidden},
+		{name: "freeze ignores other tools", tool: "search", now: utc(10, 14), expectStatus: http.StatusOK},
+		{name: "freeze only on fridays", tool: "deploy", now: utc(9, 14), expectStatus: http.StatusOK},
+		{name: "zone shifts the window", tool: "search", zone: "Europe/Paris", now: utc(6, 16), expectStatus: http.StatusForbidden, expectQueued: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			zone := tt.zone
+			if zone == "" {
+				zone = "UTC"
+			}
+
+			queue := &countingApprovalQueue{}
+			mockAudit := &mockAuditStore{}
+			handler := NewHandler(ProxyConfig{
+				DefaultUpstream: upstream.URL,
+				Timeout:         10,
+				TimeWindows:     windows,
+				TimeWindowZone:  zone,
+			}, &mockPolicyEvaluator{response: policy.Response{Allow: true}}, mockAudit, queue)
+			handler.SetClock(clock.NewFake(tt.now))
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/tool/call", strings.NewReader(`{"tool_name":"`+tt.tool+`","args":{}}`))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+
+			if err := handler.HandleToolCall(e.NewContext(req, rec)); err != nil {
+				t.Fatalf("handler failed: %v", err)
+			}
+
+			if rec.Code != tt.expectStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.expectStatus, rec.Code, rec.Body.String())
+			}
+			if queue.enqueued != tt.expectQueued {
+				t.Errorf("expected %d enqueued, got %d", tt.expectQueued, queue.enqueued)
+			}
+		})
+	}
+}
+
+func TestHandleToolCall_TimeWindowSkipsAutoApprove(t *testing.T) {
+	queue := &countingApprovalQueue{}
+	handler := NewHandler(ProxyConfig{
+		DefaultUpstream: "http://localhost:9000",
+		TimeWindows:     []TimeWindowRule{{Name: "after_hours", Window: "17:00-09:00", Action: PatternActionHumanRequired}},
+		TimeWindowZone:  "UTC",
+		AutoApprove:     AutoApproveConfig{Enabled: true, MaxRisk: 0.5},
+	}, &mockPolicyEvaluator{response: policy.Response{Allow: true, Risk: risk(0.1)}}, &mockAuditStore{}, queue)
+	handler.SetClock(clock.NewFake(time.Date(2025, 1, 6, 20, 0, 0, 0, time.UTC)))
+
+	req := httptest.NewRequest(http.MethodPost, "/tool/call", strings.NewReader(`{"tool_name":"deploy","args":{}}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	if err := handler.HandleToolCall(echo.New().NewContext(req, rec)); err != nil {
+		t.Fatalf("handler failed: %v", err)
+	}
+
+	if queue.enqueued != 1 {
+		t.Errorf("expected the after-hours call to reach a human despite its low risk, got %d enqueues", queue.enqueued)
+	}
+}
+
+func TestValidateTimeWindows(t *testing.T) {
+	if err := validateTimeWindows([]TimeWindowRule{{Name: "ok", Window: "09:00-17:00", Days: []string{"Mon"}}}, "UTC"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := validateTimeWindows([]TimeWindowRule{{Name: "bad", Window: "9-5"}}, ""); err == nil {
+		t.Error("expected error for malformed window")
+	}
+	if err := validateTimeWindows([]TimeWindowRule{{Name: "bad", Window: "09:00-17:00", Days: []string{"someday"}}}, ""); err == nil {
+		t.Error("expected error for unknown day")
+	}
+	if err := validateTimeWindows(nil, "Mars/Olympus"); err == nil {
+		t.Error("expected error for unknown zone")
+	}
+}
@@ -0,0 +1,106 @@
+package proxy
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"github.com/rs/zerolog/log"
+)
+
+// buildTLSConfig returns nil when the system trust store should be used.
+func buildTLSConfig(caFile string, insecureSkipVerify bool) (*tls.Config, error) {
+	if caFile == "" && !insecureSkipVerify {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("read upstream CA file: %w", err)
+		}
+
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", caFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if insecureSkipVerify {
+		log.Warn().Msg("UPSTREAM_INSECURE_SKIP_VERIFY is set: upstream TLS certificates are NOT verified. Never use this in production.")
+		tlsConfig.InsecureSkipVerify = true
+	}
+
+	return tlsConfig, nil
+}
+
+// Validate checks settings that would otherwise only fail on first use
+func (c ProxyConfig) Validate() error {
+	if err := validateAuditDetail(c.AuditDetail); err != nil {
+		return err
+	}
+
+	if err := validateAuditSampling(c.AuditSampling); err != nil {
+		return err
+	}
+
+	if err := validateUserQuotas(c.UserQuotas); err != nil {
+		return err
+	}
+
+	if _, err := buildTLSConfig(c.UpstreamCAFile, false); err != nil {
+		return err
+	}
+
+	for tool, route := range c.GRPCRoutes {
+		if err := route.validate(); err != nil {
+			return fmt.Errorf("grpc route for %s: %w", tool, err)
+		}
+	}
+
+	if err := validateResponseFormat(c.ResponseFormat); err != nil {
+		return err
+	}
+
+	if err := validateToolNameNormalization(c.ToolNameNormalization); err != nil {
+		return err
+	}
+
+	if err := validateSensitivePatterns(c.SensitivePatterns); err != nil {
+		return err
+	}
+
+	if err := validateToolNamePatterns(c.ToolNamePatterns); err != nil {
+		return err
+	}
+
+	if err := validateToolGroups(c.ToolGroups); err != nil {
+		return err
+	}
+
+	if err := validateTimeWindows(c.TimeWindows, c.TimeWindowZone); err != nil {
+		return err
+	}
+
+	if err := validateUpstreamRoutes(c.UpstreamRoutes); err != nil {
+		return err
+	}
+
+	_, err := buildProxyFunc(c.UpstreamProxyURL)
+	return err
+}
+
+func (c ProxyConfig) transportOptions() TransportOptions {
+	return TransportOptions{
+		CAFile:             c.UpstreamCAFile,
+		InsecureSkipVerify: c.UpstreamInsecureSkipVerify,
+		ProxyURL:           c.UpstreamProxyURL,
+	}
+}
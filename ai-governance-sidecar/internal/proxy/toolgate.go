@@ -0,0 +1,51 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
+	"github.com/rs/zerolog/log"
+)
+
+// evaluateGated checks the tool name against ToolNamePatterns before any
+// policy runs. A deny pattern blocks the call without evaluating policy; a
+// human_required pattern escalates whatever policy allows. Either way the
+// gate holds even if a policy module is wrong.
+func (h *Handler) evaluateGated(ctx context.Context, req *ToolCallRequest) (policy.Response, error) {
+	name, action, matched := h.toolNames.MatchName(req.ToolName)
+	if matched {
+		log.Info().Str("tool", req.ToolName).Str("pattern", name).Str("action", action).Msg("tool name pattern matched")
+	}
+
+	if matched && action == PatternActionDeny {
+		return policy.Response{
+			Allow:  false,
+			Reason: fmt.Sprintf("tool name matched blocked pattern: %s", name),
+			Policy: toolNamePatternPrefix + name,
+		}, nil
+	}
+
+	decision, err := h.evaluatePolicy(ctx, req)
+	if err != nil || !matched || !decision.Allow {
+		return decision, err
+	}
+
+	// Dropping the policy's risk keeps auto-approval from clearing the gate
+	decision.HumanRequired = true
+	decision.Risk = nil
+	decision.Reason = fmt.Sprintf("tool name matched sensitive pattern: %s", name)
+	return decision, nil
+}
+
+// validateToolNamePatterns rejects patterns that would otherwise be skipped
+// at startup, since a skipped block pattern silently lets the tool through
+func validateToolNamePatterns(patterns []SensitivePattern) error {
+	for _, p := range patterns {
+		if _, err := regexp.Compile(p.Pattern); err != nil {
+			return fmt.Errorf("tool name pattern %s: %w", p.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package proxy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
+	"github.com/labstack/echo/v4"
+)
+
+var testToolNamePatterns = []SensitivePattern{
+	{Name: "shell", Pattern: `^(exec|shell)$`, Action: PatternActionDeny},
+	{Name: "bulk_delete", Pattern: `^delete_all`, Action: PatternActionHumanRequired},
+}
+
+// countingEvaluator records whether policy evaluation ran
+type countingEvaluator struct {
+	mockPolicyEvaluator
+	calls int
+}
+
+func (m *countingEvaluator) Evaluate(ctx context.Context, req policy.Request) (policy.Response, error) {
+	m.calls++
+	return m.response, m.err
+}
+
+func TestHandleToolCall_ToolNamePatternBlocked(t *testing.T) {
+	mockPolicy := &countingEvaluator{mockPolicyEvaluator: mockPolicyEvaluator{response: policy.Response{Allow: true}}}
+	mockAudit := &mockAuditStore{}
+	config := ProxyConfig{
+		DefaultUpstream:  "http://localhost:9000",
+		ToolNamePatterns: testToolNamePatterns,
+	}
+	handler := NewHandler(config, mockPolicy, mockAudit, &mockApprovalQueue{})
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/tool/call", strings.NewReader(`{"tool_name":"exec","args":{"cmd":"ls"}}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+
+	if err := handler.HandleToolCall(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("handler failed: %v", err)
+	}
+
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected status 403, got %d", rec.Code)
+	}
+	if mockPolicy.calls != 0 {
+		t.Errorf("expected policy evaluation to be skipped, ran %d times", mockPolicy.calls)
+	}
+	if len(mockAudit.entries) != 1 {
+		t.Fatalf("expected 1 audit entry, got %d", len(mockAudit.entries))
+	}
+	if mockAudit.entries[0].Reason != "tool name matched blocked pattern: shell" {
+		t.Errorf("expected pattern name in audit reason, got %q", mockAudit.entries[0].Reason)
+	}
+}
+
+func TestHandleToolCall_ToolNamePatternAllowed(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer upstream.Close()
+
+	mockPolicy := &countingEvaluator{mockPolicyEvaluator: mockPolicyEvaluator{response: policy.Response{Allow: true}}}
+	config := ProxyConfig{
+		DefaultUpstream:  upstream.URL,
+		Timeout:          5,
+		ToolNamePatterns: testToolNamePatterns,
+	}
+	handler := NewHandler(config, mockPolicy, &mockAuditStore{}, &mockApprovalQueue{})
+
+	e := echo.New()
+	// "shell" is anchored, so a tool that merely contains it passes
+	req := httptest.NewRequest(http.MethodPost, "/tool/call", strings.NewReader(`{"tool_name":"shell_history_search","args":{}}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+
+	if err := handler.HandleToolCall(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("handler failed: %v", err)
+	}
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if mockPolicy.calls != 1 {
+		t.Errorf("expected policy to be evaluated once, ran %d times", mockPolicy.calls)
+	}
+}
+
+func TestToolNamePatternEscalates(t *testing.T) {
+	handler := NewHandler(ProxyConfig{ToolNamePatterns: testToolNamePatterns},
+		&mockPolicyEvaluator{response: policy.Response{Allow: true, Risk: risk(0.1)}}, &mockAuditStore{}, &mockApprovalQueue{})
+
+	decision, err := handler.evaluateGated(context.Background(), &ToolCallRequest{ToolName: "delete_all_users"})
+	if err != nil {
+		t.Fatalf("evaluate failed: %v", err)
+	}
+	if !decision.Allow || !decision.HumanRequired {
+		t.Errorf("expected an allowed call escalated to approval, got %+v", decision)
+	}
+	if decision.Risk != nil {
+		t.Errorf("expected the policy's risk dropped so auto-approval cannot apply, got %v", *decision.Risk)
+	}
+}
+
+func TestValidateToolNamePatterns(t *testing.T) {
+	if err := (ProxyConfig{ToolNamePatterns: []SensitivePattern{{Name: "broken", Pattern: "("}}}).Validate(); err == nil {
+		t.Error("expected an invalid tool name pattern to fail validation")
+	}
+}
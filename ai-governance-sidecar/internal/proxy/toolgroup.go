@@ -0,0 +1,47 @@
+package proxy
+
+import (
+	"fmt"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/toolmatch"
+)
+
+// toolGroupKey is the policy metadata key carrying the tool's group
+const toolGroupKey = "tool_group"
+
+// newToolGroups indexes ToolGroups by pattern so a tool resolves to the
+// group of its most specific matching pattern
+func newToolGroups(groups map[string][]string) map[string]string {
+	byPattern := make(map[string]string)
+	for group, patterns := range groups {
+		for _, pattern := range patterns {
+			byPattern[pattern] = group
+		}
+	}
+	return byPattern
+}
+
+// toolGroup returns the configured group for a tool, or "" if none matches
+func (h *Handler) toolGroup(toolName string) string {
+	group, _, _ := toolmatch.Lookup(h.groups, toolName)
+	return group
+}
+
+func validateToolGroups(groups map[string][]string) error {
+	owner := make(map[string]string)
+	for group, patterns := range groups {
+		if group == "" {
+			return fmt.Errorf("tool group name must not be empty")
+		}
+		for _, pattern := range patterns {
+			if pattern == "" {
+				return fmt.Errorf("tool group %s has an empty pattern", group)
+			}
+			if other, dup := owner[pattern]; dup {
+				return fmt.Errorf("tool pattern %q is in both %s and %s", pattern, other, group)
+			}
+			owner[pattern] = group
+		}
+	}
+	return nil
+}
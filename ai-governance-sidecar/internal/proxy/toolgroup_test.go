@@ -0,0 +1,84 @@
+package proxy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
+	"github.com/labstack/echo/v4"
+)
+
+// metadataPolicyEvaluator denies every call and records the metadata it saw
+type metadataPolicyEvaluator struct {
+	mockPolicyEvaluator
+	seen []map[string]any
+}
+
+func (m *metadataPolicyEvaluator) Evaluate(ctx context.Context, req policy.Request) (policy.Response, error) {
+	m.seen = append(m.seen, req.Metadata)
+	return policy.Response{Allow: false, Reason: "recorded"}, nil
+}
+
+func TestHandleToolCall_ToolGroupInPolicyMetadata(t *testing.T) {
+	config := ProxyConfig{
+		DefaultUpstream: "http://localhost:9000",
+		ToolGroups: map[string][]string{
+			"database":   {"db.*"},
+			"filesystem": {"fs.read*", "fs.stat"},
+		},
+	}
+
+	tests := []struct {
+		name string
+		body string
+		want any
+	}{
+		{name: "glob", body: `{"tool_name":"db.query","args":{}}`, want: "database"},
+		{name: "prefix glob", body: `{"tool_name":"fs.readdir","args":{}}`, want: "filesystem"},
+		{name: "exact", body: `{"tool_name":"fs.stat","args":{}}`, want: "filesystem"},
+		{name: "no match", body: `{"tool_name":"fs.write","args":{}}`, want: nil},
+		{name: "caller value dropped", body: `{"tool_name":"send_email","args":{},"metadata":{"tool_group":"database"}}`, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evaluator := &metadataPolicyEvaluator{}
+			handler := NewHandler(config, evaluator, &mockAuditStore{}, &mockApprovalQueue{})
+
+			req := httptest.NewRequest(http.MethodPost, "/tool/call", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+
+			if err := handler.HandleToolCall(echo.New().NewContext(req, rec)); err != nil {
+				t.Fatalf("handler failed: %v", err)
+			}
+			if len(evaluator.seen) != 1 {
+				t.Fatalf("expected one policy evaluation, got %d", len(evaluator.seen))
+			}
+			if got := evaluator.seen[0][toolGroupKey]; got != tt.want {
+				t.Errorf("expected tool_group %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestJustificationToolsAcceptGlobs(t *testing.T) {
+	handler := NewHandler(ProxyConfig{JustificationTools: []string{"db.*"}}, &mockPolicyEvaluator{}, &mockAuditStore{}, &mockApprovalQueue{})
+
+	if !handler.justificationTool("db.delete") {
+		t.Error("expected db.delete to match db.*")
+	}
+	if handler.justificationTool("dbx.delete") {
+		t.Error("expected dbx.delete not to match db.*")
+	}
+}
+
+func TestValidateToolGroups(t *testing.T) {
+	groups := map[string][]string{"a": {"db.*"}, "b": {"db.*"}}
+	if err := (ProxyConfig{ToolGroups: groups}).Validate(); err == nil {
+		t.Error("expected a pattern in two groups to fail validation")
+	}
+}
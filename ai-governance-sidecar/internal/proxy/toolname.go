@@ -0,0 +1,35 @@
+package proxy
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Tool name normalization steps
+const (
+	ToolNameTrim      = "trim"
+	ToolNameLowercase = "lowercase"
+)
+
+// normalizeToolName applies steps in order so that case or whitespace
+// variants of a name cannot slip past rules keyed on the exact name
+func normalizeToolName(name string, steps []string) string {
+	for _, step := range steps {
+		switch step {
+		case ToolNameTrim:
+			name = strings.TrimSpace(name)
+		case ToolNameLowercase:
+			name = strings.ToLower(name)
+		}
+	}
+	return name
+}
+
+func validateToolNameNormalization(steps []string) error {
+	for _, step := range steps {
+		if step != ToolNameTrim && step != ToolNameLowercase {
+			return fmt.Errorf("unknown tool name normalization %q", step)
+		}
+	}
+	return nil
+}
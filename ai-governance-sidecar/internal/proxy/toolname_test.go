@@ -0,0 +1,91 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
+	"github.com/labstack/echo/v4"
+)
+
+// namePolicyEvaluator denies deleteuser and records the names it saw
+type namePolicyEvaluator struct {
+	mockPolicyEvaluator
+	seen []string
+}
+
+func (m *namePolicyEvaluator) Evaluate(ctx context.Context, req policy.Request) (policy.Response, error) {
+	m.seen = append(m.seen, req.ToolName)
+	if req.ToolName == "deleteuser" {
+		return policy.Response{Allow: false, Reason: "deleteuser is blocked"}, nil
+	}
+	return policy.Response{Allow: true}, nil
+}
+
+func TestNormalizeToolName(t *testing.T) {
+	tests := []struct {
+		name   string
+		steps  []string
+		expect string
+	}{
+		{name: "off", expect: " DeleteUser "},
+		{name: "trim", steps: []string{ToolNameTrim}, expect: "DeleteUser"},
+		{name: "lowercase", steps: []string{ToolNameLowercase}, expect: " deleteuser "},
+		{name: "both", steps: []string{ToolNameTrim, ToolNameLowercase}, expect: "deleteuser"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeToolName(" DeleteUser ", tt.steps); got != tt.expect {
+				t.Errorf("expected %q, got %q", tt.expect, got)
+			}
+		})
+	}
+}
+
+func TestHandleToolCall_NormalizedToolName(t *testing.T) {
+	evaluator := &namePolicyEvaluator{}
+	mockAudit := &mockAuditStore{}
+	config := ProxyConfig{
+		DefaultUpstream:       "http://localhost:9000",
+		ToolNameNormalization: []string{ToolNameTrim, ToolNameLowercase},
+	}
+	handler := NewHandler(config, evaluator, mockAudit, &mockApprovalQueue{})
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/tool/call", strings.NewReader(`{"tool_name":"  DeleteUser","args":{"id":1}}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+
+	if err := handler.HandleToolCall(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("handler failed: %v", err)
+	}
+
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected the case variant to be denied, got %d", rec.Code)
+	}
+	if len(evaluator.seen) != 1 || evaluator.seen[0] != "deleteuser" {
+		t.Errorf("expected policy to see deleteuser, got %v", evaluator.seen)
+	}
+
+	if len(mockAudit.entries) != 1 {
+		t.Fatalf("expected 1 audit entry, got %d", len(mockAudit.entries))
+	}
+	var audited ToolCallRequest
+	if err := json.Unmarshal(mockAudit.entries[0].ToolInput, &audited); err != nil {
+		t.Fatalf("failed to parse audit entry: %v", err)
+	}
+	if audited.ToolName != "deleteuser" {
+		t.Errorf("expected audit to record deleteuser, got %q", audited.ToolName)
+	}
+}
+
+func TestValidateToolNameNormalization(t *testing.T) {
+	if err := (ProxyConfig{ToolNameNormalization: []string{"uppercase"}}).Validate(); err == nil {
+		t.Error("expected an unknown normalization step to fail validation")
+	}
+}
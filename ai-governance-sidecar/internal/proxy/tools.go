@@ -0,0 +1,197 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// Governance annotations for listed tools. They are predictions from a
+// dry run; the call itself is always evaluated again.
+const (
+	GovernanceAlwaysAllowed    = "always-allowed"
+	GovernancePolicyGated      = "policy-gated"
+	GovernanceApprovalRequired = "approval-required"
+	GovernanceDenied           = "denied"
+)
+
+const defaultToolsPath = "/tools"
+
+// ToolInfo is one tool from the upstream catalog, MCP tools/list style
+type ToolInfo struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	InputSchema json.RawMessage `json:"inputSchema,omitempty"`
+}
+
+type AnnotatedTool struct {
+	ToolInfo
+	Governance       string `json:"governance"`
+	GovernanceReason string `json:"governance_reason,omitempty"`
+}
+
+type ToolsResponse struct {
+	Tools []AnnotatedTool `json:"tools"`
+}
+
+// HandleListTools proxies the upstream tool catalog and annotates each
+// tool with the governance outcome an agent should expect.
+func (h *Handler) HandleListTools(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	catalogURL, err := h.toolsCatalogURL()
+	if err != nil {
+		return h.errorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+
+	raw, err := h.forwarder.Fetch(ctx, catalogURL)
+	if err != nil {
+		log.Error().Err(err).Str("url", catalogURL).Msg("tool catalog fetch failed")
+		return h.errorResponse(c, http.StatusBadGateway, "upstream tool catalog unavailable")
+	}
+
+	tools, err := parseToolCatalog(raw)
+	if err != nil {
+		log.Error().Err(err).Str("url", catalogURL).Msg("invalid tool catalog")
+		return h.errorResponse(c, http.StatusBadGateway, "upstream tool catalog is invalid")
+	}
+
+	header := c.Request().Header
+	annotated := make([]AnnotatedTool, 0, len(tools))
+	for _, tool := range tools {
+		status, reason := h.governanceFor(ctx, tool, header)
+		annotated = append(annotated, AnnotatedTool{ToolInfo: tool, Governance: status, GovernanceReason: reason})
+	}
+
+	return c.JSON(http.StatusOK, ToolsResponse{Tools: annotated})
+}
+
+func (h *Handler) toolsCatalogURL() (string, error) {
+	if h.config.ToolsCatalogURL != "" {
+		return h.config.ToolsCatalogURL, nil
+	}
+
+	base, err := url.Parse(h.config.DefaultUpstream)
+	if err != nil {
+		return "", fmt.Errorf("invalid default upstream: %w", err)
+	}
+	return base.JoinPath(defaultToolsPath).String(), nil
+}
+
+// parseToolCatalog accepts {"tools": [...]} or a bare array
+func parseToolCatalog(raw json.RawMessage) ([]ToolInfo, error) {
+	var wrapped struct {
+		Tools []ToolInfo `json:"tools"`
+	}
+	if err := json.Unmarshal(raw, &wrapped); err == nil && wrapped.Tools != nil {
+		return wrapped.Tools, nil
+	}
+
+	var tools []ToolInfo
+	if err := json.Unmarshal(raw, &tools); err != nil {
+		return nil, err
+	}
+	return tools, nil
+}
+
+// governanceFor dry-runs the tool with empty arguments and with example
+// arguments built from its schema. Agreement gives a firm status; if the
+// verdict changes with the arguments the tool is policy-gated.
+func (h *Handler) governanceFor(ctx context.Context, tool ToolInfo, header http.Header) (string, string) {
+	empty, err := h.dryRun(ctx, tool.Name, json.RawMessage(`{}`), header)
+	if err != nil {
+		return GovernancePolicyGated, "dry run failed"
+	}
+
+	example, err := json.Marshal(exampleFromSchema(tool.InputSchema))
+	if err != nil {
+		return GovernancePolicyGated, "dry run failed"
+	}
+	withArgs, err := h.dryRun(ctx, tool.Name, example, header)
+	if err != nil {
+		return GovernancePolicyGated, "dry run failed"
+	}
+
+	emptyStatus, withArgsStatus := governanceStatus(empty), governanceStatus(withArgs)
+	if emptyStatus != withArgsStatus {
+		return GovernancePolicyGated, "verdict depends on arguments"
+	}
+	return emptyStatus, withArgs.Reason
+}
+
+// dryRun evaluates a synthetic call without counting it against policy
+// rate limits
+func (h *Handler) dryRun(ctx context.Context, toolName string, args json.RawMessage, header http.Header) (policy.Response, error) {
+	req := &ToolCallRequest{ToolName: toolName, Args: args}
+	if err := h.normalizeRequest(ctx, req, header); err != nil {
+		return policy.Response{}, err
+	}
+	return h.evaluateGated(policy.WithReadOnlyCounters(ctx), req)
+}
+
+func governanceStatus(decision policy.Response) string {
+	switch {
+	case !decision.Allow && decision.OverrideAllowed:
+		return GovernanceApprovalRequired
+	case !decision.Allow:
+		return GovernanceDenied
+	case decision.HumanRequired:
+		return GovernanceApprovalRequired
+	default:
+		return GovernanceAlwaysAllowed
+	}
+}
+
+// exampleFromSchema fills required properties with placeholder values so
+// policies see arguments shaped like a real call.
+func exampleFromSchema(schema json.RawMessage) map[string]any {
+	example := map[string]any{}
+	if len(schema) == 0 {
+		return example
+	}
+
+	var parsed jsonSchema
+	if err := json.Unmarshal(schema, &parsed); err != nil {
+		return example
+	}
+
+	for _, name := range parsed.Required {
+		example[name] = parsed.Properties[name].example()
+	}
+	return example
+}
+
+type jsonSchema struct {
+	Type       string                `json:"type"`
+	Properties map[string]jsonSchema `json:"properties"`
+	Required   []string              `json:"required"`
+	Items      *jsonSchema           `json:"items"`
+}
+
+func (s jsonSchema) example() any {
+	switch s.Type {
+	case "integer", "number":
+		return 1
+	case "boolean":
+		return true
+	case "array":
+		if s.Items != nil {
+			return []any{s.Items.example()}
+		}
+		return []any{}
+	case "object":
+		obj := map[string]any{}
+		for _, name := range s.Required {
+			obj[name] = s.Properties[name].example()
+		}
+		return obj
+	default:
+		return "example"
+	}
+}
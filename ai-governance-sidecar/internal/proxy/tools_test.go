@@ -0,0 +1,102 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
+	"github.com/labstack/echo/v4"
+)
+
+// argsPolicyEvaluator decides by tool name, escalating shell commands only
+// when a command argument is present.
+type argsPolicyEvaluator struct {
+	mockPolicyEvaluator
+}
+
+func (m *argsPolicyEvaluator) Evaluate(ctx context.Context, req policy.Request) (policy.Response, error) {
+	switch req.ToolName {
+	case "drop_table":
+		return policy.Response{Allow: false, Reason: "destructive"}, nil
+	case "send_payment":
+		return policy.Response{Allow: true, HumanRequired: true, Reason: "payments need review"}, nil
+	case "run_shell":
+		var args map[string]any
+		json.Unmarshal(req.Args, &args)
+		if _, ok := args["command"]; ok {
+			return policy.Response{Allow: true, HumanRequired: true, Reason: "shell command"}, nil
+		}
+	}
+	return policy.Response{Allow: true, Reason: "all policies passed"}, nil
+}
+
+func TestHandleListTools(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/tools" || r.Method != http.MethodGet {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"tools":[
+			{"name":"get_weather","description":"weather"},
+			{"name":"drop_table"},
+			{"name":"send_payment"},
+			{"name":"run_shell","inputSchema":{"type":"object","properties":{"command":{"type":"string"}},"required":["command"]}}
+		]}`))
+	}))
+	defer upstream.Close()
+
+	handler := NewHandler(ProxyConfig{DefaultUpstream: upstream.URL, Timeout: 10}, &argsPolicyEvaluator{}, &mockAuditStore{}, &mockApprovalQueue{})
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/tools", nil)
+	rec := httptest.NewRecorder()
+
+	if err := handler.HandleListTools(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("handler failed: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp ToolsResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+
+	expected := map[string]string{
+		"get_weather":  GovernanceAlwaysAllowed,
+		"drop_table":   GovernanceDenied,
+		"send_payment": GovernanceApprovalRequired,
+		"run_shell":    GovernancePolicyGated,
+	}
+	if len(resp.Tools) != len(expected) {
+		t.Fatalf("expected %d tools, got %d", len(expected), len(resp.Tools))
+	}
+	for _, tool := range resp.Tools {
+		if tool.Governance != expected[tool.Name] {
+			t.Errorf("%s: expected %s, got %s", tool.Name, expected[tool.Name], tool.Governance)
+		}
+	}
+	if resp.Tools[0].Description != "weather" {
+		t.Errorf("expected upstream fields to pass through, got %+v", resp.Tools[0])
+	}
+}
+
+func TestHandleListToolsUpstreamFailure(t *testing.T) {
+	upstream := httptest.NewServer(http.NotFoundHandler())
+	defer upstream.Close()
+
+	handler := NewHandler(ProxyConfig{DefaultUpstream: upstream.URL, Timeout: 10}, &argsPolicyEvaluator{}, &mockAuditStore{}, &mockApprovalQueue{})
+
+	e := echo.New()
+	rec := httptest.NewRecorder()
+	if err := handler.HandleListTools(e.NewContext(httptest.NewRequest(http.MethodGet, "/tools", nil), rec)); err != nil {
+		t.Fatalf("handler failed: %v", err)
+	}
+	if rec.Code != http.StatusBadGateway {
+		t.Errorf("expected status 502, got %d", rec.Code)
+	}
+}
@@ -2,6 +2,8 @@ package proxy
 
 import (
 	"encoding/json"
+	"mime/multipart"
+	"net/http"
 
 	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
 )
@@ -10,25 +12,150 @@ type ToolCallRequest struct {
 	ToolName string          `json:"tool_name"`
 	Args     json.RawMessage `json:"args"`
 	Upstream string          `json:"upstream,omitempty"`
+	Method   string          `json:"method,omitempty"`
+	// TimeoutMs overrides the upstream timeout for this call, capped at
+	// the configured maximum
+	TimeoutMs int `json:"timeout_ms,omitempty"`
+	// Metadata is caller-supplied context such as a cost center; it is
+	// passed to policies alongside upstream and method
+	Metadata map[string]any `json:"metadata,omitempty"`
+	// Justification explains why a sensitive tool is being called; it is
+	// recorded with the request in the audit log
+	Justification string `json:"justification,omitempty"`
+	// Headers are captured from the incoming request, never from the body
+	Headers map[string]string `json:"-"`
+	// Files is set only for multipart uploads
+	Files  []policy.FileInfo `json:"files,omitempty"`
+	upload *multipart.Form
+	// toolGroup is the ToolGroups entry the tool name matched
+	toolGroup string
+	// user is the authenticated caller, passed to policies
+	user *policy.User
+	// warnings are the policy warnings returned with the forwarded result
+	warnings []string
 }
 
 type ToolCallResponse struct {
-	Success bool            `json:"success"`
-	Result  json.RawMessage `json:"result,omitempty"`
-	Error   string          `json:"error,omitempty"`
+	Success   bool            `json:"success"`
+	Result    json.RawMessage `json:"result,omitempty"`
+	Error     string          `json:"error,omitempty"`
+	Code      string          `json:"code,omitempty"`
+	Retryable bool            `json:"retryable,omitempty"`
+	Warnings  []string        `json:"warnings,omitempty"`
 }
 
+// CodeApprovalTimeout marks a call that was rejected because no approver
+// decided in time, as opposed to a policy or human deny.
+const CodeApprovalTimeout = "APPROVAL_TIMEOUT"
+
+const defaultApprovalTimeoutMessage = "approval timed out before a decision was made; the request may be retried"
+
 type ProxyConfig struct {
 	DefaultUpstream string
 	Timeout         int // seconds
+	// MaxTimeout caps a request's timeout_ms; zero caps it at Timeout
+	MaxTimeout      int // seconds
+	MaxReasonLength int // policy reasons longer than this are truncated in audit
+	// MaxArgsBytes and MaxArgsDepth bound the args sent to policies; zero
+	// disables the check
+	MaxArgsBytes int
+	MaxArgsDepth int
+	// ApprovalTimeoutMessage is returned to clients when approval times out
+	ApprovalTimeoutMessage string
+	// ApprovalGrantTTL lets an identical request reuse a human approval for
+	// this long; zero requires approval every time
+	ApprovalGrantTTL int // seconds
+	// ApprovalHeartbeat writes a newline to a call blocked on approval
+	// this often so idle timeouts don't drop it; zero disables
+	ApprovalHeartbeat int // seconds
+	// PolicyHeaders lists request headers exposed to policies as input.headers
+	PolicyHeaders []string
+	AutoApprove   AutoApproveConfig
+	// SensitivePatterns deny or escalate calls whose args match
+	SensitivePatterns []SensitivePattern
+	// ResponseFormat is the default for X-Response-Format: wrapped (or
+	// empty) returns a ToolCallResponse, raw relays the upstream reply
+	ResponseFormat string
+	// ToolNameNormalization lists steps (trim, lowercase) applied to every
+	// tool name before it is evaluated, routed, forwarded or audited
+	ToolNameNormalization []string
+	// ToolNamePatterns block or escalate calls by tool name before policy
+	// evaluation
+	ToolNamePatterns []SensitivePattern
+	// TimeWindows deny or escalate allowed calls during recurring windows,
+	// evaluated in TimeWindowZone (an IANA name; empty is local time)
+	TimeWindows    []TimeWindowRule
+	TimeWindowZone string
+	// UpstreamCAFile adds a PEM bundle to the trust store for upstream TLS
+	UpstreamCAFile string
+	// UpstreamInsecureSkipVerify disables certificate checks (dev only)
+	UpstreamInsecureSkipVerify bool
+	// UpstreamProxyURL overrides HTTP_PROXY/HTTPS_PROXY for upstream calls.
+	// Proxied targets are resolved by the sidecar to refuse metadata
+	// addresses, but the proxy resolves them again; it must block metadata
+	// endpoints itself for the guarantee to hold.
+	UpstreamProxyURL string
+	// UpstreamAllowlist limits client-supplied upstreams, and redirects to
+	// another host, to these hosts
+	UpstreamAllowlist []string
+	// AuditDetail is AuditDetailLean or AuditDetailFull
+	AuditDetail string
+	// AuditSampling audits 1 in N policy-allowed calls for the named tools.
+	// Denials and approvals are always audited.
+	AuditSampling map[string]int
+	// ToolsCatalogURL serves the upstream tool listing; defaults to
+	// /tools on DefaultUpstream
+	ToolsCatalogURL string
+	// GRPCRoutes sends the named tools to gRPC methods instead of HTTP
+	GRPCRoutes map[string]GRPCRoute
+	// UpstreamRoutes spreads the named tools over replica URLs, skipping
+	// replicas whose health check fails
+	UpstreamRoutes map[string][]string
+	// UpstreamHealthPath is polled on each replica's host every
+	// UpstreamHealthInterval seconds
+	UpstreamHealthPath     string
+	UpstreamHealthInterval int // seconds
+	// RequiredMetadata lists metadata keys every tool call must carry
+	RequiredMetadata []string
+	// UserQuotas limits calls per authenticated user id; the "*" entry
+	// applies to users without their own
+	UserQuotas map[string]UserQuota
+	// JustificationTools must carry justification text; policies can also
+	// require it per call
+	JustificationTools []string
+	// ToolGroups names families of tools by glob, e.g. "database":
+	// ["db.*"]. A call's group is passed to policies as
+	// metadata.tool_group.
+	ToolGroups map[string][]string
+}
+
+// allowedMethods are the HTTP verbs a tool call may use against its upstream
+var allowedMethods = map[string]bool{
+	http.MethodPost:   true,
+	http.MethodPut:    true,
+	http.MethodPatch:  true,
+	http.MethodDelete: true,
 }
 
 func (r *ToolCallRequest) ToPolicyRequest() policy.Request {
+	metadata := make(map[string]any, len(r.Metadata)+2)
+	for key, value := range r.Metadata {
+		metadata[key] = value
+	}
+	metadata["upstream"] = r.Upstream
+	metadata["method"] = r.Method
+	// Only the sidecar may name the group; a caller's value is dropped
+	delete(metadata, toolGroupKey)
+	if r.toolGroup != "" {
+		metadata[toolGroupKey] = r.toolGroup
+	}
+
 	return policy.Request{
 		ToolName: r.ToolName,
 		Args:     r.Args,
-		Metadata: map[string]any{
-			"upstream": r.Upstream,
-		},
+		Metadata: metadata,
+		Headers:  r.Headers,
+		Files:    r.Files,
+		User:     r.user,
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,187 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var (
+	ErrUpstreamNotAllowed = errors.New("upstream host not allowed")
+	ErrUpstreamBlocked    = errors.New("upstream address blocked")
+)
+
+// Cloud metadata endpoints that are never valid tool upstreams
+var blockedHosts = map[string]bool{
+	"metadata":                 true,
+	"metadata.google.internal": true,
+}
+
+var blockedIPs = []net.IP{
+	net.ParseIP("fd00:ec2::254"), // AWS IPv6 metadata
+}
+
+// upstreamGuard restricts which upstreams a client may target. Entries in
+// allow are hostnames; a leading "*." matches any subdomain.
+type upstreamGuard struct {
+	allow []string
+}
+
+func newUpstreamGuard(allow []string) *upstreamGuard {
+	normalized := make([]string, 0, len(allow))
+	for _, host := range allow {
+		if host = strings.ToLower(strings.TrimSpace(host)); host != "" {
+			normalized = append(normalized, host)
+		}
+	}
+	return &upstreamGuard{allow: normalized}
+}
+
+func (g *upstreamGuard) check(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Hostname() == "" {
+		return fmt.Errorf("%w: invalid upstream URL", ErrUpstreamNotAllowed)
+	}
+
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return fmt.Errorf("%w: scheme %q", ErrUpstreamNotAllowed, parsed.Scheme)
+	}
+
+	host := strings.ToLower(parsed.Hostname())
+	if isBlockedHost(host) {
+		return fmt.Errorf("%w: %s", ErrUpstreamBlocked, host)
+	}
+
+	if len(g.allow) == 0 {
+		return nil
+	}
+
+	for _, allowed := range g.allow {
+		if host == allowed {
+			return nil
+		}
+		if suffix, ok := strings.CutPrefix(allowed, "*."); ok && strings.HasSuffix(host, "."+suffix) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %s", ErrUpstreamNotAllowed, host)
+}
+
+func isBlockedHost(host string) bool {
+	if blockedHosts[host] {
+		return true
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		return isBlockedIP(ip)
+	}
+	return false
+}
+
+func isBlockedIP(ip net.IP) bool {
+	if ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified() {
+		return true
+	}
+
+	for _, blocked := range blockedIPs {
+		if ip.Equal(blocked) {
+			return true
+		}
+	}
+	return false
+}
+
+// blockLinkLocalDial rejects connections to blocked addresses after DNS
+// resolution, so a hostname pointing at a metadata IP is still refused.
+func blockLinkLocalDial(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	if ip := net.ParseIP(host); ip != nil && isBlockedIP(ip) {
+		return fmt.Errorf("%w: %s", ErrUpstreamBlocked, ip)
+	}
+	return nil
+}
+
+func guardedDialContext() func(ctx context.Context, network, address string) (net.Conn, error) {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   blockLinkLocalDial,
+	}
+	return dialer.DialContext
+}
+
+// maxRedirects matches the http.Client default
+const maxRedirects = 10
+
+// checkRedirect refuses redirects to blocked hosts, and redirects to another
+// host that the allowlist would not accept as an upstream. A redirect to the
+// host already being called is followed.
+func (f *Forwarder) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxRedirects)
+	}
+
+	host := strings.ToLower(req.URL.Hostname())
+	if isBlockedHost(host) {
+		return fmt.Errorf("redirect: %w: %s", ErrUpstreamBlocked, host)
+	}
+
+	if f.upstreams == nil || strings.EqualFold(req.URL.Host, via[0].URL.Host) {
+		return nil
+	}
+	if err := f.upstreams.check(req.URL.String()); err != nil {
+		return fmt.Errorf("redirect: %w", err)
+	}
+	return nil
+}
+
+// guardProxied wraps a transport's Proxy function. Through a proxy the dial
+// guard only sees the proxy's address, so the target is resolved here and
+// refused if it is a blocked address. The proxy may resolve names
+// differently, so this is best effort; the proxy has to enforce its own
+// egress rules.
+func guardProxied(proxy func(*http.Request) (*url.URL, error)) func(*http.Request) (*url.URL, error) {
+	return func(req *http.Request) (*url.URL, error) {
+		proxyURL, err := proxy(req)
+		if err != nil || proxyURL == nil {
+			return proxyURL, err
+		}
+		if err := checkResolved(req.Context(), req.URL.Hostname()); err != nil {
+			return nil, err
+		}
+		return proxyURL, nil
+	}
+}
+
+func checkResolved(ctx context.Context, host string) error {
+	host = strings.ToLower(host)
+	if isBlockedHost(host) {
+		return fmt.Errorf("%w: %s", ErrUpstreamBlocked, host)
+	}
+	if net.ParseIP(host) != nil {
+		return nil
+	}
+
+	// A name only the proxy can resolve is left to the proxy
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil
+	}
+	for _, addr := range addrs {
+		if isBlockedIP(addr.IP) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrUpstreamBlocked, host, addr.IP)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/audit"
+	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
+	"github.com/labstack/echo/v4"
+)
+
+func TestUpstreamGuard(t *testing.T) {
+	guard := newUpstreamGuard([]string{"tools.internal", "*.example.com"})
+
+	tests := []struct {
+		name      string
+		upstream  string
+		expectErr error
+	}{
+		{name: "allowed host", upstream: "http://tools.internal:9000/run"},
+		{name: "allowed subdomain", upstream: "https://api.example.com/run"},
+		{name: "disallowed host", upstream: "http://billing.internal/run", expectErr: ErrUpstreamNotAllowed},
+		{name: "bare wildcard domain not matched", upstream: "http://example.com/run", expectErr: ErrUpstreamNotAllowed},
+		{name: "non-http scheme", upstream: "file:///etc/passwd", expectErr: ErrUpstreamNotAllowed},
+		{name: "metadata IP", upstream: "http://169.254.169.254/latest/meta-data", expectErr: ErrUpstreamBlocked},
+		{name: "metadata hostname", upstream: "http://metadata.google.internal/computeMetadata", expectErr: ErrUpstreamBlocked},
+		{name: "ipv6 link-local", upstream: "http://[fe80::1]/", expectErr: ErrUpstreamBlocked},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := guard.check(tt.upstream)
+			if tt.expectErr == nil && err != nil {
+				t.Errorf("expected allowed, got %v", err)
+			}
+			if tt.expectErr != nil && !errors.Is(err, tt.expectErr) {
+				t.Errorf("expected %v, got %v", tt.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestUpstreamGuardBlocksMetadataWithoutAllowlist(t *testing.T) {
+	guard := newUpstreamGuard(nil)
+
+	if err := guard.check("http://anything.example/run"); err != nil {
+		t.Errorf("expected any host allowed without allowlist, got %v", err)
+	}
+	if err := guard.check("http://169.254.169.254/"); !errors.Is(err, ErrUpstreamBlocked) {
+		t.Errorf("expected metadata IP blocked by default, got %v", err)
+	}
+}
+
+func TestHandleToolCall_UpstreamRejected(t *testing.T) {
+	mockAudit := &mockAuditStore{}
+	config := ProxyConfig{
+		DefaultUpstream:   "http://localhost:9000",
+		UpstreamAllowlist: []string{"tools.internal"},
+	}
+	handler := NewHandler(config, &mockPolicyEvaluator{response: policy.Response{Allow: true}}, mockAudit, &mockApprovalQueue{})
+
+	e := echo.New()
+	body := `{"tool_name":"fetch","args":{},"upstream":"http://169.254.169.254/latest"}`
+	req := httptest.NewRequest(http.MethodPost, "/tool/call", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := handler.HandleToolCall(c); err != nil {
+		t.Fatalf("handler failed: %v", err)
+	}
+
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected status 403, got %d", rec.Code)
+	}
+
+	if len(mockAudit.entries) != 1 || mockAudit.entries[0].Decision != audit.DecisionDeny {
+		t.Fatalf("expected rejected upstream to be audited as deny, got %+v", mockAudit.entries)
+	}
+	if !strings.Contains(mockAudit.entries[0].Reason, "blocked") {
+		t.Errorf("unexpected audit reason: %s", mockAudit.entries[0].Reason)
+	}
+}
+
+func TestForwarder_BlocksLinkLocalAfterResolution(t *testing.T) {
+	withTransport, err := NewForwarderWithTransport(2, TransportOptions{})
+	if err != nil {
+		t.Fatalf("create forwarder: %v", err)
+	}
+
+	// NewHandler falls back to a plain forwarder; it must be guarded too
+	forwarders := map[string]*Forwarder{
+		"with transport options": withTransport,
+		"plain":                  NewForwarder(2),
+	}
+
+	for name, forwarder := range forwarders {
+		t.Run(name, func(t *testing.T) {
+			req := &ToolCallRequest{ToolName: "test", Args: json.RawMessage(`{}`)}
+			_, err := forwarder.Forward(context.Background(), "http://169.254.169.254/latest", req)
+			if !errors.Is(err, ErrUpstreamBlocked) {
+				t.Errorf("expected dial to be blocked, got %v", err)
+			}
+		})
+	}
+}
+
+func TestForwarder_RedirectsAreChecked(t *testing.T) {
+	var reached bool
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reached = true
+		w.Write([]byte(`{"result":"other"}`))
+	}))
+	defer other.Close()
+	otherURL := strings.Replace(other.URL, "127.0.0.1", "localhost", 1)
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/same":
+			http.Redirect(w, r, "/done", http.StatusFound)
+		case "/other":
+			http.Redirect(w, r, otherURL+"/run", http.StatusFound)
+		case "/metadata":
+			http.Redirect(w, r, "http://169.254.169.254/latest", http.StatusFound)
+		default:
+			w.Write([]byte(`{"result":"done"}`))
+		}
+	}))
+	defer upstream.Close()
+
+	forwarder := NewForwarder(2)
+	forwarder.upstreams = newUpstreamGuard([]string{"127.0.0.1"})
+	req := &ToolCallRequest{ToolName: "test", Args: json.RawMessage(`{}`)}
+
+	if _, err := forwarder.Forward(context.Background(), upstream.URL+"/same", req); err != nil {
+		t.Errorf("expected a redirect on the same host to be followed, got %v", err)
+	}
+	if _, err := forwarder.Forward(context.Background(), upstream.URL+"/other", req); !errors.Is(err, ErrUpstreamNotAllowed) {
+		t.Errorf("expected redirect to a disallowed host to be refused, got %v", err)
+	}
+	if reached {
+		t.Error("redirect target outside the allowlist was called")
+	}
+	if _, err := forwarder.Forward(context.Background(), upstream.URL+"/metadata", req); !errors.Is(err, ErrUpstreamBlocked) {
+		t.Errorf("expected redirect to a metadata address to be refused, got %v", err)
+	}
+}
+
+func TestForwarder_ProxiedMetadataIsBlocked(t *testing.T) {
+	var proxied int
+	proxyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied++
+		w.Write([]byte(`{}`))
+	}))
+	defer proxyServer.Close()
+
+	forwarder, err := NewForwarderWithTransport(2, TransportOptions{ProxyURL: proxyServer.URL})
+	if err != nil {
+		t.Fatalf("create forwarder: %v", err)
+	}
+
+	req := &ToolCallRequest{ToolName: "test", Args: json.RawMessage(`{}`)}
+	if _, err := forwarder.Forward(context.Background(), "http://169.254.169.254/latest", req); !errors.Is(err, ErrUpstreamBlocked) {
+		t.Errorf("expected metadata address to be blocked through the proxy, got %v", err)
+	}
+	if proxied != 0 {
+		t.Errorf("expected the proxy not to be called, got %d requests", proxied)
+	}
+}
@@ -0,0 +1,102 @@
+// Package redaction masks secret values in tool arguments before they are
+// shown to people, such as approvers on the dashboard, who need to see the
+// shape of a call but not its credentials.
+package redaction
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// Placeholder replaces every redacted value
+const Placeholder = "[REDACTED]"
+
+// DefaultKeys are the key fragments redacted when none are configured
+var DefaultKeys = []string{"password", "passwd", "secret", "token", "api_key", "apikey", "authorization", "credential", "private_key"}
+
+// Redactor replaces the value of any object key containing one of its
+// fragments, case-insensitively, at any depth. A nil Redactor leaves
+// arguments untouched.
+type Redactor struct {
+	keys []string
+}
+
+// New returns a Redactor for keys, or nil when keys is empty
+func New(keys []string) *Redactor {
+	r := &Redactor{}
+	for _, key := range keys {
+		if key = strings.ToLower(strings.TrimSpace(key)); key != "" {
+			r.keys = append(r.keys, key)
+		}
+	}
+	if len(r.keys) == 0 {
+		return nil
+	}
+	return r
+}
+
+// Args returns args with sensitive values replaced. Arguments that are not
+// valid JSON are returned as they are.
+func (r *Redactor) Args(args json.RawMessage) json.RawMessage {
+	if r == nil || len(args) == 0 {
+		return args
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(args))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return args
+	}
+
+	redacted, changed := r.value(value)
+	if !changed {
+		return args
+	}
+
+	out, err := json.Marshal(redacted)
+	if err != nil {
+		return args
+	}
+	return out
+}
+
+func (r *Redactor) value(v any) (any, bool) {
+	changed := false
+
+	switch v := v.(type) {
+	case map[string]any:
+		for key, inner := range v {
+			if r.sensitive(key) {
+				v[key] = Placeholder
+				changed = true
+				continue
+			}
+			if redacted, ok := r.value(inner); ok {
+				v[key] = redacted
+				changed = true
+			}
+		}
+	case []any:
+		for i, inner := range v {
+			if redacted, ok := r.value(inner); ok {
+				v[i] = redacted
+				changed = true
+			}
+		}
+	}
+
+	return v, changed
+}
+
+func (r *Redactor) sensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, fragment := range r.keys {
+		if strings.Contains(key, fragment) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,42 @@
+package redaction
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestArgs(t *testing.T) {
+	r := New(DefaultKeys)
+
+	tests := []struct {
+		name string
+		args string
+		want string
+	}{
+		{"top level", `{"user":"bob","password":"hunter2"}`, `{"password":"[REDACTED]","user":"bob"}`},
+		{"key fragment", `{"DB_Password":"x","access_token":"y"}`, `{"DB_Password":"[REDACTED]","access_token":"[REDACTED]"}`},
+		{"nested", `{"conn":{"host":"db","secret":{"v":1}},"items":[{"api_key":"k"}]}`, `{"conn":{"host":"db","secret":"[REDACTED]"},"items":[{"api_key":"[REDACTED]"}]}`},
+		{"nothing sensitive", `{"path": "/tmp/x",  "n": 12345678901234567890}`, `{"path": "/tmp/x",  "n": 12345678901234567890}`},
+		{"not json", `not json`, `not json`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(r.Args(json.RawMessage(tt.args))); got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNilRedactor(t *testing.T) {
+	r := New([]string{" ", ""})
+	if r != nil {
+		t.Fatal("expected no redactor without keys")
+	}
+
+	args := json.RawMessage(`{"password":"hunter2"}`)
+	if got := string(r.Args(args)); got != string(args) {
+		t.Errorf("expected args untouched, got %s", got)
+	}
+}
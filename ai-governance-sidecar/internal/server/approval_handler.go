@@ -1,24 +1,76 @@
 package server
 
 import (
+	"fmt"
+	"math/rand/v2"
 	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/dagbolade/ai-governance-sidecar/internal/approval"
+	"github.com/dagbolade/ai-governance-sidecar/internal/audit"
+	"github.com/dagbolade/ai-governance-sidecar/internal/auth"
+	"github.com/dagbolade/ai-governance-sidecar/internal/proxy"
+	"github.com/dagbolade/ai-governance-sidecar/internal/redaction"
 	"github.com/labstack/echo/v4"
 	"github.com/rs/zerolog/log"
 )
 
+// Overflow modes for approval reasons and comments over the length limit
+const (
+	OverflowReject   = "reject"
+	OverflowTruncate = "truncate"
+)
+
 type ApprovalHandler struct {
-	queue approval.Queue
+	queue           approval.Queue
+	maxReasonLength int
+	overflow        string
+	// simulation enables Simulate; see Config.SimulationEnabled
+	simulation bool
+	random     func() float64
+	// auth scopes which tools an approver may decide; refusals are
+	// written to audit
+	auth  *auth.Manager
+	audit audit.Store
+	// redactor masks secrets in the arguments of requests it returns
+	redactor *redaction.Redactor
 }
 
-func NewApprovalHandler(queue approval.Queue) *ApprovalHandler {
-	return &ApprovalHandler{queue: queue}
+// NewApprovalHandler rejects over-limit text unless overflow is
+// OverflowTruncate
+func NewApprovalHandler(queue approval.Queue, maxReasonLength int, overflow string) *ApprovalHandler {
+	return &ApprovalHandler{
+		queue:           queue,
+		maxReasonLength: maxReasonLength,
+		overflow:        overflow,
+		random:          rand.Float64,
+	}
+}
+
+// boundText applies the overflow mode to text over the length limit. The
+// truncated value is what the queue and the audit log store.
+func (h *ApprovalHandler) boundText(field, text string) (string, error) {
+	if h.maxReasonLength <= 0 || utf8.RuneCountInString(text) <= h.maxReasonLength {
+		return text, nil
+	}
+	if h.overflow == OverflowTruncate {
+		return proxy.TruncateReason(text, h.maxReasonLength), nil
+	}
+	return "", fmt.Errorf("%s exceeds maximum length of %d characters", field, h.maxReasonLength)
 }
 
 func (h *ApprovalHandler) GetPending(c echo.Context) error {
 	ctx := c.Request().Context()
 
+	page, err := parsePagination(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
 	pending, err := h.queue.GetPending(ctx)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to get pending approvals")
@@ -27,10 +79,7 @@ func (h *ApprovalHandler) GetPending(c echo.Context) error {
 		})
 	}
 
-	return c.JSON(http.StatusOK, map[string]interface{}{
-		"total":   len(pending),
-		"pending": pending,
-	})
+	return jsonWithETag(c, paginate(redactRequests(h.redactor, pending), page, "pending"))
 }
 
 func (h *ApprovalHandler) Decide(c echo.Context) error {
@@ -55,10 +104,21 @@ func (h *ApprovalHandler) Decide(c echo.Context) error {
 		})
 	}
 
+	reason, err := h.boundText("reason", req.Reason)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	if refused, err := h.checkApproverScope(c, id); refused {
+		return err
+	}
+
 	decision := approval.Decision{
 		Approved:  req.Approved,
-		Reason:    req.Reason,
-		DecidedBy: req.DecidedBy,
+		Reason:    reason,
+		DecidedBy: decider(c, req.DecidedBy),
 	}
 
 	if err := h.queue.Decide(ctx, id, decision); err != nil {
@@ -73,4 +133,149 @@ func (h *ApprovalHandler) Decide(c echo.Context) error {
 		"id":      id,
 		"decision": decision,
 	})
-}
\ No newline at end of file
+}
+
+func (h *ApprovalHandler) AddComment(c echo.Context) error {
+	ctx := c.Request().Context()
+	id := c.Param("id")
+
+	commenter, ok := h.queue.(approval.Commenter)
+	if !ok {
+		return c.JSON(http.StatusNotImplemented, map[string]string{
+			"error": "approval queue does not support comments",
+		})
+	}
+
+	var req struct {
+		Text   string `json:"text"`
+		Author string `json:"author,omitempty"`
+	}
+
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid request body",
+		})
+	}
+
+	if strings.TrimSpace(req.Text) == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "text is required",
+		})
+	}
+
+	text, err := h.boundText("text", req.Text)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	comment := approval.Comment{
+		Author: commentAuthor(c, req.Author),
+		Text:   text,
+	}
+
+	updated, err := commenter.Comment(ctx, id, comment)
+	if err != nil {
+		log.Error().Err(err).Str("id", id).Msg("failed to add approval comment")
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "approval request not found",
+		})
+	}
+
+	return c.JSON(http.StatusOK, redactRequest(h.redactor, updated))
+}
+
+// GetDeadLetters lists approval notifications that exhausted their retries
+func (h *ApprovalHandler) GetDeadLetters(c echo.Context) error {
+	reporter, ok := h.queue.(approval.DeadLetterReporter)
+	if !ok {
+		return c.JSON(http.StatusNotImplemented, map[string]string{
+			"error": "approval queue does not record dead letters",
+		})
+	}
+
+	dead := reporter.DeadLetters()
+	if dead == nil {
+		dead = []approval.DeadLetter{}
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"dead_letters": dead,
+	})
+}
+
+// GetEvents returns a request's lifecycle history, optionally limited to
+// an RFC 3339 since/until window.
+func (h *ApprovalHandler) GetEvents(c echo.Context) error {
+	ctx := c.Request().Context()
+	id := c.Param("id")
+
+	provider, ok := h.queue.(approval.HistoryProvider)
+	if !ok {
+		return c.JSON(http.StatusNotImplemented, map[string]string{
+			"error": "approval queue does not keep event history",
+		})
+	}
+
+	since, err := parseTimeParam(c, "since")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+	until, err := parseTimeParam(c, "until")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	events, err := provider.History(ctx, id, since, until)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "approval request not found",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"id":     id,
+		"events": events,
+	})
+}
+
+func parseTimeParam(c echo.Context, name string) (time.Time, error) {
+	value := c.QueryParam(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s must be an RFC 3339 timestamp", name)
+	}
+	return t, nil
+}
+
+// commentAuthor prefers the authenticated identity over a client-supplied name
+// decider names who made a decision: the signed-in user when there is one,
+// whatever decided_by says, and otherwise the claimed name
+func decider(c echo.Context, claimed string) string {
+	if auth.GetUserFromContext(c) != nil {
+		return commentAuthor(c, "")
+	}
+	return claimed
+}
+
+func commentAuthor(c echo.Context, fallback string) string {
+	if user := auth.GetUserFromContext(c); user != nil {
+		if user.Email != "" {
+			return user.Email
+		}
+		return user.ID
+	}
+
+	if fallback != "" {
+		return fallback
+	}
+	return "anonymous"
+}
@@ -0,0 +1,344 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/approval"
+	"github.com/dagbolade/ai-governance-sidecar/internal/auth"
+	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
+	"github.com/dagbolade/ai-governance-sidecar/internal/proxy"
+	"github.com/labstack/echo/v4"
+)
+
+func TestDecideReasonLength(t *testing.T) {
+	const maxLength = 20
+
+	tests := []struct {
+		name           string
+		reason         string
+		expectedStatus int
+	}{
+		{"at limit", strings.Repeat("a", maxLength), http.StatusOK},
+		{"multibyte at limit", strings.Repeat("é", maxLength), http.StatusOK},
+		{"over limit", strings.Repeat("a", maxLength+1), http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewApprovalHandler(&mockApprovalQueue{}, maxLength, OverflowReject)
+
+			e := echo.New()
+			body := fmt.Sprintf(`{"approved":true,"reason":%q}`, tt.reason)
+			req := httptest.NewRequest(http.MethodPost, "/approve/abc", strings.NewReader(body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues("abc")
+
+			if err := handler.Decide(c); err != nil {
+				t.Fatalf("handler failed: %v", err)
+			}
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, rec.Code)
+			}
+		})
+	}
+}
+
+// decidingQueue records the decision passed to Decide
+type decidingQueue struct {
+	mockApprovalQueue
+	decision approval.Decision
+}
+
+func (q *decidingQueue) Decide(ctx context.Context, id string, decision approval.Decision) error {
+	q.decision = decision
+	return nil
+}
+
+func TestDecideReasonOverflow(t *testing.T) {
+	const maxLength = 20
+
+	tests := []struct {
+		name           string
+		overflow       string
+		reason         string
+		expectedStatus int
+		expectedReason string
+	}{
+		{"reject at limit", OverflowReject, strings.Repeat("a", maxLength), http.StatusOK, strings.Repeat("a", maxLength)},
+		{"reject over limit", OverflowReject, strings.Repeat("a", maxLength+1), http.StatusBadRequest, ""},
+		{"truncate at limit", OverflowTruncate, strings.Repeat("a", maxLength), http.StatusOK, strings.Repeat("a", maxLength)},
+		{"truncate over limit", OverflowTruncate, strings.Repeat("a", maxLength+10), http.StatusOK, "aaaaaa" + proxy.TruncatedMarker},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queue := &decidingQueue{}
+			handler := NewApprovalHandler(queue, maxLength, tt.overflow)
+
+			e := echo.New()
+			body := fmt.Sprintf(`{"approved":true,"reason":%q}`, tt.reason)
+			req := httptest.NewRequest(http.MethodPost, "/approve/abc", strings.NewReader(body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues("abc")
+
+			if err := handler.Decide(c); err != nil {
+				t.Fatalf("handler failed: %v", err)
+			}
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, rec.Code)
+			}
+			if queue.decision.Reason != tt.expectedReason {
+				t.Errorf("expected stored reason %q, got %q", tt.expectedReason, queue.decision.Reason)
+			}
+			if n := len([]rune(queue.decision.Reason)); n > maxLength {
+				t.Errorf("stored reason has %d characters, limit is %d", n, maxLength)
+			}
+		})
+	}
+}
+
+func TestAddCommentAppearsInPending(t *testing.T) {
+	queue := approval.NewInMemoryQueue(5 * time.Second)
+	defer queue.Close()
+
+	authManager := auth.NewManager(auth.Config{RequireAuth: false, JWTSecret: "test-secret"})
+	srv := New(Config{Port: 8080}, &mockPolicyEvaluator{}, &mockAuditStore{}, queue, authManager)
+
+	go queue.Enqueue(context.Background(), policy.Request{ToolName: "deploy", Args: json.RawMessage(`{}`)}, "review")
+
+	var pending []approval.Request
+	for i := 0; i < 50 && len(pending) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+		pending, _ = queue.GetPending(context.Background())
+	}
+	if len(pending) != 1 {
+		t.Fatalf("expected 1 pending request, got %d", len(pending))
+	}
+	id := pending[0].ID
+
+	for _, body := range []string{`{"text":"checking with owner","author":"alice"}`, `{"text":"owner confirmed"}`} {
+		req := httptest.NewRequest(http.MethodPost, "/approvals/"+id+"/comment", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		srv.echo.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/pending", nil)
+	rec := httptest.NewRecorder()
+	srv.echo.ServeHTTP(rec, req)
+
+	var response struct {
+		Pending []approval.Request `json:"pending"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+
+	if len(response.Pending) != 1 {
+		t.Fatalf("expected request to remain pending, got %d", len(response.Pending))
+	}
+	comments := response.Pending[0].Comments
+	if len(comments) != 2 {
+		t.Fatalf("expected 2 comments, got %+v", comments)
+	}
+	if comments[0].Author != "alice" || comments[1].Author != "anonymous" {
+		t.Errorf("unexpected comment authors: %+v", comments)
+	}
+}
+
+func TestDecideWithoutAuth(t *testing.T) {
+	queue := approval.NewInMemoryQueue(5 * time.Second)
+	defer queue.Close()
+
+	authManager := auth.NewManager(auth.Config{RequireAuth: false, JWTSecret: "test-secret"})
+	srv := New(Config{Port: 8080}, &mockPolicyEvaluator{}, &mockAuditStore{}, queue, authManager)
+
+	decided := make(chan approval.Decision, 1)
+	go func() {
+		d, _ := queue.Enqueue(context.Background(), policy.Request{ToolName: "deploy", Args: json.RawMessage(`{}`)}, "review")
+		decided <- d
+	}()
+
+	var pending []approval.Request
+	for i := 0; i < 50 && len(pending) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+		pending, _ = queue.GetPending(context.Background())
+	}
+	if len(pending) != 1 {
+		t.Fatalf("expected 1 pending request, got %d", len(pending))
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/approve/"+pending[0].ID, strings.NewReader(`{"approved":true,"reason":"ok","decided_by":"bob"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	srv.echo.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	select {
+	case d := <-decided:
+		if !d.Approved || d.DecidedBy != "bob" {
+			t.Errorf("expected approval by bob, got %+v", d)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("request was not decided")
+	}
+}
+
+func TestDecideRecordsSignedInUser(t *testing.T) {
+	authManager := auth.NewManager(auth.Config{RequireAuth: true, JWTSecret: "test-secret"})
+	srv := New(Config{Port: 8080}, &mockPolicyEvaluator{}, &mockAuditStore{}, &mockApprovalQueue{}, authManager)
+	token, _ := authManager.GenerateToken(auth.User{ID: "carol", Email: "carol@example.com", Roles: []string{auth.RoleApprover}})
+
+	req := httptest.NewRequest(http.MethodPost, "/approve/req-1", strings.NewReader(`{"approved":true,"reason":"ok","decided_by":"mallory"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+	rec := httptest.NewRecorder()
+	srv.echo.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var response struct {
+		Decision approval.Decision `json:"decision"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if response.Decision.DecidedBy != "carol@example.com" {
+		t.Errorf("expected the signed-in user as decider, got %q", response.Decision.DecidedBy)
+	}
+}
+
+func TestAddCommentValidation(t *testing.T) {
+	handler := NewApprovalHandler(approval.NewInMemoryQueue(time.Second), 10, OverflowReject)
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+	}{
+		{"empty text", `{"text":"  "}`, http.StatusBadRequest},
+		{"too long", `{"text":"this is far too long"}`, http.StatusBadRequest},
+		{"unknown request", `{"text":"hello"}`, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/approvals/missing/comment", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues("missing")
+
+			if err := handler.AddComment(c); err != nil {
+				t.Fatalf("handler failed: %v", err)
+			}
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, rec.Code)
+			}
+		})
+	}
+}
+
+// scopedQueue holds one pending request per tool
+type scopedQueue struct {
+	mockApprovalQueue
+	pending []approval.Request
+	decided []string
+}
+
+func (q *scopedQueue) GetPending(ctx context.Context) ([]approval.Request, error) {
+	return q.pending, nil
+}
+
+func (q *scopedQueue) Decide(ctx context.Context, id string, decision approval.Decision) error {
+	q.decided = append(q.decided, id)
+	return nil
+}
+
+func TestDecideApproverScopes(t *testing.T) {
+	authManager := auth.NewManager(auth.Config{
+		RequireAuth:    true,
+		JWTSecret:      "test-secret",
+		ApproverScopes: map[string][]string{"db_admin": {"db.*"}},
+	})
+	store := &mockAuditStore{}
+	queue := &scopedQueue{pending: []approval.Request{
+		{ID: "req-db", ToolName: "db.query", Status: approval.StatusPending},
+		{ID: "req-fs", ToolName: "fs.write", Status: approval.StatusPending},
+	}}
+	srv := New(Config{Port: 8080}, &mockPolicyEvaluator{}, store, queue, authManager)
+
+	dbToken, _ := authManager.GenerateToken(auth.User{ID: "carol", Roles: []string{auth.RoleApprover, "db_admin"}})
+	approverToken, _ := authManager.GenerateToken(auth.User{ID: "dave", Roles: []string{auth.RoleApprover}})
+	adminToken, _ := authManager.GenerateToken(auth.User{ID: "alice", Roles: []string{auth.RoleAdmin}})
+
+	decide := func(id, token string) int {
+		req := httptest.NewRequest(http.MethodPost, "/approve/"+id, strings.NewReader(`{"approved":true,"reason":"looks fine"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+		rec := httptest.NewRecorder()
+		srv.echo.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	tests := []struct {
+		name   string
+		id     string
+		token  string
+		expect int
+	}{
+		{"db approver on db tool", "req-db", dbToken, http.StatusOK},
+		{"db approver on fs tool", "req-fs", dbToken, http.StatusForbidden},
+		{"unscoped approver", "req-db", approverToken, http.StatusForbidden},
+		{"admin on fs tool", "req-fs", adminToken, http.StatusOK},
+		{"unknown request", "req-missing", dbToken, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := decide(tt.id, tt.token); code != tt.expect {
+				t.Errorf("expected status %d, got %d", tt.expect, code)
+			}
+		})
+	}
+
+	if got := strings.Join(queue.decided, ","); got != "req-db,req-fs,req-missing" {
+		t.Errorf("expected refused decisions never to reach the queue, got %s", got)
+	}
+
+	if len(store.entries) != 2 {
+		t.Fatalf("expected both refusals to be audited, got %d entries", len(store.entries))
+	}
+	var event approverScopeEvent
+	if err := json.Unmarshal(store.entries[0].ToolInput, &event); err != nil {
+		t.Fatalf("failed to parse audit entry: %v", err)
+	}
+	if event.Event != "approval_out_of_scope" || event.Actor != "carol" || event.ToolName != "fs.write" || store.entries[0].Decision != "deny" {
+		t.Errorf("unexpected audit entry %+v (%s)", event, store.entries[0].Decision)
+	}
+}
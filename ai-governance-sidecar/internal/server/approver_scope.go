@@ -0,0 +1,75 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/audit"
+	"github.com/dagbolade/ai-governance-sidecar/internal/auth"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// approverScopeEvent is the tool_input recorded when an approver is
+// refused a request outside their authority
+type approverScopeEvent struct {
+	Event      string `json:"event"`
+	ApprovalID string `json:"approval_id"`
+	ToolName   string `json:"tool_name"`
+	Actor      string `json:"actor,omitempty"`
+}
+
+// checkApproverScope refuses a caller deciding a request for a tool their
+// roles may not approve. When refused is true the response has been
+// written. Unknown ids pass so Decide reports them as not found.
+func (h *ApprovalHandler) checkApproverScope(c echo.Context, id string) (refused bool, err error) {
+	if h.auth == nil {
+		return false, nil
+	}
+
+	pending, err := h.queue.GetPending(c.Request().Context())
+	if err != nil {
+		log.Error().Err(err).Str("id", id).Msg("failed to get pending approvals")
+		return true, c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "failed to retrieve pending approvals",
+		})
+	}
+
+	user := auth.GetUserFromContext(c)
+	for _, req := range pending {
+		if req.ID != id || h.auth.MayApprove(user, req.ToolName) {
+			continue
+		}
+
+		actor := actorID(c)
+		log.Warn().Str("id", id).Str("tool", req.ToolName).Str("user", actor).Msg("approver outside their tool scope")
+		h.recordScopeRefusal(c, approverScopeEvent{
+			Event:      "approval_out_of_scope",
+			ApprovalID: id,
+			ToolName:   req.ToolName,
+			Actor:      actor,
+		})
+		return true, c.JSON(http.StatusForbidden, map[string]string{
+			"error": fmt.Sprintf("not authorized to approve tool %q", req.ToolName),
+		})
+	}
+	return false, nil
+}
+
+// recordScopeRefusal audits a refused decision. A failed write is logged
+// and the refusal still stands.
+func (h *ApprovalHandler) recordScopeRefusal(c echo.Context, event approverScopeEvent) {
+	if h.audit == nil {
+		return
+	}
+
+	input, err := json.Marshal(event)
+	if err == nil {
+		err = h.audit.Log(c.Request().Context(), input, audit.DecisionDeny,
+			fmt.Sprintf("%s may not approve %s", event.Actor, event.ToolName))
+	}
+	if err != nil {
+		log.Warn().Err(err).Str("event", event.Event).Msg("audit logging failed")
+	}
+}
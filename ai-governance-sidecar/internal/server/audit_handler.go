@@ -1,7 +1,14 @@
 package server
 
 import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
 	"net/http"
+	"sort"
+	"strconv"
+	"time"
 
 	"github.com/dagbolade/ai-governance-sidecar/internal/audit"
 	"github.com/labstack/echo/v4"
@@ -9,7 +16,10 @@ import (
 )
 
 type AuditHandler struct {
-	store audit.Store
+	store      audit.Store
+	signingKey ed25519.PrivateKey
+	// observer wakes /audit/stream clients when entries are written
+	observer *audit.Observer
 }
 
 func NewAuditHandler(store audit.Store) *AuditHandler {
@@ -19,6 +29,20 @@ func NewAuditHandler(store audit.Store) *AuditHandler {
 func (h *AuditHandler) GetAuditLog(c echo.Context) error {
 	ctx := c.Request().Context()
 
+	page, err := parsePagination(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	before, err := parseBefore(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
 	entries, err := h.store.GetAll(ctx)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to retrieve audit log")
@@ -27,8 +51,110 @@ func (h *AuditHandler) GetAuditLog(c echo.Context) error {
 		})
 	}
 
-	return c.JSON(http.StatusOK, map[string]interface{}{
-		"total":   len(entries),
-		"entries": entries,
+	envelope := paginate(entriesBefore(entries, before), page, "entries")
+	if envelope.NextOffset != nil {
+		if last := envelope.Items[len(envelope.Items)-1]; last.ID > 0 {
+			envelope.Extra = map[string]any{"next_before": last.ID}
+		}
+	}
+	return jsonWithETag(c, envelope)
+}
+
+// parseBefore reads the ?before= entry id cursor; zero means none
+func parseBefore(c echo.Context) (int64, error) {
+	raw := c.QueryParam("before")
+	if raw == "" {
+		return 0, nil
+	}
+
+	before, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || before <= 0 {
+		return 0, fmt.Errorf("before must be a positive entry id")
+	}
+	return before, nil
+}
+
+// entriesBefore orders entries newest first by id and, with a cursor, keeps
+// those logged before it. Ids only grow, so a page read by cursor is not
+// shifted by entries written in the meantime. Entries still buffered by a
+// degraded store have no id yet; they are newest, and have no place in a
+// cursor page.
+func entriesBefore(entries []audit.Entry, before int64) []audit.Entry {
+	sorted := make([]audit.Entry, 0, len(entries))
+	for _, entry := range entries {
+		if before == 0 || (entry.ID > 0 && entry.ID < before) {
+			sorted = append(sorted, entry)
+		}
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i].ID, sorted[j].ID
+		if a == 0 || b == 0 {
+			return a == 0 && b != 0
+		}
+		return a > b
+	})
+	return sorted
+}
+
+// GetBundle downloads every audit entry with the hash-chain head, signed
+// so it can be verified offline against the published public key.
+func (h *AuditHandler) GetBundle(c echo.Context) error {
+	if h.signingKey == nil {
+		return c.JSON(http.StatusServiceUnavailable, map[string]string{
+			"error": "audit signing key unavailable",
+		})
+	}
+
+	entries, err := h.store.GetAll(c.Request().Context())
+	if err != nil {
+		log.Error().Err(err).Msg("failed to retrieve audit log")
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "failed to retrieve audit log",
+		})
+	}
+
+	now := time.Now()
+	bundle, err := audit.NewBundle(entries, h.signingKey, now)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to build audit bundle")
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "failed to build audit bundle",
+		})
+	}
+
+	filename := fmt.Sprintf("audit-bundle-%s.json", now.UTC().Format("20060102T150405Z"))
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	c.Response().Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	c.Response().WriteHeader(http.StatusOK)
+	return json.NewEncoder(c.Response()).Encode(bundle)
+}
+
+// GetPublicKey publishes the key that audit bundles are verified against
+func (h *AuditHandler) GetPublicKey(c echo.Context) error {
+	if h.signingKey == nil {
+		return c.JSON(http.StatusServiceUnavailable, map[string]string{
+			"error": "audit signing key unavailable",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"algorithm":  "ed25519",
+		"public_key": base64.StdEncoding.EncodeToString(h.signingKey.Public().(ed25519.PublicKey)),
 	})
-}
\ No newline at end of file
+}
+
+// loadAuditSigningKey returns nil if a configured key cannot be read;
+// startup validation should already have rejected it.
+func loadAuditSigningKey(path string) ed25519.PrivateKey {
+	key, err := audit.LoadSigningKey(path)
+	if err != nil {
+		log.Error().Err(err).Msg("audit signing key unavailable, bundles disabled")
+		return nil
+	}
+
+	if path == "" {
+		log.Warn().Msg("AUDIT_SIGNING_KEY_FILE not set, audit bundles are signed with a per-process key")
+	}
+	return key
+}
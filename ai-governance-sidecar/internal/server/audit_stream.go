@@ -0,0 +1,130 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/audit"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// auditStreamBacklog is how many recent entries a new stream starts with
+	// when the client sends no cursor
+	auditStreamBacklog = 50
+	auditStreamPing    = 15 * time.Second
+)
+
+// Stream sends audit entries as server-sent events: recent entries first,
+// then each new entry as it is written. Every event carries the entry ID, so
+// a client reconnecting with ?from=<id> or Last-Event-ID resumes after it.
+func (h *AuditHandler) Stream(c echo.Context) error {
+	if h.observer == nil {
+		return c.JSON(http.StatusNotImplemented, map[string]string{
+			"error": "audit streaming is not supported",
+		})
+	}
+
+	cursor, resume, err := streamCursor(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	// Subscribe before the first read so a write in between is not missed
+	wake, cancel := h.observer.Subscribe()
+	defer cancel()
+
+	ctx := c.Request().Context()
+	limit := 0
+	if !resume {
+		limit = auditStreamBacklog
+	}
+	backlog, err := audit.EntriesAfter(ctx, h.store, cursor, limit)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to retrieve audit log")
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "failed to retrieve audit log",
+		})
+	}
+
+	// The stream outlives the server's write timeout
+	if err := http.NewResponseController(c.Response()).SetWriteDeadline(time.Time{}); err != nil {
+		log.Debug().Err(err).Msg("could not clear write deadline for audit stream")
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set(echo.HeaderConnection, "keep-alive")
+	res.WriteHeader(http.StatusOK)
+
+	if cursor, err = writeAuditEvents(res, backlog, cursor); err != nil {
+		return nil
+	}
+	res.Flush()
+
+	ping := time.NewTicker(auditStreamPing)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ping.C:
+			if _, err := fmt.Fprint(res, ": ping\n\n"); err != nil {
+				return nil
+			}
+			res.Flush()
+		case <-wake:
+			entries, err := audit.EntriesAfter(ctx, h.store, cursor, 0)
+			if err != nil {
+				log.Warn().Err(err).Msg("audit stream failed to read new entries")
+				continue
+			}
+			if cursor, err = writeAuditEvents(res, entries, cursor); err != nil {
+				return nil
+			}
+			res.Flush()
+		}
+	}
+}
+
+// streamCursor reads the resume point from ?from or Last-Event-ID. resume
+// is false when neither was sent.
+func streamCursor(c echo.Context) (int64, bool, error) {
+	raw := c.QueryParam("from")
+	if raw == "" {
+		raw = c.Request().Header.Get("Last-Event-ID")
+	}
+	if raw == "" {
+		return 0, false, nil
+	}
+
+	cursor, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || cursor < 0 {
+		return 0, false, fmt.Errorf("from must be a non-negative entry id")
+	}
+	return cursor, true, nil
+}
+
+func writeAuditEvents(res *echo.Response, entries []audit.Entry, cursor int64) (int64, error) {
+	for _, entry := range entries {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			log.Warn().Err(err).Int64("id", entry.ID).Msg("failed to encode audit entry for stream")
+			cursor = entry.ID
+			continue
+		}
+		if _, err := fmt.Fprintf(res, "id: %d\ndata: %s\n\n", entry.ID, data); err != nil {
+			return cursor, err
+		}
+		cursor = entry.ID
+	}
+	return cursor, nil
+}
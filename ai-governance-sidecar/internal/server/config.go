@@ -1,23 +1,147 @@
 package server
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/dagbolade/ai-governance-sidecar/internal/approval"
+	"github.com/dagbolade/ai-governance-sidecar/internal/auth"
+	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
 	"github.com/dagbolade/ai-governance-sidecar/internal/proxy"
+	"github.com/dagbolade/ai-governance-sidecar/internal/redaction"
+	"github.com/rs/zerolog/log"
 )
 
+// LoadConfig reads the configuration from the environment. Settings that
+// cannot be parsed are reported by EnvError, so startup can refuse them
+// rather than run without the protection they configure.
 func LoadConfig() Config {
-	return Config{
-		Port:            getEnvInt("PORT", 8080),
-		ReadTimeout:     getEnvInt("READ_TIMEOUT", 30),
-		WriteTimeout:    getEnvInt("WRITE_TIMEOUT", 30),
-		ShutdownTimeout: getEnvInt("SHUTDOWN_TIMEOUT", 10),
+	var errs []error
+	cfg := Config{
+		Port:                   getEnvInt("PORT", 8080),
+		ReadTimeout:            getEnvInt("READ_TIMEOUT", 30),
+		WriteTimeout:           getEnvInt("WRITE_TIMEOUT", 30),
+		ReadHeaderTimeout:      getEnvInt("READ_HEADER_TIMEOUT", 10),
+		IdleTimeout:            getEnvInt("IDLE_TIMEOUT", 120),
+		ShutdownTimeout:        getEnvInt("SHUTDOWN_TIMEOUT", 10),
+		DBPath:                 getEnv("DB_PATH", "./db/audit.db"),
+		AuditRequired:          getEnv("AUDIT_REQUIRED", "true") != "false",
+		AuditSigningKeyFile:    os.Getenv("AUDIT_SIGNING_KEY_FILE"),
+		AuditSignEntries:       getEnv("AUDIT_SIGN_ENTRIES", "false") == "true",
+		AuditMaxToolInput:      getEnvInt("AUDIT_MAX_TOOL_INPUT_BYTES", 1<<20),
+		ApprovalTimeout:        getEnvInt("APPROVAL_TIMEOUT", 300),
+		ApprovalSLA:            getEnvInt("APPROVAL_SLA", 0),
+		ApprovalMaxPendingAge:  getEnvInt("APPROVAL_MAX_PENDING_AGE", 0),
+		ApprovalWebhookURL:     os.Getenv("APPROVAL_WEBHOOK_URL"),
+		ApprovalWebhookTimeout: getEnvInt("APPROVAL_WEBHOOK_TIMEOUT", 5),
+		ApprovalNotifyURL:      os.Getenv("APPROVAL_NOTIFY_URL"),
+		ApprovalNotifyAttempts: getEnvInt("APPROVAL_NOTIFY_ATTEMPTS", 5),
+		ApprovalNotifyBackoff:  getEnvInt("APPROVAL_NOTIFY_BACKOFF", 1),
+		ApprovalNotifyMode:     loadApprovalNotifyMode(),
+		ApprovalDigestInterval: getEnvInt("APPROVAL_NOTIFY_DIGEST_INTERVAL", 300),
+		MaxReasonLength:        getEnvInt("MAX_REASON_LENGTH", 1000),
+		ApprovalOverflow:       loadApprovalOverflow(),
+		ApprovalDedup:          getEnv("APPROVAL_DEDUP", "false") == "true",
+		CORSOrigins:            getEnvList("CORS_ORIGINS", []string{"*"}),
+		UICORSOrigins:          getEnvList("UI_CORS_ORIGINS", []string{"*"}),
+		CORSMaxAge:             getEnvInt("CORS_MAX_AGE", 600),
+		SecurityHeaders:        getEnv("SECURITY_HEADERS", "true") != "false",
+		HSTSMaxAge:             getEnvInt("HSTS_MAX_AGE", 31536000),
+		UICSP:                  os.Getenv("UI_CSP"),
+		WSCompression:          getEnv("WS_COMPRESSION", "false") == "true",
+		WSMaxConnections:       getEnvInt("WS_MAX_CONNECTIONS", 1000),
+		SimulationEnabled:      getEnv("SIMULATION_ENABLED", "false") == "true",
+		ApprovalRedactKeys:     loadApprovalRedactKeys(),
+
+		TLSCertFile:     os.Getenv("TLS_CERT_FILE"),
+		TLSKeyFile:      os.Getenv("TLS_KEY_FILE"),
+		TLSClientCAFile: os.Getenv("TLS_CLIENT_CA_FILE"),
+		TLSClientAuth:   getEnv("TLS_CLIENT_AUTH", ClientAuthRequire),
+
 		ProxyConfig: proxy.ProxyConfig{
-			DefaultUpstream: getEnv("TOOL_UPSTREAM", "http://localhost:9000"),
-			Timeout:         getEnvInt("UPSTREAM_TIMEOUT", 30),
+			DefaultUpstream:            getEnv("TOOL_UPSTREAM", "http://localhost:9000"),
+			Timeout:                    getEnvInt("UPSTREAM_TIMEOUT", 30),
+			MaxTimeout:                 getEnvInt("UPSTREAM_MAX_TIMEOUT", 0),
+			MaxReasonLength:            getEnvInt("MAX_REASON_LENGTH", 1000),
+			MaxArgsBytes:               getEnvInt("MAX_ARGS_BYTES", 1<<20),
+			MaxArgsDepth:               getEnvInt("MAX_ARGS_DEPTH", 64),
+			ApprovalTimeoutMessage:     os.Getenv("APPROVAL_TIMEOUT_MESSAGE"),
+			ApprovalGrantTTL:           getEnvInt("APPROVAL_GRANT_TTL", 0),
+			ApprovalHeartbeat:          getEnvInt("APPROVAL_HEARTBEAT_INTERVAL", 0),
+			PolicyHeaders:              getEnvList("POLICY_HEADERS", nil),
+			RequiredMetadata:           getEnvList("REQUIRED_METADATA", nil),
+			JustificationTools:         getEnvList("JUSTIFICATION_TOOLS", nil),
+			AutoApprove:                loadAutoApprove(),
+			SensitivePatterns:          collect(&errs, loadSensitivePatterns),
+			ToolNamePatterns:           collect(&errs, loadToolNamePatterns),
+			ToolNameNormalization:      getEnvList("TOOL_NAME_NORMALIZATION", nil),
+			ToolGroups:                 collect(&errs, loadToolGroups),
+			ResponseFormat:             getEnv("RESPONSE_FORMAT", proxy.ResponseFormatWrapped),
+			TimeWindows:                collect(&errs, loadTimeWindows),
+			TimeWindowZone:             os.Getenv("TIME_WINDOW_TZ"),
+			UpstreamCAFile:             os.Getenv("UPSTREAM_CA_FILE"),
+			UpstreamInsecureSkipVerify: getEnv("UPSTREAM_INSECURE_SKIP_VERIFY", "false") == "true",
+			UpstreamProxyURL:           os.Getenv("UPSTREAM_PROXY_URL"),
+			UpstreamAllowlist:          getEnvList("UPSTREAM_ALLOWLIST", nil),
+			AuditDetail:                getEnv("AUDIT_DETAIL", proxy.AuditDetailLean),
+			AuditSampling:              collect(&errs, loadAuditSampling),
+			UserQuotas:                 collect(&errs, loadUserQuotas),
+			ToolsCatalogURL:            os.Getenv("TOOLS_CATALOG_URL"),
+			GRPCRoutes:                 collect(&errs, loadGRPCRoutes),
+			UpstreamRoutes:             collect(&errs, loadUpstreamRoutes),
+			UpstreamHealthPath:         getEnv("UPSTREAM_HEALTH_PATH", "/health"),
+			UpstreamHealthInterval:     getEnvInt("UPSTREAM_HEALTH_INTERVAL", 10),
+		},
+		PolicyConfig: policy.Config{
+			Dir:          getEnv("POLICY_DIR", "./policies"),
+			ToolPolicies: collect(&errs, loadToolPolicies),
+			MaxPolicies:  getEnvInt("MAX_POLICIES", 0),
+			Warmup:       getEnv("POLICY_WARMUP", "false") == "true",
+			Shadow:       loadShadowPolicies(),
+			ExpectedHash: os.Getenv("EXPECTED_POLICY_HASH"),
+			CounterDB:    loadCounterDB(),
+
+			MetadataFields: collect(&errs, loadMetadataFields),
+			Egress:         getEnvList("EGRESS_POLICIES", nil),
+
+			BundleURL:          os.Getenv("POLICY_BUNDLE_URL"),
+			BundlePublicKey:    os.Getenv("POLICY_BUNDLE_PUBLIC_KEY"),
+			BundlePollInterval: getEnvInt("POLICY_BUNDLE_POLL_INTERVAL", 60),
+			BundleTimeout:      getEnvInt("POLICY_BUNDLE_TIMEOUT", 30),
+		},
+		AuthConfig: auth.Config{
+			JWTSecret:       os.Getenv("JWT_SECRET"),
+			TokenExpiration: 24 * time.Hour,
+			RequireAuth:     getEnv("REQUIRE_AUTH", "false") == "true",
+			UsersFile:       os.Getenv("AUTH_USERS_FILE"),
+			MaxTokenAge:     time.Duration(getEnvInt("MAX_TOKEN_AGE", 0)) * time.Second,
+			ClockSkew:       time.Duration(getEnvInt("JWT_CLOCK_SKEW", 30)) * time.Second,
+			ApproverScopes:  collect(&errs, loadApproverScopes),
+
+			ClientCertRoles: collect(&errs, loadClientCertRoles),
 		},
 	}
+	cfg.envErr = errors.Join(errs...)
+	return cfg
+}
+
+// EnvError reports every setting LoadConfig could not parse, or nil
+func (c Config) EnvError() error {
+	return c.envErr
+}
+
+// collect runs load and returns its value, adding any error to errs
+func collect[T any](errs *[]error, load func() (T, error)) T {
+	value, err := load()
+	if err != nil {
+		*errs = append(*errs, err)
+	}
+	return value
 }
 
 func getEnv(key, fallback string) string {
@@ -27,6 +151,34 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
+// loadAutoApprove enables auto-approval when AUTO_APPROVE_MAX_RISK is set,
+// optionally limited to AUTO_APPROVE_WINDOW (HH:MM-HH:MM, local time).
+func loadAutoApprove() proxy.AutoApproveConfig {
+	raw := os.Getenv("AUTO_APPROVE_MAX_RISK")
+	if raw == "" {
+		return proxy.AutoApproveConfig{}
+	}
+
+	maxRisk, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		log.Warn().Err(err).Msg("invalid AUTO_APPROVE_MAX_RISK, auto-approval disabled")
+		return proxy.AutoApproveConfig{}
+	}
+
+	cfg := proxy.AutoApproveConfig{Enabled: true, MaxRisk: maxRisk}
+
+	if window := os.Getenv("AUTO_APPROVE_WINDOW"); window != "" {
+		parsed, err := proxy.ParseTimeWindow(window)
+		if err != nil {
+			log.Warn().Err(err).Msg("invalid AUTO_APPROVE_WINDOW, auto-approval disabled")
+			return proxy.AutoApproveConfig{}
+		}
+		cfg.Window = parsed
+	}
+
+	return cfg
+}
+
 func getEnvInt(key string, fallback int) int {
 	if value := os.Getenv(key); value != "" {
 		if intVal, err := strconv.Atoi(value); err == nil {
@@ -34,4 +186,270 @@ func getEnvInt(key string, fallback int) int {
 		}
 	}
 	return fallback
-}
\ No newline at end of file
+}
+
+func getEnvList(key string, fallback []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	if len(items) == 0 {
+		return fallback
+	}
+	return items
+}
+
+// loadSensitivePatterns reads SENSITIVE_PATTERNS as a JSON array of
+// {"name", "pattern", "action"} objects.
+func loadSensitivePatterns() ([]proxy.SensitivePattern, error) {
+	value := os.Getenv("SENSITIVE_PATTERNS")
+	if value == "" {
+		return nil, nil
+	}
+
+	var patterns []proxy.SensitivePattern
+	if err := json.Unmarshal([]byte(value), &patterns); err != nil {
+		return nil, fmt.Errorf("invalid SENSITIVE_PATTERNS: %w", err)
+	}
+
+	return patterns, nil
+}
+
+// loadToolNamePatterns reads TOOL_NAME_PATTERNS in the same shape as
+// SENSITIVE_PATTERNS, matched against the tool name.
+func loadToolNamePatterns() ([]proxy.SensitivePattern, error) {
+	value := os.Getenv("TOOL_NAME_PATTERNS")
+	if value == "" {
+		return nil, nil
+	}
+
+	var patterns []proxy.SensitivePattern
+	if err := json.Unmarshal([]byte(value), &patterns); err != nil {
+		return nil, fmt.Errorf("invalid TOOL_NAME_PATTERNS: %w", err)
+	}
+
+	return patterns, nil
+}
+
+// loadMetadataFields reads POLICY_METADATA_FIELDS, a JSON object of
+// metadata key to argument path, e.g. {"database":"args.connection.database"}
+func loadMetadataFields() (map[string]string, error) {
+	value := os.Getenv("POLICY_METADATA_FIELDS")
+	if value == "" {
+		return nil, nil
+	}
+
+	var fields map[string]string
+	if err := json.Unmarshal([]byte(value), &fields); err != nil {
+		return nil, fmt.Errorf("invalid POLICY_METADATA_FIELDS: %w", err)
+	}
+
+	return fields, nil
+}
+
+// loadToolGroups reads TOOL_GROUPS, a JSON object of group name to tool
+// name globs
+func loadToolGroups() (map[string][]string, error) {
+	value := os.Getenv("TOOL_GROUPS")
+	if value == "" {
+		return nil, nil
+	}
+
+	var groups map[string][]string
+	if err := json.Unmarshal([]byte(value), &groups); err != nil {
+		return nil, fmt.Errorf("invalid TOOL_GROUPS: %w", err)
+	}
+
+	return groups, nil
+}
+
+// loadApproverScopes reads APPROVER_SCOPES, a JSON object of role to the
+// tool name globs its holders may approve
+func loadApproverScopes() (map[string][]string, error) {
+	value := os.Getenv("APPROVER_SCOPES")
+	if value == "" {
+		return nil, nil
+	}
+
+	var scopes map[string][]string
+	if err := json.Unmarshal([]byte(value), &scopes); err != nil {
+		return nil, fmt.Errorf("invalid APPROVER_SCOPES: %w", err)
+	}
+
+	return scopes, nil
+}
+
+// loadClientCertRoles reads MTLS_IDENTITIES, a JSON object of client
+// certificate identity to roles
+func loadClientCertRoles() (map[string][]string, error) {
+	value := os.Getenv("MTLS_IDENTITIES")
+	if value == "" {
+		return nil, nil
+	}
+
+	var identities map[string][]string
+	if err := json.Unmarshal([]byte(value), &identities); err != nil {
+		return nil, fmt.Errorf("invalid MTLS_IDENTITIES: %w", err)
+	}
+
+	return identities, nil
+}
+
+// loadTimeWindows reads TIME_WINDOWS as a JSON array of
+// {"name", "window", "days", "tools", "action"} objects.
+func loadTimeWindows() ([]proxy.TimeWindowRule, error) {
+	value := os.Getenv("TIME_WINDOWS")
+	if value == "" {
+		return nil, nil
+	}
+
+	var windows []proxy.TimeWindowRule
+	if err := json.Unmarshal([]byte(value), &windows); err != nil {
+		return nil, fmt.Errorf("invalid TIME_WINDOWS: %w", err)
+	}
+
+	return windows, nil
+}
+
+// loadGRPCRoutes reads GRPC_ROUTES, e.g.
+// {"lookup_user":{"target":"users:50051","method":"users.v1.Users/Get","plaintext":true}}
+func loadGRPCRoutes() (map[string]proxy.GRPCRoute, error) {
+	value := os.Getenv("GRPC_ROUTES")
+	if value == "" {
+		return nil, nil
+	}
+
+	var routes map[string]proxy.GRPCRoute
+	if err := json.Unmarshal([]byte(value), &routes); err != nil {
+		return nil, fmt.Errorf("invalid GRPC_ROUTES: %w", err)
+	}
+
+	return routes, nil
+}
+
+// loadUpstreamRoutes reads UPSTREAM_ROUTES, e.g.
+// {"search":["http://search-1:9000/call","http://search-2:9000/call"]}
+func loadUpstreamRoutes() (map[string][]string, error) {
+	value := os.Getenv("UPSTREAM_ROUTES")
+	if value == "" {
+		return nil, nil
+	}
+
+	var routes map[string][]string
+	if err := json.Unmarshal([]byte(value), &routes); err != nil {
+		return nil, fmt.Errorf("invalid UPSTREAM_ROUTES: %w", err)
+	}
+
+	return routes, nil
+}
+
+// loadAuditSampling reads AUDIT_SAMPLING, a JSON object of tool name to N
+// where 1 in N allowed calls is audited
+func loadAuditSampling() (map[string]int, error) {
+	value := os.Getenv("AUDIT_SAMPLING")
+	if value == "" {
+		return nil, nil
+	}
+
+	var rates map[string]int
+	if err := json.Unmarshal([]byte(value), &rates); err != nil {
+		return nil, fmt.Errorf("invalid AUDIT_SAMPLING: %w", err)
+	}
+
+	return rates, nil
+}
+
+// loadUserQuotas reads USER_QUOTAS, a JSON object of user id to
+// {"concurrent": N, "daily": N}; "*" sets the default
+func loadUserQuotas() (map[string]proxy.UserQuota, error) {
+	value := os.Getenv("USER_QUOTAS")
+	if value == "" {
+		return nil, nil
+	}
+
+	var quotas map[string]proxy.UserQuota
+	if err := json.Unmarshal([]byte(value), &quotas); err != nil {
+		return nil, fmt.Errorf("invalid USER_QUOTAS: %w", err)
+	}
+
+	return quotas, nil
+}
+
+// loadApprovalOverflow reads APPROVAL_OVERFLOW, reject or truncate
+func loadApprovalOverflow() string {
+	value := getEnv("APPROVAL_OVERFLOW", OverflowReject)
+	switch value {
+	case OverflowReject, OverflowTruncate:
+		return value
+	}
+	log.Warn().Str("value", value).Msg("invalid APPROVAL_OVERFLOW, rejecting over-limit reasons")
+	return OverflowReject
+}
+
+// loadCounterDB reads POLICY_COUNTER_DB, the SQLite file backing policy
+// counters; "none" keeps counts in memory
+func loadCounterDB() string {
+	if strings.TrimSpace(os.Getenv("POLICY_COUNTER_DB")) == "none" {
+		return ""
+	}
+	return getEnv("POLICY_COUNTER_DB", "./db/policy_counters.db")
+}
+
+// loadApprovalRedactKeys reads APPROVAL_REDACT_KEYS, a comma-separated
+// list of argument key fragments to mask for approvers; "none" shows
+// arguments unmasked
+func loadApprovalRedactKeys() []string {
+	if strings.TrimSpace(os.Getenv("APPROVAL_REDACT_KEYS")) == "none" {
+		return nil
+	}
+	return getEnvList("APPROVAL_REDACT_KEYS", redaction.DefaultKeys)
+}
+
+// loadApprovalNotifyMode reads APPROVAL_NOTIFY_MODE, event or digest
+func loadApprovalNotifyMode() string {
+	value := getEnv("APPROVAL_NOTIFY_MODE", approval.NotifyModeEvent)
+	switch value {
+	case approval.NotifyModeEvent, approval.NotifyModeDigest:
+		return value
+	}
+	log.Warn().Str("value", value).Msg("invalid APPROVAL_NOTIFY_MODE, notifying per request")
+	return approval.NotifyModeEvent
+}
+
+// loadShadowPolicies reads POLICY_SHADOW_MODE: "true" shadows every policy,
+// otherwise a comma-separated list of policy names is shadowed
+func loadShadowPolicies() []string {
+	value := strings.TrimSpace(os.Getenv("POLICY_SHADOW_MODE"))
+	switch value {
+	case "", "false":
+		return nil
+	case "true":
+		return []string{policy.ShadowAll}
+	}
+	return getEnvList("POLICY_SHADOW_MODE", nil)
+}
+
+// loadToolPolicies parses POLICY_TOOL_MAP, e.g.
+// {"tools":{"send_email":["sensitive_data"]},"default":["passthrough"]}
+func loadToolPolicies() (policy.ToolPolicyMap, error) {
+	var m policy.ToolPolicyMap
+
+	value := os.Getenv("POLICY_TOOL_MAP")
+	if value == "" {
+		return m, nil
+	}
+
+	if err := json.Unmarshal([]byte(value), &m); err != nil {
+		return policy.ToolPolicyMap{}, fmt.Errorf("invalid POLICY_TOOL_MAP: %w", err)
+	}
+
+	return m, nil
+}
@@ -0,0 +1,287 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
+	"github.com/dagbolade/ai-governance-sidecar/internal/proxy"
+	"github.com/labstack/echo/v4"
+)
+
+const redactedValue = "[REDACTED]"
+
+type effectiveConfig struct {
+	Server   serverConfigView   `json:"server"`
+	Proxy    proxyConfigView    `json:"proxy"`
+	Policy   policyConfigView   `json:"policy"`
+	Approval approvalConfigView `json:"approval"`
+	Audit    auditConfigView    `json:"audit"`
+	Auth     authConfigView     `json:"auth"`
+}
+
+type serverConfigView struct {
+	Port              int      `json:"port"`
+	ReadTimeout       int      `json:"read_timeout"`
+	ReadHeaderTimeout int      `json:"read_header_timeout"`
+	WriteTimeout      int      `json:"write_timeout"`
+	IdleTimeout       int      `json:"idle_timeout"`
+	ShutdownTimeout   int      `json:"shutdown_timeout"`
+	CORSOrigins       []string `json:"cors_origins"`
+	UICORSOrigins     []string `json:"ui_cors_origins"`
+	CORSMaxAge        int      `json:"cors_max_age"`
+	SecurityHeaders   bool     `json:"security_headers"`
+	HSTSMaxAge        int      `json:"hsts_max_age"`
+	UICSP             string   `json:"ui_csp"`
+	WSCompression     bool     `json:"ws_compression"`
+	WSMaxConnections  int      `json:"ws_max_connections"`
+
+	TLSCertFile     string `json:"tls_cert_file,omitempty"`
+	TLSClientCAFile string `json:"tls_client_ca_file,omitempty"`
+	TLSClientAuth   string `json:"tls_client_auth,omitempty"`
+}
+
+type proxyConfigView struct {
+	DefaultUpstream string   `json:"default_upstream"`
+	Timeout         int      `json:"timeout"`
+	MaxTimeout      int      `json:"max_timeout"`
+	MaxArgsBytes    int      `json:"max_args_bytes"`
+	MaxArgsDepth    int      `json:"max_args_depth"`
+	ResponseFormat  string   `json:"response_format"`
+	PolicyHeaders   []string `json:"policy_headers"`
+	RequiredMeta    []string `json:"required_metadata,omitempty"`
+	Justification   []string `json:"justification_tools,omitempty"`
+	CAFile          string   `json:"ca_file,omitempty"`
+	InsecureTLS     bool     `json:"insecure_skip_verify"`
+	ProxyURL        string   `json:"proxy_url,omitempty"`
+	Allowlist       []string `json:"upstream_allowlist"`
+	ToolsCatalogURL string   `json:"tools_catalog_url,omitempty"`
+
+	GRPCRoutes map[string]proxy.GRPCRoute `json:"grpc_routes,omitempty"`
+	UserQuotas map[string]proxy.UserQuota `json:"user_quotas,omitempty"`
+
+	TimeWindows    []proxy.TimeWindowRule `json:"time_windows,omitempty"`
+	TimeWindowZone string                 `json:"time_window_tz,omitempty"`
+
+	ToolNamePatterns      []proxy.SensitivePattern `json:"tool_name_patterns,omitempty"`
+	ToolNameNormalization []string                 `json:"tool_name_normalization,omitempty"`
+	ToolGroups            map[string][]string      `json:"tool_groups,omitempty"`
+
+	UpstreamRoutes map[string][]string `json:"upstream_routes,omitempty"`
+	HealthPath     string              `json:"health_path,omitempty"`
+	HealthInterval int                 `json:"health_interval,omitempty"`
+}
+
+type policyConfigView struct {
+	Dir          string               `json:"dir"`
+	ToolPolicies policy.ToolPolicyMap `json:"tool_policies"`
+	MaxPolicies  int                  `json:"max_policies"`
+	Warmup       bool                 `json:"warmup"`
+	Shadow       []string             `json:"shadow,omitempty"`
+	ExpectedHash string               `json:"expected_hash,omitempty"`
+	CounterDB    string               `json:"counter_db,omitempty"`
+
+	MetadataFields map[string]string `json:"metadata_fields,omitempty"`
+	Egress         []string          `json:"egress,omitempty"`
+
+	BundleURL          string `json:"bundle_url,omitempty"`
+	BundlePollInterval int    `json:"bundle_poll_interval,omitempty"`
+	BundleTimeout      int    `json:"bundle_timeout,omitempty"`
+}
+
+type approvalConfigView struct {
+	Timeout         int    `json:"timeout"`
+	SLA             int    `json:"sla"`
+	MaxPendingAge   int    `json:"max_pending_age"`
+	GrantTTL        int    `json:"grant_ttl"`
+	Heartbeat       int    `json:"heartbeat_interval"`
+	TimeoutMessage  string `json:"timeout_message,omitempty"`
+	MaxReasonLength int    `json:"max_reason_length"`
+	Overflow        string `json:"overflow"`
+	Dedup           bool   `json:"dedup"`
+	AutoApprove     bool   `json:"auto_approve"`
+	WebhookURL      string `json:"webhook_url,omitempty"`
+	WebhookTimeout  int    `json:"webhook_timeout"`
+	NotifyURL       string `json:"notify_url,omitempty"`
+	NotifyAttempts  int    `json:"notify_attempts"`
+	NotifyBackoff   int    `json:"notify_backoff"`
+	NotifyMode      string `json:"notify_mode"`
+	DigestInterval  int    `json:"notify_digest_interval,omitempty"`
+	Simulation      bool   `json:"simulation_enabled"`
+
+	RedactKeys []string `json:"redact_keys"`
+}
+
+type auditConfigView struct {
+	DBPath         string `json:"db_path"`
+	Required       bool   `json:"required"`
+	SigningKeyFile string `json:"signing_key_file,omitempty"`
+	SignEntries    bool   `json:"sign_entries"`
+	Detail         string `json:"detail"`
+	MaxToolInput   int    `json:"max_tool_input_bytes"`
+
+	Sampling map[string]int `json:"sampling,omitempty"`
+}
+
+type authConfigView struct {
+	RequireAuth     bool   `json:"require_auth"`
+	JWTSecret       string `json:"jwt_secret"`
+	TokenExpiration string `json:"token_expiration"`
+	UsersFile       string `json:"users_file,omitempty"`
+	MaxTokenAge     string `json:"max_token_age"`
+	ClockSkew       string `json:"clock_skew"`
+
+	ApproverScopes  map[string][]string `json:"approver_scopes,omitempty"`
+	ClientCertRoles map[string][]string `json:"mtls_identities,omitempty"`
+}
+
+// handleConfig returns the effective runtime configuration with secrets masked.
+func (s *Server) handleConfig(c echo.Context) error {
+	return c.JSON(http.StatusOK, s.effectiveConfig())
+}
+
+func (s *Server) effectiveConfig() effectiveConfig {
+	cfg := s.config
+
+	// The client auth mode means nothing without a client CA
+	var clientAuth string
+	if cfg.TLSClientCAFile != "" {
+		clientAuth = cfg.TLSClientAuth
+	}
+
+	return effectiveConfig{
+		Server: serverConfigView{
+			Port:              cfg.Port,
+			ReadTimeout:       cfg.ReadTimeout,
+			ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+			WriteTimeout:      cfg.WriteTimeout,
+			IdleTimeout:       cfg.IdleTimeout,
+			ShutdownTimeout:   cfg.ShutdownTimeout,
+			CORSOrigins:       s.corsOrigins(),
+			UICORSOrigins:     s.uiCORSOrigins(),
+			CORSMaxAge:        s.config.CORSMaxAge,
+			SecurityHeaders:   cfg.SecurityHeaders,
+			HSTSMaxAge:        cfg.HSTSMaxAge,
+			UICSP:             s.uiCSP(),
+			WSCompression:     cfg.WSCompression,
+			WSMaxConnections:  cfg.WSMaxConnections,
+
+			TLSCertFile:     cfg.TLSCertFile,
+			TLSClientCAFile: cfg.TLSClientCAFile,
+			TLSClientAuth:   clientAuth,
+		},
+		Proxy: proxyConfigView{
+			DefaultUpstream: cfg.ProxyConfig.DefaultUpstream,
+			Timeout:         cfg.ProxyConfig.Timeout,
+			MaxTimeout:      cfg.ProxyConfig.MaxTimeout,
+			MaxArgsBytes:    cfg.ProxyConfig.MaxArgsBytes,
+			MaxArgsDepth:    cfg.ProxyConfig.MaxArgsDepth,
+			ResponseFormat:  cfg.ProxyConfig.ResponseFormat,
+			PolicyHeaders:   cfg.ProxyConfig.PolicyHeaders,
+			RequiredMeta:    cfg.ProxyConfig.RequiredMetadata,
+			Justification:   cfg.ProxyConfig.JustificationTools,
+			CAFile:          cfg.ProxyConfig.UpstreamCAFile,
+			InsecureTLS:     cfg.ProxyConfig.UpstreamInsecureSkipVerify,
+			ProxyURL:        redactURL(cfg.ProxyConfig.UpstreamProxyURL),
+			Allowlist:       cfg.ProxyConfig.UpstreamAllowlist,
+			ToolsCatalogURL: redactURL(cfg.ProxyConfig.ToolsCatalogURL),
+			GRPCRoutes:      cfg.ProxyConfig.GRPCRoutes,
+			UserQuotas:      cfg.ProxyConfig.UserQuotas,
+			TimeWindows:     cfg.ProxyConfig.TimeWindows,
+			TimeWindowZone:  cfg.ProxyConfig.TimeWindowZone,
+			UpstreamRoutes:  redactRoutes(cfg.ProxyConfig.UpstreamRoutes),
+			HealthPath:      cfg.ProxyConfig.UpstreamHealthPath,
+			HealthInterval:  cfg.ProxyConfig.UpstreamHealthInterval,
+
+			ToolNamePatterns:      cfg.ProxyConfig.ToolNamePatterns,
+			ToolNameNormalization: cfg.ProxyConfig.ToolNameNormalization,
+			ToolGroups:            cfg.ProxyConfig.ToolGroups,
+		},
+		Policy: policyConfigView{
+			Dir:          cfg.PolicyConfig.Dir,
+			ToolPolicies: cfg.PolicyConfig.ToolPolicies,
+			MaxPolicies:  cfg.PolicyConfig.MaxPolicies,
+			Warmup:       cfg.PolicyConfig.Warmup,
+			Shadow:       cfg.PolicyConfig.Shadow,
+			ExpectedHash: cfg.PolicyConfig.ExpectedHash,
+			CounterDB:    cfg.PolicyConfig.CounterDB,
+
+			MetadataFields: cfg.PolicyConfig.MetadataFields,
+			Egress:         cfg.PolicyConfig.Egress,
+
+			BundleURL:          redactURL(cfg.PolicyConfig.BundleURL),
+			BundlePollInterval: cfg.PolicyConfig.BundlePollInterval,
+			BundleTimeout:      cfg.PolicyConfig.BundleTimeout,
+		},
+		Approval: approvalConfigView{
+			Timeout:         cfg.ApprovalTimeout,
+			SLA:             cfg.ApprovalSLA,
+			MaxPendingAge:   cfg.ApprovalMaxPendingAge,
+			GrantTTL:        cfg.ProxyConfig.ApprovalGrantTTL,
+			Heartbeat:       cfg.ProxyConfig.ApprovalHeartbeat,
+			TimeoutMessage:  cfg.ProxyConfig.ApprovalTimeoutMessage,
+			MaxReasonLength: cfg.MaxReasonLength,
+			Overflow:        cfg.ApprovalOverflow,
+			Dedup:           cfg.ApprovalDedup,
+			AutoApprove:     cfg.ProxyConfig.AutoApprove.Enabled,
+			WebhookURL:      redactURL(cfg.ApprovalWebhookURL),
+			WebhookTimeout:  cfg.ApprovalWebhookTimeout,
+			NotifyURL:       redactURL(cfg.ApprovalNotifyURL),
+			NotifyAttempts:  cfg.ApprovalNotifyAttempts,
+			NotifyBackoff:   cfg.ApprovalNotifyBackoff,
+			NotifyMode:      cfg.ApprovalNotifyMode,
+			DigestInterval:  cfg.ApprovalDigestInterval,
+			Simulation:      cfg.SimulationEnabled,
+			RedactKeys:      cfg.ApprovalRedactKeys,
+		},
+		Audit: auditConfigView{
+			DBPath:         cfg.DBPath,
+			Required:       cfg.AuditRequired,
+			SigningKeyFile: cfg.AuditSigningKeyFile,
+			SignEntries:    cfg.AuditSignEntries,
+			MaxToolInput:   cfg.AuditMaxToolInput,
+			Detail:         cfg.ProxyConfig.AuditDetail,
+			Sampling:       cfg.ProxyConfig.AuditSampling,
+		},
+		Auth: authConfigView{
+			RequireAuth:     cfg.AuthConfig.RequireAuth,
+			JWTSecret:       redact(cfg.AuthConfig.JWTSecret),
+			TokenExpiration: cfg.AuthConfig.TokenExpiration.String(),
+			UsersFile:       cfg.AuthConfig.UsersFile,
+			MaxTokenAge:     cfg.AuthConfig.MaxTokenAge.String(),
+			ClockSkew:       cfg.AuthConfig.ClockSkew.String(),
+			ApproverScopes:  cfg.AuthConfig.ApproverScopes,
+			ClientCertRoles: cfg.AuthConfig.ClientCertRoles,
+		},
+	}
+}
+
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return redactedValue
+}
+
+// redactURL masks any password embedded in a URL
+func redactURL(raw string) string {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return redact(raw)
+	}
+	return parsed.Redacted()
+}
+
+func redactRoutes(routes map[string][]string) map[string][]string {
+	if len(routes) == 0 {
+		return nil
+	}
+
+	redacted := make(map[string][]string, len(routes))
+	for tool, urls := range routes {
+		for _, u := range urls {
+			redacted[tool] = append(redacted[tool], redactURL(u))
+		}
+	}
+	return redacted
+}
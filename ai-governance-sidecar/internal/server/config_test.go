@@ -2,6 +2,7 @@ package server
 
 import (
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -113,4 +114,66 @@ func TestLoadConfig(t *testing.T) {
 	if cfg.ReadTimeout != 30 {
 		t.Errorf("expected default read timeout 30, got %d", cfg.ReadTimeout)
 	}
-}
\ No newline at end of file
+}
+
+func TestLoadConfigCounterDB(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "", want: "./db/policy_counters.db"},
+		{value: "/data/counters.db", want: "/data/counters.db"},
+		{value: "none", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Setenv("POLICY_COUNTER_DB", tt.value)
+
+			if got := LoadConfig().PolicyConfig.CounterDB; got != tt.want {
+				t.Errorf("expected counter db %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestLoadConfigReportsInvalidApproverScopes(t *testing.T) {
+	t.Setenv("APPROVER_SCOPES", `{"db_admin": "db.*"}`)
+
+	cfg := LoadConfig()
+
+	if err := cfg.EnvError(); err == nil || !strings.Contains(err.Error(), "APPROVER_SCOPES") {
+		t.Errorf("expected an APPROVER_SCOPES error, got %v", err)
+	}
+	if cfg.AuthConfig.ApproverScopes != nil {
+		t.Errorf("expected no scopes from invalid JSON, got %v", cfg.AuthConfig.ApproverScopes)
+	}
+}
+
+func TestLoadConfigReportsInvalidJSONSettings(t *testing.T) {
+	for _, key := range []string{
+		"POLICY_TOOL_MAP",
+		"SENSITIVE_PATTERNS",
+		"TIME_WINDOWS",
+		"TOOL_NAME_PATTERNS",
+		"TOOL_GROUPS",
+		"POLICY_METADATA_FIELDS",
+		"MTLS_IDENTITIES",
+		"GRPC_ROUTES",
+		"UPSTREAM_ROUTES",
+		"AUDIT_SAMPLING",
+		"USER_QUOTAS",
+	} {
+		t.Run(key, func(t *testing.T) {
+			t.Setenv(key, `{"typo"`)
+
+			if err := LoadConfig().EnvError(); err == nil || !strings.Contains(err.Error(), key) {
+				t.Errorf("expected a %s error, got %v", key, err)
+			}
+		})
+	}
+
+	if err := LoadConfig().EnvError(); err != nil {
+		t.Errorf("expected no error without those settings, got %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/audit"
+	"github.com/dagbolade/ai-governance-sidecar/internal/auth"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// DelegationHandler lets admins lend the approver role to users for a
+// time window. Grants and revocations are written to the audit log.
+type DelegationHandler struct {
+	manager *auth.Manager
+	audit   audit.Store
+}
+
+func NewDelegationHandler(manager *auth.Manager, store audit.Store) *DelegationHandler {
+	return &DelegationHandler{manager: manager, audit: store}
+}
+
+// delegationEvent is the tool_input recorded for delegation changes
+type delegationEvent struct {
+	Event      string          `json:"event"`
+	Delegation auth.Delegation `json:"delegation"`
+	Actor      string          `json:"actor,omitempty"`
+}
+
+func (h *DelegationHandler) Grant(c echo.Context) error {
+	var req struct {
+		UserID  string    `json:"user_id"`
+		Role    string    `json:"role"`
+		Reason  string    `json:"reason"`
+		Start   time.Time `json:"start"`
+		Expires time.Time `json:"expires"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid request body",
+		})
+	}
+	if req.Role == "" {
+		req.Role = auth.RoleApprover
+	}
+
+	actor := actorID(c)
+	delegation, err := h.manager.Delegate(auth.Delegation{
+		UserID:    req.UserID,
+		Role:      req.Role,
+		GrantedBy: actor,
+		Reason:    req.Reason,
+		Start:     req.Start,
+		Expires:   req.Expires,
+	})
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	log.Info().Str("user", delegation.UserID).Str("role", delegation.Role).Str("by", actor).
+		Time("expires", delegation.Expires).Msg("role delegated")
+	h.record(c, "delegation_granted", delegation, actor,
+		fmt.Sprintf("%s role delegated to %s until %s", delegation.Role, delegation.UserID, delegation.Expires.UTC().Format(time.RFC3339)))
+
+	return c.JSON(http.StatusCreated, delegation)
+}
+
+func (h *DelegationHandler) List(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"delegations": h.manager.Delegations(),
+	})
+}
+
+func (h *DelegationHandler) Revoke(c echo.Context) error {
+	delegation, err := h.manager.RevokeDelegation(c.Param("id"))
+	if errors.Is(err, auth.ErrDelegationNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	actor := actorID(c)
+	log.Info().Str("user", delegation.UserID).Str("role", delegation.Role).Str("by", actor).Msg("delegation revoked")
+	h.record(c, "delegation_revoked", delegation, actor,
+		fmt.Sprintf("%s role delegation to %s revoked", delegation.Role, delegation.UserID))
+
+	return c.JSON(http.StatusOK, delegation)
+}
+
+// record audits a delegation change. A failed write is logged but does not
+// undo the change.
+func (h *DelegationHandler) record(c echo.Context, event string, delegation auth.Delegation, actor, reason string) {
+	input, err := json.Marshal(delegationEvent{Event: event, Delegation: delegation, Actor: actor})
+	if err == nil {
+		err = h.audit.Log(c.Request().Context(), input, audit.DecisionAllow, reason)
+	}
+	if err != nil {
+		log.Warn().Err(err).Str("event", event).Msg("audit logging failed")
+	}
+}
+
+func actorID(c echo.Context) string {
+	if user := auth.GetUserFromContext(c); user != nil {
+		return user.ID
+	}
+	return ""
+}
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/auth"
+	"github.com/dagbolade/ai-governance-sidecar/internal/clock"
+	"github.com/labstack/echo/v4"
+)
+
+func TestDelegatedApproverWindow(t *testing.T) {
+	fake := clock.NewFake(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	authManager := auth.NewManager(auth.Config{RequireAuth: true, JWTSecret: "test-secret"})
+	authManager.SetClock(fake)
+
+	store := &mockAuditStore{}
+	srv := New(Config{Port: 8080}, &mockPolicyEvaluator{}, store, &mockApprovalQueue{}, authManager)
+
+	adminToken, _ := authManager.GenerateToken(auth.User{ID: "alice", Roles: []string{auth.RoleAdmin}})
+	bobToken, _ := authManager.GenerateToken(auth.User{ID: "bob", Roles: []string{auth.RoleViewer}})
+
+	approve := func() int {
+		req := httptest.NewRequest(http.MethodPost, "/approve/req-1", strings.NewReader(`{"approved":true,"reason":"covering for carol"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+bobToken)
+		rec := httptest.NewRecorder()
+		srv.echo.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := approve(); code != http.StatusForbidden {
+		t.Fatalf("expected viewer to be refused before delegation, got %d", code)
+	}
+
+	body := `{"user_id":"bob","reason":"carol on leave","expires":"` + fake.Now().Add(time.Hour).Format(time.RFC3339) + `"}`
+	req := httptest.NewRequest(http.MethodPost, "/auth/delegations", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer "+adminToken)
+	rec := httptest.NewRecorder()
+	srv.echo.ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected delegation to be created, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if code := approve(); code != http.StatusOK {
+		t.Errorf("expected delegated user to approve inside the window, got %d", code)
+	}
+
+	fake.Advance(2 * time.Hour)
+	if code := approve(); code != http.StatusForbidden {
+		t.Errorf("expected delegated user to be refused after expiry, got %d", code)
+	}
+
+	if len(store.entries) != 1 {
+		t.Fatalf("expected the grant to be audited, got %d entries", len(store.entries))
+	}
+	var event delegationEvent
+	if err := json.Unmarshal(store.entries[0].ToolInput, &event); err != nil {
+		t.Fatalf("failed to parse audit entry: %v", err)
+	}
+	if event.Event != "delegation_granted" || event.Actor != "alice" || event.Delegation.UserID != "bob" {
+		t.Errorf("unexpected audit event %+v", event)
+	}
+}
+
+func TestDelegationRejectsAdminRole(t *testing.T) {
+	authManager := auth.NewManager(auth.Config{RequireAuth: true, JWTSecret: "test-secret"})
+	srv := New(Config{Port: 8080}, &mockPolicyEvaluator{}, &mockAuditStore{}, &mockApprovalQueue{}, authManager)
+
+	body := `{"user_id":"bob","role":"admin","expires":"` + time.Now().Add(time.Hour).Format(time.RFC3339) + `"}`
+	req := httptest.NewRequest(http.MethodPost, "/auth/delegations", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	srv.echo.ServeHTTP(rec, asAdmin(t, authManager, req))
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected admin delegation to be refused, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,52 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// handleError renders errors that reach Echo, such as unmatched routes and
+// method mismatches, in the same {"error", "code"} shape as the handlers.
+// Unexpected errors are logged and reported without their detail.
+func (s *Server) handleError(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	status := http.StatusInternalServerError
+	message := http.StatusText(status)
+
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		status = httpErr.Code
+		message = fmt.Sprint(httpErr.Message)
+	} else {
+		log.Error().Err(err).Str("uri", c.Request().RequestURI).Msg("unhandled error")
+	}
+
+	if c.Request().Method == http.MethodHead {
+		err = c.NoContent(status)
+	} else {
+		err = c.JSON(status, map[string]string{
+			"error": message,
+			"code":  errorCode(status),
+		})
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("failed to write error response")
+	}
+}
+
+// errorCode turns a status into a stable code, e.g. 405 -> METHOD_NOT_ALLOWED
+func errorCode(status int) string {
+	text := http.StatusText(status)
+	if text == "" {
+		return fmt.Sprintf("HTTP_%d", status)
+	}
+	return strings.ToUpper(strings.NewReplacer(" ", "_", "-", "_", "'", "").Replace(text))
+}
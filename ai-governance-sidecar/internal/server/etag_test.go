@@ -0,0 +1,56 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/audit"
+	"github.com/dagbolade/ai-governance-sidecar/internal/auth"
+)
+
+func TestListEndpointsHonorIfNoneMatch(t *testing.T) {
+	store := &mockAuditStore{entries: []audit.Entry{{ID: 1, Decision: audit.DecisionAllow, Reason: "ok"}}}
+	authManager := auth.NewManager(auth.Config{RequireAuth: false, JWTSecret: "test-secret"})
+	srv := New(Config{Port: 8080}, &mockPolicyEvaluator{}, store, &mockApprovalQueue{}, authManager)
+
+	get := func(path, etag string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		rec := httptest.NewRecorder()
+		srv.echo.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for _, path := range []string{"/audit", "/pending"} {
+		t.Run(path, func(t *testing.T) {
+			first := get(path, "")
+			etag := first.Header().Get("ETag")
+			if first.Code != http.StatusOK || etag == "" {
+				t.Fatalf("expected 200 with an ETag, got %d %q", first.Code, etag)
+			}
+
+			repeat := get(path, etag)
+			if repeat.Code != http.StatusNotModified || repeat.Body.Len() != 0 {
+				t.Errorf("expected 304 with no body for the prior ETag, got %d (%d bytes)", repeat.Code, repeat.Body.Len())
+			}
+			if repeat.Header().Get("ETag") != etag {
+				t.Errorf("expected the 304 to repeat the ETag, got %q", repeat.Header().Get("ETag"))
+			}
+
+			if stale := get(path, `"stale"`); stale.Code != http.StatusOK {
+				t.Errorf("expected 200 for a different ETag, got %d", stale.Code)
+			}
+		})
+	}
+
+	// A new entry changes the audit list, so the old ETag no longer matches
+	etag := get("/audit", "").Header().Get("ETag")
+	store.entries = append(store.entries, audit.Entry{ID: 2, Decision: audit.DecisionDeny, Reason: "no"})
+	changed := get("/audit", etag)
+	if changed.Code != http.StatusOK || changed.Header().Get("ETag") == etag {
+		t.Errorf("expected a fresh 200 and ETag after the list changed, got %d", changed.Code)
+	}
+}
@@ -0,0 +1,270 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	clientSendBuffer = 256
+	eventHistorySize = 100
+	// Warn when a client's send buffer is this full
+	sendBufferWarnRatio = 0.8
+)
+
+var errClientGone = errors.New("websocket client disconnected")
+
+// Hub fans out sequenced messages to connected WebSocket clients and
+// keeps a bounded history of replayable events for reconnecting clients.
+type Hub struct {
+	mu      sync.RWMutex
+	clients map[*wsClient]struct{}
+	seq     uint64
+	history []sequencedMessage
+	maxHist int
+	// evicted is the seq of the newest replayable event dropped from history
+	evicted uint64
+	stats   HubStats
+
+	// conns counts open connections, including ones still being upgraded
+	// or already dropped as slow but not yet closed. Zero maxConns is no
+	// limit.
+	conns    int
+	maxConns int
+}
+
+// HubStats reports WebSocket hub health
+type HubStats struct {
+	ConnectedClients  int    `json:"connected_clients"`
+	MessagesSent      uint64 `json:"messages_sent"`
+	MessagesDropped   uint64 `json:"messages_dropped"`
+	SlowClientsClosed uint64 `json:"slow_clients_closed"`
+	RejectedClients   uint64 `json:"rejected_clients"`
+	LastSeq           uint64 `json:"last_seq"`
+}
+
+type sequencedMessage struct {
+	seq  uint64
+	data []byte
+}
+
+type wsClient struct {
+	conn *websocket.Conn
+	send chan []byte
+}
+
+func NewHub() *Hub {
+	return &Hub{
+		clients: make(map[*wsClient]struct{}),
+		maxHist: eventHistorySize,
+	}
+}
+
+func newWSClient(conn *websocket.Conn) *wsClient {
+	return &wsClient{
+		conn: conn,
+		send: make(chan []byte, clientSendBuffer),
+	}
+}
+
+// Register adds a client and queues any retained events newer than since.
+// Both happen under the hub lock so no event can fall in between. When
+// events after since have already left the history, a replay_gap message
+// goes first so the client knows to reload state rather than trust the
+// replay.
+func (h *Hub) Register(client *wsClient, since uint64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.clients[client] = struct{}{}
+
+	if since == 0 {
+		return
+	}
+
+	if since < h.evicted {
+		data, err := encodeMessage(h.seq, "replay_gap", map[string]interface{}{
+			"since":      since,
+			"oldest_seq": h.history[0].seq,
+		})
+		if err != nil {
+			log.Error().Err(err).Msg("failed to encode websocket replay gap")
+		} else {
+			h.deliver(client, data)
+		}
+	}
+
+	for _, msg := range h.history {
+		if msg.seq > since {
+			h.deliver(client, msg.data)
+		}
+	}
+}
+
+// Acquire reserves a connection slot, reporting false when the hub is at
+// its limit. Every successful Acquire must be paired with Release.
+func (h *Hub) Acquire() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.maxConns > 0 && h.conns >= h.maxConns {
+		h.stats.RejectedClients++
+		return false
+	}
+	h.conns++
+	return true
+}
+
+func (h *Hub) Release() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.conns--
+}
+
+// SetMaxConnections caps concurrent connections; zero removes the cap
+func (h *Hub) SetMaxConnections(n int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.maxConns = n
+}
+
+func (h *Hub) Unregister(client *wsClient) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.removeLocked(client)
+}
+
+// Broadcast sends a message to every client. Replayable messages are
+// retained so reconnecting clients can catch up with ?since=<seq>.
+func (h *Hub) Broadcast(msgType string, fields map[string]interface{}, replayable bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.broadcastLocked(msgType, fields, replayable)
+}
+
+// BroadcastLoaded is Broadcast for state snapshots. load runs under the hub
+// lock so concurrent snapshots reach clients in the order they were taken.
+func (h *Hub) BroadcastLoaded(msgType string, load func() (map[string]interface{}, error)) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	fields, err := load()
+	if err != nil {
+		return err
+	}
+
+	h.broadcastLocked(msgType, fields, false)
+	return nil
+}
+
+func (h *Hub) broadcastLocked(msgType string, fields map[string]interface{}, replayable bool) {
+	h.seq++
+	data, err := encodeMessage(h.seq, msgType, fields)
+	if err != nil {
+		log.Error().Err(err).Str("type", msgType).Msg("failed to encode websocket message")
+		return
+	}
+
+	if replayable {
+		h.remember(sequencedMessage{seq: h.seq, data: data})
+	}
+
+	for client := range h.clients {
+		h.deliver(client, data)
+	}
+}
+
+// Send queues a message for a single client, stamped with the current
+// sequence number so the client knows where to resume from.
+func (h *Hub) Send(client *wsClient, msgType string, fields map[string]interface{}) error {
+	return h.SendLoaded(client, msgType, func() (map[string]interface{}, error) {
+		return fields, nil
+	})
+}
+
+// SendLoaded captures a snapshot with load and queues it for one client
+// without letting a broadcast slip in between. Changes made before load
+// are in the snapshot; anything later is broadcast after it, so a client
+// that connects while a decision lands cannot end up with stale state.
+func (h *Hub) SendLoaded(client *wsClient, msgType string, load func() (map[string]interface{}, error)) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.clients[client]; !ok {
+		return errClientGone
+	}
+
+	fields, err := load()
+	if err != nil {
+		return err
+	}
+
+	data, err := encodeMessage(h.seq, msgType, fields)
+	if err != nil {
+		return err
+	}
+
+	h.deliver(client, data)
+	return nil
+}
+
+// Stats returns a snapshot of hub counters
+func (h *Hub) Stats() HubStats {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	stats := h.stats
+	stats.ConnectedClients = len(h.clients)
+	stats.LastSeq = h.seq
+	return stats
+}
+
+func (h *Hub) remember(msg sequencedMessage) {
+	h.history = append(h.history, msg)
+	if len(h.history) > h.maxHist {
+		drop := len(h.history) - h.maxHist
+		h.evicted = h.history[drop-1].seq
+		h.history = h.history[drop:]
+	}
+}
+
+// deliver must be called with h.mu held
+func (h *Hub) deliver(client *wsClient, data []byte) {
+	select {
+	case client.send <- data:
+		h.stats.MessagesSent++
+		if float64(len(client.send)) >= float64(cap(client.send))*sendBufferWarnRatio {
+			log.Warn().Int("queued", len(client.send)).Int("capacity", cap(client.send)).
+				Msg("websocket client send buffer near capacity")
+		}
+	default:
+		log.Warn().Msg("websocket client too slow, disconnecting")
+		h.stats.MessagesDropped++
+		h.stats.SlowClientsClosed++
+		h.removeLocked(client)
+	}
+}
+
+func (h *Hub) removeLocked(client *wsClient) {
+	if _, ok := h.clients[client]; !ok {
+		return
+	}
+	delete(h.clients, client)
+	close(client.send)
+}
+
+func encodeMessage(seq uint64, msgType string, fields map[string]interface{}) ([]byte, error) {
+	msg := make(map[string]interface{}, len(fields)+2)
+	for k, v := range fields {
+		msg[k] = v
+	}
+	msg["type"] = msgType
+	msg["seq"] = seq
+
+	return json.Marshal(msg)
+}
@@ -0,0 +1,25 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/approval"
+	"github.com/labstack/echo/v4"
+)
+
+type metricsResponse struct {
+	WebSocket HubStats           `json:"websocket"`
+	Approvals *approval.SLAStats `json:"approvals,omitempty"`
+}
+
+func (s *Server) handleMetrics(c echo.Context) error {
+	resp := metricsResponse{
+		WebSocket: s.hub.Stats(),
+	}
+	if reporter, ok := s.approval.(approval.SLAReporter); ok {
+		stats := reporter.SLAStats()
+		resp.Approvals = &stats
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
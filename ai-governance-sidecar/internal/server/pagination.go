@@ -0,0 +1,110 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+const maxPageLimit = 1000
+
+// pageEnvelope is the response shape shared by list endpoints. The page is
+// sent under the endpoint's own key, "entries" or "pending" as before
+// pagination, so existing clients keep working. Limit is null when the
+// caller set none, and NextOffset is null on the last page. Extra holds
+// endpoint-specific cursor fields.
+type pageEnvelope[T any] struct {
+	key        string
+	Items      []T
+	Total      int
+	Limit      int
+	Offset     int
+	NextOffset *int
+	Extra      map[string]any
+}
+
+func (p pageEnvelope[T]) MarshalJSON() ([]byte, error) {
+	var limit any
+	if p.Limit > 0 {
+		limit = p.Limit
+	}
+
+	fields := map[string]any{
+		p.key:         p.Items,
+		"total":       p.Total,
+		"limit":       limit,
+		"offset":      p.Offset,
+		"next_offset": p.NextOffset,
+	}
+	for k, v := range p.Extra {
+		fields[k] = v
+	}
+	return json.Marshal(fields)
+}
+
+// pagination selects a page; zero limit means every item from offset on
+type pagination struct {
+	limit  int
+	offset int
+}
+
+// parsePagination reads ?limit= and ?offset=, capping limit at
+// maxPageLimit. Without ?limit= everything is returned, as before paging.
+func parsePagination(c echo.Context) (pagination, error) {
+	var p pagination
+
+	if raw := c.QueryParam("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			return p, fmt.Errorf("limit must be a positive integer")
+		}
+		if limit > maxPageLimit {
+			limit = maxPageLimit
+		}
+		p.limit = limit
+	}
+
+	if raw := c.QueryParam("offset"); raw != "" {
+		offset, err := strconv.Atoi(raw)
+		if err != nil || offset < 0 {
+			return p, fmt.Errorf("offset must be a non-negative integer")
+		}
+		p.offset = offset
+	}
+
+	return p, nil
+}
+
+// paginate returns the page of items p selects, to be sent under key
+func paginate[T any](items []T, p pagination, key string) pageEnvelope[T] {
+	total := len(items)
+
+	start := p.offset
+	if start > total {
+		start = total
+	}
+	end := total
+	if p.limit > 0 && start+p.limit < total {
+		end = start + p.limit
+	}
+
+	envelope := pageEnvelope[T]{
+		key:    key,
+		Items:  items[start:end],
+		Total:  total,
+		Limit:  p.limit,
+		Offset: p.offset,
+	}
+	if envelope.Items == nil {
+		envelope.Items = []T{}
+	}
+
+	if end < total {
+		next := end
+		envelope.NextOffset = &next
+	}
+
+	return envelope
+}
@@ -0,0 +1,162 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/audit"
+	"github.com/dagbolade/ai-governance-sidecar/internal/auth"
+)
+
+func TestPaginate(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+
+	tests := []struct {
+		name        string
+		limit       int
+		offset      int
+		expectItems []int
+		expectNext  *int
+	}{
+		{name: "first page", limit: 2, offset: 0, expectItems: []int{1, 2}, expectNext: intPtr(2)},
+		{name: "middle page", limit: 2, offset: 2, expectItems: []int{3, 4}, expectNext: intPtr(4)},
+		{name: "last partial page", limit: 2, offset: 4, expectItems: []int{5}},
+		{name: "exact last page", limit: 5, offset: 0, expectItems: []int{1, 2, 3, 4, 5}},
+		{name: "offset past end", limit: 2, offset: 10, expectItems: []int{}},
+		{name: "no limit", limit: 0, offset: 1, expectItems: []int{2, 3, 4, 5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := paginate(items, pagination{limit: tt.limit, offset: tt.offset}, "items")
+
+			if page.Total != len(items) || page.Limit != tt.limit || page.Offset != tt.offset {
+				t.Errorf("unexpected envelope: %+v", page)
+			}
+			if len(page.Items) != len(tt.expectItems) {
+				t.Fatalf("expected items %v, got %v", tt.expectItems, page.Items)
+			}
+			for i := range page.Items {
+				if page.Items[i] != tt.expectItems[i] {
+					t.Errorf("expected items %v, got %v", tt.expectItems, page.Items)
+				}
+			}
+
+			switch {
+			case tt.expectNext == nil && page.NextOffset != nil:
+				t.Errorf("expected no next_offset, got %d", *page.NextOffset)
+			case tt.expectNext != nil && (page.NextOffset == nil || *page.NextOffset != *tt.expectNext):
+				t.Errorf("expected next_offset %d, got %v", *tt.expectNext, page.NextOffset)
+			}
+		})
+	}
+}
+
+func intPtr(v int) *int { return &v }
+
+func TestAuditLogPaginationEnvelope(t *testing.T) {
+	store := &mockAuditStore{}
+	for i := 0; i < 3; i++ {
+		store.entries = append(store.entries, audit.Entry{ID: int64(i + 1), Decision: audit.DecisionAllow})
+	}
+
+	authManager := auth.NewManager(auth.Config{RequireAuth: false, JWTSecret: "test-secret"})
+	srv := New(Config{Port: 8080}, &mockPolicyEvaluator{}, store, &mockApprovalQueue{}, authManager)
+
+	req := httptest.NewRequest(http.MethodGet, "/audit?limit=2&offset=0", nil)
+	rec := httptest.NewRecorder()
+	srv.echo.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+
+	var response map[string]json.RawMessage
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	// Entries keep the key they had before pagination
+	for _, field := range []string{"entries", "total", "limit", "offset", "next_offset"} {
+		if _, ok := response[field]; !ok {
+			t.Errorf("expected %s in envelope", field)
+		}
+	}
+	if string(response["next_offset"]) != "2" || string(response["total"]) != "3" {
+		t.Errorf("unexpected paging fields: total=%s next_offset=%s", response["total"], response["next_offset"])
+	}
+
+	for _, query := range []string{"limit=0", "limit=abc", "offset=-1", "before=0"} {
+		path := "/pending?"
+		if query == "before=0" {
+			path = "/audit?"
+		}
+		req := httptest.NewRequest(http.MethodGet, path+query, nil)
+		rec := httptest.NewRecorder()
+		srv.echo.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 for %s, got %d", query, rec.Code)
+		}
+	}
+}
+
+func TestAuditLogCursor(t *testing.T) {
+	store := &mockAuditStore{}
+	logEntry := func() {
+		store.entries = append(store.entries, audit.Entry{ID: int64(len(store.entries) + 1), Decision: audit.DecisionAllow})
+	}
+	for i := 0; i < 5; i++ {
+		logEntry()
+	}
+
+	authManager := auth.NewManager(auth.Config{RequireAuth: false, JWTSecret: "test-secret"})
+	srv := New(Config{Port: 8080}, &mockPolicyEvaluator{}, store, &mockApprovalQueue{}, authManager)
+
+	get := func(query string) (ids []int64, nextBefore *int64, limit json.RawMessage) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/audit"+query, nil)
+		rec := httptest.NewRecorder()
+		srv.echo.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200 for %q, got %d", query, rec.Code)
+		}
+
+		var response struct {
+			Entries    []audit.Entry   `json:"entries"`
+			NextBefore *int64          `json:"next_before"`
+			Limit      json.RawMessage `json:"limit"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+			t.Fatalf("failed to parse response: %v", err)
+		}
+		for _, entry := range response.Entries {
+			ids = append(ids, entry.ID)
+		}
+		return ids, response.NextBefore, response.Limit
+	}
+
+	// Without a limit nothing is cut off
+	if ids, next, limit := get(""); len(ids) != 5 || next != nil || string(limit) != "null" {
+		t.Errorf("expected every entry and no cursor, got %v next=%v limit=%s", ids, next, limit)
+	}
+
+	ids, next, _ := get("?limit=2")
+	if fmt.Sprint(ids) != "[5 4]" || next == nil || *next != 4 {
+		t.Fatalf("expected newest page [5 4] with next_before 4, got %v next=%v", ids, next)
+	}
+
+	// A write between pages does not shift the next one
+	logEntry()
+	ids, next, _ = get(fmt.Sprintf("?limit=2&before=%d", *next))
+	if fmt.Sprint(ids) != "[3 2]" || next == nil || *next != 2 {
+		t.Fatalf("expected [3 2] with next_before 2, got %v next=%v", ids, next)
+	}
+
+	ids, next, _ = get(fmt.Sprintf("?limit=2&before=%d", *next))
+	if fmt.Sprint(ids) != "[1]" || next != nil {
+		t.Errorf("expected last page [1] without a cursor, got %v next=%v", ids, next)
+	}
+}
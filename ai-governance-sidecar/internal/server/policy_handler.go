@@ -0,0 +1,161 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/audit"
+	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+)
+
+type PolicyHandler struct {
+	policy policy.Evaluator
+	// audit records policies being disabled and enabled
+	audit audit.Store
+}
+
+func NewPolicyHandler(pol policy.Evaluator) *PolicyHandler {
+	return &PolicyHandler{policy: pol}
+}
+
+// Reload loads the policy set again. Files that failed to load are listed
+// under policy_errors with the stage and, for invalid modules, the offset
+// the validator stopped at.
+func (h *PolicyHandler) Reload(c echo.Context) error {
+	if err := h.policy.Reload(); err != nil {
+		log.Error().Err(err).Msg("policy reload failed")
+
+		status := http.StatusInternalServerError
+		if errors.Is(err, policy.ErrTooManyPolicies) {
+			status = http.StatusUnprocessableEntity
+		}
+
+		return c.JSON(status, h.withLoadErrors(map[string]interface{}{
+			"error": err.Error(),
+		}))
+	}
+
+	return c.JSON(http.StatusOK, h.withLoadErrors(map[string]interface{}{
+		"status": "reloaded",
+	}))
+}
+
+// loadErrorReporter is implemented by evaluators that keep the policy files
+// skipped on their last load
+type loadErrorReporter interface {
+	LoadErrors() []policy.LoadError
+}
+
+// withLoadErrors adds policy_errors to body when any file was skipped
+func (h *PolicyHandler) withLoadErrors(body map[string]interface{}) map[string]interface{} {
+	if reporter, ok := h.policy.(loadErrorReporter); ok {
+		if errs := reporter.LoadErrors(); len(errs) > 0 {
+			body["policy_errors"] = errs
+		}
+	}
+	return body
+}
+
+// policyLister is implemented by evaluators that can describe their
+// loaded policy set
+type policyLister interface {
+	Policies() []policy.PolicyInfo
+	PolicyHash() string
+}
+
+// List returns the loaded policies and the hash that EXPECTED_POLICY_HASH
+// pins
+func (h *PolicyHandler) List(c echo.Context) error {
+	lister, ok := h.policy.(policyLister)
+	if !ok {
+		return c.JSON(http.StatusNotImplemented, map[string]string{
+			"error": "policy evaluator does not list policies",
+		})
+	}
+
+	return c.JSON(http.StatusOK, h.withLoadErrors(map[string]interface{}{
+		"policies": lister.Policies(),
+		"hash":     lister.PolicyHash(),
+	}))
+}
+
+// Schema returns the JSON Schema of the policy input and output documents
+func (h *PolicyHandler) Schema(c echo.Context) error {
+	return c.JSON(http.StatusOK, policy.Schema())
+}
+
+// policyToggler is implemented by evaluators that can switch individual
+// policies off without reloading
+type policyToggler interface {
+	SetPolicyEnabled(name string, enabled bool) error
+}
+
+// policyToggleEvent is the tool_input recorded for enable and disable calls
+type policyToggleEvent struct {
+	Event  string `json:"event"`
+	Policy string `json:"policy"`
+	Actor  string `json:"actor,omitempty"`
+}
+
+// Disable skips a loaded policy during evaluation until it is enabled again
+// or the next reload. The module file is left alone.
+func (h *PolicyHandler) Disable(c echo.Context) error {
+	return h.setEnabled(c, false)
+}
+
+// Enable undoes Disable
+func (h *PolicyHandler) Enable(c echo.Context) error {
+	return h.setEnabled(c, true)
+}
+
+func (h *PolicyHandler) setEnabled(c echo.Context, enabled bool) error {
+	toggler, ok := h.policy.(policyToggler)
+	if !ok {
+		return c.JSON(http.StatusNotImplemented, map[string]string{
+			"error": "policy evaluator does not support disabling policies",
+		})
+	}
+
+	name := c.Param("name")
+	if err := toggler.SetPolicyEnabled(name, enabled); err != nil {
+		if errors.Is(err, policy.ErrPolicyNotLoaded) {
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		log.Error().Err(err).Str("policy", name).Msg("policy toggle failed")
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "failed to update policy",
+		})
+	}
+
+	event, status := "policy_disabled", "disabled"
+	if enabled {
+		event, status = "policy_enabled", "enabled"
+	}
+	h.record(c, event, name)
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"policy": name,
+		"status": status,
+	})
+}
+
+// record audits a policy being toggled. A failed write is logged but does
+// not undo the change.
+func (h *PolicyHandler) record(c echo.Context, event, name string) {
+	if h.audit == nil {
+		return
+	}
+
+	input, err := json.Marshal(policyToggleEvent{Event: event, Policy: name, Actor: actorID(c)})
+	if err == nil {
+		err = h.audit.Log(c.Request().Context(), input, audit.DecisionAllow, event+": "+name)
+	}
+	if err != nil {
+		log.Warn().Err(err).Str("event", event).Msg("audit logging failed")
+	}
+}
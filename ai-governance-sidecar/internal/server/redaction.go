@@ -0,0 +1,25 @@
+package server
+
+import (
+	"github.com/dagbolade/ai-governance-sidecar/internal/approval"
+	"github.com/dagbolade/ai-governance-sidecar/internal/redaction"
+)
+
+// redactRequest masks secrets in req's arguments for display. The queue
+// keeps the original, which is what gets forwarded once approved.
+func redactRequest(r *redaction.Redactor, req approval.Request) approval.Request {
+	req.Args = r.Args(req.Args)
+	return req
+}
+
+func redactRequests(r *redaction.Redactor, reqs []approval.Request) []approval.Request {
+	if r == nil {
+		return reqs
+	}
+
+	redacted := make([]approval.Request, len(reqs))
+	for i, req := range reqs {
+		redacted[i] = redactRequest(r, req)
+	}
+	return redacted
+}
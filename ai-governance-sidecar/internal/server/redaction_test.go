@@ -0,0 +1,63 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/approval"
+	"github.com/dagbolade/ai-governance-sidecar/internal/auth"
+	"github.com/dagbolade/ai-governance-sidecar/internal/redaction"
+)
+
+func TestPendingPayloadRedactsArgs(t *testing.T) {
+	args := json.RawMessage(`{"user":"bob","db":{"password":"hunter2"},"api_key":"sk-live-123"}`)
+	queue := &pendingListQueue{}
+	queue.set(approval.Request{ID: "req-1", ToolName: "db.connect", Args: args, Status: approval.StatusPending})
+
+	assertRedacted := func(t *testing.T, payload string) {
+		t.Helper()
+		for _, secret := range []string{"hunter2", "sk-live-123"} {
+			if strings.Contains(payload, secret) {
+				t.Errorf("expected %q to be redacted, got %s", secret, payload)
+			}
+		}
+		if !strings.Contains(payload, redaction.Placeholder) || !strings.Contains(payload, `"bob"`) {
+			t.Errorf("expected only secret values masked, got %s", payload)
+		}
+	}
+
+	t.Run("http", func(t *testing.T) {
+		authManager := auth.NewManager(auth.Config{RequireAuth: false, JWTSecret: "test-secret"})
+		srv := New(Config{Port: 8080, ApprovalRedactKeys: redaction.DefaultKeys}, &mockPolicyEvaluator{}, &mockAuditStore{}, queue, authManager)
+
+		rec := httptest.NewRecorder()
+		srv.echo.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/pending", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+		assertRedacted(t, rec.Body.String())
+	})
+
+	t.Run("websocket", func(t *testing.T) {
+		handler := NewWSHandler(queue)
+		handler.minInterval = 0
+		handler.SetRedactor(redaction.New(redaction.DefaultKeys))
+
+		client := &wsClient{send: make(chan []byte, clientSendBuffer)}
+		handler.hub.Register(client, 0)
+		handler.requestPendingBroadcast()
+
+		if len(client.send) != 1 {
+			t.Fatalf("expected a pending broadcast, got %d messages", len(client.send))
+		}
+		assertRedacted(t, string(<-client.send))
+	})
+
+	pending, _ := queue.GetPending(t.Context())
+	if string(pending[0].Args) != string(args) {
+		t.Errorf("expected the queued request to keep its arguments, got %s", pending[0].Args)
+	}
+}
@@ -0,0 +1,118 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/audit"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+)
+
+type ReportHandler struct {
+	store audit.Store
+}
+
+func NewReportHandler(store audit.Store) *ReportHandler {
+	return &ReportHandler{store: store}
+}
+
+// GetApprovers reports each approver's approvals, denials and average
+// decision latency between the optional from and to query parameters.
+func (h *ReportHandler) GetApprovers(c echo.Context) error {
+	reporter, ok := h.store.(audit.ApproverReporter)
+	if !ok {
+		return c.JSON(http.StatusNotImplemented, map[string]string{
+			"error": "approver reports are not supported by this audit store",
+		})
+	}
+
+	from, to, err := parseReportWindow(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	approvers, err := reporter.ApproverReport(c.Request().Context(), from, to)
+	if err != nil {
+		return reportError(c, err, "approver")
+	}
+
+	return c.JSON(http.StatusOK, windowedReport("approvers", approvers, from, to))
+}
+
+// GetDenials reports how many calls were denied in each deny category
+// between the optional from and to query parameters.
+func (h *ReportHandler) GetDenials(c echo.Context) error {
+	reporter, ok := h.store.(audit.DenialReporter)
+	if !ok {
+		return c.JSON(http.StatusNotImplemented, map[string]string{
+			"error": "denial reports are not supported by this audit store",
+		})
+	}
+
+	from, to, err := parseReportWindow(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	denials, err := reporter.DenialReport(c.Request().Context(), from, to)
+	if err != nil {
+		return reportError(c, err, "denial")
+	}
+
+	total := 0
+	for _, row := range denials {
+		total += row.Count
+	}
+
+	report := windowedReport("categories", denials, from, to)
+	report["total"] = total
+	return c.JSON(http.StatusOK, report)
+}
+
+// parseReportWindow reads the optional from and to query parameters
+func parseReportWindow(c echo.Context) (time.Time, time.Time, error) {
+	from, err := parseTimeParam(c, "from")
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	to, err := parseTimeParam(c, "to")
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		return time.Time{}, time.Time{}, errors.New("to must not be before from")
+	}
+	return from, to, nil
+}
+
+func reportError(c echo.Context, err error, kind string) error {
+	if errors.Is(err, audit.ErrStoreUnavailable) {
+		return c.JSON(http.StatusServiceUnavailable, map[string]string{
+			"error": err.Error(),
+		})
+	}
+	log.Error().Err(err).Msgf("failed to build %s report", kind)
+	return c.JSON(http.StatusInternalServerError, map[string]string{
+		"error": fmt.Sprintf("failed to build %s report", kind),
+	})
+}
+
+func windowedReport(key string, rows any, from, to time.Time) map[string]interface{} {
+	report := map[string]interface{}{
+		key: rows,
+	}
+	if !from.IsZero() {
+		report["from"] = from
+	}
+	if !to.IsZero() {
+		report["to"] = to
+	}
+	return report
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/auth"
+	"github.com/labstack/echo/v4"
+)
+
+func TestSecurityHeaders(t *testing.T) {
+	authManager := auth.NewManager(auth.Config{RequireAuth: false, JWTSecret: "test-secret"})
+	srv := New(Config{
+		Port:            8080,
+		SecurityHeaders: true,
+		HSTSMaxAge:      3600,
+		UICSP:           "default-src 'self'",
+	}, &mockPolicyEvaluator{}, &mockAuditStore{}, &mockApprovalQueue{}, authManager)
+
+	request := func(path string, https bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if https {
+			req.Header.Set(echo.HeaderXForwardedProto, "https")
+		}
+		rec := httptest.NewRecorder()
+		srv.echo.ServeHTTP(rec, req)
+		return rec
+	}
+
+	tests := []struct {
+		name      string
+		path      string
+		https     bool
+		expectCSP string
+		expectSTS string
+	}{
+		{name: "api over https", path: "/health", https: true, expectCSP: apiCSP, expectSTS: "max-age=3600; includeSubdomains"},
+		{name: "api over http", path: "/health", expectCSP: apiCSP},
+		{name: "ui", path: "/ui", https: true, expectCSP: "default-src 'self'", expectSTS: "max-age=3600; includeSubdomains"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := request(tt.path, tt.https)
+			headers := rec.Header()
+
+			if got := headers.Get(echo.HeaderXContentTypeOptions); got != "nosniff" {
+				t.Errorf("expected X-Content-Type-Options nosniff, got %q", got)
+			}
+			if got := headers.Get(echo.HeaderXFrameOptions); got != "DENY" {
+				t.Errorf("expected X-Frame-Options DENY, got %q", got)
+			}
+			if got := headers.Get(echo.HeaderContentSecurityPolicy); got != tt.expectCSP {
+				t.Errorf("expected CSP %q, got %q", tt.expectCSP, got)
+			}
+			if got := headers.Get(echo.HeaderStrictTransportSecurity); got != tt.expectSTS {
+				t.Errorf("expected HSTS %q, got %q", tt.expectSTS, got)
+			}
+			if got := headers.Get(echo.HeaderXXSSProtection); got != "" {
+				t.Errorf("expected no X-XSS-Protection, got %q", got)
+			}
+		})
+	}
+}
+
+func TestSecurityHeadersDisabled(t *testing.T) {
+	authManager := auth.NewManager(auth.Config{RequireAuth: false, JWTSecret: "test-secret"})
+	srv := New(Config{Port: 8080}, &mockPolicyEvaluator{}, &mockAuditStore{}, &mockApprovalQueue{}, authManager)
+
+	rec := httptest.NewRecorder()
+	srv.echo.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	if got := rec.Header().Get(echo.HeaderContentSecurityPolicy); got != "" {
+		t.Errorf("expected no CSP with security headers off, got %q", got)
+	}
+}
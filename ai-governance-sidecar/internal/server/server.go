@@ -4,29 +4,115 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/dagbolade/ai-governance-sidecar/internal/approval"
 	"github.com/dagbolade/ai-governance-sidecar/internal/audit"
-	"github.com/dagbolade/ai-governance-sidecar/internal/auth" 
+	"github.com/dagbolade/ai-governance-sidecar/internal/auth"
 	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
 	"github.com/dagbolade/ai-governance-sidecar/internal/proxy"
+	"github.com/dagbolade/ai-governance-sidecar/internal/redaction"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/rs/zerolog/log"
 )
 
 type Server struct {
-	echo   *echo.Echo
-	config Config
+	echo     *echo.Echo
+	config   Config
+	hub      *Hub
+	audit    audit.Store
+	approval approval.Queue
+	policy   policy.Evaluator
+	proxy    *proxy.Handler
 }
 
 type Config struct {
-	Port            int
-	ReadTimeout     int
-	WriteTimeout    int
+	Port         int
+	ReadTimeout  int // seconds to read a whole request, headers and body
+	WriteTimeout int
+	// ReadHeaderTimeout cuts off clients that trickle request headers
+	ReadHeaderTimeout int // seconds
+	// IdleTimeout closes keep-alive connections with no new request
+	IdleTimeout     int // seconds
 	ShutdownTimeout int
-	ProxyConfig     proxy.ProxyConfig
+	DBPath          string
+	AuditRequired   bool // fail startup when the audit store cannot be opened
+	// AuditSigningKeyFile holds the base64 Ed25519 key that signs audit
+	// bundles; a random per-process key is used when empty
+	AuditSigningKeyFile string
+	// AuditSignEntries signs each audit row at insert with that key
+	AuditSignEntries bool
+	// AuditMaxToolInput is the largest tool_input or detail stored whole, in
+	// bytes; larger ones are kept as a truncation marker. Zero is no limit
+	AuditMaxToolInput int
+	ApprovalTimeout   int // seconds
+	// ApprovalSLA flags requests pending longer than this; zero disables
+	ApprovalSLA int // seconds
+	// ApprovalMaxPendingAge auto-denies requests pending longer than this;
+	// zero disables
+	ApprovalMaxPendingAge int // seconds
+	// ApprovalWebhookURL is consulted before queueing for a human
+	ApprovalWebhookURL     string
+	ApprovalWebhookTimeout int // seconds
+	// ApprovalNotifyURL is posted each new approval request in the
+	// background, retried ApprovalNotifyAttempts times with exponential
+	// backoff starting at ApprovalNotifyBackoff
+	ApprovalNotifyURL      string
+	ApprovalNotifyAttempts int
+	ApprovalNotifyBackoff  int // seconds
+	// ApprovalNotifyMode is approval.NotifyModeEvent, one post per request,
+	// or approval.NotifyModeDigest, one summary per ApprovalDigestInterval
+	ApprovalNotifyMode     string
+	ApprovalDigestInterval int // seconds
+	MaxReasonLength        int
+	// ApprovalOverflow is OverflowReject or OverflowTruncate for approval
+	// reasons and comments over MaxReasonLength
+	ApprovalOverflow string
+	// ApprovalDedup merges a request from the same caller whose tool,
+	// arguments, metadata and files match one already pending into it, so a
+	// single decision answers both calls
+	ApprovalDedup bool
+	CORSOrigins   []string
+	// UICORSOrigins applies to /ui assets instead of CORSOrigins
+	UICORSOrigins []string
+	// CORSMaxAge is how many seconds browsers may cache an API preflight
+	CORSMaxAge int
+	// SecurityHeaders adds nosniff, frame, HSTS and CSP headers to every
+	// response. HSTSMaxAge is in seconds; zero leaves HSTS off. UICSP
+	// replaces the default Content-Security-Policy for /ui.
+	SecurityHeaders bool
+	HSTSMaxAge      int
+	UICSP           string
+	// WSCompression negotiates per-message deflate on /ws
+	WSCompression bool
+	// WSMaxConnections caps concurrent /ws clients; zero is unlimited
+	WSMaxConnections int
+	// SimulationEnabled turns on POST /approvals/simulate, which decides
+	// pending approvals without a human for load tests. Never set it in
+	// production.
+	SimulationEnabled bool
+	// ApprovalRedactKeys are the argument key fragments masked in approval
+	// requests served to approvers, over HTTP and /ws; empty shows
+	// arguments as queued
+	ApprovalRedactKeys []string
+
+	// TLSCertFile and TLSKeyFile serve HTTPS instead of HTTP. With
+	// TLSClientCAFile, client certificates signed by that CA are verified
+	// and TLSClientAuth (ClientAuthRequire or ClientAuthOptional) decides
+	// whether clients without one are refused.
+	TLSCertFile     string
+	TLSKeyFile      string
+	TLSClientCAFile string
+	TLSClientAuth   string
+
+	ProxyConfig  proxy.ProxyConfig
+	PolicyConfig policy.Config
+	AuthConfig   auth.Config
+
+	// envErr holds the settings LoadConfig could not parse
+	envErr error
 }
 
 func New(cfg Config, pol policy.Evaluator, aud audit.Store, appr approval.Queue, authManager *auth.Manager) *Server {
@@ -35,9 +121,13 @@ func New(cfg Config, pol policy.Evaluator, aud audit.Store, appr approval.Queue,
 	e.HidePort = true
 
 	s := &Server{
-		echo:   e,
-		config: cfg,
+		echo:     e,
+		config:   cfg,
+		audit:    aud,
+		approval: appr,
+		policy:   pol,
 	}
+	e.HTTPErrorHandler = s.handleError
 
 	s.setupMiddleware()
 	s.setupRoutes(pol, aud, appr, authManager)
@@ -47,18 +137,44 @@ func New(cfg Config, pol policy.Evaluator, aud audit.Store, appr approval.Queue,
 
 func (s *Server) Start() error {
 	addr := fmt.Sprintf(":%d", s.config.Port)
-	log.Info().Int("port", s.config.Port).Msg("starting HTTP server")
 
-	s.echo.Server.ReadTimeout = time.Duration(s.config.ReadTimeout) * time.Second
-	s.echo.Server.WriteTimeout = time.Duration(s.config.WriteTimeout) * time.Second
+	tlsConfig, err := s.config.TLSConfig()
+	if err != nil {
+		return err
+	}
+
+	s.configureHTTPServer()
 
-	if err := s.echo.Start(addr); err != nil && err != http.ErrServerClosed {
+	if tlsConfig == nil {
+		log.Info().Int("port", s.config.Port).Msg("starting HTTP server")
+		err = s.echo.Start(addr)
+	} else {
+		log.Info().Int("port", s.config.Port).Bool("client_certs", tlsConfig.ClientCAs != nil).Msg("starting HTTPS server")
+		err = s.startTLS(addr, tlsConfig)
+	}
+	if err != nil && err != http.ErrServerClosed {
 		return fmt.Errorf("server failed: %w", err)
 	}
 
 	return nil
 }
 
+// ServeHTTP runs a request through the full middleware and route stack
+// without a listener, for in-process checks such as the startup self-test
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.echo.ServeHTTP(w, r)
+}
+
+// configureHTTPServer bounds how long a client may take to send a request.
+// Long approval waits happen after the request is read and are governed by
+// the request context, not these deadlines.
+func (s *Server) configureHTTPServer() {
+	s.echo.Server.ReadTimeout = time.Duration(s.config.ReadTimeout) * time.Second
+	s.echo.Server.ReadHeaderTimeout = time.Duration(s.config.ReadHeaderTimeout) * time.Second
+	s.echo.Server.WriteTimeout = time.Duration(s.config.WriteTimeout) * time.Second
+	s.echo.Server.IdleTimeout = time.Duration(s.config.IdleTimeout) * time.Second
+}
+
 func (s *Server) Shutdown(ctx context.Context) error {
 	log.Info().Msg("shutting down server")
 
@@ -69,6 +185,10 @@ func (s *Server) Shutdown(ctx context.Context) error {
 		return fmt.Errorf("shutdown failed: %w", err)
 	}
 
+	if err := s.proxy.Close(); err != nil {
+		log.Warn().Err(err).Msg("failed to close upstream connections")
+	}
+
 	return nil
 }
 
@@ -91,40 +211,117 @@ func (s *Server) setupMiddleware() {
 
 	s.echo.Use(middleware.Recover())
 
+	s.useSecurityHeaders()
+
+	// The API and the static UI assets get separate CORS policies
 	s.echo.Use(middleware.CORSWithConfig(middleware.CORSConfig{
-		AllowOrigins: []string{"*"},
-		AllowMethods: []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete},
-		AllowHeaders: []string{"Content-Type", "Authorization"},
+		Skipper:          isUIPath,
+		AllowOrigins:     s.corsOrigins(),
+		AllowMethods:     apiCORSMethods,
+		AllowHeaders:     []string{"Content-Type", "Authorization", "If-None-Match", proxy.HeaderDryRun, proxy.HeaderResponseFormat},
+		ExposeHeaders:    []string{"ETag"},
 		AllowCredentials: true,
+		MaxAge:           s.config.CORSMaxAge,
+	}))
+
+	s.echo.Use(middleware.CORSWithConfig(middleware.CORSConfig{
+		Skipper:      func(c echo.Context) bool { return !isUIPath(c) },
+		AllowOrigins: s.uiCORSOrigins(),
+		AllowMethods: []string{http.MethodGet, http.MethodHead},
 	}))
 }
 
+// apiCORSMethods covers every verb the API routes and the proxy's upstream
+// methods use
+var apiCORSMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodOptions,
+}
+
+func isUIPath(c echo.Context) bool {
+	path := c.Request().URL.Path
+	return path == "/ui" || strings.HasPrefix(path, "/ui/")
+}
+
 func (s *Server) setupRoutes(pol policy.Evaluator, aud audit.Store, appr approval.Queue, authManager *auth.Manager) {
-	proxyHandler := proxy.NewHandler(s.config.ProxyConfig, pol, aud, appr)
+	// Writes go through the observer so /audit/stream sees them as they land
+	observed := audit.NewObserver(aud)
+	proxyHandler := proxy.NewHandler(s.config.ProxyConfig, pol, observed, appr)
+	s.proxy = proxyHandler
 	auditHandler := NewAuditHandler(aud)
-	approvalHandler := NewApprovalHandler(appr)
+	auditHandler.signingKey = loadAuditSigningKey(s.config.AuditSigningKeyFile)
+	auditHandler.observer = observed
+	redactor := redaction.New(s.config.ApprovalRedactKeys)
+	approvalHandler := NewApprovalHandler(appr, s.config.MaxReasonLength, s.config.ApprovalOverflow)
+	approvalHandler.simulation = s.config.SimulationEnabled
+	approvalHandler.auth = authManager
+	approvalHandler.audit = observed
+	approvalHandler.redactor = redactor
+	if s.config.SimulationEnabled {
+		log.Warn().Msg("SIMULATION_ENABLED is set: POST /approvals/simulate decides approvals without a human")
+	}
+	policyHandler := NewPolicyHandler(pol)
+	policyHandler.audit = observed
+	reportHandler := NewReportHandler(aud)
 	wsHandler := NewWSHandler(appr)
+	wsHandler.EnableCompression(s.config.WSCompression)
+	wsHandler.SetMaxConnections(s.config.WSMaxConnections)
+	wsHandler.SetRedactor(redactor)
+	s.hub = wsHandler.hub
 	authHandler := auth.NewHandler(authManager)
+	delegationHandler := NewDelegationHandler(authManager, observed)
+	uiHandler := NewUIHandler(embeddedUI())
 
 	// Public endpoints (no auth required)
 	s.echo.GET("/health", s.handleHealth)
-	s.echo.POST("/login", authHandler.Login) 
+	s.echo.GET("/ready", s.handleReady)
+	s.echo.POST("/login", authHandler.Login)
 
 	// Apply auth middleware to protected routes
 	protected := s.echo.Group("")
 	protected.Use(authManager.Middleware())
-	
+
 	// Protected endpoints
 	protected.GET("/me", authHandler.Me)
+	protected.GET("/auth/introspect", authHandler.Introspect)
+	protected.POST("/auth/reload", authHandler.ReloadUsers, authManager.RequireRole(auth.RoleAdmin))
+	protected.GET("/auth/delegations", delegationHandler.List, authManager.RequireRole(auth.RoleAdmin))
+	protected.POST("/auth/delegations", delegationHandler.Grant, authManager.RequireRole(auth.RoleAdmin))
+	protected.DELETE("/auth/delegations/:id", delegationHandler.Revoke, authManager.RequireRole(auth.RoleAdmin))
 	protected.POST("/tool/call", proxyHandler.HandleToolCall)
+	protected.POST("/tool/call/batch", proxyHandler.HandleBatch)
+	protected.POST("/tool/call/:audit_id/appeal", proxyHandler.HandleAppeal)
+	protected.GET("/tools", proxyHandler.HandleListTools)
 	protected.GET("/audit", auditHandler.GetAuditLog)
+	protected.GET("/audit/bundle", auditHandler.GetBundle, authManager.RequireRole(auth.RoleAdmin))
+	protected.GET("/audit/public-key", auditHandler.GetPublicKey)
+	protected.GET("/audit/stream", auditHandler.Stream, authManager.RequireRole(auth.RoleAdmin))
 	protected.GET("/pending", approvalHandler.GetPending)
-	protected.POST("/approve/:id", approvalHandler.Decide)
+	protected.POST("/approve/:id", approvalHandler.Decide, authManager.RequireAnyRoleWhenAuth(auth.RoleAdmin, auth.RoleApprover))
+	protected.POST("/approvals/:id/comment", approvalHandler.AddComment)
+	protected.GET("/approvals/:id/events", approvalHandler.GetEvents)
+	protected.GET("/approvals/dead-letters", approvalHandler.GetDeadLetters, authManager.RequireRole(auth.RoleAdmin))
+	protected.POST("/approvals/simulate", approvalHandler.Simulate, authManager.RequireRole(auth.RoleAdmin))
+	protected.GET("/reports/approvers", reportHandler.GetApprovers, authManager.RequireRole(auth.RoleAdmin))
+	protected.GET("/reports/denials", reportHandler.GetDenials, authManager.RequireRole(auth.RoleAdmin))
 	protected.GET("/ws", wsHandler.HandleWebSocket)
-	
+	protected.GET("/metrics", s.handleMetrics)
+	protected.GET("/config", s.handleConfig, authManager.RequireRole(auth.RoleAdmin))
+	protected.GET("/policies", policyHandler.List)
+	protected.GET("/policies/schema", policyHandler.Schema)
+	protected.POST("/policies/reload", policyHandler.Reload, authManager.RequireRole(auth.RoleAdmin))
+	protected.POST("/policies/:name/disable", policyHandler.Disable, authManager.RequireRole(auth.RoleAdmin))
+	protected.POST("/policies/:name/enable", policyHandler.Enable, authManager.RequireRole(auth.RoleAdmin))
+	protected.POST("/debug/evaluate", proxyHandler.HandleDebugEvaluate, authManager.RequireRole(auth.RoleAdmin))
+
 	// UI routes
-	protected.GET("/ui", s.handleUI)
-	protected.GET("/ui/*", s.handleUI)
+	protected.GET("/ui", uiHandler.Serve)
+	protected.GET("/ui/*", uiHandler.Serve)
 }
 
 func (s *Server) handleHealth(c echo.Context) error {
@@ -133,29 +330,72 @@ func (s *Server) handleHealth(c echo.Context) error {
 	})
 }
 
-func (s *Server) handleUI(c echo.Context) error {
-	// TODO: Serve embedded React UI
-	return c.HTML(http.StatusOK, `
-		<!DOCTYPE html>
-		<html>
-		<head>
-			<title>AI Governance Sidecar</title>
-			<meta charset="UTF-8">
-			<meta name="viewport" content="width=device-width, initial-scale=1.0">
-		</head>
-		<body>
-			<div id="root">
-				<h1>AI Governance Sidecar</h1>
-				<p>UI is being built. For now, use the API endpoints:</p>
-				<ul>
-					<li>POST /login - Login to get JWT token</li>
-					<li>GET /me - Get current user info</li>
-					<li>GET /pending - View pending approvals (auth required)</li>
-					<li>POST /approve/:id - Approve/deny requests (auth required)</li>
-					<li>GET /audit - View audit log (auth required)</li>
-				</ul>
-			</div>
-		</body>
-		</html>
-	`)
-}
\ No newline at end of file
+// availabilityReporter is implemented by audit stores that can run degraded
+type availabilityReporter interface {
+	Available() bool
+}
+
+// watcherHealthReporter is implemented by evaluators that hot-reload policies
+type watcherHealthReporter interface {
+	WatcherHealthy() bool
+}
+
+// staleReporter is implemented by evaluators that keep serving the last
+// good policies after a failed reload or bundle refresh
+type staleReporter interface {
+	Stale() bool
+}
+
+// bundleStatusReporter is implemented by evaluators that can load policies
+// from a remote bundle
+type bundleStatusReporter interface {
+	BundleStatus() (policy.BundleStatus, bool)
+}
+
+func (s *Server) handleReady(c echo.Context) error {
+	if reporter, ok := s.audit.(availabilityReporter); ok && !reporter.Available() {
+		return c.JSON(http.StatusServiceUnavailable, map[string]string{
+			"status": "degraded",
+			"audit":  "unavailable",
+		})
+	}
+
+	status := map[string]string{
+		"status": "ready",
+	}
+
+	// Loaded policies keep evaluating while the watcher recovers, so a lost
+	// watch is reported without failing readiness
+	if reporter, ok := s.policy.(watcherHealthReporter); ok && !reporter.WatcherHealthy() {
+		status["policy_watcher"] = "recovering"
+	}
+
+	// Stale policies still evaluate, so staleness is reported the same way
+	if reporter, ok := s.policy.(staleReporter); ok && reporter.Stale() {
+		status["policy"] = "stale"
+	}
+	if reporter, ok := s.policy.(bundleStatusReporter); ok {
+		if bundle, ok := reporter.BundleStatus(); ok {
+			status["policy_refreshed_at"] = bundle.LastRefresh.UTC().Format(time.RFC3339)
+			if bundle.Error != "" {
+				status["policy_refresh_error"] = bundle.Error
+			}
+		}
+	}
+
+	return c.JSON(http.StatusOK, status)
+}
+
+func (s *Server) corsOrigins() []string {
+	if len(s.config.CORSOrigins) == 0 {
+		return []string{"*"}
+	}
+	return s.config.CORSOrigins
+}
+
+func (s *Server) uiCORSOrigins() []string {
+	if len(s.config.UICORSOrigins) == 0 {
+		return []string{"*"}
+	}
+	return s.config.UICORSOrigins
+}
@@ -1,10 +1,19 @@
 package server
 
 import (
+	"bufio"
 	"context"
+	"crypto/ed25519"
+	"encoding/base64"
 	"encoding/json"
+	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -13,6 +22,7 @@ import (
 	"github.com/dagbolade/ai-governance-sidecar/internal/auth"
 	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
 	"github.com/dagbolade/ai-governance-sidecar/internal/proxy"
+	"github.com/labstack/echo/v4"
 )
 
 type mockPolicyEvaluator struct{}
@@ -180,4 +190,660 @@ func TestServerShutdown(t *testing.T) {
 	if err := srv.Shutdown(ctx); err != nil {
 		t.Errorf("shutdown failed: %v", err)
 	}
-}
\ No newline at end of file
+}
+func TestConfigEndpointMasksSecrets(t *testing.T) {
+	cfg := Config{
+		Port:            8080,
+		ReadTimeout:     30,
+		ShutdownTimeout: 10,
+		PolicyConfig:    policy.Config{Dir: "/app/policies"},
+		ApprovalTimeout: 300,
+		CORSOrigins:     []string{"https://dashboard.example.com"},
+		ProxyConfig: proxy.ProxyConfig{
+			DefaultUpstream: "http://localhost:9000",
+			Timeout:         30,
+		},
+		AuthConfig: auth.Config{
+			JWTSecret:   "super-secret-value",
+			RequireAuth: true,
+		},
+	}
+
+	mockAuthManager := auth.NewManager(cfg.AuthConfig)
+	srv := New(cfg, &mockPolicyEvaluator{}, &mockAuditStore{}, &mockApprovalQueue{}, mockAuthManager)
+
+	req := asAdmin(t, mockAuthManager, httptest.NewRequest(http.MethodGet, "/config", nil))
+	rec := httptest.NewRecorder()
+
+	srv.echo.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+
+	body := rec.Body.String()
+	if strings.Contains(body, "super-secret-value") {
+		t.Fatal("config response leaked JWT secret")
+	}
+
+	var response effectiveConfig
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+
+	if response.Auth.JWTSecret != redactedValue {
+		t.Errorf("expected masked secret, got '%s'", response.Auth.JWTSecret)
+	}
+	if response.Server.Port != 8080 {
+		t.Errorf("expected port 8080, got %d", response.Server.Port)
+	}
+	if response.Policy.Dir != "/app/policies" {
+		t.Errorf("expected policy dir '/app/policies', got '%s'", response.Policy.Dir)
+	}
+	if response.Approval.Timeout != 300 {
+		t.Errorf("expected approval timeout 300, got %d", response.Approval.Timeout)
+	}
+	if len(response.Server.CORSOrigins) != 1 || response.Server.CORSOrigins[0] != "https://dashboard.example.com" {
+		t.Errorf("unexpected CORS origins: %v", response.Server.CORSOrigins)
+	}
+}
+
+func TestConfigEndpointRequiresAdmin(t *testing.T) {
+	cfg := Config{
+		Port: 8080,
+		AuthConfig: auth.Config{
+			JWTSecret:   "test-secret",
+			RequireAuth: true,
+		},
+	}
+
+	authManager := auth.NewManager(cfg.AuthConfig)
+	srv := New(cfg, &mockPolicyEvaluator{}, &mockAuditStore{}, &mockApprovalQueue{}, authManager)
+
+	token, err := authManager.GenerateToken(auth.User{ID: "viewer", Email: "viewer@example.com", Roles: []string{auth.RoleViewer}})
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/config", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+
+	srv.echo.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected status 403, got %d", rec.Code)
+	}
+}
+
+func TestAdminRouteRequiresAuthentication(t *testing.T) {
+	for _, requireAuth := range []bool{true, false} {
+		authManager := auth.NewManager(auth.Config{RequireAuth: requireAuth, JWTSecret: "test-secret"})
+		srv := New(Config{Port: 8080}, &mockPolicyEvaluator{}, &mockAuditStore{}, &mockApprovalQueue{}, authManager)
+
+		rec := httptest.NewRecorder()
+		srv.echo.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/config", nil))
+
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("RequireAuth=%v: expected status 401 without a token, got %d", requireAuth, rec.Code)
+		}
+	}
+}
+
+// asAdmin sends req with a token for an admin user issued by m
+func asAdmin(t *testing.T, m *auth.Manager, req *http.Request) *http.Request {
+	t.Helper()
+	token, err := m.GenerateToken(auth.User{ID: "admin", Email: "admin@example.com", Roles: []string{auth.RoleAdmin}})
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+	req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+	return req
+}
+
+func TestMetricsEndpoint(t *testing.T) {
+	cfg := Config{Port: 8080}
+	mockAuthManager := auth.NewManager(auth.Config{RequireAuth: false, JWTSecret: "test-secret"})
+	srv := New(cfg, &mockPolicyEvaluator{}, &mockAuditStore{}, &mockApprovalQueue{}, mockAuthManager)
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	rec := httptest.NewRecorder()
+
+	srv.echo.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+
+	var response map[string]map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+
+	if _, ok := response["websocket"]["connected_clients"]; !ok {
+		t.Error("expected websocket.connected_clients in metrics")
+	}
+}
+
+func TestAuditBundleEndpoint(t *testing.T) {
+	store := &mockAuditStore{}
+	store.Log(context.Background(), json.RawMessage(`{"tool_name":"a"}`), audit.DecisionAllow, "ok")
+	store.Log(context.Background(), json.RawMessage(`{"tool_name":"b"}`), audit.DecisionDeny, "blocked")
+
+	mockAuthManager := auth.NewManager(auth.Config{RequireAuth: true, JWTSecret: "test-secret"})
+	srv := New(Config{Port: 8080}, &mockPolicyEvaluator{}, store, &mockApprovalQueue{}, mockAuthManager)
+
+	rec := httptest.NewRecorder()
+	srv.echo.ServeHTTP(rec, asAdmin(t, mockAuthManager, httptest.NewRequest(http.MethodGet, "/audit/public-key", nil)))
+	var key struct {
+		PublicKey string `json:"public_key"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &key); err != nil {
+		t.Fatalf("failed to parse public key: %v", err)
+	}
+	pub, err := base64.StdEncoding.DecodeString(key.PublicKey)
+	if err != nil {
+		t.Fatalf("failed to decode public key: %v", err)
+	}
+
+	rec = httptest.NewRecorder()
+	srv.echo.ServeHTTP(rec, asAdmin(t, mockAuthManager, httptest.NewRequest(http.MethodGet, "/audit/bundle", nil)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	if !strings.HasPrefix(rec.Header().Get(echo.HeaderContentDisposition), "attachment;") {
+		t.Errorf("expected download, got Content-Disposition %q", rec.Header().Get(echo.HeaderContentDisposition))
+	}
+
+	var bundle audit.Bundle
+	if err := json.Unmarshal(rec.Body.Bytes(), &bundle); err != nil {
+		t.Fatalf("failed to parse bundle: %v", err)
+	}
+	if len(bundle.Entries) != 2 {
+		t.Errorf("expected 2 entries, got %d", len(bundle.Entries))
+	}
+	if err := audit.VerifyBundle(bundle, ed25519.PublicKey(pub)); err != nil {
+		t.Errorf("expected bundle to verify against published key, got %v", err)
+	}
+}
+
+func TestSlowClientIsDisconnected(t *testing.T) {
+	cfg := Config{Port: 8080, ReadTimeout: 5, ReadHeaderTimeout: 1}
+	mockAuthManager := auth.NewManager(auth.Config{RequireAuth: false, JWTSecret: "test-secret"})
+	srv := New(cfg, &mockPolicyEvaluator{}, &mockAuditStore{}, &mockApprovalQueue{}, mockAuthManager)
+	srv.configureHTTPServer()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+	httpServer := srv.echo.Server
+	httpServer.Handler = srv.echo
+	go httpServer.Serve(ln)
+	defer httpServer.Close()
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	defer conn.Close()
+
+	// Send part of the headers and then stall, slowloris style
+	if _, err := conn.Write([]byte("GET /health HTTP/1.1\r\nHost: localhost\r\n")); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	start := time.Now()
+	conn.SetReadDeadline(time.Now().Add(4 * time.Second))
+	if _, err := io.ReadAll(conn); err != nil {
+		t.Fatalf("expected server to close the connection, got %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("expected slow client cut off after the header timeout, took %v", elapsed)
+	}
+}
+
+type degradedAuditStore struct {
+	mockAuditStore
+	available bool
+}
+
+func (m *degradedAuditStore) Available() bool { return m.available }
+
+func TestReadyEndpoint(t *testing.T) {
+	tests := []struct {
+		name         string
+		store        audit.Store
+		expectStatus int
+	}{
+		{name: "plain store", store: &mockAuditStore{}, expectStatus: http.StatusOK},
+		{name: "recovered store", store: &degradedAuditStore{available: true}, expectStatus: http.StatusOK},
+		{name: "degraded store", store: &degradedAuditStore{}, expectStatus: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAuthManager := auth.NewManager(auth.Config{RequireAuth: true, JWTSecret: "test-secret"})
+			srv := New(Config{Port: 8080}, &mockPolicyEvaluator{}, tt.store, &mockApprovalQueue{}, mockAuthManager)
+
+			req := httptest.NewRequest(http.MethodGet, "/ready", nil)
+			rec := httptest.NewRecorder()
+
+			srv.echo.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectStatus {
+				t.Errorf("expected status %d, got %d", tt.expectStatus, rec.Code)
+			}
+		})
+	}
+}
+
+type watchingEvaluator struct {
+	mockPolicyEvaluator
+	healthy bool
+}
+
+func (m *watchingEvaluator) WatcherHealthy() bool { return m.healthy }
+
+func TestReadyReportsPolicyWatcher(t *testing.T) {
+	mockAuthManager := auth.NewManager(auth.Config{RequireAuth: true, JWTSecret: "test-secret"})
+	srv := New(Config{Port: 8080}, &watchingEvaluator{}, &mockAuditStore{}, &mockApprovalQueue{}, mockAuthManager)
+
+	req := httptest.NewRequest(http.MethodGet, "/ready", nil)
+	rec := httptest.NewRecorder()
+
+	srv.echo.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200 while the watcher recovers, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), `"policy_watcher":"recovering"`) {
+		t.Errorf("expected watcher state in body, got %s", rec.Body.String())
+	}
+}
+
+type failingReloadEvaluator struct {
+	mockPolicyEvaluator
+	err error
+}
+
+func (m *failingReloadEvaluator) Reload() error { return m.err }
+
+func TestPolicyReloadEndpoint(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		expectStatus int
+	}{
+		{name: "success", expectStatus: http.StatusOK},
+		{name: "too many policies", err: fmt.Errorf("%w: found 5, limit is 2", policy.ErrTooManyPolicies), expectStatus: http.StatusUnprocessableEntity},
+		{name: "other failure", err: fmt.Errorf("read directory: denied"), expectStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAuthManager := auth.NewManager(auth.Config{RequireAuth: true, JWTSecret: "test-secret"})
+			srv := New(Config{Port: 8080}, &failingReloadEvaluator{err: tt.err}, &mockAuditStore{}, &mockApprovalQueue{}, mockAuthManager)
+
+			req := asAdmin(t, mockAuthManager, httptest.NewRequest(http.MethodPost, "/policies/reload", nil))
+			rec := httptest.NewRecorder()
+
+			srv.echo.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectStatus {
+				t.Errorf("expected status %d, got %d", tt.expectStatus, rec.Code)
+			}
+			if tt.expectStatus == http.StatusUnprocessableEntity && !strings.Contains(rec.Body.String(), "limit is 2") {
+				t.Errorf("expected limit in response, got %s", rec.Body.String())
+			}
+		})
+	}
+}
+
+type togglingEvaluator struct {
+	mockPolicyEvaluator
+	disabled map[string]bool
+}
+
+func (m *togglingEvaluator) SetPolicyEnabled(name string, enabled bool) error {
+	if name != "sensitive_data" {
+		return fmt.Errorf("%w: %s", policy.ErrPolicyNotLoaded, name)
+	}
+	m.disabled[name] = !enabled
+	return nil
+}
+
+func TestPolicyDisableEndpoint(t *testing.T) {
+	mockAuthManager := auth.NewManager(auth.Config{RequireAuth: true, JWTSecret: "test-secret"})
+	evaluator := &togglingEvaluator{disabled: map[string]bool{}}
+	store := &mockAuditStore{}
+	srv := New(Config{Port: 8080}, evaluator, store, &mockApprovalQueue{}, mockAuthManager)
+
+	rec := httptest.NewRecorder()
+	srv.echo.ServeHTTP(rec, asAdmin(t, mockAuthManager, httptest.NewRequest(http.MethodPost, "/policies/sensitive_data/disable", nil)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if !evaluator.disabled["sensitive_data"] {
+		t.Error("expected policy to be disabled")
+	}
+	if len(store.entries) != 1 || !strings.Contains(string(store.entries[0].ToolInput), `"policy_disabled"`) {
+		t.Errorf("expected a policy_disabled audit entry, got %+v", store.entries)
+	}
+
+	rec = httptest.NewRecorder()
+	srv.echo.ServeHTTP(rec, asAdmin(t, mockAuthManager, httptest.NewRequest(http.MethodPost, "/policies/sensitive_data/enable", nil)))
+	if rec.Code != http.StatusOK || evaluator.disabled["sensitive_data"] {
+		t.Errorf("expected policy to be enabled, got status %d", rec.Code)
+	}
+	if len(store.entries) != 2 {
+		t.Errorf("expected enable to be audited, got %d entries", len(store.entries))
+	}
+
+	rec = httptest.NewRecorder()
+	srv.echo.ServeHTTP(rec, asAdmin(t, mockAuthManager, httptest.NewRequest(http.MethodPost, "/policies/unknown/disable", nil)))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 for unknown policy, got %d", rec.Code)
+	}
+}
+
+func TestApproverReportEndpoint(t *testing.T) {
+	store, err := audit.NewSQLiteStore(filepath.Join(t.TempDir(), "audit.db"))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	store.LogApproval(ctx, json.RawMessage(`{}`), audit.DecisionAllow, "approved", "alice@example.com", 3*time.Second)
+	store.LogApproval(ctx, json.RawMessage(`{}`), audit.DecisionDeny, "rejected", "alice@example.com", time.Second)
+
+	mockAuthManager := auth.NewManager(auth.Config{RequireAuth: true, JWTSecret: "test-secret"})
+	srv := New(Config{Port: 8080}, &mockPolicyEvaluator{}, store, &mockApprovalQueue{}, mockAuthManager)
+
+	from := url.QueryEscape(time.Now().Add(-time.Hour).Format(time.RFC3339))
+	rec := httptest.NewRecorder()
+	srv.echo.ServeHTTP(rec, asAdmin(t, mockAuthManager, httptest.NewRequest(http.MethodGet, "/reports/approvers?from="+from, nil)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var report struct {
+		Approvers []audit.ApproverStats `json:"approvers"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+		t.Fatalf("failed to parse report: %v", err)
+	}
+	want := audit.ApproverStats{Approver: "alice@example.com", Approvals: 1, Denials: 1, AvgLatencyMs: 2000}
+	if len(report.Approvers) != 1 || report.Approvers[0] != want {
+		t.Errorf("expected %+v, got %+v", want, report.Approvers)
+	}
+
+	rec = httptest.NewRecorder()
+	srv.echo.ServeHTTP(rec, asAdmin(t, mockAuthManager, httptest.NewRequest(http.MethodGet, "/reports/approvers?from=yesterday", nil)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for invalid from, got %d", rec.Code)
+	}
+
+	// The in-memory test store cannot aggregate
+	srv = New(Config{Port: 8080}, &mockPolicyEvaluator{}, &mockAuditStore{}, &mockApprovalQueue{}, mockAuthManager)
+	rec = httptest.NewRecorder()
+	srv.echo.ServeHTTP(rec, asAdmin(t, mockAuthManager, httptest.NewRequest(http.MethodGet, "/reports/approvers", nil)))
+	if rec.Code != http.StatusNotImplemented {
+		t.Errorf("expected 501 without reporting support, got %d", rec.Code)
+	}
+}
+
+func TestDenialReportEndpoint(t *testing.T) {
+	store, err := audit.NewSQLiteStore(filepath.Join(t.TempDir(), "audit.db"))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	store.Log(audit.WithDenyCategory(ctx, audit.DenySSRFBlock), json.RawMessage(`{}`), audit.DecisionDeny, "blocked")
+	store.Log(audit.WithDenyCategory(ctx, audit.DenyApprovalTimeout), json.RawMessage(`{}`), audit.DecisionDeny, "timed out")
+	store.Log(audit.WithDenyCategory(ctx, audit.DenyApprovalTimeout), json.RawMessage(`{}`), audit.DecisionDeny, "timed out")
+
+	mockAuthManager := auth.NewManager(auth.Config{RequireAuth: true, JWTSecret: "test-secret"})
+	srv := New(Config{Port: 8080}, &mockPolicyEvaluator{}, store, &mockApprovalQueue{}, mockAuthManager)
+
+	from := url.QueryEscape(time.Now().Add(-time.Hour).Format(time.RFC3339))
+	rec := httptest.NewRecorder()
+	srv.echo.ServeHTTP(rec, asAdmin(t, mockAuthManager, httptest.NewRequest(http.MethodGet, "/reports/denials?from="+from, nil)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var report struct {
+		Categories []audit.DenialStats `json:"categories"`
+		Total      int                 `json:"total"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+		t.Fatalf("failed to parse report: %v", err)
+	}
+	if report.Total != 3 || len(report.Categories) != 2 || report.Categories[0] != (audit.DenialStats{Category: audit.DenyApprovalTimeout, Count: 2}) {
+		t.Errorf("unexpected report: %+v", report)
+	}
+
+	rec = httptest.NewRecorder()
+	srv.echo.ServeHTTP(rec, asAdmin(t, mockAuthManager, httptest.NewRequest(http.MethodGet, "/reports/denials?to=yesterday", nil)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for invalid to, got %d", rec.Code)
+	}
+}
+
+func TestUnmatchedRoutesReturnJSON(t *testing.T) {
+	mockAuthManager := auth.NewManager(auth.Config{RequireAuth: false, JWTSecret: "test-secret"})
+	srv := New(Config{Port: 8080}, &mockPolicyEvaluator{}, &mockAuditStore{}, &mockApprovalQueue{}, mockAuthManager)
+
+	tests := []struct {
+		name         string
+		method       string
+		path         string
+		expectStatus int
+		expectCode   string
+	}{
+		{name: "unknown route", method: http.MethodGet, path: "/does-not-exist", expectStatus: http.StatusNotFound, expectCode: "NOT_FOUND"},
+		// The protected group's catch-all answers wrong methods with 404
+		{name: "wrong method", method: http.MethodPost, path: "/health", expectStatus: http.StatusNotFound, expectCode: "NOT_FOUND"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			rec := httptest.NewRecorder()
+
+			srv.echo.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectStatus {
+				t.Errorf("expected status %d, got %d", tt.expectStatus, rec.Code)
+			}
+			if ct := rec.Header().Get(echo.HeaderContentType); !strings.HasPrefix(ct, echo.MIMEApplicationJSON) {
+				t.Errorf("expected JSON content type, got %q", ct)
+			}
+
+			var body map[string]string
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("expected JSON body, got %q: %v", rec.Body.String(), err)
+			}
+			if body["code"] != tt.expectCode || body["error"] == "" {
+				t.Errorf("expected code %s with a message, got %v", tt.expectCode, body)
+			}
+		})
+	}
+}
+
+func TestErrorHandlerRendersMethodNotAllowed(t *testing.T) {
+	mockAuthManager := auth.NewManager(auth.Config{RequireAuth: false, JWTSecret: "test-secret"})
+	srv := New(Config{Port: 8080}, &mockPolicyEvaluator{}, &mockAuditStore{}, &mockApprovalQueue{}, mockAuthManager)
+
+	req := httptest.NewRequest(http.MethodPatch, "/health", nil)
+	rec := httptest.NewRecorder()
+	srv.handleError(echo.ErrMethodNotAllowed, srv.echo.NewContext(req, rec))
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected status 405, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), `"code":"METHOD_NOT_ALLOWED"`) {
+		t.Errorf("expected JSON error code, got %s", rec.Body.String())
+	}
+}
+
+func TestErrorHandlerHidesInternalErrors(t *testing.T) {
+	mockAuthManager := auth.NewManager(auth.Config{RequireAuth: false, JWTSecret: "test-secret"})
+	srv := New(Config{Port: 8080}, &mockPolicyEvaluator{}, &mockAuditStore{}, &mockApprovalQueue{}, mockAuthManager)
+	srv.echo.GET("/boom", func(c echo.Context) error {
+		return fmt.Errorf("database password is hunter2")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/boom", nil)
+	rec := httptest.NewRecorder()
+	srv.echo.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected status 500, got %d", rec.Code)
+	}
+	if strings.Contains(rec.Body.String(), "hunter2") {
+		t.Errorf("internal error detail leaked: %s", rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), `"code":"INTERNAL_SERVER_ERROR"`) {
+		t.Errorf("expected JSON error code, got %s", rec.Body.String())
+	}
+}
+
+func TestCORSDiffersForUIAndAPI(t *testing.T) {
+	queue := approval.NewInMemoryQueue(time.Second)
+	defer queue.Close()
+
+	authManager := auth.NewManager(auth.Config{RequireAuth: false, JWTSecret: "test-secret"})
+	srv := New(Config{
+		Port:          8080,
+		CORSOrigins:   []string{"https://console.example.com"},
+		UICORSOrigins: []string{"*"},
+	}, &mockPolicyEvaluator{}, &mockAuditStore{}, queue, authManager)
+
+	request := func(path, origin string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set(echo.HeaderOrigin, origin)
+		rec := httptest.NewRecorder()
+		srv.echo.ServeHTTP(rec, req)
+		return rec
+	}
+
+	ui := request("/ui/app.js", "https://anywhere.example.org")
+	if got := ui.Header().Get(echo.HeaderAccessControlAllowOrigin); got != "*" {
+		t.Errorf("expected open CORS for UI assets, got %q", got)
+	}
+	if ui.Header().Get(echo.HeaderAccessControlAllowCredentials) != "" {
+		t.Error("expected UI assets not to allow credentials")
+	}
+
+	api := request("/pending", "https://anywhere.example.org")
+	if got := api.Header().Get(echo.HeaderAccessControlAllowOrigin); got != "" {
+		t.Errorf("expected API to reject an unlisted origin, got %q", got)
+	}
+
+	allowed := request("/pending", "https://console.example.com")
+	if got := allowed.Header().Get(echo.HeaderAccessControlAllowOrigin); got != "https://console.example.com" {
+		t.Errorf("expected API to allow the configured origin, got %q", got)
+	}
+	if allowed.Header().Get(echo.HeaderAccessControlAllowCredentials) != "true" {
+		t.Error("expected API to allow credentials")
+	}
+}
+
+func TestAuditStreamSendsNewEntries(t *testing.T) {
+	store, err := audit.NewSQLiteStore(filepath.Join(t.TempDir(), "audit.db"))
+	if err != nil {
+		t.Fatalf("failed to open audit store: %v", err)
+	}
+	defer store.Close()
+	store.Log(context.Background(), json.RawMessage(`{"tool_name":"old"}`), audit.DecisionAllow, "already seen")
+
+	mockAuthManager := auth.NewManager(auth.Config{RequireAuth: true, JWTSecret: "test-secret"})
+	srv := New(Config{Port: 8080}, &mockPolicyEvaluator{}, store, &mockApprovalQueue{}, mockAuthManager)
+
+	ts := httptest.NewServer(srv.echo)
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Resume after the existing entry, so only the new write is streamed
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"/audit/stream?from=1", nil)
+	resp, err := http.DefaultClient.Do(asAdmin(t, mockAuthManager, req))
+	if err != nil {
+		t.Fatalf("failed to open stream: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get(echo.HeaderContentType); ct != "text/event-stream" {
+		t.Fatalf("expected text/event-stream, got %q", ct)
+	}
+
+	callReq, _ := http.NewRequest(http.MethodPost, ts.URL+"/tool/call", strings.NewReader(`{"tool_name":"fresh","args":{}}`))
+	callReq.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	call, err := http.DefaultClient.Do(asAdmin(t, mockAuthManager, callReq))
+	if err != nil {
+		t.Fatalf("tool call failed: %v", err)
+	}
+	call.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	var id, data string
+	for scanner.Scan() {
+		line := scanner.Text()
+		if v, ok := strings.CutPrefix(line, "id: "); ok {
+			id = v
+		}
+		if v, ok := strings.CutPrefix(line, "data: "); ok {
+			data = v
+			break
+		}
+	}
+	if data == "" {
+		t.Fatalf("no event received: %v", scanner.Err())
+	}
+
+	if id != "2" {
+		t.Errorf("expected event id 2, got %q", id)
+	}
+	var entry audit.Entry
+	if err := json.Unmarshal([]byte(data), &entry); err != nil {
+		t.Fatalf("failed to parse event data: %v", err)
+	}
+	if !strings.Contains(string(entry.ToolInput), "fresh") {
+		t.Errorf("expected the new entry, got %+v", entry)
+	}
+}
+
+func TestCORSPreflightCachesAndAllowsPatch(t *testing.T) {
+	authManager := auth.NewManager(auth.Config{RequireAuth: false, JWTSecret: "test-secret"})
+	srv := New(Config{
+		Port:        8080,
+		CORSOrigins: []string{"https://console.example.com"},
+		CORSMaxAge:  900,
+	}, &mockPolicyEvaluator{}, &mockAuditStore{}, &mockApprovalQueue{}, authManager)
+
+	req := httptest.NewRequest(http.MethodOptions, "/tool/call", nil)
+	req.Header.Set(echo.HeaderOrigin, "https://console.example.com")
+	req.Header.Set(echo.HeaderAccessControlRequestMethod, http.MethodPatch)
+	rec := httptest.NewRecorder()
+	srv.echo.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected preflight status 204, got %d", rec.Code)
+	}
+	if got := rec.Header().Get(echo.HeaderAccessControlMaxAge); got != "900" {
+		t.Errorf("expected max-age 900, got %q", got)
+	}
+	methods := rec.Header().Get(echo.HeaderAccessControlAllowMethods)
+	for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions} {
+		if !strings.Contains(methods, method) {
+			t.Errorf("expected %s in allowed methods, got %q", method, methods)
+		}
+	}
+}
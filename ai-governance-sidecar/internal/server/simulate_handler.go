@@ -0,0 +1,118 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/approval"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// Strategies for POST /approvals/simulate
+const (
+	SimulateApproveAll = "approve_all"
+	SimulateDenyAll    = "deny_all"
+	SimulateRandom     = "random"
+)
+
+// simulatedApprover is recorded as the approver of simulated decisions
+const simulatedApprover = "simulator"
+
+// SimulateRequest is the body of POST /approvals/simulate
+type SimulateRequest struct {
+	Strategy string `json:"strategy"`
+	// ApproveRatio is the share of requests the random strategy approves;
+	// zero means half
+	ApproveRatio float64 `json:"approve_ratio,omitempty"`
+	// Limit caps how many pending requests are decided; zero decides all
+	Limit int `json:"limit,omitempty"`
+}
+
+// SimulateResult counts what a simulation pass decided. Skipped requests
+// were decided by someone else, or timed out, while the pass ran.
+type SimulateResult struct {
+	Strategy string `json:"strategy"`
+	Approved int    `json:"approved"`
+	Denied   int    `json:"denied"`
+	Skipped  int    `json:"skipped"`
+}
+
+// Simulate decides pending approvals without a human so the approval flow
+// can be load-tested. It is refused unless SIMULATION_ENABLED is set.
+func (h *ApprovalHandler) Simulate(c echo.Context) error {
+	if !h.simulation {
+		return c.JSON(http.StatusForbidden, map[string]string{
+			"error": "approval simulation is disabled; set SIMULATION_ENABLED=true outside production",
+		})
+	}
+
+	var req SimulateRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid request body",
+		})
+	}
+
+	approve, err := h.simulatedVerdict(req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	ctx := c.Request().Context()
+	pending, err := h.queue.GetPending(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to get pending approvals")
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "failed to retrieve pending approvals",
+		})
+	}
+	if req.Limit > 0 && len(pending) > req.Limit {
+		pending = pending[:req.Limit]
+	}
+
+	result := SimulateResult{Strategy: req.Strategy}
+	for _, p := range pending {
+		decision := approval.Decision{
+			Approved:  approve(),
+			Reason:    "simulated decision (" + req.Strategy + ")",
+			DecidedBy: simulatedApprover,
+		}
+		if err := h.queue.Decide(ctx, p.ID, decision); err != nil {
+			result.Skipped++
+			continue
+		}
+		if decision.Approved {
+			result.Approved++
+		} else {
+			result.Denied++
+		}
+	}
+
+	log.Warn().Str("strategy", req.Strategy).Int("approved", result.Approved).Int("denied", result.Denied).
+		Msg("simulated approval decisions")
+	return c.JSON(http.StatusOK, result)
+}
+
+// simulatedVerdict returns the decision function for a strategy
+func (h *ApprovalHandler) simulatedVerdict(req SimulateRequest) (func() bool, error) {
+	switch req.Strategy {
+	case SimulateApproveAll:
+		return func() bool { return true }, nil
+	case SimulateDenyAll:
+		return func() bool { return false }, nil
+	case SimulateRandom:
+		ratio := req.ApproveRatio
+		if ratio < 0 || ratio > 1 {
+			return nil, fmt.Errorf("approve_ratio must be between 0 and 1")
+		}
+		if ratio == 0 {
+			ratio = 0.5
+		}
+		return func() bool { return h.random() < ratio }, nil
+	default:
+		return nil, fmt.Errorf("strategy must be %s, %s or %s", SimulateApproveAll, SimulateDenyAll, SimulateRandom)
+	}
+}
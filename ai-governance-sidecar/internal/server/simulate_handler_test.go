@@ -0,0 +1,130 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/approval"
+	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
+	"github.com/labstack/echo/v4"
+)
+
+// enqueuePending queues n requests and waits until all are pending. The
+// returned channel yields each request's decision.
+func enqueuePending(t *testing.T, queue *approval.InMemoryQueue, n int) <-chan approval.Decision {
+	t.Helper()
+
+	decisions := make(chan approval.Decision, n)
+	for i := 0; i < n; i++ {
+		go func() {
+			decision, _ := queue.Enqueue(context.Background(), policy.Request{ToolName: "deploy", Args: json.RawMessage(`{}`)}, "review")
+			decisions <- decision
+		}()
+	}
+
+	for i := 0; i < 100; i++ {
+		pending, _ := queue.GetPending(context.Background())
+		if len(pending) == n {
+			return decisions
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("expected %d pending requests", n)
+	return nil
+}
+
+func simulate(t *testing.T, handler *ApprovalHandler, body string) (*httptest.ResponseRecorder, SimulateResult) {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/approvals/simulate", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+
+	if err := handler.Simulate(echo.New().NewContext(req, rec)); err != nil {
+		t.Fatalf("handler failed: %v", err)
+	}
+
+	var result SimulateResult
+	if rec.Code == http.StatusOK {
+		if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+			t.Fatalf("failed to parse response: %v", err)
+		}
+	}
+	return rec, result
+}
+
+func TestSimulateResolvesPendingRequests(t *testing.T) {
+	tests := []struct {
+		name           string
+		strategy       string
+		random         []float64
+		expectApproved int
+	}{
+		{name: "approve all", strategy: SimulateApproveAll, expectApproved: 4},
+		{name: "deny all", strategy: SimulateDenyAll, expectApproved: 0},
+		{name: "random", strategy: SimulateRandom, random: []float64{0.1, 0.9, 0.2, 0.8}, expectApproved: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queue := approval.NewInMemoryQueue(5 * time.Second)
+			defer queue.Close()
+
+			handler := NewApprovalHandler(queue, 0, OverflowReject)
+			handler.simulation = true
+			draws := tt.random
+			handler.random = func() float64 {
+				next := draws[0]
+				draws = draws[1:]
+				return next
+			}
+
+			decisions := enqueuePending(t, queue, 4)
+
+			rec, result := simulate(t, handler, `{"strategy":"`+tt.strategy+`"}`)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+			}
+			if result.Approved != tt.expectApproved || result.Denied != 4-tt.expectApproved {
+				t.Errorf("expected %d approved, got %+v", tt.expectApproved, result)
+			}
+
+			approved := 0
+			for i := 0; i < 4; i++ {
+				decision := <-decisions
+				if decision.DecidedBy != simulatedApprover {
+					t.Errorf("expected decision by %q, got %+v", simulatedApprover, decision)
+				}
+				if decision.Approved {
+					approved++
+				}
+			}
+			if approved != tt.expectApproved {
+				t.Errorf("expected %d waiters approved, got %d", tt.expectApproved, approved)
+			}
+		})
+	}
+}
+
+func TestSimulateRequiresFlagAndStrategy(t *testing.T) {
+	queue := approval.NewInMemoryQueue(5 * time.Second)
+	defer queue.Close()
+
+	disabled := NewApprovalHandler(queue, 0, OverflowReject)
+	if rec, _ := simulate(t, disabled, `{"strategy":"approve_all"}`); rec.Code != http.StatusForbidden {
+		t.Errorf("expected 403 without SIMULATION_ENABLED, got %d", rec.Code)
+	}
+
+	enabled := NewApprovalHandler(queue, 0, OverflowReject)
+	enabled.simulation = true
+	for _, body := range []string{`{"strategy":"maybe"}`, `{"strategy":"random","approve_ratio":2}`} {
+		if rec, _ := simulate(t, enabled, body); rec.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 for %s, got %d", body, rec.Code)
+		}
+	}
+}
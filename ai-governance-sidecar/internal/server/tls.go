@@ -0,0 +1,75 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Client certificate modes when TLSClientCAFile is set
+const (
+	// ClientAuthRequire refuses the handshake without a trusted certificate
+	ClientAuthRequire = "require"
+	// ClientAuthOptional verifies a certificate when one is sent and lets
+	// clients without one authenticate with a bearer token
+	ClientAuthOptional = "optional"
+)
+
+// TLSConfig builds the listener's TLS settings from the configured files.
+// It returns nil when TLSCertFile is empty, in which case the server
+// speaks plain HTTP.
+func (c Config) TLSConfig() (*tls.Config, error) {
+	if c.TLSCertFile == "" {
+		if c.TLSKeyFile != "" || c.TLSClientCAFile != "" {
+			return nil, errors.New("TLS_KEY_FILE and TLS_CLIENT_CA_FILE require TLS_CERT_FILE")
+		}
+		return nil, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(c.TLSCertFile, c.TLSKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("load TLS certificate: %w", err)
+	}
+
+	cfg := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if c.TLSClientCAFile == "" {
+		return cfg, nil
+	}
+
+	pem, err := os.ReadFile(c.TLSClientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("read TLS client CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("TLS client CA %s holds no PEM certificates", c.TLSClientCAFile)
+	}
+	cfg.ClientCAs = pool
+
+	switch c.TLSClientAuth {
+	case ClientAuthRequire, "":
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	case ClientAuthOptional:
+		cfg.ClientAuth = tls.VerifyClientCertIfGiven
+	default:
+		return nil, fmt.Errorf("TLS_CLIENT_AUTH must be %s or %s, got %q", ClientAuthRequire, ClientAuthOptional, c.TLSClientAuth)
+	}
+	return cfg, nil
+}
+
+// startTLS serves HTTPS on addr with the same timeouts as plain HTTP
+func (s *Server) startTLS(addr string, cfg *tls.Config) error {
+	srv := s.echo.TLSServer
+	srv.Addr = addr
+	srv.TLSConfig = cfg
+	srv.ReadTimeout = s.echo.Server.ReadTimeout
+	srv.ReadHeaderTimeout = s.echo.Server.ReadHeaderTimeout
+	srv.WriteTimeout = s.echo.Server.WriteTimeout
+	srv.IdleTimeout = s.echo.Server.IdleTimeout
+	return s.echo.StartServer(srv)
+}
@@ -0,0 +1,43 @@
+package server
+
+import (
+	"testing"
+)
+
+func TestTLSConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr bool
+	}{
+		{name: "plain HTTP"},
+		{
+			name:    "client CA without a server cert",
+			config:  Config{TLSClientCAFile: "ca.pem"},
+			wantErr: true,
+		},
+		{
+			name:    "missing cert files",
+			config:  Config{TLSCertFile: "missing.pem", TLSKeyFile: "missing.key"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := tt.config.TLSConfig()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if cfg != nil {
+				t.Error("expected no TLS config without TLS_CERT_FILE")
+			}
+		})
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !embedui
+
+package server
+
+import "io/fs"
+
+// embeddedUI returns nil: the UI is only compiled in with -tags embedui
+func embeddedUI() fs.FS {
+	return nil
+}
@@ -0,0 +1,22 @@
+//go:build embedui
+
+package server
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// uiDist holds the production UI build, copied to web/dist before building
+// with -tags embedui
+//
+//go:embed all:web/dist
+var uiDist embed.FS
+
+func embeddedUI() fs.FS {
+	assets, err := fs.Sub(uiDist, "web/dist")
+	if err != nil {
+		return nil
+	}
+	return assets
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"bytes"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// uiIndex is the entry point of the built UI
+const uiIndex = "index.html"
+
+// UIHandler serves the React UI under /ui. Without a built UI it serves a
+// short page pointing at the API instead.
+type UIHandler struct {
+	// assets is nil when no UI was embedded
+	assets fs.FS
+}
+
+// NewUIHandler serves assets, which must contain index.html at its root.
+// A nil FS, or one without index.html, falls back to the placeholder page.
+func NewUIHandler(assets fs.FS) *UIHandler {
+	if assets != nil {
+		if _, err := fs.Stat(assets, uiIndex); err != nil {
+			log.Warn().Msg("embedded UI has no index.html, serving placeholder page")
+			assets = nil
+		}
+	}
+	return &UIHandler{assets: assets}
+}
+
+// Serve returns the requested asset. Paths without a file extension are
+// client-side routes and get index.html; missing assets are a 404.
+func (h *UIHandler) Serve(c echo.Context) error {
+	if h.assets == nil {
+		return c.HTML(http.StatusOK, uiPlaceholderPage)
+	}
+
+	name := strings.TrimPrefix(path.Clean("/"+c.Param("*")), "/")
+	if name == "" {
+		return h.serveFile(c, uiIndex)
+	}
+
+	if info, err := fs.Stat(h.assets, name); err == nil && !info.IsDir() {
+		return h.serveFile(c, name)
+	}
+	if path.Ext(name) != "" {
+		return echo.ErrNotFound
+	}
+	return h.serveFile(c, uiIndex)
+}
+
+func (h *UIHandler) serveFile(c echo.Context, name string) error {
+	data, err := fs.ReadFile(h.assets, name)
+	if err != nil {
+		return echo.ErrNotFound
+	}
+	http.ServeContent(c.Response(), c.Request(), name, time.Time{}, bytes.NewReader(data))
+	return nil
+}
+
+const uiPlaceholderPage = `<!DOCTYPE html>
+<html>
+<head>
+	<title>AI Governance Sidecar</title>
+	<meta charset="UTF-8">
+	<meta name="viewport" content="width=device-width, initial-scale=1.0">
+</head>
+<body>
+	<div id="root">
+		<h1>AI Governance Sidecar</h1>
+		<p>This build does not include the UI. Build it with <code>-tags embedui</code> (see UI_DEPLOYMENT.md), or use the API endpoints:</p>
+		<ul>
+			<li>POST /login - Login to get JWT token</li>
+			<li>GET /me - Get current user info</li>
+			<li>GET /pending - View pending approvals (auth required)</li>
+			<li>POST /approve/:id - Approve/deny requests (approver or admin)</li>
+			<li>GET /audit - View audit log (auth required)</li>
+		</ul>
+	</div>
+</body>
+</html>
+`
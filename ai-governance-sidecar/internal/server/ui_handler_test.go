@@ -0,0 +1,88 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/auth"
+	"github.com/labstack/echo/v4"
+)
+
+func serveUI(t *testing.T, handler *UIHandler, target string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	e := echo.New()
+	e.GET("/ui", handler.Serve)
+	e.GET("/ui/*", handler.Serve)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	return rec
+}
+
+func TestUIHandlerServesEmbeddedBuild(t *testing.T) {
+	handler := NewUIHandler(fstest.MapFS{
+		"index.html":        {Data: []byte(`<div id="root"></div>`)},
+		"static/js/main.js": {Data: []byte(`console.log("ui")`)},
+	})
+
+	tests := []struct {
+		name         string
+		target       string
+		expectStatus int
+		expectBody   string
+	}{
+		{name: "root", target: "/ui", expectStatus: http.StatusOK, expectBody: `<div id="root">`},
+		{name: "asset", target: "/ui/static/js/main.js", expectStatus: http.StatusOK, expectBody: `console.log`},
+		{name: "client route", target: "/ui/approvals/123", expectStatus: http.StatusOK, expectBody: `<div id="root">`},
+		{name: "missing asset", target: "/ui/static/js/missing.js", expectStatus: http.StatusNotFound},
+		{name: "path escape", target: "/ui/../../go.mod", expectStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serveUI(t, handler, tt.target)
+			if rec.Code != tt.expectStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectStatus, rec.Code)
+			}
+			if !strings.Contains(rec.Body.String(), tt.expectBody) {
+				t.Errorf("expected body to contain %q, got %q", tt.expectBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestUIHandlerFallsBackWithoutBuild(t *testing.T) {
+	for name, handler := range map[string]*UIHandler{
+		"no embed":      NewUIHandler(nil),
+		"empty embed":   NewUIHandler(fstest.MapFS{}),
+		"missing index": NewUIHandler(fstest.MapFS{"static/js/main.js": {Data: []byte(`x`)}}),
+	} {
+		t.Run(name, func(t *testing.T) {
+			for _, target := range []string{"/ui", "/ui/static/js/main.js"} {
+				rec := serveUI(t, handler, target)
+				if rec.Code != http.StatusOK {
+					t.Fatalf("%s: expected status 200, got %d", target, rec.Code)
+				}
+				if !strings.Contains(rec.Body.String(), "does not include the UI") {
+					t.Errorf("%s: expected placeholder page, got %q", target, rec.Body.String())
+				}
+			}
+		})
+	}
+}
+
+func TestUIRouteServesPlaceholderByDefault(t *testing.T) {
+	authManager := auth.NewManager(auth.Config{RequireAuth: false, JWTSecret: "test-secret"})
+	srv := New(Config{Port: 8080}, &mockPolicyEvaluator{}, &mockAuditStore{}, &mockApprovalQueue{}, authManager)
+
+	rec := httptest.NewRecorder()
+	srv.echo.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ui", nil))
+
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "AI Governance Sidecar") {
+		t.Errorf("expected placeholder page, got %d %q", rec.Code, rec.Body.String())
+	}
+}
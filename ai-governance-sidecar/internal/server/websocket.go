@@ -2,54 +2,119 @@ package server
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 	"sync"
+	"time"
 
 	"github.com/dagbolade/ai-governance-sidecar/internal/approval"
+	"github.com/dagbolade/ai-governance-sidecar/internal/redaction"
 	"github.com/gorilla/websocket"
 	"github.com/labstack/echo/v4"
 	"github.com/rs/zerolog/log"
 )
 
-var upgrader = websocket.Upgrader{
-	CheckOrigin: func(r *http.Request) bool {
-		return true // Allow all origins in development
-	},
+func newUpgrader() websocket.Upgrader {
+	return websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool {
+			return true // Allow all origins in development
+		},
+	}
 }
 
+// pendingBroadcastInterval is the minimum gap between pending_update
+// broadcasts; triggers inside the window are coalesced into one.
+const pendingBroadcastInterval = 250 * time.Millisecond
+
+// errPendingUnchanged skips a broadcast of an identical pending set
+var errPendingUnchanged = errors.New("pending set unchanged")
+
 type WSHandler struct {
-	queue   approval.Queue
-	clients map[*websocket.Conn]bool
-	mu      sync.RWMutex
+	queue    approval.Queue
+	hub      *Hub
+	upgrader websocket.Upgrader
+
+	throttleMu    sync.Mutex
+	minInterval   time.Duration
+	lastBroadcast time.Time
+	scheduled     bool
+
+	// lastDigest is only accessed under the hub lock
+	lastDigest string
+
+	// redactor masks secrets in the arguments of broadcast requests
+	redactor *redaction.Redactor
 }
 
 func NewWSHandler(queue approval.Queue) *WSHandler {
 	handler := &WSHandler{
-		queue:   queue,
-		clients: make(map[*websocket.Conn]bool),
+		queue:       queue,
+		hub:         NewHub(),
+		upgrader:    newUpgrader(),
+		minInterval: pendingBroadcastInterval,
 	}
-	
+
 	go handler.watchApprovals()
-	
+	go handler.watchEvents()
+
 	return handler
 }
 
+// EnableCompression offers per-message deflate to clients. Clients that do
+// not negotiate it keep receiving uncompressed frames.
+func (h *WSHandler) EnableCompression(enabled bool) {
+	h.upgrader.EnableCompression = enabled
+}
+
+// SetRedactor masks matching argument values in the pending requests sent
+// to clients
+func (h *WSHandler) SetRedactor(r *redaction.Redactor) {
+	h.redactor = r
+}
+
+// SetMaxConnections caps concurrent WebSocket clients; upgrades beyond it
+// are refused with 503. Zero means no limit.
+func (h *WSHandler) SetMaxConnections(n int) {
+	h.hub.SetMaxConnections(n)
+}
+
 func (h *WSHandler) HandleWebSocket(c echo.Context) error {
-	ws, err := upgrader.Upgrade(c.Response(), c.Request(), nil)
+	since, err := parseSince(c.QueryParam("since"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "invalid since cursor",
+		})
+	}
+
+	if !h.hub.Acquire() {
+		log.Warn().Msg("websocket connection limit reached, rejecting client")
+		return c.JSON(http.StatusServiceUnavailable, map[string]string{
+			"error": "too many websocket connections",
+		})
+	}
+	defer h.hub.Release()
+
+	ws, err := h.upgrader.Upgrade(c.Response(), c.Request(), nil)
 	if err != nil {
 		log.Error().Err(err).Msg("websocket upgrade failed")
 		return err
 	}
 	defer ws.Close()
 
-	h.addClient(ws)
-	defer h.removeClient(ws)
+	client := newWSClient(ws)
+	h.hub.Register(client, since)
+	defer h.hub.Unregister(client)
 
-	log.Info().Msg("websocket client connected")
+	go h.writePump(client)
+
+	log.Info().Uint64("since", since).Msg("websocket client connected")
 
 	// Send current pending approvals
-	if err := h.sendPending(ws); err != nil {
+	if err := h.sendPending(client); err != nil {
 		log.Error().Err(err).Msg("failed to send pending approvals")
 		return err
 	}
@@ -65,58 +130,144 @@ func (h *WSHandler) HandleWebSocket(c echo.Context) error {
 		}
 	}
 
+	log.Info().Msg("websocket client disconnected")
 	return nil
 }
 
+func (h *WSHandler) writePump(client *wsClient) {
+	for data := range client.send {
+		if err := client.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+			log.Warn().Err(err).Msg("failed to write to websocket client")
+			client.conn.Close()
+			return
+		}
+	}
+}
+
 func (h *WSHandler) watchApprovals() {
 	if q, ok := h.queue.(*approval.InMemoryQueue); ok {
 		notifyCh := q.NotifyChannel()
 		for range notifyCh {
-			h.broadcastPending()
+			h.requestPendingBroadcast()
 		}
 	}
 }
 
-func (h *WSHandler) broadcastPending() {
-	h.mu.RLock()
-	defer h.mu.RUnlock()
-
-	for client := range h.clients {
-		if err := h.sendPending(client); err != nil {
-			log.Warn().Err(err).Msg("failed to broadcast to client")
+func (h *WSHandler) watchEvents() {
+	if q, ok := h.queue.(*approval.InMemoryQueue); ok {
+		for event := range q.Events() {
+			h.broadcastEvent(event)
+			h.requestPendingBroadcast()
 		}
 	}
 }
 
-func (h *WSHandler) sendPending(ws *websocket.Conn) error {
+func (h *WSHandler) broadcastEvent(event approval.Event) {
+	fields := map[string]interface{}{
+		"id":        event.Request.ID,
+		"tool_name": event.Request.ToolName,
+		"status":    event.Request.Status,
+		"time":      event.Time,
+	}
+	if event.Decision != nil {
+		fields["decision"] = event.Decision
+	}
+
+	h.hub.Broadcast("approval_"+string(event.Type), fields, true)
+}
+
+// requestPendingBroadcast rate-limits pending_update broadcasts. A trigger
+// inside the interval schedules one trailing broadcast, so the last change
+// always reaches clients.
+func (h *WSHandler) requestPendingBroadcast() {
+	h.throttleMu.Lock()
+	if h.scheduled {
+		h.throttleMu.Unlock()
+		return
+	}
+
+	wait := h.minInterval - time.Since(h.lastBroadcast)
+	if wait <= 0 {
+		h.lastBroadcast = time.Now()
+		h.throttleMu.Unlock()
+		h.broadcastPending()
+		return
+	}
+
+	h.scheduled = true
+	h.throttleMu.Unlock()
+
+	time.AfterFunc(wait, func() {
+		h.throttleMu.Lock()
+		h.scheduled = false
+		h.lastBroadcast = time.Now()
+		h.throttleMu.Unlock()
+		h.broadcastPending()
+	})
+}
+
+func (h *WSHandler) broadcastPending() {
+	err := h.hub.BroadcastLoaded("pending_update", h.loadPendingChange)
+	if err != nil && !errors.Is(err, errPendingUnchanged) {
+		log.Warn().Err(err).Msg("failed to load pending approvals for broadcast")
+	}
+}
+
+// loadPendingChange is loadPending for broadcasts: it fails with
+// errPendingUnchanged when the ids and statuses match the last broadcast.
+func (h *WSHandler) loadPendingChange() (map[string]interface{}, error) {
 	pending, err := h.queue.GetPending(context.Background())
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	msg := map[string]interface{}{
-		"type":    "pending_update",
-		"total":   len(pending),
-		"pending": pending,
+	digest := pendingDigest(pending)
+	if digest == h.lastDigest {
+		return nil, errPendingUnchanged
 	}
+	h.lastDigest = digest
+
+	return pendingFields(redactRequests(h.redactor, pending)), nil
+}
 
-	data, err := json.Marshal(msg)
+// sendPending sends the initial snapshot. The client is already registered,
+// and the snapshot is taken under the hub lock, so a decision made while
+// connecting is either in the snapshot or in a later broadcast.
+func (h *WSHandler) sendPending(client *wsClient) error {
+	return h.hub.SendLoaded(client, "pending_update", h.loadPending)
+}
+
+// loadPending runs under the hub lock. The queue never blocks on the hub
+// while holding its own locks, so this cannot deadlock.
+func (h *WSHandler) loadPending() (map[string]interface{}, error) {
+	pending, err := h.queue.GetPending(context.Background())
 	if err != nil {
-		return err
+		return nil, err
 	}
+	return pendingFields(redactRequests(h.redactor, pending)), nil
+}
 
-	return ws.WriteMessage(websocket.TextMessage, data)
+func pendingFields(pending []approval.Request) map[string]interface{} {
+	return map[string]interface{}{
+		"total":   len(pending),
+		"pending": pending,
+	}
 }
 
-func (h *WSHandler) addClient(ws *websocket.Conn) {
-	h.mu.Lock()
-	defer h.mu.Unlock()
-	h.clients[ws] = true
+// pendingDigest changes whenever anything clients are shown changes: a
+// request added or removed, or its status, comments, duplicate count or
+// other fields updated
+func pendingDigest(pending []approval.Request) string {
+	hash := sha256.New()
+	// Requests hold only plain values and valid JSON args, so encoding
+	// cannot fail
+	json.NewEncoder(hash).Encode(pending)
+	return hex.EncodeToString(hash.Sum(nil))
 }
 
-func (h *WSHandler) removeClient(ws *websocket.Conn) {
-	h.mu.Lock()
-	defer h.mu.Unlock()
-	delete(h.clients, ws)
-	log.Info().Msg("websocket client disconnected")
-}
\ No newline at end of file
+func parseSince(value string) (uint64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.ParseUint(value, 10, 64)
+}
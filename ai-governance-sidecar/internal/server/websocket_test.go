@@ -0,0 +1,479 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dagbolade/ai-governance-sidecar/internal/approval"
+	"github.com/dagbolade/ai-governance-sidecar/internal/policy"
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+)
+
+func TestHubReplaysEventsSinceCursor(t *testing.T) {
+	hub := NewHub()
+
+	for _, id := range []string{"a", "b", "c"} {
+		hub.Broadcast("approval_decided", map[string]interface{}{"id": id}, true)
+	}
+	hub.Broadcast("pending_update", map[string]interface{}{"total": 0}, false)
+
+	client := &wsClient{send: make(chan []byte, clientSendBuffer)}
+	hub.Register(client, 1)
+
+	var ids []string
+	for len(client.send) > 0 {
+		var msg map[string]interface{}
+		if err := json.Unmarshal(<-client.send, &msg); err != nil {
+			t.Fatalf("failed to decode message: %v", err)
+		}
+		if msg["type"] != "approval_decided" {
+			t.Errorf("unexpected replayed message type: %v", msg["type"])
+		}
+		ids = append(ids, msg["id"].(string))
+	}
+
+	if strings.Join(ids, ",") != "b,c" {
+		t.Errorf("expected only missed events b,c, got %v", ids)
+	}
+}
+
+func TestHubHistoryIsBounded(t *testing.T) {
+	hub := NewHub()
+
+	for i := 0; i < eventHistorySize+10; i++ {
+		hub.Broadcast("approval_decided", nil, true)
+	}
+
+	if len(hub.history) != eventHistorySize {
+		t.Errorf("expected history of %d, got %d", eventHistorySize, len(hub.history))
+	}
+	if hub.history[0].seq != 11 {
+		t.Errorf("expected oldest retained seq 11, got %d", hub.history[0].seq)
+	}
+}
+
+func TestHubReportsReplayGap(t *testing.T) {
+	hub := NewHub()
+
+	for i := 0; i < eventHistorySize+10; i++ {
+		hub.Broadcast("approval_decided", nil, true)
+	}
+
+	decode := func(data []byte) map[string]interface{} {
+		var msg map[string]interface{}
+		if err := json.Unmarshal(data, &msg); err != nil {
+			t.Fatalf("failed to decode message: %v", err)
+		}
+		return msg
+	}
+
+	// Events 6 to 10 are gone, so the replay from 5 is incomplete
+	behind := &wsClient{send: make(chan []byte, clientSendBuffer)}
+	hub.Register(behind, 5)
+	gap := decode(<-behind.send)
+	if gap["type"] != "replay_gap" || gap["since"] != float64(5) || gap["oldest_seq"] != float64(11) {
+		t.Errorf("expected a replay gap from 5 to 11, got %v", gap)
+	}
+	if n := len(behind.send); n != eventHistorySize {
+		t.Errorf("expected the retained %d events after the gap, got %d", eventHistorySize, n)
+	}
+
+	// Nothing after 10 has been dropped
+	current := &wsClient{send: make(chan []byte, clientSendBuffer)}
+	hub.Register(current, 10)
+	if msg := decode(<-current.send); msg["type"] != "approval_decided" {
+		t.Errorf("expected no gap for a cursor within history, got %v", msg)
+	}
+}
+
+func TestWebSocketReconnectWithSince(t *testing.T) {
+	queue := approval.NewInMemoryQueue(time.Second)
+	defer queue.Close()
+
+	handler := NewWSHandler(queue)
+
+	// Events that happened while the client was disconnected
+	handler.hub.Broadcast("approval_decided", map[string]interface{}{"id": "seen"}, true)
+	handler.hub.Broadcast("approval_decided", map[string]interface{}{"id": "missed-1"}, true)
+	handler.hub.Broadcast("approval_timeout", map[string]interface{}{"id": "missed-2"}, true)
+
+	e := echo.New()
+	e.GET("/ws", handler.HandleWebSocket)
+	srv := httptest.NewServer(e)
+	defer srv.Close()
+
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws?since=1"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	defer conn.Close()
+
+	expected := []struct {
+		msgType string
+		seq     float64
+	}{
+		{"approval_decided", 2},
+		{"approval_timeout", 3},
+		{"pending_update", 3},
+	}
+
+	for _, want := range expected {
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		var msg map[string]interface{}
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatalf("read failed: %v", err)
+		}
+		if msg["type"] != want.msgType || msg["seq"] != want.seq {
+			t.Errorf("expected %s seq %v, got %v seq %v", want.msgType, want.seq, msg["type"], msg["seq"])
+		}
+	}
+}
+
+func TestWebSocketInvalidSince(t *testing.T) {
+	queue := approval.NewInMemoryQueue(time.Second)
+	defer queue.Close()
+
+	handler := NewWSHandler(queue)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/ws?since=abc", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := handler.HandleWebSocket(c); err != nil {
+		t.Fatalf("handler failed: %v", err)
+	}
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", rec.Code)
+	}
+}
+
+func TestHubStatsTracksClients(t *testing.T) {
+	hub := NewHub()
+
+	first := &wsClient{send: make(chan []byte, clientSendBuffer)}
+	second := &wsClient{send: make(chan []byte, clientSendBuffer)}
+
+	hub.Register(first, 0)
+	hub.Register(second, 0)
+	if got := hub.Stats().ConnectedClients; got != 2 {
+		t.Errorf("expected 2 connected clients, got %d", got)
+	}
+
+	hub.Broadcast("pending_update", nil, false)
+	if got := hub.Stats().MessagesSent; got != 2 {
+		t.Errorf("expected 2 messages sent, got %d", got)
+	}
+
+	hub.Unregister(first)
+	if got := hub.Stats().ConnectedClients; got != 1 {
+		t.Errorf("expected 1 connected client after unregister, got %d", got)
+	}
+
+	// Unregistering twice must not double count or panic
+	hub.Unregister(first)
+	hub.Unregister(second)
+	if got := hub.Stats().ConnectedClients; got != 0 {
+		t.Errorf("expected 0 connected clients, got %d", got)
+	}
+}
+
+func TestHubDropsSlowClient(t *testing.T) {
+	hub := NewHub()
+
+	slow := &wsClient{send: make(chan []byte, 1)}
+	hub.Register(slow, 0)
+
+	hub.Broadcast("pending_update", nil, false)
+	hub.Broadcast("pending_update", nil, false)
+
+	stats := hub.Stats()
+	if stats.ConnectedClients != 0 {
+		t.Errorf("expected slow client to be disconnected, got %d clients", stats.ConnectedClients)
+	}
+	if stats.MessagesDropped != 1 || stats.SlowClientsClosed != 1 {
+		t.Errorf("expected 1 dropped message and 1 slow client, got %+v", stats)
+	}
+}
+
+// A decision racing a new connection must never leave the client with a
+// snapshot that still lists the decided request.
+func TestWebSocketConnectThenDecide(t *testing.T) {
+	queue := approval.NewInMemoryQueue(10 * time.Second)
+	defer queue.Close()
+
+	handler := NewWSHandler(queue)
+
+	e := echo.New()
+	e.GET("/ws", handler.HandleWebSocket)
+	srv := httptest.NewServer(e)
+	defer srv.Close()
+
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws"
+	ctx := context.Background()
+
+	for i := 0; i < 25; i++ {
+		go queue.Enqueue(ctx, policy.Request{ToolName: "race_tool", Args: json.RawMessage(`{}`)}, "needs review")
+
+		var id string
+		for deadline := time.Now().Add(2 * time.Second); id == "" && time.Now().Before(deadline); {
+			if pending, _ := queue.GetPending(ctx); len(pending) == 1 {
+				id = pending[0].ID
+			} else {
+				time.Sleep(time.Millisecond)
+			}
+		}
+		if id == "" {
+			t.Fatalf("iteration %d: request never became pending", i)
+		}
+
+		go queue.Decide(ctx, id, approval.Decision{Approved: true, DecidedBy: "tester"})
+
+		conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+		if err != nil {
+			t.Fatalf("dial failed: %v", err)
+		}
+
+		if total := lastPendingTotal(t, conn); total != 0 {
+			t.Errorf("iteration %d: client left with %d pending after decision", i, total)
+		}
+		conn.Close()
+	}
+}
+
+// lastPendingTotal reads until the client has seen an empty pending list and
+// nothing further arrives, returning the last total observed.
+func lastPendingTotal(t *testing.T, conn *websocket.Conn) int {
+	t.Helper()
+
+	total := -1
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		wait := deadline
+		if total == 0 {
+			wait = time.Now().Add(50 * time.Millisecond)
+		}
+		conn.SetReadDeadline(wait)
+
+		var msg map[string]interface{}
+		if err := conn.ReadJSON(&msg); err != nil {
+			return total
+		}
+		if msg["type"] == "pending_update" {
+			total = int(msg["total"].(float64))
+		}
+	}
+}
+
+// pendingListQueue serves a fixed pending list
+type pendingListQueue struct {
+	mockApprovalQueue
+	mu      sync.Mutex
+	pending []approval.Request
+}
+
+func (q *pendingListQueue) GetPending(ctx context.Context) ([]approval.Request, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return append([]approval.Request(nil), q.pending...), nil
+}
+
+func (q *pendingListQueue) set(pending ...approval.Request) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.pending = pending
+}
+
+func TestPendingBroadcastSkipsUnchangedSet(t *testing.T) {
+	queue := &pendingListQueue{}
+	queue.set(approval.Request{ID: "a", Status: approval.StatusPending})
+
+	handler := NewWSHandler(queue)
+	handler.minInterval = 0
+
+	client := &wsClient{send: make(chan []byte, clientSendBuffer)}
+	handler.hub.Register(client, 0)
+
+	handler.requestPendingBroadcast()
+	handler.requestPendingBroadcast()
+	if got := len(client.send); got != 1 {
+		t.Fatalf("expected 1 broadcast for an unchanged pending set, got %d", got)
+	}
+
+	queue.set(approval.Request{ID: "a", Status: approval.StatusPending, Comments: []approval.Comment{{Author: "bob", Text: "checking"}}})
+	handler.requestPendingBroadcast()
+	if got := len(client.send); got != 2 {
+		t.Errorf("expected a broadcast after a comment, got %d messages", got)
+	}
+
+	queue.set(approval.Request{ID: "a", Status: approval.StatusPending, Comments: []approval.Comment{{Author: "bob", Text: "checking"}}, Duplicates: 1})
+	handler.requestPendingBroadcast()
+	if got := len(client.send); got != 3 {
+		t.Errorf("expected a broadcast after a duplicate joined, got %d messages", got)
+	}
+
+	queue.set(approval.Request{ID: "a", Status: approval.StatusApproved})
+	handler.requestPendingBroadcast()
+	if got := len(client.send); got != 4 {
+		t.Errorf("expected a broadcast after a status change, got %d messages", got)
+	}
+}
+
+func TestPendingBroadcastCoalescesBursts(t *testing.T) {
+	queue := &pendingListQueue{}
+	handler := NewWSHandler(queue)
+	handler.minInterval = 50 * time.Millisecond
+
+	client := &wsClient{send: make(chan []byte, clientSendBuffer)}
+	handler.hub.Register(client, 0)
+
+	for i := 0; i < 10; i++ {
+		queue.set(approval.Request{ID: fmt.Sprintf("req-%d", i), Status: approval.StatusPending})
+		handler.requestPendingBroadcast()
+	}
+	if got := len(client.send); got != 1 {
+		t.Fatalf("expected only the leading broadcast inside the window, got %d", got)
+	}
+
+	time.Sleep(150 * time.Millisecond)
+	if got := len(client.send); got != 2 {
+		t.Fatalf("expected one trailing broadcast, got %d messages", got)
+	}
+
+	<-client.send
+	var last map[string]interface{}
+	json.Unmarshal(<-client.send, &last)
+	pending := last["pending"].([]interface{})
+	if id := pending[0].(map[string]interface{})["id"]; id != "req-9" {
+		t.Errorf("expected trailing broadcast to carry the latest state, got %v", id)
+	}
+}
+
+func TestWebSocketCompressionNegotiation(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		t.Run(strconv.FormatBool(enabled), func(t *testing.T) {
+			queue := approval.NewInMemoryQueue(time.Second)
+			defer queue.Close()
+
+			handler := NewWSHandler(queue)
+			handler.EnableCompression(enabled)
+
+			e := echo.New()
+			e.GET("/ws", handler.HandleWebSocket)
+			srv := httptest.NewServer(e)
+			defer srv.Close()
+
+			url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws"
+			dialer := websocket.Dialer{EnableCompression: true}
+			conn, resp, err := dialer.Dial(url, nil)
+			if err != nil {
+				t.Fatalf("dial failed: %v", err)
+			}
+			defer conn.Close()
+
+			negotiated := strings.Contains(resp.Header.Get("Sec-Websocket-Extensions"), "permessage-deflate")
+			if negotiated != enabled {
+				t.Errorf("expected compression negotiated=%v, got extensions %q", enabled, resp.Header.Get("Sec-Websocket-Extensions"))
+			}
+
+			conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+			var msg map[string]interface{}
+			if err := conn.ReadJSON(&msg); err != nil {
+				t.Fatalf("read failed: %v", err)
+			}
+			if msg["type"] != "pending_update" {
+				t.Errorf("expected initial pending_update, got %v", msg["type"])
+			}
+		})
+	}
+}
+
+func TestWebSocketCompressionFallsBackForPlainClients(t *testing.T) {
+	queue := approval.NewInMemoryQueue(time.Second)
+	defer queue.Close()
+
+	handler := NewWSHandler(queue)
+	handler.EnableCompression(true)
+
+	e := echo.New()
+	e.GET("/ws", handler.HandleWebSocket)
+	srv := httptest.NewServer(e)
+	defer srv.Close()
+
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws"
+	conn, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	defer conn.Close()
+
+	if ext := resp.Header.Get("Sec-Websocket-Extensions"); ext != "" {
+		t.Errorf("expected no extensions for a client without compression, got %q", ext)
+	}
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var msg map[string]interface{}
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+}
+
+func TestWebSocketConnectionLimit(t *testing.T) {
+	queue := approval.NewInMemoryQueue(time.Second)
+	defer queue.Close()
+
+	handler := NewWSHandler(queue)
+	handler.SetMaxConnections(2)
+
+	e := echo.New()
+	e.GET("/ws", handler.HandleWebSocket)
+	srv := httptest.NewServer(e)
+	defer srv.Close()
+
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws"
+	for i := 0; i < 2; i++ {
+		conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+		if err != nil {
+			t.Fatalf("dial %d failed: %v", i, err)
+		}
+		defer conn.Close()
+	}
+
+	_, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	if err == nil {
+		t.Fatal("expected the connection over the limit to be refused")
+	}
+	if resp == nil || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected status 503, got %v", resp)
+	}
+	if rejected := handler.hub.Stats().RejectedClients; rejected != 1 {
+		t.Errorf("expected 1 rejected client, got %d", rejected)
+	}
+}
+
+func TestWebSocketConnectionLimitFreesSlots(t *testing.T) {
+	hub := NewHub()
+	hub.SetMaxConnections(1)
+
+	if !hub.Acquire() {
+		t.Fatal("expected first connection to be accepted")
+	}
+	if hub.Acquire() {
+		t.Fatal("expected second connection to be refused")
+	}
+	hub.Release()
+	if !hub.Acquire() {
+		t.Error("expected a released slot to be reusable")
+	}
+}
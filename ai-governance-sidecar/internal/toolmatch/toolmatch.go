@@ -0,0 +1,91 @@
+// Package toolmatch matches tool names against glob patterns such as
+// "db.*" or "fs.read*", so config-driven gates and policy input name
+// families of tools the same way.
+package toolmatch
+
+import (
+	"sort"
+	"strings"
+)
+
+// IsGlob reports whether pattern contains a wildcard
+func IsGlob(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?")
+}
+
+// Match reports whether name matches pattern. "*" matches any run of
+// characters, including none and including dots; "?" matches exactly one.
+// Everything else matches itself, case-sensitively.
+func Match(pattern, name string) bool {
+	p, n := []rune(pattern), []rune(name)
+	pi, ni := 0, 0
+	star, mark := -1, 0
+
+	for ni < len(n) {
+		switch {
+		case pi < len(p) && p[pi] == '*':
+			star, mark = pi, ni
+			pi++
+		case pi < len(p) && (p[pi] == '?' || p[pi] == n[ni]):
+			pi++
+			ni++
+		case star >= 0:
+			// Let the last star absorb one more character and retry
+			pi = star + 1
+			mark++
+			ni = mark
+		default:
+			return false
+		}
+	}
+
+	for pi < len(p) && p[pi] == '*' {
+		pi++
+	}
+	return pi == len(p)
+}
+
+// MatchAny reports whether name matches any of patterns
+func MatchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if Match(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Lookup finds the entry of m that applies to name. An exact key wins;
+// otherwise the most specific matching glob does, the one with the most
+// literal characters, with ties broken alphabetically so the choice never
+// depends on map order. It returns the key that matched.
+func Lookup[V any](m map[string]V, name string) (V, string, bool) {
+	if value, ok := m[name]; ok {
+		return value, name, true
+	}
+
+	var globs []string
+	for key := range m {
+		if IsGlob(key) && Match(key, name) {
+			globs = append(globs, key)
+		}
+	}
+
+	if len(globs) == 0 {
+		var zero V
+		return zero, "", false
+	}
+
+	sort.Slice(globs, func(i, j int) bool {
+		li, lj := literalLen(globs[i]), literalLen(globs[j])
+		if li != lj {
+			return li > lj
+		}
+		return globs[i] < globs[j]
+	})
+	return m[globs[0]], globs[0], true
+}
+
+func literalLen(pattern string) int {
+	return len(pattern) - strings.Count(pattern, "*") - strings.Count(pattern, "?")
+}
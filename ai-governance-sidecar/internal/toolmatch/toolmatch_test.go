@@ -0,0 +1,67 @@
+package toolmatch
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"db.*", "db.query", true},
+		{"db.*", "db.", true},
+		{"db.*", "db.users.delete", true},
+		{"db.*", "db", false},
+		{"db.*", "dbx.query", false},
+		{"fs.read*", "fs.read", true},
+		{"fs.read*", "fs.readdir", true},
+		{"fs.read*", "fs.write", false},
+		{"*.delete", "db.users.delete", true},
+		{"*.delete", "db.users.deleted", false},
+		{"db.?", "db.a", true},
+		{"db.?", "db.ab", false},
+		{"*", "anything", true},
+		{"send_email", "send_email", true},
+		{"send_email", "Send_Email", false},
+		{"a*b*c", "axxbyyc", true},
+		{"a*b*c", "axxbyy", false},
+	}
+
+	for _, tt := range tests {
+		if got := Match(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLookupPrefersExactThenMostSpecific(t *testing.T) {
+	m := map[string]string{
+		"*":         "any",
+		"db.*":      "db",
+		"db.users*": "users",
+		"db.query":  "query",
+	}
+
+	tests := []struct {
+		name    string
+		want    string
+		wantKey string
+	}{
+		{"db.query", "query", "db.query"},
+		{"db.users.delete", "users", "db.users*"},
+		{"db.orders", "db", "db.*"},
+		{"fs.read", "any", "*"},
+	}
+
+	for _, tt := range tests {
+		got, key, ok := Lookup(m, tt.name)
+		if !ok || got != tt.want || key != tt.wantKey {
+			t.Errorf("Lookup(%q) = %q via %q (%v), want %q via %q", tt.name, got, key, ok, tt.want, tt.wantKey)
+		}
+	}
+
+	delete(m, "*")
+	if _, _, ok := Lookup(m, "fs.read"); ok {
+		t.Error("expected no match without a catch-all")
+	}
+}